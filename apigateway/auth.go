@@ -0,0 +1,120 @@
+package main
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// principalHeader names who makes a request to the backends, which trust it as
+// is. The gateway never forwards the header of the client and only sets it for
+// a caller which authenticated with a token.
+const principalHeader = "X-Principal"
+
+// bearerPrefix is the scheme of the Authorization header of the tokens.
+const bearerPrefix = "Bearer "
+
+// principal is a caller of the gateway and its token.
+type principal struct {
+	name  string
+	token string
+}
+
+// parsePrincipals parses the principals of the tokens setting, a list of
+// name=token pairs separated by commas or line breaks.
+func parsePrincipals(s string) ([]principal, error) {
+	var ps []principal
+	names := make(map[string]bool)
+	tokens := make(map[string]bool)
+	for _, f := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == '\n' }) {
+		f = strings.TrimSpace(f)
+		if f == "" {
+			continue
+		}
+
+		i := strings.Index(f, "=")
+		if i < 0 {
+			// the pair is not shown, the token would be in the logs
+			return nil, fmt.Errorf("tokens must be name=token pairs, got one without =")
+		}
+		p := principal{name: strings.TrimSpace(f[:i]), token: strings.TrimSpace(f[i+1:])}
+		if p.name == "" || p.token == "" {
+			return nil, fmt.Errorf("tokens must have a name and a token")
+		}
+		if names[p.name] {
+			return nil, fmt.Errorf("tokens has the principal %q twice", p.name)
+		}
+		if tokens[p.token] {
+			return nil, fmt.Errorf("tokens has the token of %q for another principal as well", p.name)
+		}
+		names[p.name], tokens[p.token] = true, true
+		ps = append(ps, p)
+	}
+
+	return ps, nil
+}
+
+// authenticate drops the principal header of the client and sets it to the
+// principal whose token the request has in the Authorization header. A request
+// without a token is passed on without a principal, one with a token which is
+// not known is refused.
+func authenticate(principals []principal) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r.Header.Del(principalHeader)
+
+			auth := r.Header.Get("Authorization")
+			if auth == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			// the backends do not know the tokens
+			r.Header.Del("Authorization")
+
+			if !strings.HasPrefix(auth, bearerPrefix) {
+				unauthorized(w, "the Authorization header must be a Bearer token")
+				return
+			}
+			name, ok := lookup(principals, strings.TrimSpace(auth[len(bearerPrefix):]))
+			if !ok {
+				unauthorized(w, "unknown token")
+				return
+			}
+
+			r.Header.Set(principalHeader, name)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// lookup returns the name of the principal of the token. Every token is
+// compared in constant time, so the time taken does not tell how much of a
+// token was guessed.
+func lookup(principals []principal, token string) (string, bool) {
+	var name string
+	found := false
+	for _, p := range principals {
+		if subtle.ConstantTimeCompare([]byte(p.token), []byte(token)) == 1 {
+			name, found = p.name, true
+		}
+	}
+	return name, found
+}
+
+// requirePrincipal refuses the requests which authenticate did not set a
+// principal for, like the promotions of releases, which record who made them.
+func requirePrincipal(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(principalHeader) == "" {
+			unauthorized(w, "a token is required, send it in the Authorization header as Bearer <token>")
+			return
+		}
+		next(w, r)
+	}
+}
+
+func unauthorized(w http.ResponseWriter, msg string) {
+	w.Header().Set("WWW-Authenticate", `Bearer realm="conmansys"`)
+	http.Error(w, msg, http.StatusUnauthorized)
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func TestParsePrincipals(t *testing.T) {
+	tt := map[string]struct {
+		s    string
+		want []principal
+		err  bool
+	}{
+		"empty":         {s: ""},
+		"commas":        {s: "alice=a1, bob=b2", want: []principal{{"alice", "a1"}, {"bob", "b2"}}},
+		"lines":         {s: "alice=a1\nbob=b=2\n", want: []principal{{"alice", "a1"}, {"bob", "b=2"}}},
+		"no token":      {s: "alice", err: true},
+		"empty name":    {s: "=a1", err: true},
+		"same name":     {s: "alice=a1,alice=a2", err: true},
+		"same token":    {s: "alice=a1,bob=a1", err: true},
+		"empty entries": {s: ",,alice=a1,", want: []principal{{"alice", "a1"}}},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			ps, err := parsePrincipals(tc.s)
+			if err != nil {
+				if !tc.err {
+					t.Fatalf("expected no error, got: %v", err)
+				}
+				return
+			}
+			if tc.err {
+				t.Fatal("expected an error")
+			}
+
+			if len(ps) != len(tc.want) {
+				t.Fatalf("expected: %v, got: %v", tc.want, ps)
+			}
+			for i := range ps {
+				if ps[i] != tc.want[i] {
+					t.Fatalf("expected: %v, got: %v", tc.want, ps)
+				}
+			}
+		})
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	var forwarded *http.Request
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		forwarded = r
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer up.Close()
+	upURL, _ := url.Parse(up.URL)
+
+	r := mux.NewRouter()
+	r.Use(authenticate([]principal{{"alice", "a1"}, {"bob", "b2"}}))
+	r.HandleFunc("/api/releases/{name}/promote", requirePrincipal(proxyHandler(upURL)))
+	r.HandleFunc("/api/items", proxyHandler(upURL))
+
+	tt := map[string]struct {
+		path      string
+		auth      string
+		principal string
+		status    int
+		want      string
+	}{
+		"token":                {path: "/api/releases/r/promote", auth: "Bearer b2", status: http.StatusOK, want: "bob"},
+		"forged principal":     {path: "/api/releases/r/promote", auth: "Bearer a1", principal: "bob", status: http.StatusOK, want: "alice"},
+		"principal only":       {path: "/api/releases/r/promote", principal: "alice", status: http.StatusUnauthorized},
+		"unknown token":        {path: "/api/releases/r/promote", auth: "Bearer c3", status: http.StatusUnauthorized},
+		"basic auth":           {path: "/api/releases/r/promote", auth: "Basic YWxpY2U6YTE=", status: http.StatusUnauthorized},
+		"anonymous":            {path: "/api/items", status: http.StatusOK},
+		"anonymous principal":  {path: "/api/items", principal: "alice", status: http.StatusOK},
+		"unknown token anyway": {path: "/api/items", auth: "Bearer c3", status: http.StatusUnauthorized},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			forwarded = nil
+			req := httptest.NewRequest(http.MethodPost, tc.path, nil)
+			if tc.auth != "" {
+				req.Header.Set("Authorization", tc.auth)
+			}
+			if tc.principal != "" {
+				req.Header.Set(principalHeader, tc.principal)
+			}
+
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+
+			if rec.Code != tc.status {
+				t.Fatalf("expected status: %v, got: %v", tc.status, rec.Code)
+			}
+			if tc.status != http.StatusOK {
+				if forwarded != nil {
+					t.Fatal("expected the request not to be forwarded")
+				}
+				if rec.Header().Get("WWW-Authenticate") == "" {
+					t.Fatal("expected a WWW-Authenticate header")
+				}
+				return
+			}
+
+			if got := forwarded.Header.Get(principalHeader); got != tc.want {
+				t.Fatalf("expected principal: %q, got: %q", tc.want, got)
+			}
+			if got := forwarded.Header.Get("Authorization"); got != "" {
+				t.Fatalf("expected the token not to be forwarded, got: %q", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Glorforidor/conmansys/config"
+)
+
+func TestLoadConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "config.yaml")
+	content := "addr: :8080\nconfservice_host: conf:80\ninsservice_host: ins:80\nshutdown_timeout: 5s\n"
+	if err := ioutil.WriteFile(file, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	os.Setenv("INSSERVICE_HOST", "ins-env:80")
+	defer os.Unsetenv("INSSERVICE_HOST")
+
+	cfg := defaults()
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	printOnly, err := config.Load(fs, []string{"-config", file, "-addr", ":9090", "-print-config"}, &cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !printOnly {
+		t.Fatal("expected -print-config to be reported")
+	}
+
+	want := defaults()
+	want.Addr = ":9090"
+	want.ConfserviceHost = "conf:80"
+	want.InsserviceHost = "ins-env:80"
+	want.ShutdownTimeout = 5 * time.Second
+	if cfg != want {
+		t.Fatalf("expected: %+v, got: %+v", want, cfg)
+	}
+
+	var buf bytes.Buffer
+	if err := config.Print(&buf, &cfg); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "shutdown_timeout: 5s\n") {
+		t.Fatalf("expected the durations to be printed as read, got:\n%v", buf.String())
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/Glorforidor/conmansys/logging"
+	"github.com/gorilla/mux"
+)
+
+func TestRequestID(t *testing.T) {
+	var forwarded string
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		forwarded = r.Header.Get(logging.Header)
+		// the backends echo the id as well
+		w.Header().Set(logging.Header, forwarded)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer up.Close()
+	upURL, _ := url.Parse(up.URL)
+
+	r := mux.NewRouter()
+	r.Use(logging.Middleware)
+	r.HandleFunc("/api/{name}", proxyHandler(upURL))
+
+	tt := map[string]struct {
+		id   string
+		keep bool
+	}{
+		"accepted":  {id: "abc-123.DEF_4", keep: true},
+		"generated": {id: ""},
+		"invalid":   {id: "no spaces\nor newlines"},
+		"too long":  {id: string(make([]byte, 129))},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			forwarded = ""
+			req := httptest.NewRequest(http.MethodGet, "/api/items", nil)
+			if tc.id != "" {
+				req.Header.Set(logging.Header, tc.id)
+			}
+
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+
+			ids := rec.Header().Values(logging.Header)
+			if len(ids) != 1 {
+				t.Fatalf("expected the request id to be echoed once, got: %q", ids)
+			}
+			if ids[0] != forwarded {
+				t.Fatalf("expected the echoed id %q to be forwarded, got: %q", ids[0], forwarded)
+			}
+			if tc.keep && ids[0] != tc.id {
+				t.Fatalf("expected id: %q, got: %q", tc.id, ids[0])
+			}
+			if !tc.keep && (ids[0] == tc.id || ids[0] == "") {
+				t.Fatalf("expected a new id, got: %q", ids[0])
+			}
+		})
+	}
+}
@@ -2,8 +2,9 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"net/http/httputil"
 	"net/url"
@@ -11,53 +12,155 @@ import (
 	"os/signal"
 	"time"
 
+	"github.com/Glorforidor/conmansys/config"
+	"github.com/Glorforidor/conmansys/health"
+	"github.com/Glorforidor/conmansys/logging"
+	"github.com/Glorforidor/conmansys/metrics"
+	"github.com/Glorforidor/conmansys/tracing"
 	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gorilla/mux/otelmux"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 )
 
+// configuration of the gateway. Every setting is read from the YAML file of
+// -config, the environment variable and the flag of its field, see config.Load.
+type configuration struct {
+	Addr            string        `yaml:"addr" env:"ADDR" flag:"addr" usage:"address of the HTTP server"`
+	ReadTimeout     time.Duration `yaml:"read_timeout" env:"READ_TIMEOUT" flag:"read-timeout" usage:"maximum duration of reading a request"`
+	WriteTimeout    time.Duration `yaml:"write_timeout" env:"WRITE_TIMEOUT" flag:"write-timeout" usage:"maximum duration of writing a response"`
+	IdleTimeout     time.Duration `yaml:"idle_timeout" env:"IDLE_TIMEOUT" flag:"idle-timeout" usage:"how long an idle connection is kept open"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT" flag:"shutdown-timeout" usage:"how long running requests may take to finish at shutdown"`
+	LogLevel        string        `yaml:"log_level" env:"LOG_LEVEL" flag:"log-level" usage:"level of the logs: debug, info, warn or error"`
+	TracesExporter  string        `yaml:"traces_exporter" env:"OTEL_TRACES_EXPORTER" flag:"traces-exporter" usage:"exporter of the spans: otlp, stdout or none"`
+
+	ConfserviceHost string `yaml:"confservice_host" env:"CONFSERVICE_HOST" flag:"confservice-host" usage:"host and port of the confservice"`
+	InsserviceHost  string `yaml:"insservice_host" env:"INSSERVICE_HOST" flag:"insservice-host" usage:"host and port of the insservice"`
+
+	Tokens string `yaml:"tokens" env:"TOKENS" flag:"tokens" secret:"true" usage:"tokens of the principals, name=token pairs separated by commas or line breaks"`
+}
+
+// defaults returns the configuration used for what is not set.
+func defaults() configuration {
+	return configuration{
+		Addr:            ":80",
+		ReadTimeout:     15 * time.Second,
+		WriteTimeout:    15 * time.Second,
+		IdleTimeout:     60 * time.Second,
+		ShutdownTimeout: 15 * time.Second,
+		LogLevel:        "info",
+		TracesExporter:  "none",
+	}
+}
+
 func main() {
-	confserviceHost, ok := os.LookupEnv("CONFSERVICE_HOST")
-	if !ok {
-		log.Fatal("Missing environment variable CONFSERIVCE_HOST")
+	cfg := defaults()
+	printOnly, err := config.Load(flag.CommandLine, os.Args[1:], &cfg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if printOnly {
+		if err := config.Print(os.Stdout, &cfg); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if err := logging.Setup(cfg.LogLevel); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	shutdownTracing, err := tracing.Setup(context.Background(), "apigateway", cfg.TracesExporter)
+	if err != nil {
+		slog.Error("could not set up tracing", "error", err)
+		os.Exit(1)
+	}
+
+	if cfg.ConfserviceHost == "" || cfg.InsserviceHost == "" {
+		slog.Error("confservice_host and insservice_host required but not set")
+		os.Exit(2)
 	}
 
-	insserviceHost, ok := os.LookupEnv("INSSERVICE_HOST")
-	if !ok {
-		log.Fatal("Missing environment variable INSSERIVCE_HOST")
+	confserviceURL, err := url.Parse(fmt.Sprintf("http://%v", cfg.ConfserviceHost))
+	if err != nil {
+		slog.Error("invalid confservice_host", "error", err)
+		os.Exit(2)
+	}
+	insserviceURL, err := url.Parse(fmt.Sprintf("http://%v", cfg.InsserviceHost))
+	if err != nil {
+		slog.Error("invalid insservice_host", "error", err)
+		os.Exit(2)
 	}
 
-	confserviceURL := fmt.Sprintf("http://%v", confserviceHost)
-	insserviceURL := fmt.Sprintf("http://%v", insserviceHost)
+	principals, err := parsePrincipals(cfg.Tokens)
+	if err != nil {
+		slog.Error("invalid tokens", "error", err)
+		os.Exit(2)
+	}
 
 	r := mux.NewRouter()
-	r.HandleFunc("/health", health)
-	r.HandleFunc("/api", api)
+	r.Use(otelmux.Middleware("apigateway"), logging.Middleware, metrics.Instrument, authenticate(principals))
+	r.HandleFunc("/health", health.Livez)
+	r.HandleFunc("/livez", health.Livez).Methods(http.MethodGet)
+	r.HandleFunc("/readyz", health.Readyz(
+		health.Check{Name: "confservice", Fn: health.Upstream(confserviceURL.String())},
+		health.Check{Name: "insservice", Fn: health.Upstream(insserviceURL.String())},
+	)).Methods(http.MethodGet)
+	r.Handle("/metrics", promhttp.Handler()).Methods(http.MethodGet)
+	spec := openAPI(backend{"confservice", confserviceURL.String()}, backend{"insservice", insserviceURL.String()})
+	r.HandleFunc("/api", spec).Methods(http.MethodGet)
+	r.HandleFunc("/api/openapi.json", spec).Methods(http.MethodGet)
 	r.HandleFunc("/api/items", proxyHandler(confserviceURL))
 	r.HandleFunc("/api/items/{id}", proxyHandler(confserviceURL))
+	r.HandleFunc("/api/items/{id}/labels", proxyHandler(confserviceURL))
+	r.HandleFunc("/api/itemtypes", proxyHandler(confserviceURL))
+	r.HandleFunc("/api/itemtypes/{name}", proxyHandler(confserviceURL))
 	r.HandleFunc("/api/modules", proxyHandler(confserviceURL))
 	r.HandleFunc("/api/modules/{id}", proxyHandler(confserviceURL))
+	r.HandleFunc("/api/modules/{id}/labels", proxyHandler(confserviceURL))
+	r.HandleFunc("/api/modules/{id}/graph", proxyHandler(confserviceURL))
+	r.HandleFunc("/api/modules/{id}/impact", proxyHandler(confserviceURL))
+	r.HandleFunc("/api/modules/{id}/clone", proxyHandler(confserviceURL))
 	r.HandleFunc("/api/itemmodules", proxyHandler(confserviceURL))
 	r.HandleFunc("/api/itemmodules/{id}", proxyHandler(confserviceURL))
 	r.HandleFunc("/api/moduledependencies", proxyHandler(confserviceURL))
 	r.HandleFunc("/api/moduledependencies/dependent/{dependentID}/dependee/{dependeeID}", proxyHandler(confserviceURL))
 	r.HandleFunc("/api/moduledependencies/dependent/{id}", proxyHandler(confserviceURL))
 	r.HandleFunc("/api/moduledependencies/dependee/{id}", proxyHandler(confserviceURL))
+	r.HandleFunc("/api/graphql", proxyHandler(confserviceURL))
+	r.HandleFunc("/api/import/csv", proxyHandler(confserviceURL))
+	r.HandleFunc("/api/releases", proxyHandler(confserviceURL))
+	r.HandleFunc("/api/releases/{name}", proxyHandler(confserviceURL))
+	r.HandleFunc("/api/releases/{name}/promote", requirePrincipal(proxyHandler(confserviceURL)))
+	r.HandleFunc("/api/releases/{name}/promotions", proxyHandler(confserviceURL))
+	r.HandleFunc("/api/releases/{name}/promotions/{id}/approve", requirePrincipal(proxyHandler(confserviceURL)))
+	r.HandleFunc("/api/environments/{env}", proxyHandler(insserviceURL))
 	r.HandleFunc("/api/insfile", proxyHandler(insserviceURL))
 	r.HandleFunc("/api/insfile/text", proxyHandler(insserviceURL))
 	r.HandleFunc("/api/insfile/traverse", proxyHandler(insserviceURL))
 	r.HandleFunc("/api/insfile/traverse/text", proxyHandler(insserviceURL))
+	r.HandleFunc("/api/insfile/release/{name}", proxyHandler(insserviceURL))
+	r.HandleFunc("/api/insfile/release/{name}/text", proxyHandler(insserviceURL))
+	r.HandleFunc("/api/insfile/environment/{env}", proxyHandler(insserviceURL))
+	r.HandleFunc("/api/insfile/environment/{env}/text", proxyHandler(insserviceURL))
+	r.HandleFunc("/api/diff", proxyHandler(insserviceURL))
+	r.HandleFunc("/api/diff/text", proxyHandler(insserviceURL))
 
 	srv := http.Server{
-		Addr:         "",
+		Addr:         cfg.Addr,
 		Handler:      r,
-		ReadTimeout:  15 * time.Second,
-		WriteTimeout: 15 * time.Second,
-		IdleTimeout:  60 * time.Second,
+		ReadTimeout:  cfg.ReadTimeout,
+		WriteTimeout: cfg.WriteTimeout,
+		IdleTimeout:  cfg.IdleTimeout,
 	}
 
 	// run server in own go routine.
 	go func() {
-		if err := srv.ListenAndServe(); err != nil {
-			log.Println(err)
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			slog.Error("http server failed", "error", err)
 		}
 	}()
 
@@ -71,45 +174,46 @@ func main() {
 	<-c
 
 	// wait some time before shutting down to finish work.
-	wait := 15 * time.Second
-	ctx, cancel := context.WithTimeout(context.Background(), wait)
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
 	defer cancel()
 
 	// if there are no connections, then it shutsdown immediately otherwise
 	// block until wait time is over.
 	srv.Shutdown(ctx)
 
-	log.Println("shutting down")
-	os.Exit(0)
-}
+	if err := shutdownTracing(ctx); err != nil {
+		slog.Error("could not export spans", "error", err)
+	}
 
-func health(w http.ResponseWriter, r *http.Request) {
-	w.WriteHeader(http.StatusOK)
+	slog.Info("shutting down")
+	os.Exit(0)
 }
 
-const (
-	usage = `
-GET, POST /api/items
-GET, DELETE /api/items/:id
-`
-)
-
-func api(w http.ResponseWriter, r *http.Request) {
-	w.WriteHeader(http.StatusOK)
-	w.Write([]byte(usage))
-}
+// proxyTransport traces the requests to the services as children of the span of
+// the request to the gateway and passes the trace context on to them.
+var proxyTransport = otelhttp.NewTransport(http.DefaultTransport)
 
 // proxyHandler is used for reverse proxying. It will ask the target for the
-// given resource.
-func proxyHandler(target string) func(http.ResponseWriter, *http.Request) {
-	return func(w http.ResponseWriter, r *http.Request) {
-		remote, err := url.Parse(target)
-		if err != nil {
-			log.Fatalf("%v", err)
+// given resource. Requests the target can not be reached for or fails with a
+// 5xx status are counted as upstream errors.
+func proxyHandler(remote *url.URL) func(http.ResponseWriter, *http.Request) {
+	proxy := httputil.NewSingleHostReverseProxy(remote)
+	proxy.Transport = proxyTransport
+	proxy.ModifyResponse = func(resp *http.Response) error {
+		// the response already has the request id of the gateway
+		resp.Header.Del(logging.Header)
+		if resp.StatusCode >= http.StatusInternalServerError {
+			upstreamErrors.WithLabelValues(remote.Host).Inc()
 		}
-		log.Printf("%#v", remote)
+		return nil
+	}
+	proxy.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
+		upstreamErrors.WithLabelValues(remote.Host).Inc()
+		logging.FromContext(r.Context()).Error("could not proxy request", "upstream", remote.Host, "error", err)
+		w.WriteHeader(http.StatusBadGateway)
+	}
 
-		proxy := httputil.NewSingleHostReverseProxy(remote)
+	return func(w http.ResponseWriter, r *http.Request) {
 		// reslice path to remove /api/
 		r.URL.Path = r.URL.Path[len("/api/"):]
 
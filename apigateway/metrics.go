@@ -0,0 +1,14 @@
+package main
+
+import (
+	"github.com/Glorforidor/conmansys/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var upstreamErrors = metrics.Register(prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "gateway_upstream_errors_total",
+		Help: "Number of proxied requests the upstream failed, by upstream host.",
+	},
+	[]string{"upstream"},
+)).(*prometheus.CounterVec)
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/Glorforidor/conmansys/metrics"
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestUpstreamErrors(t *testing.T) {
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer up.Close()
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+
+	upURL, _ := url.Parse(up.URL)
+	downURL, _ := url.Parse(down.URL)
+
+	r := mux.NewRouter()
+	r.Use(metrics.Instrument)
+	r.HandleFunc("/api/{name}", proxyHandler(upURL))
+	r.HandleFunc("/api/down/{name}", proxyHandler(downURL))
+
+	tt := map[string]struct {
+		path     string
+		upstream string
+		status   int
+		errors   float64
+	}{
+		"ok":          {path: "/api/items", upstream: upURL.Host, status: http.StatusOK},
+		"failed":      {path: "/api/fail", upstream: upURL.Host, status: http.StatusInternalServerError, errors: 1},
+		"unreachable": {path: "/api/down/items", upstream: downURL.Host, status: http.StatusBadGateway, errors: 1},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			before := testutil.ToFloat64(upstreamErrors.WithLabelValues(tc.upstream))
+
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tc.path, nil))
+			if rec.Code != tc.status {
+				t.Fatalf("expected status: %v, got: %v", tc.status, rec.Code)
+			}
+
+			if got := testutil.ToFloat64(upstreamErrors.WithLabelValues(tc.upstream)) - before; got != tc.errors {
+				t.Fatalf("expected %v upstream errors, got: %v", tc.errors, got)
+			}
+		})
+	}
+
+	if got := testutil.ToFloat64(metrics.Requests.WithLabelValues("/api/{name}", "get", "500")); got != 1 {
+		t.Fatalf("expected the failed request to be counted by its route, got: %v", got)
+	}
+}
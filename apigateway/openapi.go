@@ -0,0 +1,242 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Glorforidor/conmansys/logging"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+// backend is a service whose OpenAPI document is part of the document of the
+// gateway.
+type backend struct {
+	name string
+	url  string
+}
+
+// internalPaths are the paths of the backends which are not proxied by the
+// gateway.
+var internalPaths = map[string]bool{
+	"/health":       true,
+	"/livez":        true,
+	"/metrics":      true,
+	"/readyz":       true,
+	"/openapi.json": true,
+}
+
+// specClient traces the requests for the documents of the backends as children
+// of the span of the request to the gateway and sends its request id along.
+var specClient = &http.Client{
+	Timeout:   5 * time.Second,
+	Transport: otelhttp.NewTransport(logging.Transport{}),
+}
+
+// fetchSpec gets the OpenAPI document of the backend for the request of ctx.
+func fetchSpec(ctx context.Context, b backend) (map[string]interface{}, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, b.url+"/openapi.json", nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not get the document of %v: %v", b.name, err)
+	}
+
+	resp, err := specClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("could not get the document of %v: %v", b.name, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("could not get the document of %v: %v", b.name, resp.Status)
+	}
+
+	var doc map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("could not decode the document of %v: %v", b.name, err)
+	}
+
+	return doc, nil
+}
+
+// openAPI returns a handler serving the documents of the backends merged into
+// one with the paths as the gateway proxies them. The documents are fetched on
+// every request, so it is never stale.
+func openAPI(backends ...backend) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		docs := make([]map[string]interface{}, len(backends))
+		names := make([]string, len(backends))
+		for i, b := range backends {
+			doc, err := fetchSpec(r.Context(), b)
+			if err != nil {
+				logging.FromContext(r.Context()).Error("could not get the document", "error", err)
+				http.Error(w, fmt.Sprintf("the document of %v is not available", b.name), http.StatusBadGateway)
+				return
+			}
+			docs[i] = doc
+			names[i] = b.name
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(mergeSpecs(names, docs)); err != nil {
+			logging.FromContext(r.Context()).Error("could not encode the document", "error", err)
+		}
+	}
+}
+
+// mergeSpecs merges the documents of the backends. The paths are prefixed with
+// /api. Components with the same name are shared if they are the same,
+// otherwise the component of a later backend is renamed to
+// <backend>.<name> and its references are rewritten.
+func mergeSpecs(names []string, docs []map[string]interface{}) map[string]interface{} {
+	paths := make(map[string]interface{})
+	components := make(map[string]map[string]interface{})
+
+	for i, doc := range docs {
+		renames := renameComponents(names[i], components, object(doc["components"]))
+		doc = rewriteRefs(doc, renames).(map[string]interface{})
+
+		for section, v := range object(doc["components"]) {
+			if components[section] == nil {
+				components[section] = make(map[string]interface{})
+			}
+			for name, c := range object(v) {
+				if renamed, ok := renames["#/components/"+section+"/"+name]; ok {
+					name = strings.TrimPrefix(renamed, "#/components/"+section+"/")
+				}
+				components[section][name] = c
+			}
+		}
+
+		for path, item := range object(doc["paths"]) {
+			if internalPaths[path] {
+				continue
+			}
+			paths["/api"+path] = item
+		}
+	}
+
+	paths["/api/openapi.json"] = map[string]interface{}{
+		"get": map[string]interface{}{
+			"summary": "This document.",
+			"responses": map[string]interface{}{
+				"200": map[string]interface{}{
+					"description": "The OpenAPI document of the gateway.",
+					"content": map[string]interface{}{
+						"application/json": map[string]interface{}{
+							"schema": map[string]interface{}{"type": "object"},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":       "apigateway",
+			"description": fmt.Sprintf("The routes of %v as proxied by the gateway.", strings.Join(names, " and ")),
+			"version":     "1.0.0",
+		},
+		"paths":      paths,
+		"components": components,
+	}
+}
+
+// renameComponents decides which components of the backend are renamed
+// because a component with the same name but a different definition is
+// already merged. A component which refers to a renamed component is renamed
+// too, as it is not the same anymore. The renames are keyed by the reference.
+func renameComponents(name string, merged map[string]map[string]interface{}, components map[string]interface{}) map[string]string {
+	renames := make(map[string]string)
+
+	sections := make([]string, 0, len(components))
+	for section := range components {
+		sections = append(sections, section)
+	}
+	sort.Strings(sections)
+
+	for changed := true; changed; {
+		changed = false
+		for _, section := range sections {
+			for c, v := range object(components[section]) {
+				ref := "#/components/" + section + "/" + c
+				if _, ok := renames[ref]; ok {
+					continue
+				}
+
+				existing, ok := merged[section][c]
+				if !ok {
+					continue
+				}
+
+				if !reflect.DeepEqual(existing, v) || refersTo(v, renames) {
+					renames[ref] = "#/components/" + section + "/" + name + "." + c
+					changed = true
+				}
+			}
+		}
+	}
+
+	return renames
+}
+
+// refersTo reports whether v has a reference to one of the renamed components.
+func refersTo(v interface{}, renames map[string]string) bool {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, e := range t {
+			if s, ok := e.(string); ok && k == "$ref" {
+				if _, ok := renames[s]; ok {
+					return true
+				}
+			}
+			if refersTo(e, renames) {
+				return true
+			}
+		}
+	case []interface{}:
+		for _, e := range t {
+			if refersTo(e, renames) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// rewriteRefs returns a copy of v with the references to renamed components
+// pointing to their new names.
+func rewriteRefs(v interface{}, renames map[string]string) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(t))
+		for k, e := range t {
+			if s, ok := e.(string); ok && k == "$ref" {
+				if renamed, ok := renames[s]; ok {
+					e = renamed
+				}
+			}
+			m[k] = rewriteRefs(e, renames)
+		}
+		return m
+	case []interface{}:
+		l := make([]interface{}, len(t))
+		for i, e := range t {
+			l[i] = rewriteRefs(e, renames)
+		}
+		return l
+	}
+	return v
+}
+
+// object returns v as a JSON object or nil if it is not one.
+func object(v interface{}) map[string]interface{} {
+	m, _ := v.(map[string]interface{})
+	return m
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const confDoc = `{
+	"paths": {
+		"/health": {},
+		"/modules/{id}": {"get": {"responses": {"200": {"$ref": "#/components/responses/Module"}}}}
+	},
+	"components": {
+		"responses": {"Module": {"content": {"application/json": {"schema": {"$ref": "#/components/schemas/Module"}}}}},
+		"schemas": {
+			"ErrorMessage": {"type": "string", "nullable": true},
+			"Module": {"type": "object", "properties": {"owner": {"type": "string"}}}
+		}
+	}
+}`
+
+const insDoc = `{
+	"paths": {
+		"/openapi.json": {},
+		"/insfile": {"post": {"responses": {"200": {"$ref": "#/components/responses/Module"}}}}
+	},
+	"components": {
+		"responses": {"Module": {"content": {"application/json": {"schema": {"$ref": "#/components/schemas/Module"}}}}},
+		"schemas": {
+			"ErrorMessage": {"type": "string", "nullable": true},
+			"Module": {"type": "object", "properties": {"id": {"type": "integer"}}}
+		}
+	}
+}`
+
+func serveDoc(doc string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/openapi.json" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(doc))
+	}))
+}
+
+func TestOpenAPI(t *testing.T) {
+	conf := serveDoc(confDoc)
+	defer conf.Close()
+	ins := serveDoc(insDoc)
+	defer ins.Close()
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/api/openapi.json", nil)
+	openAPI(backend{"confservice", conf.URL}, backend{"insservice", ins.URL})(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status: %v, got: %v", http.StatusOK, rec.Code)
+	}
+
+	var doc struct {
+		Paths      map[string]json.RawMessage
+		Components map[string]map[string]json.RawMessage
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&doc); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, p := range []string{"/api/modules/{id}", "/api/insfile", "/api/openapi.json"} {
+		if _, ok := doc.Paths[p]; !ok {
+			t.Errorf("expected path %v, got: %v", p, doc.Paths)
+		}
+	}
+	for _, p := range []string{"/api/health", "/health", "/insfile"} {
+		if _, ok := doc.Paths[p]; ok {
+			t.Errorf("expected no path %v", p)
+		}
+	}
+
+	tt := map[string]struct {
+		section string
+		name    string
+		exists  bool
+	}{
+		"shared":          {section: "schemas", name: "ErrorMessage", exists: true},
+		"first":           {section: "schemas", name: "Module", exists: true},
+		"renamed":         {section: "schemas", name: "insservice.Module", exists: true},
+		"refers renamed":  {section: "responses", name: "insservice.Module", exists: true},
+		"shared not kept": {section: "schemas", name: "insservice.ErrorMessage", exists: false},
+	}
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			if _, ok := doc.Components[tc.section][tc.name]; ok != tc.exists {
+				t.Fatalf("expected %v %v to exist: %v", tc.section, tc.name, tc.exists)
+			}
+		})
+	}
+
+	want := `{"200":{"$ref":"#/components/responses/insservice.Module"}}`
+	var insfile struct {
+		Post struct {
+			Responses json.RawMessage
+		}
+	}
+	if err := json.Unmarshal(doc.Paths["/api/insfile"], &insfile); err != nil {
+		t.Fatal(err)
+	}
+	if string(insfile.Post.Responses) != want {
+		t.Fatalf("expected responses: %v, got: %v", want, string(insfile.Post.Responses))
+	}
+}
+
+func TestOpenAPIBackendDown(t *testing.T) {
+	conf := serveDoc(confDoc)
+	defer conf.Close()
+	ins := serveDoc(insDoc)
+	ins.Close()
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/api/openapi.json", nil)
+	openAPI(backend{"confservice", conf.URL}, backend{"insservice", ins.URL})(rec, req)
+
+	if rec.Code != http.StatusBadGateway {
+		t.Fatalf("expected status: %v, got: %v", http.StatusBadGateway, rec.Code)
+	}
+}
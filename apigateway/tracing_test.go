@@ -0,0 +1,73 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/Glorforidor/conmansys/logging"
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gorilla/mux/otelmux"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracePropagation(t *testing.T) {
+	rec := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec))
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer otel.SetTracerProvider(trace.NewNoopTracerProvider())
+
+	var upstream trace.SpanContext
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		upstream = trace.SpanContextFromContext(ctx)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer up.Close()
+	upURL, _ := url.Parse(up.URL)
+
+	r := mux.NewRouter()
+	r.Use(otelmux.Middleware("apigateway"), logging.Middleware)
+	r.HandleFunc("/api/{name}", proxyHandler(upURL))
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	req := httptest.NewRequest(http.MethodGet, "/api/items", nil)
+	req.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	if got := upstream.TraceID().String(); got != traceID {
+		t.Fatalf("expected the trace %v to be passed on, got: %v", traceID, got)
+	}
+
+	spans := rec.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("expected a server and a client span, got: %v", len(spans))
+	}
+	var server, client sdktrace.ReadOnlySpan
+	for _, s := range spans {
+		switch s.SpanKind() {
+		case trace.SpanKindServer:
+			server = s
+		case trace.SpanKindClient:
+			client = s
+		}
+	}
+	if server == nil || client == nil {
+		t.Fatalf("expected a server and a client span, got: %v", spans)
+	}
+	if server.Name() != "/api/{name}" {
+		t.Fatalf("expected the server span to be named by its route, got: %q", server.Name())
+	}
+	if client.Parent().SpanID() != server.SpanContext().SpanID() {
+		t.Fatal("expected the client span to be a child of the server span")
+	}
+	if upstream.SpanID() != client.SpanContext().SpanID() {
+		t.Fatal("expected the upstream to be a child of the client span")
+	}
+}
@@ -0,0 +1,319 @@
+// Package client is a typed client of the conmansys API. It talks to the
+// confservice and the insservice, either through the apigateway or to the
+// services directly.
+//
+//	c, err := client.New("http://localhost:8079/api")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	modules, err := c.ListModules(ctx, &client.ListOptions{Selector: "team=billing"})
+//
+// Errors responded by the services are returned as *Error and can be checked
+// with errors.Is against ErrInvalid, ErrNotFound, ErrConflict and
+// ErrUnavailable.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	revealHeader    = "X-Reveal-Token"
+	principalHeader = "X-Principal"
+)
+
+// Client sends requests to the confservice and the insservice.
+type Client struct {
+	confURL string
+	insURL  string
+
+	http        *http.Client
+	retries     int
+	backoff     time.Duration
+	revealToken string
+	principal   string
+	token       string
+}
+
+// Option configures optional behaviour of the client.
+type Option func(*Client)
+
+// WithHTTPClient sends the requests with c instead of a client with a timeout
+// of 30 seconds.
+func WithHTTPClient(c *http.Client) Option {
+	return func(cl *Client) {
+		cl.http = c
+	}
+}
+
+// WithInsserviceURL sends the requests of the insservice routes to another
+// base URL than the confservice routes, which is needed when the services are
+// called directly instead of through the apigateway.
+func WithInsserviceURL(u string) Option {
+	return func(c *Client) {
+		c.insURL = u
+	}
+}
+
+// WithRetries retries a request which failed to reach the service or got a
+// bad gateway, service unavailable or gateway timeout status up to n times.
+// The wait before a retry grows by backoff every time. Only GET, PUT and
+// DELETE requests are retried, as they can be sent again safely.
+func WithRetries(n int, backoff time.Duration) Option {
+	return func(c *Client) {
+		c.retries = n
+		c.backoff = backoff
+	}
+}
+
+// WithRevealToken sends the reveal token of the confservice, so the values of
+// secret items are returned unmasked.
+func WithRevealToken(token string) Option {
+	return func(c *Client) {
+		c.revealToken = token
+	}
+}
+
+// WithPrincipal sends the principal which promotes and approves releases.
+// The apigateway drops it, so it is only used when the services are called
+// directly.
+func WithPrincipal(principal string) Option {
+	return func(c *Client) {
+		c.principal = principal
+	}
+}
+
+// WithToken authenticates every request with the token as a bearer token. The
+// apigateway takes the principal of the token, which promotions and approvals
+// through it need.
+func WithToken(token string) Option {
+	return func(c *Client) {
+		c.token = token
+	}
+}
+
+// New returns a client of the services at the base URL, e.g. the /api of the
+// apigateway. By default requests are retried twice.
+func New(baseURL string, opts ...Option) (*Client, error) {
+	c := &Client{
+		confURL: baseURL,
+		http:    &http.Client{Timeout: 30 * time.Second},
+		retries: 2,
+		backoff: 200 * time.Millisecond,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.insURL == "" {
+		c.insURL = c.confURL
+	}
+
+	for _, u := range []*string{&c.confURL, &c.insURL} {
+		p, err := url.Parse(*u)
+		if err != nil {
+			return nil, fmt.Errorf("invalid base URL: %v", err)
+		}
+		if p.Scheme != "http" && p.Scheme != "https" {
+			return nil, fmt.Errorf("invalid base URL %q: the scheme must be http or https", *u)
+		}
+		*u = strings.TrimRight(p.String(), "/")
+	}
+
+	return c, nil
+}
+
+// request is a request to one of the services.
+type request struct {
+	method      string
+	base        string
+	path        string
+	query       url.Values
+	body        interface{}
+	contentType string
+	header      http.Header
+}
+
+// conf returns a request to the confservice.
+func (c *Client) conf(method, path string, args ...interface{}) *request {
+	return &request{method: method, base: c.confURL, path: fmt.Sprintf(path, args...)}
+}
+
+// ins returns a request to the insservice.
+func (c *Client) ins(method, path string, args ...interface{}) *request {
+	return &request{method: method, base: c.insURL, path: fmt.Sprintf(path, args...)}
+}
+
+// reveal asks for the secret items unmasked if the client has a reveal token.
+func (c *Client) reveal(r *request) *request {
+	if c.revealToken == "" {
+		return r
+	}
+
+	if r.query == nil {
+		r.query = url.Values{}
+	}
+	r.query.Set("reveal", "true")
+	if r.header == nil {
+		r.header = http.Header{}
+	}
+	r.header.Set(revealHeader, c.revealToken)
+	return r
+}
+
+// encode returns the body of the request. A body which is not a reader is
+// encoded as JSON.
+func (r *request) encode() ([]byte, string, error) {
+	switch b := r.body.(type) {
+	case nil:
+		return nil, "", nil
+	case io.Reader:
+		t, err := ioutil.ReadAll(b)
+		return t, r.contentType, err
+	}
+
+	t, err := json.Marshal(r.body)
+	if err != nil {
+		return nil, "", fmt.Errorf("could not encode request body: %v", err)
+	}
+	return t, "application/json", nil
+}
+
+// idempotent reports whether the request can be sent again safely.
+func (r *request) idempotent() bool {
+	switch r.method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// retryable reports whether a response with the status may succeed if the
+// request is sent again.
+func retryable(status int) bool {
+	switch status {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// send sends the request, retrying it if it may succeed later, and returns the
+// status and the body of the response.
+func (c *Client) send(ctx context.Context, r *request) (int, []byte, error) {
+	body, contentType, err := r.encode()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	u := r.base + r.path
+	if len(r.query) > 0 {
+		u += "?" + r.query.Encode()
+	}
+
+	attempts := 1
+	if r.idempotent() {
+		attempts += c.retries
+	}
+
+	var (
+		status int
+		b      []byte
+	)
+	for i := 0; i < attempts; i++ {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				return 0, nil, ctx.Err()
+			case <-time.After(time.Duration(i) * c.backoff):
+			}
+		}
+
+		req, err := http.NewRequest(r.method, u, bytes.NewReader(body))
+		if err != nil {
+			return 0, nil, err
+		}
+		req = req.WithContext(ctx)
+		for k, v := range r.header {
+			req.Header[k] = v
+		}
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		if c.token != "" {
+			req.Header.Set("Authorization", "Bearer "+c.token)
+		}
+
+		var resp *http.Response
+		resp, err = c.http.Do(req)
+		if err != nil {
+			if ctx.Err() != nil || i == attempts-1 {
+				return 0, nil, err
+			}
+			continue
+		}
+
+		status = resp.StatusCode
+		b, err = ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return 0, nil, fmt.Errorf("could not read response body: %v", err)
+		}
+
+		if !retryable(status) {
+			break
+		}
+	}
+
+	return status, b, nil
+}
+
+// do sends the request and decodes the JSON response into out. An error
+// status is returned as *Error.
+func (c *Client) do(ctx context.Context, r *request, out interface{}) error {
+	status, b, err := c.send(ctx, r)
+	if err != nil {
+		return err
+	}
+
+	if status >= http.StatusBadRequest {
+		e := &Error{StatusCode: status}
+		if err := json.Unmarshal(b, e); err != nil {
+			// not every error passes the services, e.g. a bad gateway
+			e.Message = strings.TrimSpace(string(b))
+		}
+		return e
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(b, out); err != nil {
+		return fmt.Errorf("could not decode response body: %v", err)
+	}
+	return nil
+}
+
+// doText sends the request and returns the text response. An error status is
+// returned as *Error with the first line of the response as the message.
+func (c *Client) doText(ctx context.Context, r *request) (string, error) {
+	status, b, err := c.send(ctx, r)
+	if err != nil {
+		return "", err
+	}
+
+	if status >= http.StatusBadRequest {
+		msg := strings.SplitN(strings.TrimSpace(string(b)), "\n", 2)[0]
+		return "", &Error{StatusCode: status, Message: strings.TrimSpace(msg)}
+	}
+
+	return string(b), nil
+}
@@ -0,0 +1,280 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestNew(t *testing.T) {
+	tt := map[string]struct {
+		url   string
+		opts  []Option
+		valid bool
+	}{
+		"gateway":       {url: "http://localhost:8079/api/", valid: true},
+		"services":      {url: "http://confservice", opts: []Option{WithInsserviceURL("http://insservice")}, valid: true},
+		"no scheme":     {url: "localhost:8079", valid: false},
+		"bad ins":       {url: "http://confservice", opts: []Option{WithInsserviceURL("::")}, valid: false},
+		"wrong scheme":  {url: "ftp://localhost", valid: false},
+		"invalid":       {url: "http://local host", valid: false},
+		"trailing none": {url: "https://example.com", valid: true},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			_, err := New(tc.url, tc.opts...)
+			if (err == nil) != tc.valid {
+				t.Fatalf("expected valid: %v, got: %v", tc.valid, err)
+			}
+		})
+	}
+}
+
+func TestDecode(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method + " " + r.URL.Path {
+		case "GET /api/modules/1":
+			w.Write([]byte(`{"module": {"id": 1, "value": "billing", "version": "0.0.1", "owner": "team"}, "error": null}`))
+		case "GET /api/items":
+			if r.URL.Query().Get("selector") != "team=billing" {
+				t.Errorf("expected the selector, got: %v", r.URL.RawQuery)
+			}
+			w.Write([]byte(`{"items": [{"id": 1, "value": "a"}, {"id": 2, "value": "b"}], "error": null}`))
+		case "DELETE /api/items/3":
+			w.Write([]byte(`{"rows_affected": 1, "error": null}`))
+		case "POST /api/insfile":
+			b, _ := ioutil.ReadAll(r.Body)
+			if string(b) != `[{"id":1},{"id":4}]` {
+				t.Errorf("expected the module ids, got: %s", b)
+			}
+			if r.URL.Query().Get("policy") != PolicyHighestVersion {
+				t.Errorf("expected the policy, got: %v", r.URL.RawQuery)
+			}
+			w.Write([]byte(`{"items": [{"value": "a"}], "modules": [{"id": 1}, {"id": 4}], "excluded": [], "module_conflicts": [], "conflicts": [], "error": null}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL + "/api")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	m, err := c.GetModule(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Value != "billing" || m.Owner != "team" {
+		t.Fatalf("expected the module, got: %+v", m)
+	}
+
+	items, err := c.ListItems(ctx, &ListOptions{Selector: "team=billing"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("expected 2 items, got: %v", len(items))
+	}
+
+	n, err := c.DeleteItem(ctx, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Fatalf("expected 1 row affected, got: %v", n)
+	}
+
+	ins, err := c.Insfile(ctx, Modules{IDs: []int64{1, 4}}, &InsfileOptions{Policy: PolicyHighestVersion})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ins.Items) != 1 || len(ins.Modules) != 2 {
+		t.Fatalf("expected 1 item and 2 modules, got: %+v", ins)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/items":
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"item": null, "error": "invalid value", "fields": [{"field": "value", "message": "must be set"}]}`))
+		case "/items/9":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"item": null, "error": "not found"}`))
+		case "/modules/1":
+			w.WriteHeader(http.StatusConflict)
+			w.Write([]byte(`{"rows_affected": 0, "error": "module is in use", "impact": {"insfiles": [1, 2]}}`))
+		case "/insfile/text":
+			w.WriteHeader(http.StatusConflict)
+			w.Write([]byte("the modules of the insfile conflict\r\n1 conflicts with 2\r\n"))
+		default:
+			w.WriteHeader(http.StatusBadGateway)
+			w.Write([]byte("bad gateway"))
+		}
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL, WithRetries(0, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	_, err = c.CreateItem(ctx, &Item{})
+	var e *Error
+	if !errors.Is(err, ErrInvalid) || !errors.As(err, &e) || len(e.Fields) != 1 || e.Fields[0].Field != "value" {
+		t.Fatalf("expected an invalid error with a field, got: %v", err)
+	}
+
+	_, err = c.GetItem(ctx, 9)
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected not found, got: %v", err)
+	}
+
+	_, err = c.DeleteModule(ctx, 1, nil)
+	if !errors.Is(err, ErrConflict) || !errors.As(err, &e) || e.Impact == nil || len(e.Impact.Insfiles) != 2 {
+		t.Fatalf("expected a conflict with the impact, got: %v", err)
+	}
+
+	_, err = c.InsfileText(ctx, Modules{IDs: []int64{1}}, nil)
+	if !errors.Is(err, ErrConflict) || !errors.As(err, &e) || e.Message != "the modules of the insfile conflict" {
+		t.Fatalf("expected a conflict, got: %v", err)
+	}
+
+	_, err = c.ListReleases(ctx)
+	if !errors.Is(err, ErrUnavailable) || !errors.As(err, &e) || e.Message != "bad gateway" {
+		t.Fatalf("expected unavailable, got: %v", err)
+	}
+}
+
+func TestRetries(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"modules": [], "error": null}`))
+	}))
+	defer srv.Close()
+
+	tt := map[string]struct {
+		retries int
+		call    func(c *Client) error
+		calls   int32
+		err     error
+	}{
+		"succeeds": {
+			retries: 2,
+			call:    func(c *Client) error { _, err := c.ListModules(context.Background(), nil); return err },
+			calls:   3,
+		},
+		"gives up": {
+			retries: 1,
+			call:    func(c *Client) error { _, err := c.ListModules(context.Background(), nil); return err },
+			calls:   2,
+			err:     ErrUnavailable,
+		},
+		"post is not retried": {
+			retries: 2,
+			call:    func(c *Client) error { _, err := c.CreateModule(context.Background(), &Module{}); return err },
+			calls:   1,
+			err:     ErrUnavailable,
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			atomic.StoreInt32(&calls, 0)
+			c, err := New(srv.URL, WithRetries(tc.retries, time.Millisecond))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = tc.call(c)
+			if tc.err == nil && err != nil || tc.err != nil && !errors.Is(err, tc.err) {
+				t.Fatalf("expected error: %v, got: %v", tc.err, err)
+			}
+			if n := atomic.LoadInt32(&calls); n != tc.calls {
+				t.Fatalf("expected %v calls, got: %v", tc.calls, n)
+			}
+		})
+	}
+}
+
+func TestCanceled(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL, WithRetries(5, time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err = c.ListItems(ctx, nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the deadline to be exceeded, got: %v", err)
+	}
+}
+
+func TestServices(t *testing.T) {
+	conf := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(revealHeader) != "token" || r.URL.Query().Get("reveal") != "true" {
+			t.Errorf("expected the reveal token, got: %v %v", r.Header, r.URL.RawQuery)
+		}
+		if r.Header.Get("Authorization") != "Bearer t1" {
+			t.Errorf("expected the token, got: %v", r.Header)
+		}
+		w.Write([]byte(`{"item": {"id": 1, "value": "secret", "secret": true}, "error": null}`))
+	}))
+	defer conf.Close()
+
+	ins := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/insfile/release/2026.10/text" || r.URL.Query().Get("optional") != "" {
+			t.Errorf("expected the release insfile, got: %v", r.URL)
+		}
+		if r.Header.Get("Authorization") != "Bearer t1" {
+			t.Errorf("expected the token, got: %v", r.Header)
+		}
+		w.Write([]byte("items\r\n"))
+	}))
+	defer ins.Close()
+
+	c, err := New(conf.URL, WithInsserviceURL(ins.URL), WithRevealToken("token"), WithToken("t1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	item, err := c.GetItem(context.Background(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item.Value != "secret" {
+		t.Fatalf("expected the revealed value, got: %v", item.Value)
+	}
+
+	s, err := c.ReleaseInsfileText(context.Background(), "2026.10", &InsfileOptions{Optional: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(s, "items") {
+		t.Fatalf("expected the insfile, got: %q", s)
+	}
+}
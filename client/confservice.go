@@ -0,0 +1,568 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// The response envelopes of the confservice.
+type (
+	itemResponse struct {
+		Item *Item `json:"item"`
+	}
+	itemsResponse struct {
+		Items []*Item `json:"items"`
+	}
+	itemTypeResponse struct {
+		ItemType *ItemType `json:"item_type"`
+	}
+	itemTypesResponse struct {
+		ItemTypes []*ItemType `json:"item_types"`
+	}
+	moduleResponse struct {
+		Module *Module `json:"module"`
+	}
+	modulesResponse struct {
+		Modules []*Module `json:"modules"`
+	}
+	labelsResponse struct {
+		Labels map[string]string `json:"labels"`
+	}
+	impactResponse struct {
+		Impact *Impact `json:"impact"`
+	}
+	cloneResponse struct {
+		Clone *ModuleClone `json:"clone"`
+	}
+	itemModuleResponse struct {
+		ItemModule *ItemModule `json:"item_module"`
+	}
+	itemModulesResponse struct {
+		ItemModules []*ItemModule `json:"item_modules"`
+	}
+	moduleDependencyResponse struct {
+		ModuleDependency *ModuleDependency `json:"module_dependency"`
+	}
+	moduleDependenciesResponse struct {
+		ModuleDependencies []*ModuleDependency `json:"module_dependencies"`
+	}
+	deleteResponse struct {
+		RowsAffected int64 `json:"rows_affected"`
+	}
+	releaseResponse struct {
+		Release *Release `json:"release"`
+	}
+	releasesResponse struct {
+		Releases []*Release `json:"releases"`
+	}
+	promotionResponse struct {
+		Promotion *Promotion `json:"promotion"`
+	}
+	promotionsResponse struct {
+		Promotions []*Promotion `json:"promotions"`
+	}
+)
+
+// missing is the error of a get which the confservice answered without what
+// was asked for, which is how it answers ids that do not exist.
+func missing(what string, key interface{}) error {
+	return &Error{StatusCode: http.StatusNotFound, Message: fmt.Sprintf("%v %v not found", what, key)}
+}
+
+// ListOptions filter the items or modules of a list. Empty options do not
+// filter.
+type ListOptions struct {
+	// Selector is a label selector, e.g. "team=billing,tier!=experimental".
+	Selector string
+	// Owner must be exactly the owner.
+	Owner string
+	// Search must be part of the value or description, ignoring case.
+	Search string
+	// Annotation is the key of an annotation which must be set.
+	Annotation string
+	// UpdatedSince is the time the item or module must be updated at or
+	// after.
+	UpdatedSince time.Time
+}
+
+func (o *ListOptions) query() url.Values {
+	q := url.Values{}
+	if o == nil {
+		return q
+	}
+
+	if o.Selector != "" {
+		q.Set("selector", o.Selector)
+	}
+	if o.Owner != "" {
+		q.Set("owner", o.Owner)
+	}
+	if o.Search != "" {
+		q.Set("q", o.Search)
+	}
+	if o.Annotation != "" {
+		q.Set("annotation", o.Annotation)
+	}
+	if !o.UpdatedSince.IsZero() {
+		q.Set("updated_since", o.UpdatedSince.Format(time.RFC3339))
+	}
+	return q
+}
+
+// ListItems lists the items matching the options.
+func (c *Client) ListItems(ctx context.Context, opts *ListOptions) ([]*Item, error) {
+	r := c.conf(http.MethodGet, "/items")
+	r.query = opts.query()
+
+	var resp itemsResponse
+	err := c.do(ctx, c.reveal(r), &resp)
+	return resp.Items, err
+}
+
+// GetItem gets the item with the id.
+func (c *Client) GetItem(ctx context.Context, id int64) (*Item, error) {
+	var resp itemResponse
+	if err := c.do(ctx, c.reveal(c.conf(http.MethodGet, "/items/%d", id)), &resp); err != nil {
+		return nil, err
+	}
+	if resp.Item == nil {
+		return nil, missing("item", id)
+	}
+	return resp.Item, nil
+}
+
+// CreateItem creates the item and returns it with its id.
+func (c *Client) CreateItem(ctx context.Context, item *Item) (*Item, error) {
+	r := c.conf(http.MethodPost, "/items")
+	r.body = item
+
+	var resp itemResponse
+	err := c.do(ctx, r, &resp)
+	return resp.Item, err
+}
+
+// UpdateItem saves the item with the id of the item.
+func (c *Client) UpdateItem(ctx context.Context, item *Item) (*Item, error) {
+	r := c.conf(http.MethodPut, "/items/%d", item.ID)
+	r.body = item
+
+	var resp itemResponse
+	err := c.do(ctx, r, &resp)
+	return resp.Item, err
+}
+
+// SetItemLabels replaces the labels of the item with the id.
+func (c *Client) SetItemLabels(ctx context.Context, id int64, labels map[string]string) (map[string]string, error) {
+	r := c.conf(http.MethodPut, "/items/%d/labels", id)
+	r.body = labels
+
+	var resp labelsResponse
+	err := c.do(ctx, r, &resp)
+	return resp.Labels, err
+}
+
+// DeleteItem deletes the item with the id and returns the number of deleted
+// rows.
+func (c *Client) DeleteItem(ctx context.Context, id int64) (int64, error) {
+	var resp deleteResponse
+	err := c.do(ctx, c.conf(http.MethodDelete, "/items/%d", id), &resp)
+	return resp.RowsAffected, err
+}
+
+// ListItemTypes lists the item types.
+func (c *Client) ListItemTypes(ctx context.Context) ([]*ItemType, error) {
+	var resp itemTypesResponse
+	err := c.do(ctx, c.conf(http.MethodGet, "/itemtypes"), &resp)
+	return resp.ItemTypes, err
+}
+
+// GetItemType gets the item type with the name.
+func (c *Client) GetItemType(ctx context.Context, name string) (*ItemType, error) {
+	var resp itemTypeResponse
+	if err := c.do(ctx, c.conf(http.MethodGet, "/itemtypes/%s", url.PathEscape(name)), &resp); err != nil {
+		return nil, err
+	}
+	if resp.ItemType == nil {
+		return nil, missing("item type", name)
+	}
+	return resp.ItemType, nil
+}
+
+// CreateItemType creates the item type.
+func (c *Client) CreateItemType(ctx context.Context, t *ItemType) (*ItemType, error) {
+	r := c.conf(http.MethodPost, "/itemtypes")
+	r.body = t
+
+	var resp itemTypeResponse
+	err := c.do(ctx, r, &resp)
+	return resp.ItemType, err
+}
+
+// UpdateItemType saves the item type with the name of the type.
+func (c *Client) UpdateItemType(ctx context.Context, t *ItemType) (*ItemType, error) {
+	r := c.conf(http.MethodPut, "/itemtypes/%s", url.PathEscape(t.Name))
+	r.body = t
+
+	var resp itemTypeResponse
+	err := c.do(ctx, r, &resp)
+	return resp.ItemType, err
+}
+
+// DeleteItemType deletes the item type with the name and returns the number
+// of deleted rows.
+func (c *Client) DeleteItemType(ctx context.Context, name string) (int64, error) {
+	var resp deleteResponse
+	err := c.do(ctx, c.conf(http.MethodDelete, "/itemtypes/%s", url.PathEscape(name)), &resp)
+	return resp.RowsAffected, err
+}
+
+// ListModules lists the modules matching the options.
+func (c *Client) ListModules(ctx context.Context, opts *ListOptions) ([]*Module, error) {
+	r := c.conf(http.MethodGet, "/modules")
+	r.query = opts.query()
+
+	var resp modulesResponse
+	err := c.do(ctx, r, &resp)
+	return resp.Modules, err
+}
+
+// GetModule gets the module with the id.
+func (c *Client) GetModule(ctx context.Context, id int64) (*Module, error) {
+	var resp moduleResponse
+	if err := c.do(ctx, c.conf(http.MethodGet, "/modules/%d", id), &resp); err != nil {
+		return nil, err
+	}
+	if resp.Module == nil {
+		return nil, missing("module", id)
+	}
+	return resp.Module, nil
+}
+
+// CreateModule creates the module and returns it with its id.
+func (c *Client) CreateModule(ctx context.Context, m *Module) (*Module, error) {
+	r := c.conf(http.MethodPost, "/modules")
+	r.body = m
+
+	var resp moduleResponse
+	err := c.do(ctx, r, &resp)
+	return resp.Module, err
+}
+
+// UpdateModule saves the module with the id of the module.
+func (c *Client) UpdateModule(ctx context.Context, m *Module) (*Module, error) {
+	r := c.conf(http.MethodPut, "/modules/%d", m.ID)
+	r.body = m
+
+	var resp moduleResponse
+	err := c.do(ctx, r, &resp)
+	return resp.Module, err
+}
+
+// SetModuleLabels replaces the labels of the module with the id.
+func (c *Client) SetModuleLabels(ctx context.Context, id int64, labels map[string]string) (map[string]string, error) {
+	r := c.conf(http.MethodPut, "/modules/%d/labels", id)
+	r.body = labels
+
+	var resp labelsResponse
+	err := c.do(ctx, r, &resp)
+	return resp.Labels, err
+}
+
+// The directions of a module graph.
+const (
+	// Down follows the modules a module depends on.
+	Down = "down"
+	// Up follows the modules depending on a module.
+	Up = "up"
+)
+
+// GraphOptions limit the graph of a module.
+type GraphOptions struct {
+	// Direction is Down, the default, or Up.
+	Direction string
+	// Depth is the number of dependencies to follow, every one if it is 0.
+	Depth int
+}
+
+// ModuleGraph gets the transitive dependencies of the module with the id.
+func (c *Client) ModuleGraph(ctx context.Context, id int64, opts *GraphOptions) (*Graph, error) {
+	r := c.conf(http.MethodGet, "/modules/%d/graph", id)
+	r.query = url.Values{}
+	if opts != nil {
+		if opts.Direction != "" {
+			r.query.Set("direction", opts.Direction)
+		}
+		if opts.Depth > 0 {
+			r.query.Set("depth", strconv.Itoa(opts.Depth))
+		}
+	}
+
+	var resp Graph
+	if err := c.do(ctx, r, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ModuleImpact gets what is affected if the module with the id is deleted.
+func (c *Client) ModuleImpact(ctx context.Context, id int64) (*Impact, error) {
+	var resp impactResponse
+	err := c.do(ctx, c.conf(http.MethodGet, "/modules/%d/impact", id), &resp)
+	return resp.Impact, err
+}
+
+// CloneModule copies the module with the id to a new module at the version
+// with its items and dependencies. With rewire the modules depending on the
+// module depend on the copy instead.
+func (c *Client) CloneModule(ctx context.Context, id int64, version string, rewire bool) (*ModuleClone, error) {
+	r := c.conf(http.MethodPost, "/modules/%d/clone", id)
+	r.body = struct {
+		Version string `json:"version"`
+		Rewire  bool   `json:"rewire"`
+	}{version, rewire}
+
+	var resp cloneResponse
+	err := c.do(ctx, r, &resp)
+	return resp.Clone, err
+}
+
+// DeleteModuleOptions tell what is deleted with a module.
+type DeleteModuleOptions struct {
+	// CascadeDependencies deletes the module dependencies of the module.
+	CascadeDependencies bool
+	// Force orphans the items only linked to the module.
+	Force bool
+}
+
+// DeleteModule deletes the module with the id and returns the number of
+// deleted rows. A module in use is refused with an Error holding the Impact.
+func (c *Client) DeleteModule(ctx context.Context, id int64, opts *DeleteModuleOptions) (int64, error) {
+	r := c.conf(http.MethodDelete, "/modules/%d", id)
+	r.query = url.Values{}
+	if opts != nil {
+		if opts.CascadeDependencies {
+			r.query.Set("cascade", "dependencies")
+		}
+		if opts.Force {
+			r.query.Set("force", "")
+		}
+	}
+
+	var resp deleteResponse
+	err := c.do(ctx, r, &resp)
+	return resp.RowsAffected, err
+}
+
+// ListItemModules lists the links between items and modules.
+func (c *Client) ListItemModules(ctx context.Context) ([]*ItemModule, error) {
+	var resp itemModulesResponse
+	err := c.do(ctx, c.conf(http.MethodGet, "/itemmodules"), &resp)
+	return resp.ItemModules, err
+}
+
+// GetItemModule gets the item module with the id.
+func (c *Client) GetItemModule(ctx context.Context, id int64) (*ItemModule, error) {
+	var resp itemModuleResponse
+	if err := c.do(ctx, c.conf(http.MethodGet, "/itemmodules/%d", id), &resp); err != nil {
+		return nil, err
+	}
+	if resp.ItemModule == nil {
+		return nil, missing("item module", id)
+	}
+	return resp.ItemModule, nil
+}
+
+// CreateItemModule links the item to the module.
+func (c *Client) CreateItemModule(ctx context.Context, itemID, moduleID int64) (*ItemModule, error) {
+	r := c.conf(http.MethodPost, "/itemmodules")
+	r.body = &ItemModule{ItemID: itemID, ModuleID: moduleID}
+
+	var resp itemModuleResponse
+	err := c.do(ctx, r, &resp)
+	return resp.ItemModule, err
+}
+
+// DeleteItemModule deletes the item module with the id and returns the number
+// of deleted rows.
+func (c *Client) DeleteItemModule(ctx context.Context, id int64) (int64, error) {
+	var resp deleteResponse
+	err := c.do(ctx, c.conf(http.MethodDelete, "/itemmodules/%d", id), &resp)
+	return resp.RowsAffected, err
+}
+
+// ListModuleDependencies lists the module dependencies.
+func (c *Client) ListModuleDependencies(ctx context.Context) ([]*ModuleDependency, error) {
+	var resp moduleDependenciesResponse
+	err := c.do(ctx, c.conf(http.MethodGet, "/moduledependencies"), &resp)
+	return resp.ModuleDependencies, err
+}
+
+// ListDependencies lists the module dependencies of the dependent.
+func (c *Client) ListDependencies(ctx context.Context, dependent int64) ([]*ModuleDependency, error) {
+	var resp moduleDependenciesResponse
+	err := c.do(ctx, c.conf(http.MethodGet, "/moduledependencies/dependent/%d", dependent), &resp)
+	return resp.ModuleDependencies, err
+}
+
+// ListDependents lists the module dependencies on the dependee.
+func (c *Client) ListDependents(ctx context.Context, dependee int64) ([]*ModuleDependency, error) {
+	var resp moduleDependenciesResponse
+	err := c.do(ctx, c.conf(http.MethodGet, "/moduledependencies/dependee/%d", dependee), &resp)
+	return resp.ModuleDependencies, err
+}
+
+// CreateModuleDependency makes the dependent depend on the dependee. A
+// dependency without a kind is Required.
+func (c *Client) CreateModuleDependency(ctx context.Context, d *ModuleDependency) (*ModuleDependency, error) {
+	r := c.conf(http.MethodPost, "/moduledependencies")
+	r.body = d
+
+	var resp moduleDependencyResponse
+	err := c.do(ctx, r, &resp)
+	return resp.ModuleDependency, err
+}
+
+// DeleteModuleDependency deletes the dependency of the dependent on the
+// dependee and returns the number of deleted rows.
+func (c *Client) DeleteModuleDependency(ctx context.Context, dependent, dependee int64) (int64, error) {
+	var resp deleteResponse
+	err := c.do(ctx, c.conf(http.MethodDelete, "/moduledependencies/dependent/%d/dependee/%d", dependent, dependee), &resp)
+	return resp.RowsAffected, err
+}
+
+// DeleteDependencies deletes the module dependencies of the dependent and
+// returns the number of deleted rows.
+func (c *Client) DeleteDependencies(ctx context.Context, dependent int64) (int64, error) {
+	var resp deleteResponse
+	err := c.do(ctx, c.conf(http.MethodDelete, "/moduledependencies/dependent/%d", dependent), &resp)
+	return resp.RowsAffected, err
+}
+
+// DeleteDependents deletes the module dependencies on the dependee and returns
+// the number of deleted rows.
+func (c *Client) DeleteDependents(ctx context.Context, dependee int64) (int64, error) {
+	var resp deleteResponse
+	err := c.do(ctx, c.conf(http.MethodDelete, "/moduledependencies/dependee/%d", dependee), &resp)
+	return resp.RowsAffected, err
+}
+
+// ImportCSV imports the items of the CSV. With dryRun the rows are only
+// validated. A CSV with invalid rows is refused with an Error holding the
+// Rows.
+func (c *Client) ImportCSV(ctx context.Context, csv io.Reader, dryRun bool) (*Import, error) {
+	r := c.conf(http.MethodPost, "/import/csv")
+	r.body = csv
+	r.contentType = "text/csv"
+	if dryRun {
+		r.query = url.Values{"dry_run": {"true"}}
+	}
+
+	var resp Import
+	if err := c.do(ctx, r, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ListReleases lists the releases.
+func (c *Client) ListReleases(ctx context.Context) ([]*Release, error) {
+	var resp releasesResponse
+	err := c.do(ctx, c.reveal(c.conf(http.MethodGet, "/releases")), &resp)
+	return resp.Releases, err
+}
+
+// GetRelease gets the release with the name.
+func (c *Client) GetRelease(ctx context.Context, name string) (*Release, error) {
+	var resp releaseResponse
+	err := c.do(ctx, c.reveal(c.conf(http.MethodGet, "/releases/%s", url.PathEscape(name))), &resp)
+	return resp.Release, err
+}
+
+// CreateRelease freezes the modules and their closure under the name. With
+// optional the optional dependencies are followed too.
+func (c *Client) CreateRelease(ctx context.Context, name string, modules []int64, optional bool) (*Release, error) {
+	r := c.conf(http.MethodPost, "/releases")
+	r.body = &Release{Name: name, Modules: modules, Optional: optional}
+
+	var resp releaseResponse
+	err := c.do(ctx, r, &resp)
+	return resp.Release, err
+}
+
+// PromoteRelease promotes the release to the environment, or the environment
+// after the last one it reached if to is empty. A promotion into a protected
+// environment is Pending until it is approved.
+func (c *Client) PromoteRelease(ctx context.Context, name, to string) (*Promotion, error) {
+	r := c.principalRequest(c.conf(http.MethodPost, "/releases/%s/promote", url.PathEscape(name)))
+	r.body = struct {
+		To string `json:"to,omitempty"`
+	}{to}
+
+	var resp promotionResponse
+	err := c.do(ctx, r, &resp)
+	return resp.Promotion, err
+}
+
+// ListPromotions lists the promotions of the release.
+func (c *Client) ListPromotions(ctx context.Context, name string) ([]*Promotion, error) {
+	var resp promotionsResponse
+	err := c.do(ctx, c.conf(http.MethodGet, "/releases/%s/promotions", url.PathEscape(name)), &resp)
+	return resp.Promotions, err
+}
+
+// ApprovePromotion approves the pending promotion with the id of the release.
+func (c *Client) ApprovePromotion(ctx context.Context, name string, id int64) (*Promotion, error) {
+	r := c.principalRequest(c.conf(http.MethodPost, "/releases/%s/promotions/%d/approve", url.PathEscape(name), id))
+
+	var resp promotionResponse
+	err := c.do(ctx, r, &resp)
+	return resp.Promotion, err
+}
+
+// principalRequest sends the principal of the client with the request.
+func (c *Client) principalRequest(r *request) *request {
+	if c.principal == "" {
+		return r
+	}
+
+	if r.header == nil {
+		r.header = http.Header{}
+	}
+	r.header.Set(principalHeader, c.principal)
+	return r
+}
+
+// GraphQL runs the query with the variables and decodes the data into out.
+// Errors of the query are returned as GraphQLErrors after the data is decoded.
+func (c *Client) GraphQL(ctx context.Context, query string, variables map[string]interface{}, out interface{}) error {
+	r := c.conf(http.MethodPost, "/graphql")
+	r.body = struct {
+		Query     string                 `json:"query"`
+		Variables map[string]interface{} `json:"variables,omitempty"`
+	}{query, variables}
+
+	var resp struct {
+		Data   json.RawMessage `json:"data"`
+		Errors GraphQLErrors   `json:"errors"`
+	}
+	if err := c.do(ctx, r, &resp); err != nil {
+		return err
+	}
+
+	if out != nil && len(resp.Data) > 0 && string(resp.Data) != "null" {
+		if err := json.Unmarshal(resp.Data, out); err != nil {
+			return err
+		}
+	}
+
+	if len(resp.Errors) > 0 {
+		return resp.Errors
+	}
+	return nil
+}
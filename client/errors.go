@@ -0,0 +1,98 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// The kinds of errors a service responds with. Use errors.Is to check an
+// error returned by the client against them.
+var (
+	// ErrInvalid is a request which the service refused, see the Fields of
+	// the Error.
+	ErrInvalid = errors.New("invalid request")
+	// ErrNotFound is a request for something which does not exist.
+	ErrNotFound = errors.New("not found")
+	// ErrConflict is a request which conflicts with what is stored, e.g. a
+	// module which is in use or modules of an insfile which conflict.
+	ErrConflict = errors.New("conflict")
+	// ErrUnavailable is a service which is down or failed.
+	ErrUnavailable = errors.New("service unavailable")
+)
+
+// FieldError reports why the value of a single field was rejected.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (f *FieldError) String() string {
+	return fmt.Sprintf("%v: %v", f.Field, f.Message)
+}
+
+// Error is the error response of a service. Besides the message it holds what
+// the service reported with it, which depends on the route.
+type Error struct {
+	StatusCode int    `json:"-"`
+	Message    string `json:"error"`
+	// Fields are the fields which were rejected.
+	Fields []*FieldError `json:"fields,omitempty"`
+	// ModuleConflicts are the modules of an insfile or release which
+	// conflict.
+	ModuleConflicts []*ModuleConflict `json:"module_conflicts,omitempty"`
+	// Conflicts are the item conflicts of an insfile with the error policy.
+	Conflicts []*ItemConflict `json:"conflicts,omitempty"`
+	// Impact is why a module could not be deleted.
+	Impact *Impact `json:"impact,omitempty"`
+	// Rows are the rows of an import which was refused.
+	Rows []*ImportRow `json:"rows,omitempty"`
+}
+
+func (e *Error) Error() string {
+	msg := e.Message
+	if msg == "" {
+		msg = http.StatusText(e.StatusCode)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%v (%v)", msg, e.StatusCode)
+	for _, f := range e.Fields {
+		fmt.Fprintf(&b, "; %v", f)
+	}
+	return b.String()
+}
+
+// Is reports whether the status of the response is of the kind of target.
+func (e *Error) Is(target error) bool {
+	switch target {
+	case ErrInvalid:
+		return e.StatusCode == http.StatusBadRequest
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrConflict:
+		return e.StatusCode == http.StatusConflict
+	case ErrUnavailable:
+		return e.StatusCode >= http.StatusInternalServerError
+	}
+	return false
+}
+
+// GraphQLError is an error of a GraphQL query.
+type GraphQLError struct {
+	Message string        `json:"message"`
+	Path    []interface{} `json:"path,omitempty"`
+}
+
+// GraphQLErrors are the errors of a GraphQL query which the confservice
+// answered. The data of the fields which did resolve is still decoded.
+type GraphQLErrors []*GraphQLError
+
+func (errs GraphQLErrors) Error() string {
+	msgs := make([]string, len(errs))
+	for i, e := range errs {
+		msgs[i] = e.Message
+	}
+	return "graphql: " + strings.Join(msgs, "; ")
+}
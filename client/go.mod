@@ -0,0 +1,3 @@
+module github.com/Glorforidor/conmansys/client
+
+go 1.13
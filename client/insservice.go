@@ -0,0 +1,180 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// The item conflict policies of an insfile.
+const (
+	// PolicyNearestModule picks the version of the module closest to the
+	// requested modules.
+	PolicyNearestModule = "nearest-module"
+	// PolicyHighestVersion picks the highest version.
+	PolicyHighestVersion = "highest-version"
+	// PolicyError refuses the insfile.
+	PolicyError = "error"
+)
+
+// InsfileOptions decide how an insfile is resolved.
+type InsfileOptions struct {
+	// Optional follows the optional dependencies too.
+	Optional bool
+	// Policy decides item conflicts, PolicyNearestModule if it is empty.
+	Policy string
+}
+
+func (o *InsfileOptions) query() url.Values {
+	q := url.Values{}
+	if o == nil {
+		return q
+	}
+
+	if o.Optional {
+		q.Set("optional", "true")
+	}
+	if o.Policy != "" {
+		q.Set("policy", o.Policy)
+	}
+	return q
+}
+
+// Modules are the modules an insfile is rendered for, either by their ids or
+// by a label selector.
+type Modules struct {
+	IDs      []int64
+	Selector string
+}
+
+// body returns the request body of the modules.
+func (m Modules) body() interface{} {
+	if m.Selector != "" {
+		return struct {
+			Selector string `json:"selector"`
+		}{m.Selector}
+	}
+
+	type module struct {
+		ID int64 `json:"id"`
+	}
+	ms := make([]module, len(m.IDs))
+	for i, id := range m.IDs {
+		ms[i] = module{id}
+	}
+	return ms
+}
+
+// insfile sends a request for an insfile and decodes the response. Conflicts
+// are returned as an Error holding them.
+func (c *Client) insfile(ctx context.Context, r *request) (*Insfile, error) {
+	var resp Insfile
+	if err := c.do(ctx, r, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Insfile renders the insfile of the modules with the modules it is made of.
+func (c *Client) Insfile(ctx context.Context, modules Modules, opts *InsfileOptions) (*Insfile, error) {
+	r := c.ins(http.MethodPost, "/insfile")
+	r.query = opts.query()
+	r.body = modules.body()
+	return c.insfile(ctx, r)
+}
+
+// InsfileText renders the insfile of the modules as text.
+func (c *Client) InsfileText(ctx context.Context, modules Modules, opts *InsfileOptions) (string, error) {
+	r := c.ins(http.MethodPost, "/insfile/text")
+	r.query = opts.query()
+	r.body = modules.body()
+	return c.doText(ctx, r)
+}
+
+// TraverseInsfile renders the items of the insfile of the modules.
+func (c *Client) TraverseInsfile(ctx context.Context, modules Modules, opts *InsfileOptions) (*Insfile, error) {
+	r := c.ins(http.MethodPost, "/insfile/traverse")
+	r.query = opts.query()
+	r.body = modules.body()
+	return c.insfile(ctx, r)
+}
+
+// TraverseInsfileText renders the items of the insfile of the modules as text,
+// one value per line.
+func (c *Client) TraverseInsfileText(ctx context.Context, modules Modules, opts *InsfileOptions) (string, error) {
+	r := c.ins(http.MethodPost, "/insfile/traverse/text")
+	r.query = opts.query()
+	r.body = modules.body()
+	return c.doText(ctx, r)
+}
+
+// ReleaseInsfile renders the insfile of the release from what was frozen in
+// it. Only the Policy of the options is used.
+func (c *Client) ReleaseInsfile(ctx context.Context, name string, opts *InsfileOptions) (*Insfile, error) {
+	r := c.ins(http.MethodGet, "/insfile/release/%s", url.PathEscape(name))
+	r.query = policyQuery(opts)
+	return c.insfile(ctx, r)
+}
+
+// ReleaseInsfileText renders the insfile of the release as text.
+func (c *Client) ReleaseInsfileText(ctx context.Context, name string, opts *InsfileOptions) (string, error) {
+	r := c.ins(http.MethodGet, "/insfile/release/%s/text", url.PathEscape(name))
+	r.query = policyQuery(opts)
+	return c.doText(ctx, r)
+}
+
+// EnvironmentInsfile renders the insfile of the release in the environment.
+// Only the Policy of the options is used.
+func (c *Client) EnvironmentInsfile(ctx context.Context, env string, opts *InsfileOptions) (*Insfile, error) {
+	r := c.ins(http.MethodGet, "/insfile/environment/%s", url.PathEscape(env))
+	r.query = policyQuery(opts)
+	return c.insfile(ctx, r)
+}
+
+// EnvironmentInsfileText renders the insfile of the release in the
+// environment as text.
+func (c *Client) EnvironmentInsfileText(ctx context.Context, env string, opts *InsfileOptions) (string, error) {
+	r := c.ins(http.MethodGet, "/insfile/environment/%s/text", url.PathEscape(env))
+	r.query = policyQuery(opts)
+	return c.doText(ctx, r)
+}
+
+// policyQuery returns the query of the options without optional, which the
+// insfile of a release takes from the release.
+func policyQuery(opts *InsfileOptions) url.Values {
+	q := opts.query()
+	q.Del("optional")
+	return q
+}
+
+// Environment gets the promotion of the release in the environment.
+func (c *Client) Environment(ctx context.Context, env string) (*Promotion, error) {
+	var resp promotionResponse
+	err := c.do(ctx, c.ins(http.MethodGet, "/environments/%s", url.PathEscape(env)), &resp)
+	return resp.Promotion, err
+}
+
+// Diff lists the items and modules which are added, removed or changed going
+// from one side to the other. A side is a release, "release:<name>", or module
+// ids, "modules:1,2".
+func (c *Client) Diff(ctx context.Context, from, to string, opts *InsfileOptions) (*Diff, error) {
+	r := c.ins(http.MethodGet, "/diff")
+	r.query = opts.query()
+	r.query.Set("from", from)
+	r.query.Set("to", to)
+
+	var resp Diff
+	if err := c.do(ctx, r, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// DiffText lists the changes going from one side to the other as text.
+func (c *Client) DiffText(ctx context.Context, from, to string, opts *InsfileOptions) (string, error) {
+	r := c.ins(http.MethodGet, "/diff/text")
+	r.query = opts.query()
+	r.query.Set("from", from)
+	r.query.Set("to", to)
+	return c.doText(ctx, r)
+}
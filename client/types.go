@@ -0,0 +1,249 @@
+package client
+
+import "time"
+
+// Metadata describes an item or a module. CreatedAt and UpdatedAt are set by
+// the confservice and ignored when an item or module is saved.
+type Metadata struct {
+	Description string                 `json:"description"`
+	Owner       string                 `json:"owner"`
+	Homepage    string                 `json:"homepage"`
+	Annotations map[string]interface{} `json:"annotations"`
+	CreatedAt   time.Time              `json:"created_at"`
+	UpdatedAt   time.Time              `json:"updated_at"`
+}
+
+// Item is a single configuration value. The value of a secret item is masked
+// unless it is revealed.
+type Item struct {
+	ID      int64             `json:"id"`
+	Value   string            `json:"value"`
+	Type    string            `json:"type"`
+	Version string            `json:"version"`
+	Secret  bool              `json:"secret"`
+	Labels  map[string]string `json:"labels"`
+	Metadata
+}
+
+// ItemType describes the values an item of a given type may hold.
+type ItemType struct {
+	Name    string   `json:"name"`
+	Pattern string   `json:"pattern,omitempty"`
+	Enum    []string `json:"enum,omitempty"`
+	Min     *int64   `json:"min,omitempty"`
+	Max     *int64   `json:"max,omitempty"`
+}
+
+type Module struct {
+	ID      int64             `json:"id"`
+	Value   string            `json:"value"`
+	Version string            `json:"version"`
+	Labels  map[string]string `json:"labels"`
+	Metadata
+}
+
+// ModuleClone is a copy of a module at a new version together with how many of
+// its item modules and module dependencies were copied and how many dependents
+// were moved to the copy.
+type ModuleClone struct {
+	Module       *Module `json:"module"`
+	From         int64   `json:"from"`
+	Items        int64   `json:"items"`
+	Dependencies int64   `json:"dependencies"`
+	Rewired      int64   `json:"rewired"`
+}
+
+type ItemModule struct {
+	ID       int64 `json:"id"`
+	ItemID   int64 `json:"item_id"`
+	ModuleID int64 `json:"module_id"`
+}
+
+// The kinds of module dependencies.
+const (
+	Required   = "required"
+	Optional   = "optional"
+	Conflicts  = "conflicts"
+	Recommends = "recommends"
+)
+
+// ModuleDependency makes the dependent depend on the dependee in the way given
+// by the kind. A dependency without a kind is required.
+type ModuleDependency struct {
+	Dependent int64  `json:"dependent"`
+	Dependee  int64  `json:"dependee"`
+	Kind      string `json:"kind"`
+}
+
+// GraphNode is a module of a dependency graph with its distance from the root.
+type GraphNode struct {
+	*Module
+	Depth int `json:"depth"`
+}
+
+// Graph is the transitive dependencies of a module.
+type Graph struct {
+	Root      int64               `json:"root"`
+	Direction string              `json:"direction"`
+	Nodes     []*GraphNode        `json:"nodes"`
+	Edges     []*ModuleDependency `json:"edges"`
+}
+
+// Impact is what is affected if a module is deleted.
+type Impact struct {
+	Module        *Module             `json:"module"`
+	Dependents    []*GraphNode        `json:"dependents"`
+	Dependencies  []*ModuleDependency `json:"dependencies"`
+	OrphanedItems []*Item             `json:"orphaned_items"`
+	Insfiles      []int64             `json:"insfiles"`
+}
+
+// ImportRow is the outcome of a row of a CSV import.
+type ImportRow struct {
+	Row          int           `json:"row"`
+	Outcome      string        `json:"outcome"`
+	ItemID       int64         `json:"item_id,omitempty"`
+	ModuleID     int64         `json:"module_id,omitempty"`
+	ItemModuleID int64         `json:"item_module_id,omitempty"`
+	Created      []string      `json:"created,omitempty"`
+	Fields       []*FieldError `json:"fields,omitempty"`
+}
+
+// Import is the outcome of a CSV import.
+type Import struct {
+	DryRun bool         `json:"dry_run"`
+	Rows   []*ImportRow `json:"rows"`
+}
+
+// Release is a named set of modules frozen together with the modules and items
+// of their closure.
+type Release struct {
+	Name      string           `json:"name"`
+	Modules   []int64          `json:"modules"`
+	Optional  bool             `json:"optional"`
+	Closure   []*ReleaseModule `json:"closure,omitempty"`
+	CreatedAt time.Time        `json:"created_at"`
+}
+
+// ReleaseModule is a module of the closure of a release with its items.
+type ReleaseModule struct {
+	ID      int64   `json:"id"`
+	Value   string  `json:"value"`
+	Version string  `json:"version"`
+	Depth   int     `json:"depth"`
+	From    int64   `json:"from,omitempty"`
+	Kind    string  `json:"kind,omitempty"`
+	Items   []*Item `json:"items"`
+}
+
+// The states of a promotion.
+const (
+	Pending  = "pending"
+	Promoted = "promoted"
+)
+
+// Promotion records that a release was asked to move into an environment.
+type Promotion struct {
+	ID          int64      `json:"id"`
+	Release     string     `json:"release"`
+	Environment string     `json:"environment"`
+	Status      string     `json:"status"`
+	RequestedBy string     `json:"requested_by"`
+	RequestedAt time.Time  `json:"requested_at"`
+	ApprovedBy  string     `json:"approved_by,omitempty"`
+	PromotedAt  *time.Time `json:"promoted_at,omitempty"`
+}
+
+// Exclusion is a module left out of an insfile and why.
+type Exclusion struct {
+	ID     int64  `json:"id"`
+	From   int64  `json:"from"`
+	Kind   string `json:"kind"`
+	Reason string `json:"reason"`
+}
+
+// ModuleConflict tells that two modules of an insfile conflict.
+type ModuleConflict struct {
+	ID     int64  `json:"id"`
+	With   int64  `json:"with"`
+	Reason string `json:"reason"`
+}
+
+// Candidate is a version of an item contributed by a module.
+type Candidate struct {
+	Module  int64  `json:"module"`
+	Depth   int    `json:"depth"`
+	Version string `json:"version"`
+}
+
+// ItemConflict is an item contributed by several modules at different
+// versions and the version the policy picked.
+type ItemConflict struct {
+	Value      string       `json:"value"`
+	Type       string       `json:"type"`
+	Candidates []*Candidate `json:"candidates"`
+	Winner     string       `json:"winner,omitempty"`
+	Policy     string       `json:"policy"`
+	Reason     string       `json:"reason"`
+}
+
+// InsfileItem is an item of an insfile.
+type InsfileItem struct {
+	ID      int64  `json:"id,omitempty"`
+	Value   string `json:"value,omitempty"`
+	Type    string `json:"type,omitempty"`
+	Version string `json:"version,omitempty"`
+}
+
+// InsfileModule is a module an insfile is made of.
+type InsfileModule struct {
+	ID      int64             `json:"id"`
+	Value   string            `json:"value,omitempty"`
+	Version string            `json:"version,omitempty"`
+	Labels  map[string]string `json:"labels,omitempty"`
+}
+
+// Insfile is the items of an insfile together with what it was resolved from.
+// Promotion is only set for the insfile of an environment.
+type Insfile struct {
+	Items           []*InsfileItem    `json:"items"`
+	Modules         []*InsfileModule  `json:"modules"`
+	Excluded        []*Exclusion      `json:"excluded"`
+	ModuleConflicts []*ModuleConflict `json:"module_conflicts"`
+	Conflicts       []*ItemConflict   `json:"conflicts"`
+	Promotion       *Promotion        `json:"promotion,omitempty"`
+}
+
+// ItemChange is an item whose version differs between the sides of a diff.
+type ItemChange struct {
+	Value string `json:"value"`
+	Type  string `json:"type"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+// ModuleChange is a module whose value or version differs between the sides
+// of a diff.
+type ModuleChange struct {
+	ID    int64  `json:"id"`
+	Value string `json:"value"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+// Diff is what is added, removed and changed going from one side to the other.
+type Diff struct {
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Items struct {
+		Added   []*InsfileItem `json:"added"`
+		Removed []*InsfileItem `json:"removed"`
+		Changed []*ItemChange  `json:"changed"`
+	} `json:"items"`
+	Modules struct {
+		Added   []*InsfileModule `json:"added"`
+		Removed []*InsfileModule `json:"removed"`
+		Changed []*ModuleChange  `json:"changed"`
+	} `json:"modules"`
+	Conflicts []*ItemConflict `json:"conflicts"`
+}
@@ -0,0 +1,387 @@
+// Package config loads the configuration of a service from its defaults, an
+// optional YAML file, the environment and the flags, each overriding the ones
+// before it. All services load their configuration with it, so they are
+// configured the same way.
+//
+// The configuration is a struct whose fields name their sources in tags:
+//
+//	type configuration struct {
+//		Addr   string `yaml:"addr" env:"ADDR" flag:"addr" usage:"address of the HTTP server"`
+//		DBPass string `yaml:"db_pass" env:"DBPASS" flag:"db-pass" secret:"true" usage:"password of the database"`
+//	}
+//
+// Fields are strings, ints, bools, durations like 15s or lists of strings,
+// which are comma separated in the environment and the flags. The fields of a
+// struct tagged `yaml:",inline"` are read like fields of the configuration, so
+// settings shared by several services can be declared once. Secrets are read
+// from the file of the variable with _FILE appended, e.g. DBPASS_FILE, the key
+// with _file appended or the flag with -file appended, as Docker and
+// Kubernetes mount them, or from the variable and the key themselves. They are
+// not taken from a flag, which would show them in the list of processes, and
+// Print redacts them.
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// FileFlag and FileEnv name the YAML file of the configuration.
+	FileFlag = "config"
+	FileEnv  = "CONFIG_FILE"
+	// PrintFlag asks to print the configuration instead of running.
+	PrintFlag = "print-config"
+)
+
+// redacted is printed instead of the value of a secret.
+const redacted = "REDACTED"
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// field is a field of the configuration and the names of its sources.
+type field struct {
+	key    string
+	env    string
+	flag   string
+	usage  string
+	secret bool
+	v      reflect.Value
+}
+
+// set parses s into the field.
+func (f *field) set(s string) error {
+	switch {
+	case f.v.Type() == durationType:
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return fmt.Errorf("must be a duration like 15s, got: %q", s)
+		}
+		f.v.SetInt(int64(d))
+	case f.v.Kind() == reflect.String:
+		f.v.SetString(s)
+	case f.v.Kind() == reflect.Int:
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return fmt.Errorf("must be a number, got: %q", s)
+		}
+		f.v.SetInt(int64(n))
+	case f.v.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return fmt.Errorf("must be true or false, got: %q", s)
+		}
+		f.v.SetBool(b)
+	case f.v.Kind() == reflect.Slice:
+		// an empty list is set, only a missing one is nil
+		l := []string{}
+		for _, e := range strings.Split(s, ",") {
+			if e = strings.TrimSpace(e); e != "" {
+				l = append(l, e)
+			}
+		}
+		f.v.Set(reflect.ValueOf(l))
+	}
+	return nil
+}
+
+// value returns the value of the field as it is printed.
+func (f *field) value() interface{} {
+	switch {
+	case f.secret && !f.v.IsZero():
+		return redacted
+	case f.v.Type() == durationType:
+		return time.Duration(f.v.Int()).String()
+	case f.v.Kind() == reflect.Slice && f.v.IsNil():
+		// null, not an empty list which would be set
+		return nil
+	default:
+		return f.v.Interface()
+	}
+}
+
+func fields(cfg interface{}) ([]*field, error) {
+	v := reflect.ValueOf(cfg)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return nil, errors.New("configuration must be a pointer to a struct")
+	}
+
+	return structFields(v.Elem())
+}
+
+// structFields returns the fields of the struct and of the structs inlined into
+// it.
+func structFields(v reflect.Value) ([]*field, error) {
+	var fs []*field
+	for i := 0; i < v.NumField(); i++ {
+		sf := v.Type().Field(i)
+		key := sf.Tag.Get("yaml")
+		if key == "" || key == "-" {
+			continue
+		}
+
+		fv := v.Field(i)
+		if key == ",inline" && fv.Kind() == reflect.Struct {
+			inlined, err := structFields(fv)
+			if err != nil {
+				return nil, err
+			}
+			fs = append(fs, inlined...)
+			continue
+		}
+
+		switch fv.Kind() {
+		case reflect.String, reflect.Int, reflect.Bool:
+		case reflect.Int64:
+			if fv.Type() != durationType {
+				return nil, fmt.Errorf("field %v has unsupported type %v", sf.Name, fv.Type())
+			}
+		case reflect.Slice:
+			if fv.Type().Elem().Kind() != reflect.String {
+				return nil, fmt.Errorf("field %v has unsupported type %v", sf.Name, fv.Type())
+			}
+		default:
+			return nil, fmt.Errorf("field %v has unsupported type %v", sf.Name, fv.Type())
+		}
+
+		fs = append(fs, &field{
+			key:    key,
+			env:    sf.Tag.Get("env"),
+			flag:   sf.Tag.Get("flag"),
+			usage:  sf.Tag.Get("usage"),
+			secret: sf.Tag.Get("secret") == "true",
+			v:      fv,
+		})
+	}
+
+	return fs, nil
+}
+
+// flagValue keeps the value of a flag until the flags are applied last.
+type flagValue struct {
+	f     *field
+	file  bool
+	value *string
+}
+
+func (v flagValue) String() string {
+	// flag shows no default for the empty string
+	if v.f == nil || v.file || v.f.v.IsZero() {
+		return ""
+	}
+	if l, ok := v.f.value().([]string); ok {
+		return strings.Join(l, ",")
+	}
+	return fmt.Sprint(v.f.value())
+}
+
+func (v flagValue) Set(s string) error {
+	*v.value = s
+	return nil
+}
+
+func (v flagValue) IsBoolFlag() bool {
+	return v.f != nil && !v.file && v.f.v.Kind() == reflect.Bool
+}
+
+// Load fills cfg, a pointer to a struct with the defaults, from the YAML file
+// of -config or CONFIG_FILE, the environment and the flags in args. It
+// registers the flags of the fields, -config and -print-config on fs and
+// reports whether -print-config was given.
+func Load(fs *flag.FlagSet, args []string, cfg interface{}) (bool, error) {
+	fields, err := fields(cfg)
+	if err != nil {
+		return false, err
+	}
+
+	file := fs.String(FileFlag, "", "YAML file of the configuration, also "+FileEnv)
+	printConfig := fs.Bool(PrintFlag, false, "print the configuration with the secrets redacted and exit")
+
+	// the flags are applied after the file and the environment
+	var flags []flagValue
+	for _, f := range fields {
+		if f.flag == "" {
+			continue
+		}
+		v := flagValue{f: f, file: f.secret, value: new(string)}
+		name, usage, env := f.flag, f.usage, f.env
+		if f.secret {
+			name, usage, env = name+"-file", "file of the "+usage, env+"_FILE"
+		}
+		if f.env != "" {
+			usage += ", also " + env
+		}
+		fs.Var(v, name, usage)
+		flags = append(flags, v)
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return false, err
+	}
+	set := make(map[*string]bool)
+	fs.Visit(func(fl *flag.Flag) {
+		if v, ok := fl.Value.(flagValue); ok {
+			set[v.value] = true
+		}
+	})
+
+	if *file == "" {
+		*file = os.Getenv(FileEnv)
+	}
+	if *file != "" {
+		if err := loadFile(*file, fields); err != nil {
+			return false, err
+		}
+	}
+
+	if err := loadEnv(fields); err != nil {
+		return false, err
+	}
+
+	for _, s := range flags {
+		if !set[s.value] {
+			continue
+		}
+		v := *s.value
+		if s.file {
+			if v, err = readSecret(v); err != nil {
+				return false, fmt.Errorf("flag -%v-file: %v", s.f.flag, err)
+			}
+		}
+		if err := s.f.set(v); err != nil {
+			return false, fmt.Errorf("flag -%v: %v", s.f.flag, err)
+		}
+	}
+
+	return *printConfig, nil
+}
+
+func loadFile(path string, fields []*field) error {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("could not read configuration: %v", err)
+	}
+
+	var m map[string]interface{}
+	if err := yaml.Unmarshal(b, &m); err != nil {
+		return fmt.Errorf("could not decode configuration %v: %v", path, err)
+	}
+
+	byKey := make(map[string]*field, len(fields))
+	for _, f := range fields {
+		byKey[f.key] = f
+		if f.secret {
+			byKey[f.key+"_file"] = f
+		}
+	}
+
+	for key, raw := range m {
+		f, ok := byKey[key]
+		if !ok {
+			return fmt.Errorf("configuration %v: unknown key %q", path, key)
+		}
+
+		var v string
+		switch raw := raw.(type) {
+		case nil:
+			// like a key which is not there
+			continue
+		case []interface{}:
+			l := make([]string, len(raw))
+			for i, e := range raw {
+				l[i] = fmt.Sprint(e)
+			}
+			v = strings.Join(l, ",")
+		default:
+			v = fmt.Sprint(raw)
+		}
+
+		if key != f.key {
+			if _, ok := m[f.key]; ok {
+				return fmt.Errorf("configuration %v: only one of %v and %v may be set", path, f.key, key)
+			}
+			if v, err = readSecret(v); err != nil {
+				return fmt.Errorf("configuration %v: %v: %v", path, key, err)
+			}
+		}
+
+		if err := f.set(v); err != nil {
+			return fmt.Errorf("configuration %v: %v: %v", path, key, err)
+		}
+	}
+
+	return nil
+}
+
+func loadEnv(fields []*field) error {
+	for _, f := range fields {
+		if f.env == "" {
+			continue
+		}
+
+		v, ok := os.LookupEnv(f.env)
+		if f.secret {
+			path, isFile := os.LookupEnv(f.env + "_FILE")
+			if ok && isFile {
+				return fmt.Errorf("only one of %v and %v_FILE may be set", f.env, f.env)
+			}
+			if isFile {
+				s, err := readSecret(path)
+				if err != nil {
+					return fmt.Errorf("%v_FILE: %v", f.env, err)
+				}
+				v, ok = s, true
+			}
+		}
+		if !ok {
+			continue
+		}
+
+		if err := f.set(v); err != nil {
+			return fmt.Errorf("%v: %v", f.env, err)
+		}
+	}
+
+	return nil
+}
+
+// readSecret reads the secret in the file without the line break editors and
+// echo leave at its end.
+func readSecret(path string) (string, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("could not read secret: %v", err)
+	}
+	return strings.TrimRight(string(b), "\r\n"), nil
+}
+
+// Print writes cfg as YAML in the format of the file of Load, with the values
+// of the secrets redacted.
+func Print(w io.Writer, cfg interface{}) error {
+	fields, err := fields(cfg)
+	if err != nil {
+		return err
+	}
+
+	m := make(yaml.MapSlice, 0, len(fields))
+	for _, f := range fields {
+		m = append(m, yaml.MapItem{Key: f.key, Value: f.value()})
+	}
+
+	b, err := yaml.Marshal(m)
+	if err != nil {
+		return fmt.Errorf("could not encode configuration: %v", err)
+	}
+	_, err = w.Write(b)
+	return err
+}
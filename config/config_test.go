@@ -0,0 +1,173 @@
+package config
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testConfig struct {
+	Addr    string        `yaml:"addr" env:"TEST_ADDR" flag:"addr" usage:"address"`
+	Timeout time.Duration `yaml:"timeout" env:"TEST_TIMEOUT" flag:"timeout" usage:"timeout"`
+	Retries int           `yaml:"retries" env:"TEST_RETRIES" flag:"retries" usage:"retries"`
+	Debug   bool          `yaml:"debug" env:"TEST_DEBUG" flag:"debug" usage:"debug"`
+	Envs    []string      `yaml:"envs" env:"TEST_ENVS" flag:"envs" usage:"environments"`
+	Pass    string        `yaml:"pass" env:"TEST_PASS" flag:"pass" secret:"true" usage:"password"`
+	DB      testDB        `yaml:",inline"`
+}
+
+// testDB is inlined into testConfig like settings shared by services.
+type testDB struct {
+	Host string `yaml:"db_host" env:"TEST_DB_HOST" flag:"db-host" usage:"database host"`
+}
+
+func defaults() testConfig {
+	return testConfig{Addr: ":80", Timeout: 15 * time.Second, Retries: 10, DB: testDB{Host: "localhost"}}
+}
+
+func write(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := write(t, dir, "config.yaml", `
+addr: ":8080"
+timeout: 30s
+retries: 3
+envs: [staging, prod]
+db_host: db
+pass_file: `+write(t, dir, "pass", "from file\n")+`
+`)
+	secret := write(t, dir, "secret", "flag secret\n")
+
+	tt := map[string]struct {
+		env  map[string]string
+		args []string
+		want testConfig
+		err  bool
+	}{
+		"defaults": {
+			want: defaults(),
+		},
+		"file": {
+			args: []string{"-config", file},
+			want: testConfig{Addr: ":8080", Timeout: 30 * time.Second, Retries: 3, Envs: []string{"staging", "prod"}, Pass: "from file", DB: testDB{Host: "db"}},
+		},
+		"file of the environment": {
+			env:  map[string]string{FileEnv: file},
+			want: testConfig{Addr: ":8080", Timeout: 30 * time.Second, Retries: 3, Envs: []string{"staging", "prod"}, Pass: "from file", DB: testDB{Host: "db"}},
+		},
+		"environment over file": {
+			env:  map[string]string{"TEST_ADDR": ":9090", "TEST_ENVS": "", "TEST_PASS": "from env", "TEST_DEBUG": "true", "TEST_DB_HOST": "envdb"},
+			args: []string{"-config", file},
+			want: testConfig{Addr: ":9090", Timeout: 30 * time.Second, Retries: 3, Debug: true, Envs: []string{}, Pass: "from env", DB: testDB{Host: "envdb"}},
+		},
+		"flags over environment": {
+			env:  map[string]string{"TEST_ADDR": ":9090", "TEST_RETRIES": "5", "TEST_PASS_FILE": file},
+			args: []string{"-config", file, "-addr", ":7070", "-debug", "-pass-file", secret, "-db-host", "flagdb"},
+			want: testConfig{Addr: ":7070", Timeout: 30 * time.Second, Retries: 5, Debug: true, Envs: []string{"staging", "prod"}, Pass: "flag secret", DB: testDB{Host: "flagdb"}},
+		},
+		"secret twice": {
+			env: map[string]string{"TEST_PASS": "a", "TEST_PASS_FILE": secret},
+			err: true,
+		},
+		"bad number": {
+			env: map[string]string{"TEST_RETRIES": "many"},
+			err: true,
+		},
+		"bad duration": {
+			args: []string{"-timeout", "15"},
+			err:  true,
+		},
+		"null in file": {
+			args: []string{"-config", write(t, dir, "null.yaml", "envs: null\naddr:\n")},
+			want: defaults(),
+		},
+		"unknown key": {
+			args: []string{"-config", write(t, dir, "unknown.yaml", "port: 80\n")},
+			err:  true,
+		},
+		"missing secret": {
+			args: []string{"-pass-file", filepath.Join(dir, "missing")},
+			err:  true,
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			for k, v := range tc.env {
+				os.Setenv(k, v)
+				defer os.Unsetenv(k)
+			}
+
+			cfg := defaults()
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			fs.SetOutput(ioutil.Discard)
+			_, err := Load(fs, tc.args, &cfg)
+			if err != nil {
+				if !tc.err {
+					t.Fatalf("expected no error, got: %v", err)
+				}
+				return
+			}
+			if tc.err {
+				t.Fatal("expected an error")
+			}
+
+			if !reflect.DeepEqual(cfg, tc.want) {
+				t.Fatalf("expected: %+v, got: %+v", tc.want, cfg)
+			}
+		})
+	}
+}
+
+func TestPrint(t *testing.T) {
+	cfg := defaults()
+	cfg.Pass = "hunter2"
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	printConfig, err := Load(fs, []string{"-print-config"}, &cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !printConfig {
+		t.Fatal("expected -print-config to be reported")
+	}
+
+	var buf bytes.Buffer
+	if err := Print(&buf, &cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `addr: :80
+timeout: 15s
+retries: 10
+debug: false
+envs: null
+pass: REDACTED
+db_host: localhost
+`
+	if buf.String() != want {
+		t.Fatalf("expected:\n%v\ngot:\n%v", want, buf.String())
+	}
+	if strings.Contains(buf.String(), "hunter2") {
+		t.Fatal("expected the secret to be redacted")
+	}
+}
@@ -0,0 +1,5 @@
+module github.com/Glorforidor/conmansys/config
+
+go 1.21
+
+require gopkg.in/yaml.v2 v2.3.0
@@ -0,0 +1,804 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.2.0
+// - protoc             v3.19.1
+// source: confservice.proto
+
+package confpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// ConfServiceClient is the client API for ConfService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type ConfServiceClient interface {
+	GetItem(ctx context.Context, in *GetItemRequest, opts ...grpc.CallOption) (*Item, error)
+	// ListItems streams every item which matches the selector.
+	ListItems(ctx context.Context, in *ListItemsRequest, opts ...grpc.CallOption) (ConfService_ListItemsClient, error)
+	CreateItem(ctx context.Context, in *Item, opts ...grpc.CallOption) (*Item, error)
+	UpdateItem(ctx context.Context, in *Item, opts ...grpc.CallOption) (*Item, error)
+	DeleteItem(ctx context.Context, in *IDRequest, opts ...grpc.CallOption) (*DeleteResponse, error)
+	GetModule(ctx context.Context, in *IDRequest, opts ...grpc.CallOption) (*Module, error)
+	// ListModules streams every module which matches the selector.
+	ListModules(ctx context.Context, in *ListModulesRequest, opts ...grpc.CallOption) (ConfService_ListModulesClient, error)
+	CreateModule(ctx context.Context, in *Module, opts ...grpc.CallOption) (*Module, error)
+	UpdateModule(ctx context.Context, in *Module, opts ...grpc.CallOption) (*Module, error)
+	// DeleteModule refuses with FAILED_PRECONDITION to delete a module which is
+	// part of module dependencies or the only module of items. With cascade the
+	// module dependencies are deleted with it, but items are never orphaned.
+	DeleteModule(ctx context.Context, in *DeleteModuleRequest, opts ...grpc.CallOption) (*DeleteResponse, error)
+	GetItemModule(ctx context.Context, in *IDRequest, opts ...grpc.CallOption) (*ItemModule, error)
+	ListItemModules(ctx context.Context, in *ListItemModulesRequest, opts ...grpc.CallOption) (ConfService_ListItemModulesClient, error)
+	CreateItemModule(ctx context.Context, in *ItemModule, opts ...grpc.CallOption) (*ItemModule, error)
+	DeleteItemModule(ctx context.Context, in *IDRequest, opts ...grpc.CallOption) (*DeleteResponse, error)
+	// ListModuleDependencies streams the module dependencies of the dependent
+	// or dependee if either is set and every module dependency otherwise.
+	ListModuleDependencies(ctx context.Context, in *ListModuleDependenciesRequest, opts ...grpc.CallOption) (ConfService_ListModuleDependenciesClient, error)
+	CreateModuleDependency(ctx context.Context, in *ModuleDependency, opts ...grpc.CallOption) (*ModuleDependency, error)
+	DeleteModuleDependency(ctx context.Context, in *DeleteModuleDependencyRequest, opts ...grpc.CallOption) (*DeleteResponse, error)
+}
+
+type confServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewConfServiceClient(cc grpc.ClientConnInterface) ConfServiceClient {
+	return &confServiceClient{cc}
+}
+
+func (c *confServiceClient) GetItem(ctx context.Context, in *GetItemRequest, opts ...grpc.CallOption) (*Item, error) {
+	out := new(Item)
+	err := c.cc.Invoke(ctx, "/conmansys.confservice.ConfService/GetItem", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *confServiceClient) ListItems(ctx context.Context, in *ListItemsRequest, opts ...grpc.CallOption) (ConfService_ListItemsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &ConfService_ServiceDesc.Streams[0], "/conmansys.confservice.ConfService/ListItems", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &confServiceListItemsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type ConfService_ListItemsClient interface {
+	Recv() (*Item, error)
+	grpc.ClientStream
+}
+
+type confServiceListItemsClient struct {
+	grpc.ClientStream
+}
+
+func (x *confServiceListItemsClient) Recv() (*Item, error) {
+	m := new(Item)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *confServiceClient) CreateItem(ctx context.Context, in *Item, opts ...grpc.CallOption) (*Item, error) {
+	out := new(Item)
+	err := c.cc.Invoke(ctx, "/conmansys.confservice.ConfService/CreateItem", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *confServiceClient) UpdateItem(ctx context.Context, in *Item, opts ...grpc.CallOption) (*Item, error) {
+	out := new(Item)
+	err := c.cc.Invoke(ctx, "/conmansys.confservice.ConfService/UpdateItem", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *confServiceClient) DeleteItem(ctx context.Context, in *IDRequest, opts ...grpc.CallOption) (*DeleteResponse, error) {
+	out := new(DeleteResponse)
+	err := c.cc.Invoke(ctx, "/conmansys.confservice.ConfService/DeleteItem", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *confServiceClient) GetModule(ctx context.Context, in *IDRequest, opts ...grpc.CallOption) (*Module, error) {
+	out := new(Module)
+	err := c.cc.Invoke(ctx, "/conmansys.confservice.ConfService/GetModule", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *confServiceClient) ListModules(ctx context.Context, in *ListModulesRequest, opts ...grpc.CallOption) (ConfService_ListModulesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &ConfService_ServiceDesc.Streams[1], "/conmansys.confservice.ConfService/ListModules", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &confServiceListModulesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type ConfService_ListModulesClient interface {
+	Recv() (*Module, error)
+	grpc.ClientStream
+}
+
+type confServiceListModulesClient struct {
+	grpc.ClientStream
+}
+
+func (x *confServiceListModulesClient) Recv() (*Module, error) {
+	m := new(Module)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *confServiceClient) CreateModule(ctx context.Context, in *Module, opts ...grpc.CallOption) (*Module, error) {
+	out := new(Module)
+	err := c.cc.Invoke(ctx, "/conmansys.confservice.ConfService/CreateModule", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *confServiceClient) UpdateModule(ctx context.Context, in *Module, opts ...grpc.CallOption) (*Module, error) {
+	out := new(Module)
+	err := c.cc.Invoke(ctx, "/conmansys.confservice.ConfService/UpdateModule", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *confServiceClient) DeleteModule(ctx context.Context, in *DeleteModuleRequest, opts ...grpc.CallOption) (*DeleteResponse, error) {
+	out := new(DeleteResponse)
+	err := c.cc.Invoke(ctx, "/conmansys.confservice.ConfService/DeleteModule", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *confServiceClient) GetItemModule(ctx context.Context, in *IDRequest, opts ...grpc.CallOption) (*ItemModule, error) {
+	out := new(ItemModule)
+	err := c.cc.Invoke(ctx, "/conmansys.confservice.ConfService/GetItemModule", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *confServiceClient) ListItemModules(ctx context.Context, in *ListItemModulesRequest, opts ...grpc.CallOption) (ConfService_ListItemModulesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &ConfService_ServiceDesc.Streams[2], "/conmansys.confservice.ConfService/ListItemModules", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &confServiceListItemModulesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type ConfService_ListItemModulesClient interface {
+	Recv() (*ItemModule, error)
+	grpc.ClientStream
+}
+
+type confServiceListItemModulesClient struct {
+	grpc.ClientStream
+}
+
+func (x *confServiceListItemModulesClient) Recv() (*ItemModule, error) {
+	m := new(ItemModule)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *confServiceClient) CreateItemModule(ctx context.Context, in *ItemModule, opts ...grpc.CallOption) (*ItemModule, error) {
+	out := new(ItemModule)
+	err := c.cc.Invoke(ctx, "/conmansys.confservice.ConfService/CreateItemModule", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *confServiceClient) DeleteItemModule(ctx context.Context, in *IDRequest, opts ...grpc.CallOption) (*DeleteResponse, error) {
+	out := new(DeleteResponse)
+	err := c.cc.Invoke(ctx, "/conmansys.confservice.ConfService/DeleteItemModule", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *confServiceClient) ListModuleDependencies(ctx context.Context, in *ListModuleDependenciesRequest, opts ...grpc.CallOption) (ConfService_ListModuleDependenciesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &ConfService_ServiceDesc.Streams[3], "/conmansys.confservice.ConfService/ListModuleDependencies", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &confServiceListModuleDependenciesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type ConfService_ListModuleDependenciesClient interface {
+	Recv() (*ModuleDependency, error)
+	grpc.ClientStream
+}
+
+type confServiceListModuleDependenciesClient struct {
+	grpc.ClientStream
+}
+
+func (x *confServiceListModuleDependenciesClient) Recv() (*ModuleDependency, error) {
+	m := new(ModuleDependency)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *confServiceClient) CreateModuleDependency(ctx context.Context, in *ModuleDependency, opts ...grpc.CallOption) (*ModuleDependency, error) {
+	out := new(ModuleDependency)
+	err := c.cc.Invoke(ctx, "/conmansys.confservice.ConfService/CreateModuleDependency", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *confServiceClient) DeleteModuleDependency(ctx context.Context, in *DeleteModuleDependencyRequest, opts ...grpc.CallOption) (*DeleteResponse, error) {
+	out := new(DeleteResponse)
+	err := c.cc.Invoke(ctx, "/conmansys.confservice.ConfService/DeleteModuleDependency", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ConfServiceServer is the server API for ConfService service.
+// All implementations must embed UnimplementedConfServiceServer
+// for forward compatibility
+type ConfServiceServer interface {
+	GetItem(context.Context, *GetItemRequest) (*Item, error)
+	// ListItems streams every item which matches the selector.
+	ListItems(*ListItemsRequest, ConfService_ListItemsServer) error
+	CreateItem(context.Context, *Item) (*Item, error)
+	UpdateItem(context.Context, *Item) (*Item, error)
+	DeleteItem(context.Context, *IDRequest) (*DeleteResponse, error)
+	GetModule(context.Context, *IDRequest) (*Module, error)
+	// ListModules streams every module which matches the selector.
+	ListModules(*ListModulesRequest, ConfService_ListModulesServer) error
+	CreateModule(context.Context, *Module) (*Module, error)
+	UpdateModule(context.Context, *Module) (*Module, error)
+	// DeleteModule refuses with FAILED_PRECONDITION to delete a module which is
+	// part of module dependencies or the only module of items. With cascade the
+	// module dependencies are deleted with it, but items are never orphaned.
+	DeleteModule(context.Context, *DeleteModuleRequest) (*DeleteResponse, error)
+	GetItemModule(context.Context, *IDRequest) (*ItemModule, error)
+	ListItemModules(*ListItemModulesRequest, ConfService_ListItemModulesServer) error
+	CreateItemModule(context.Context, *ItemModule) (*ItemModule, error)
+	DeleteItemModule(context.Context, *IDRequest) (*DeleteResponse, error)
+	// ListModuleDependencies streams the module dependencies of the dependent
+	// or dependee if either is set and every module dependency otherwise.
+	ListModuleDependencies(*ListModuleDependenciesRequest, ConfService_ListModuleDependenciesServer) error
+	CreateModuleDependency(context.Context, *ModuleDependency) (*ModuleDependency, error)
+	DeleteModuleDependency(context.Context, *DeleteModuleDependencyRequest) (*DeleteResponse, error)
+	mustEmbedUnimplementedConfServiceServer()
+}
+
+// UnimplementedConfServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedConfServiceServer struct {
+}
+
+func (UnimplementedConfServiceServer) GetItem(context.Context, *GetItemRequest) (*Item, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetItem not implemented")
+}
+func (UnimplementedConfServiceServer) ListItems(*ListItemsRequest, ConfService_ListItemsServer) error {
+	return status.Errorf(codes.Unimplemented, "method ListItems not implemented")
+}
+func (UnimplementedConfServiceServer) CreateItem(context.Context, *Item) (*Item, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateItem not implemented")
+}
+func (UnimplementedConfServiceServer) UpdateItem(context.Context, *Item) (*Item, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateItem not implemented")
+}
+func (UnimplementedConfServiceServer) DeleteItem(context.Context, *IDRequest) (*DeleteResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteItem not implemented")
+}
+func (UnimplementedConfServiceServer) GetModule(context.Context, *IDRequest) (*Module, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetModule not implemented")
+}
+func (UnimplementedConfServiceServer) ListModules(*ListModulesRequest, ConfService_ListModulesServer) error {
+	return status.Errorf(codes.Unimplemented, "method ListModules not implemented")
+}
+func (UnimplementedConfServiceServer) CreateModule(context.Context, *Module) (*Module, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateModule not implemented")
+}
+func (UnimplementedConfServiceServer) UpdateModule(context.Context, *Module) (*Module, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateModule not implemented")
+}
+func (UnimplementedConfServiceServer) DeleteModule(context.Context, *DeleteModuleRequest) (*DeleteResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteModule not implemented")
+}
+func (UnimplementedConfServiceServer) GetItemModule(context.Context, *IDRequest) (*ItemModule, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetItemModule not implemented")
+}
+func (UnimplementedConfServiceServer) ListItemModules(*ListItemModulesRequest, ConfService_ListItemModulesServer) error {
+	return status.Errorf(codes.Unimplemented, "method ListItemModules not implemented")
+}
+func (UnimplementedConfServiceServer) CreateItemModule(context.Context, *ItemModule) (*ItemModule, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateItemModule not implemented")
+}
+func (UnimplementedConfServiceServer) DeleteItemModule(context.Context, *IDRequest) (*DeleteResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteItemModule not implemented")
+}
+func (UnimplementedConfServiceServer) ListModuleDependencies(*ListModuleDependenciesRequest, ConfService_ListModuleDependenciesServer) error {
+	return status.Errorf(codes.Unimplemented, "method ListModuleDependencies not implemented")
+}
+func (UnimplementedConfServiceServer) CreateModuleDependency(context.Context, *ModuleDependency) (*ModuleDependency, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateModuleDependency not implemented")
+}
+func (UnimplementedConfServiceServer) DeleteModuleDependency(context.Context, *DeleteModuleDependencyRequest) (*DeleteResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteModuleDependency not implemented")
+}
+func (UnimplementedConfServiceServer) mustEmbedUnimplementedConfServiceServer() {}
+
+// UnsafeConfServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ConfServiceServer will
+// result in compilation errors.
+type UnsafeConfServiceServer interface {
+	mustEmbedUnimplementedConfServiceServer()
+}
+
+func RegisterConfServiceServer(s grpc.ServiceRegistrar, srv ConfServiceServer) {
+	s.RegisterService(&ConfService_ServiceDesc, srv)
+}
+
+func _ConfService_GetItem_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetItemRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ConfServiceServer).GetItem(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/conmansys.confservice.ConfService/GetItem",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ConfServiceServer).GetItem(ctx, req.(*GetItemRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ConfService_ListItems_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ListItemsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ConfServiceServer).ListItems(m, &confServiceListItemsServer{stream})
+}
+
+type ConfService_ListItemsServer interface {
+	Send(*Item) error
+	grpc.ServerStream
+}
+
+type confServiceListItemsServer struct {
+	grpc.ServerStream
+}
+
+func (x *confServiceListItemsServer) Send(m *Item) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _ConfService_CreateItem_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Item)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ConfServiceServer).CreateItem(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/conmansys.confservice.ConfService/CreateItem",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ConfServiceServer).CreateItem(ctx, req.(*Item))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ConfService_UpdateItem_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Item)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ConfServiceServer).UpdateItem(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/conmansys.confservice.ConfService/UpdateItem",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ConfServiceServer).UpdateItem(ctx, req.(*Item))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ConfService_DeleteItem_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(IDRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ConfServiceServer).DeleteItem(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/conmansys.confservice.ConfService/DeleteItem",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ConfServiceServer).DeleteItem(ctx, req.(*IDRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ConfService_GetModule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(IDRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ConfServiceServer).GetModule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/conmansys.confservice.ConfService/GetModule",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ConfServiceServer).GetModule(ctx, req.(*IDRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ConfService_ListModules_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ListModulesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ConfServiceServer).ListModules(m, &confServiceListModulesServer{stream})
+}
+
+type ConfService_ListModulesServer interface {
+	Send(*Module) error
+	grpc.ServerStream
+}
+
+type confServiceListModulesServer struct {
+	grpc.ServerStream
+}
+
+func (x *confServiceListModulesServer) Send(m *Module) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _ConfService_CreateModule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Module)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ConfServiceServer).CreateModule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/conmansys.confservice.ConfService/CreateModule",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ConfServiceServer).CreateModule(ctx, req.(*Module))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ConfService_UpdateModule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Module)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ConfServiceServer).UpdateModule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/conmansys.confservice.ConfService/UpdateModule",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ConfServiceServer).UpdateModule(ctx, req.(*Module))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ConfService_DeleteModule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteModuleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ConfServiceServer).DeleteModule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/conmansys.confservice.ConfService/DeleteModule",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ConfServiceServer).DeleteModule(ctx, req.(*DeleteModuleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ConfService_GetItemModule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(IDRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ConfServiceServer).GetItemModule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/conmansys.confservice.ConfService/GetItemModule",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ConfServiceServer).GetItemModule(ctx, req.(*IDRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ConfService_ListItemModules_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ListItemModulesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ConfServiceServer).ListItemModules(m, &confServiceListItemModulesServer{stream})
+}
+
+type ConfService_ListItemModulesServer interface {
+	Send(*ItemModule) error
+	grpc.ServerStream
+}
+
+type confServiceListItemModulesServer struct {
+	grpc.ServerStream
+}
+
+func (x *confServiceListItemModulesServer) Send(m *ItemModule) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _ConfService_CreateItemModule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ItemModule)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ConfServiceServer).CreateItemModule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/conmansys.confservice.ConfService/CreateItemModule",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ConfServiceServer).CreateItemModule(ctx, req.(*ItemModule))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ConfService_DeleteItemModule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(IDRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ConfServiceServer).DeleteItemModule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/conmansys.confservice.ConfService/DeleteItemModule",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ConfServiceServer).DeleteItemModule(ctx, req.(*IDRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ConfService_ListModuleDependencies_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ListModuleDependenciesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ConfServiceServer).ListModuleDependencies(m, &confServiceListModuleDependenciesServer{stream})
+}
+
+type ConfService_ListModuleDependenciesServer interface {
+	Send(*ModuleDependency) error
+	grpc.ServerStream
+}
+
+type confServiceListModuleDependenciesServer struct {
+	grpc.ServerStream
+}
+
+func (x *confServiceListModuleDependenciesServer) Send(m *ModuleDependency) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _ConfService_CreateModuleDependency_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ModuleDependency)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ConfServiceServer).CreateModuleDependency(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/conmansys.confservice.ConfService/CreateModuleDependency",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ConfServiceServer).CreateModuleDependency(ctx, req.(*ModuleDependency))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ConfService_DeleteModuleDependency_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteModuleDependencyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ConfServiceServer).DeleteModuleDependency(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/conmansys.confservice.ConfService/DeleteModuleDependency",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ConfServiceServer).DeleteModuleDependency(ctx, req.(*DeleteModuleDependencyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// ConfService_ServiceDesc is the grpc.ServiceDesc for ConfService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ConfService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "conmansys.confservice.ConfService",
+	HandlerType: (*ConfServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetItem",
+			Handler:    _ConfService_GetItem_Handler,
+		},
+		{
+			MethodName: "CreateItem",
+			Handler:    _ConfService_CreateItem_Handler,
+		},
+		{
+			MethodName: "UpdateItem",
+			Handler:    _ConfService_UpdateItem_Handler,
+		},
+		{
+			MethodName: "DeleteItem",
+			Handler:    _ConfService_DeleteItem_Handler,
+		},
+		{
+			MethodName: "GetModule",
+			Handler:    _ConfService_GetModule_Handler,
+		},
+		{
+			MethodName: "CreateModule",
+			Handler:    _ConfService_CreateModule_Handler,
+		},
+		{
+			MethodName: "UpdateModule",
+			Handler:    _ConfService_UpdateModule_Handler,
+		},
+		{
+			MethodName: "DeleteModule",
+			Handler:    _ConfService_DeleteModule_Handler,
+		},
+		{
+			MethodName: "GetItemModule",
+			Handler:    _ConfService_GetItemModule_Handler,
+		},
+		{
+			MethodName: "CreateItemModule",
+			Handler:    _ConfService_CreateItemModule_Handler,
+		},
+		{
+			MethodName: "DeleteItemModule",
+			Handler:    _ConfService_DeleteItemModule_Handler,
+		},
+		{
+			MethodName: "CreateModuleDependency",
+			Handler:    _ConfService_CreateModuleDependency_Handler,
+		},
+		{
+			MethodName: "DeleteModuleDependency",
+			Handler:    _ConfService_DeleteModuleDependency_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ListItems",
+			Handler:       _ConfService_ListItems_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "ListModules",
+			Handler:       _ConfService_ListModules_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "ListItemModules",
+			Handler:       _ConfService_ListItemModules_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "ListModuleDependencies",
+			Handler:       _ConfService_ListModuleDependencies_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "confservice.proto",
+}
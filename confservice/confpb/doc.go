@@ -0,0 +1,5 @@
+// Package confpb holds the protobuf messages and the gRPC service of the
+// confservice generated from confservice.proto.
+package confpb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative confservice.proto
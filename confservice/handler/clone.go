@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Glorforidor/conmansys/confservice/storage"
+	"github.com/gorilla/mux"
+)
+
+type cloneRequest struct {
+	// Version is the version of the copy.
+	Version string `json:"version"`
+	// Rewire moves the dependents of the module to the copy.
+	Rewire bool `json:"rewire"`
+}
+
+type cloneResponse struct {
+	Clone  *storage.ModuleClone  `json:"clone"`
+	Error  *string               `json:"error"`
+	Fields []*storage.FieldError `json:"fields,omitempty"`
+}
+
+// cloneModule copies the module with the id in the path to a new module with
+// the version in the request body, together with its items and the modules it
+// depends on. With rewire the modules which depend on the module depend on the
+// copy instead, while the modules conflicting with it keep conflicting with the
+// module only. A module with the same value at the new version gives a
+// conflict status. It returns the response as an empty interface and a http
+// status.
+func (h handler) cloneModule(r *http.Request) (data interface{}, status int) {
+	params := mux.Vars(r)
+	id := strings.TrimSpace(params["id"])
+	var resp cloneResponse
+	var req cloneRequest
+	var errMsg string
+
+	// routing should prevent this, but might as well guard it
+	if id == "" {
+		errMsg = errMissingValue.Error()
+		resp.Error = &errMsg
+		return resp, http.StatusBadRequest
+	}
+
+	i, err := strconv.ParseInt(id, 10, 64)
+	// routing should prevent this, but might as well guard it
+	if err != nil {
+		errMsg = errNaN.Error()
+		resp.Error = &errMsg
+		return resp, http.StatusBadRequest
+	}
+
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		errMsg = errWrongFormat.Error()
+		resp.Error = &errMsg
+		return resp, http.StatusBadRequest
+	}
+
+	req.Version = strings.TrimSpace(req.Version)
+	if req.Version == "" {
+		errMsg = errMissingValue.Error()
+		resp.Error = &errMsg
+		return resp, http.StatusBadRequest
+	}
+
+	modules, err := h.storage.GetModules(r.Context())
+	if err != nil {
+		logError(r, err)
+		errMsg = errInternal.Error()
+		resp.Error = &errMsg
+		return resp, http.StatusInternalServerError
+	}
+
+	var module *storage.Module
+	for _, m := range modules {
+		if m.ID == i {
+			module = m
+		}
+	}
+
+	if module == nil {
+		errMsg = errNotFound.Error()
+		resp.Error = &errMsg
+		return resp, http.StatusNotFound
+	}
+
+	if req.Version == module.Version {
+		errMsg = errInvalid.Error()
+		resp.Error = &errMsg
+		resp.Fields = []*storage.FieldError{{
+			Field:   "version",
+			Message: fmt.Sprintf("must differ from the version %q of the module", module.Version),
+		}}
+		return resp, http.StatusBadRequest
+	}
+
+	for _, m := range modules {
+		if m.Value == module.Value && m.Version == req.Version {
+			errMsg = fmt.Sprintf("module %q %v already exists as module %v", m.Value, m.Version, m.ID)
+			resp.Error = &errMsg
+			return resp, http.StatusConflict
+		}
+	}
+
+	clone, err := h.storage.CloneModule(r.Context(), i, req.Version, req.Rewire)
+	if err != nil {
+		logError(r, err)
+		errMsg = errInternal.Error()
+		resp.Error = &errMsg
+		return resp, http.StatusInternalServerError
+	}
+
+	// deleted since it was read
+	if clone == nil {
+		errMsg = errNotFound.Error()
+		resp.Error = &errMsg
+		return resp, http.StatusNotFound
+	}
+
+	resp.Clone = clone
+	return resp, http.StatusCreated
+}
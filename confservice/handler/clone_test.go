@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Glorforidor/conmansys/confservice/storage"
+)
+
+func TestCloneModule(t *testing.T) {
+	tt := map[string]struct {
+		url          string
+		body         string
+		status       int
+		items        int64
+		dependencies int64
+		rewired      int64
+		closed       bool
+	}{
+		"clone": {
+			url: "/modules/2/clone", body: `{"version": "0.0.3"}`,
+			status: http.StatusCreated, items: 1, dependencies: 1,
+		},
+		"rewire": {
+			url: "/modules/2/clone", body: `{"version": "0.0.3", "rewire": true}`,
+			status: http.StatusCreated, items: 1, dependencies: 1, rewired: 1,
+		},
+		"same version":    {url: "/modules/2/clone", body: `{"version": "0.0.1"}`, status: http.StatusBadRequest},
+		"existing":        {url: "/modules/2/clone", body: `{"version": "0.0.2"}`, status: http.StatusConflict},
+		"missing version": {url: "/modules/2/clone", body: `{}`, status: http.StatusBadRequest},
+		"missing":         {url: "/modules/42/clone", body: `{"version": "0.0.2"}`, status: http.StatusNotFound},
+		"wrong format":    {url: "/modules/2/clone", body: `[]`, status: http.StatusBadRequest},
+		"closed storage": {
+			url: "/modules/2/clone", body: `{"version": "0.0.2"}`, status: http.StatusInternalServerError, closed: true,
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			db := newReleaseDB()
+			// module 5 is module B at version 0.0.2 already
+			db.modules = append(db.modules, &storage.Module{ID: 5, Value: "B", Version: "0.0.2"})
+			db.closed = tc.closed
+			srv := httptest.NewServer(New(db))
+			defer srv.Close()
+
+			resp, err := http.Post(srv.URL+tc.url, "application/json", bytes.NewBufferString(tc.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tc.status {
+				t.Fatalf("expected status: %v, got: %v", tc.status, resp.StatusCode)
+			}
+
+			var body cloneResponse
+			if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+				t.Fatal(err)
+			}
+
+			if tc.status != http.StatusCreated {
+				if body.Error == nil {
+					t.Fatal("expected an error, got: nil")
+				}
+				return
+			}
+
+			c := body.Clone
+			if c.Module.Value != "B" || c.Module.Version != "0.0.3" || c.From != 2 {
+				t.Fatalf("expected a copy of module 2 at 0.0.3, got: %+v", c.Module)
+			}
+			if c.Items != tc.items || c.Dependencies != tc.dependencies || c.Rewired != tc.rewired {
+				t.Fatalf("expected %v items, %v dependencies and %v rewired, got: %v, %v and %v",
+					tc.items, tc.dependencies, tc.rewired, c.Items, c.Dependencies, c.Rewired)
+			}
+
+			// module 4 conflicts with module 2, which stays with module 2
+			for _, md := range db.dependencies {
+				if md.Dependee == 2 && md.Kind != storage.Conflicts && tc.rewired > 0 {
+					t.Fatalf("expected every dependent to be moved to the copy, got: %+v", md)
+				}
+				if md.Dependent == 4 && md.Dependee != 2 {
+					t.Fatalf("expected the conflict to stay with module 2, got: %+v", md)
+				}
+			}
+		})
+	}
+}
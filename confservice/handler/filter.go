@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Glorforidor/conmansys/confservice/storage"
+	"github.com/Glorforidor/conmansys/labels"
+)
+
+// filter holds the query parameters which narrow down the item and module
+// lists. A filter with every field left empty matches everything.
+type filter struct {
+	selector     labels.Selector
+	owner        string
+	search       string
+	annotation   string
+	updatedSince time.Time
+}
+
+// parseFilter reads the filter from the query of the request:
+//
+//	selector       label selector, see the labels package
+//	owner          the owner must be exactly this
+//	q              the value or description must contain this, ignoring case
+//	annotation     the annotation with this key must be set
+//	updated_since  updated at or after this RFC 3339 time
+func parseFilter(r *http.Request) (filter, error) {
+	q := r.URL.Query()
+	var f filter
+
+	sel, err := labels.Parse(q.Get("selector"))
+	if err != nil {
+		return filter{}, err
+	}
+	f.selector = sel
+
+	f.owner = strings.TrimSpace(q.Get("owner"))
+	f.search = strings.ToLower(strings.TrimSpace(q.Get("q")))
+	f.annotation = strings.TrimSpace(q.Get("annotation"))
+
+	if s := strings.TrimSpace(q.Get("updated_since")); s != "" {
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return filter{}, fmt.Errorf("updated_since must be a RFC 3339 time: %v", err)
+		}
+		f.updatedSince = t
+	}
+
+	return f, nil
+}
+
+func (f filter) matches(value string, l map[string]string, m *storage.Metadata) bool {
+	if !f.selector.Matches(l) {
+		return false
+	}
+
+	if f.owner != "" && m.Owner != f.owner {
+		return false
+	}
+
+	if f.search != "" &&
+		!strings.Contains(strings.ToLower(value), f.search) &&
+		!strings.Contains(strings.ToLower(m.Description), f.search) {
+		return false
+	}
+
+	if f.annotation != "" {
+		if _, ok := m.Annotations[f.annotation]; !ok {
+			return false
+		}
+	}
+
+	if !f.updatedSince.IsZero() && m.UpdatedAt.Before(f.updatedSince) {
+		return false
+	}
+
+	return true
+}
+
+// filterItems returns the items which are matched by the filter.
+func filterItems(f filter, items []*storage.Item) []*storage.Item {
+	filtered := []*storage.Item{}
+	for _, i := range items {
+		if f.matches(i.Value, i.Labels, &i.Metadata) {
+			filtered = append(filtered, i)
+		}
+	}
+
+	return filtered
+}
+
+// filterModules returns the modules which are matched by the filter.
+func filterModules(f filter, modules []*storage.Module) []*storage.Module {
+	filtered := []*storage.Module{}
+	for _, m := range modules {
+		if f.matches(m.Value, m.Labels, &m.Metadata) {
+			filtered = append(filtered, m)
+		}
+	}
+
+	return filtered
+}
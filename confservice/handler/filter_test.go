@@ -0,0 +1,166 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestFilter(t *testing.T) {
+	router := New(db)
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+
+	tt := map[string]struct {
+		url    string
+		query  url.Values
+		status int
+		want   []int64
+	}{
+		"items by owner":          {url: "items", query: url.Values{"owner": {"billing"}}, status: http.StatusOK, want: []int64{1}},
+		"items by description":    {url: "items", query: url.Values{"q": {"ENDPOINT"}}, status: http.StatusOK, want: []int64{1}},
+		"items by value":          {url: "items", query: url.Values{"q": {"hunter"}}, status: http.StatusOK, want: []int64{3}},
+		"items by annotation":     {url: "items", query: url.Values{"annotation": {"runbook"}}, status: http.StatusOK, want: []int64{2}},
+		"items updated since":     {url: "items", query: url.Values{"updated_since": {"2026-09-15T00:00:00Z"}}, status: http.StatusOK, want: []int64{1}},
+		"items owner and label":   {url: "items", query: url.Values{"owner": {"tax"}, "selector": {"team=billing"}}, status: http.StatusOK, want: []int64{}},
+		"items bad updated since": {url: "items", query: url.Values{"updated_since": {"yesterday"}}, status: http.StatusBadRequest},
+		"modules by owner":        {url: "modules", query: url.Values{"owner": {"billing"}}, status: http.StatusOK, want: []int64{1}},
+		"modules by description":  {url: "modules", query: url.Values{"q": {"billing"}}, status: http.StatusOK, want: []int64{1}},
+		"modules bad since":       {url: "modules", query: url.Values{"updated_since": {"2026-10-01"}}, status: http.StatusBadRequest},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			resp, err := http.Get(fmt.Sprintf("%v/%v?%v", srv.URL, tc.url, tc.query.Encode()))
+			if err != nil {
+				t.Fatalf("could not send GET request: %v", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tc.status {
+				t.Fatalf("expected: %v, got: %v", tc.status, resp.StatusCode)
+			}
+
+			if tc.status != http.StatusOK {
+				return
+			}
+
+			var data struct {
+				Items   []struct{ ID int64 } `json:"items"`
+				Modules []struct{ ID int64 } `json:"modules"`
+			}
+			if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+				t.Fatalf("could not decode response: %v", err)
+			}
+
+			got := []int64{}
+			for _, i := range data.Items {
+				got = append(got, i.ID)
+			}
+			for _, m := range data.Modules {
+				got = append(got, m.ID)
+			}
+
+			if fmt.Sprint(got) != fmt.Sprint(tc.want) {
+				t.Fatalf("expected: %v, got: %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestMetadata(t *testing.T) {
+	router := New(db)
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+
+	tt := map[string]struct {
+		method string
+		url    string
+		input  map[string]interface{}
+		status int
+		closed bool
+	}{
+		"create item": {
+			method: http.MethodPost, url: "items", status: http.StatusCreated,
+			input: map[string]interface{}{
+				"value": "v", "type": "test", "version": "1",
+				"description": "d", "owner": "billing", "homepage": "https://example.com",
+				"annotations": map[string]interface{}{"ticket": 42},
+			},
+		},
+		"create item bad homepage": {
+			method: http.MethodPost, url: "items", status: http.StatusBadRequest,
+			input: map[string]interface{}{"value": "v", "type": "test", "version": "1", "homepage": "example.com"},
+		},
+		"create module bad homepage": {
+			method: http.MethodPost, url: "modules", status: http.StatusBadRequest,
+			input: map[string]interface{}{"value": "v", "version": "1", "homepage": "ftp://example.com"},
+		},
+		"update item bad homepage": {
+			method: http.MethodPut, url: "items/1", status: http.StatusBadRequest,
+			input: map[string]interface{}{"value": "v", "type": "test", "version": "1", "homepage": "/relative"},
+		},
+		"update module": {
+			method: http.MethodPut, url: "modules/1", status: http.StatusOK,
+			input: map[string]interface{}{"value": "A", "version": "0.0.2", "description": "d", "owner": "billing"},
+		},
+		"update module bad homepage": {
+			method: http.MethodPut, url: "modules/1", status: http.StatusBadRequest,
+			input: map[string]interface{}{"value": "A", "version": "0.0.2", "homepage": "nope"},
+		},
+		"update module missing values": {
+			method: http.MethodPut, url: "modules/1", status: http.StatusBadRequest,
+			input: map[string]interface{}{"description": "d"},
+		},
+		"update module wrong input": {
+			method: http.MethodPut, url: "modules/1", status: http.StatusBadRequest,
+			input: map[string]interface{}{"value": 1},
+		},
+		"update missing module": {
+			method: http.MethodPut, url: "modules/42", status: http.StatusNotFound,
+			input: map[string]interface{}{"value": "A", "version": "0.0.2"},
+		},
+		"update module closed storage": {
+			method: http.MethodPut, url: "modules/1", status: http.StatusInternalServerError, closed: true,
+			input: map[string]interface{}{"value": "A", "version": "0.0.2"},
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			if err := json.NewEncoder(buf).Encode(tc.input); err != nil {
+				t.Fatalf("could not encode input: %v", err)
+			}
+
+			if tc.closed {
+				db.Close()
+				defer reopen()
+			}
+
+			req, _ := http.NewRequest(tc.method, fmt.Sprintf("%v/%v", srv.URL, tc.url), buf)
+			resp, err := srv.Client().Do(req)
+			if err != nil {
+				t.Fatalf("could not send %v request: %v", tc.method, err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tc.status {
+				t.Fatalf("expected: %v, got: %v", tc.status, resp.StatusCode)
+			}
+
+			var data map[string]interface{}
+			if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+				t.Fatalf("could not decode response: %v", err)
+			}
+
+			if tc.status == http.StatusBadRequest && tc.input["homepage"] != nil && data["fields"] == nil {
+				t.Fatalf("expected field errors in response, got: %v", data)
+			}
+		})
+	}
+}
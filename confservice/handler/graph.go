@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Glorforidor/conmansys/confservice/storage"
+	"github.com/Glorforidor/conmansys/resolve"
+	"github.com/gorilla/mux"
+)
+
+type graphNode struct {
+	*storage.Module
+	Depth int `json:"depth"`
+}
+
+type graphResponse struct {
+	Root      int64                       `json:"root"`
+	Direction string                      `json:"direction"`
+	Nodes     []*graphNode                `json:"nodes"`
+	Edges     []*storage.ModuleDependency `json:"edges"`
+	Error     *string                     `json:"error"`
+}
+
+// graph retrieves the transitive dependencies of a module in the direction
+// given by the query, "down" to the modules it depends on and "up" to the
+// modules depending on it, and packs the nodes and edges into a response. The
+// depth query limits the number of hops from the module. It returns the
+// response as an empty interface and a http status.
+func (h handler) graph(r *http.Request) (data interface{}, status int) {
+	params := mux.Vars(r)
+	id := strings.TrimSpace(params["id"])
+	q := r.URL.Query()
+	var resp graphResponse
+	var errMsg string
+
+	// ensure that there are empty slices
+	resp.Nodes = []*graphNode{}
+	resp.Edges = []*storage.ModuleDependency{}
+
+	// routing should prevent this, but might as well guard it
+	if id == "" {
+		errMsg = errMissingValue.Error()
+		resp.Error = &errMsg
+		return resp, http.StatusBadRequest
+	}
+
+	i, err := strconv.ParseInt(id, 10, 64)
+	// routing should prevent this, but might as well guard it
+	if err != nil {
+		errMsg = errNaN.Error()
+		resp.Error = &errMsg
+		return resp, http.StatusBadRequest
+	}
+
+	direction := q.Get("direction")
+	if direction == "" {
+		direction = resolve.Down
+	}
+	if direction != resolve.Down && direction != resolve.Up {
+		errMsg = fmt.Sprintf("direction must be %q or %q", resolve.Down, resolve.Up)
+		resp.Error = &errMsg
+		return resp, http.StatusBadRequest
+	}
+
+	depth := 0
+	if d := q.Get("depth"); d != "" {
+		depth, err = strconv.Atoi(d)
+		if err != nil || depth < 0 {
+			errMsg = "depth must be a non-negative number"
+			resp.Error = &errMsg
+			return resp, http.StatusBadRequest
+		}
+	}
+
+	resp.Root = i
+	resp.Direction = direction
+
+	modules, err := h.storage.GetModules(r.Context())
+	if err != nil {
+		logError(r, err)
+		errMsg = errInternal.Error()
+		resp.Error = &errMsg
+		return resp, http.StatusInternalServerError
+	}
+
+	byID := make(map[int64]*storage.Module, len(modules))
+	for _, m := range modules {
+		byID[m.ID] = m
+	}
+
+	if byID[i] == nil {
+		errMsg = errNotFound.Error()
+		resp.Error = &errMsg
+		return resp, http.StatusNotFound
+	}
+
+	deps, err := h.storage.GetModuleDependencies(r.Context())
+	if err != nil {
+		logError(r, err)
+		errMsg = errInternal.Error()
+		resp.Error = &errMsg
+		return resp, http.StatusInternalServerError
+	}
+
+	depths, edges := resolve.Walk(deps, i, direction, depth)
+	for id, d := range depths {
+		m := byID[id]
+		if m == nil {
+			// the dependency points to a module which is gone, show what
+			// is known about it
+			m = &storage.Module{ID: id}
+		}
+		resp.Nodes = append(resp.Nodes, &graphNode{Module: m, Depth: d})
+	}
+
+	// sort to give a stable response
+	sort.Slice(resp.Nodes, func(a, b int) bool {
+		if resp.Nodes[a].Depth != resp.Nodes[b].Depth {
+			return resp.Nodes[a].Depth < resp.Nodes[b].Depth
+		}
+		return resp.Nodes[a].ID < resp.Nodes[b].ID
+	})
+
+	if edges != nil {
+		resp.Edges = edges
+	}
+
+	return resp, http.StatusOK
+}
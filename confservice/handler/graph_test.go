@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Glorforidor/conmansys/confservice/storage"
+)
+
+// graphDB holds the dependencies:
+//
+//	1 -> 2 -> 3 -> 4
+//	5 -> 3
+//	4 -> 2 (cycle)
+//	5 conflicts 1, which is not followed
+var graphDB = &dbmock{
+	modules: []*storage.Module{
+		{ID: 1, Value: "A"},
+		{ID: 2, Value: "B"},
+		{ID: 3, Value: "C"},
+		{ID: 4, Value: "D"},
+		{ID: 5, Value: "E"},
+	},
+	dependencies: []*storage.ModuleDependency{
+		{Dependent: 1, Dependee: 2},
+		{Dependent: 2, Dependee: 3},
+		{Dependent: 3, Dependee: 4},
+		{Dependent: 5, Dependee: 3},
+		{Dependent: 4, Dependee: 2},
+		{Dependent: 5, Dependee: 1, Kind: storage.Conflicts},
+	},
+}
+
+func TestGraph(t *testing.T) {
+	router := New(graphDB)
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+
+	tt := map[string]struct {
+		url    string
+		status int
+		nodes  []int64
+		closed bool
+	}{
+		"default direction": {url: "modules/1/graph", status: http.StatusOK, nodes: []int64{1, 2, 3, 4}},
+		"down depth":        {url: "modules/1/graph?direction=down&depth=2", status: http.StatusOK, nodes: []int64{1, 2, 3}},
+		"up":                {url: "modules/4/graph?direction=up", status: http.StatusOK, nodes: []int64{4, 3, 2, 5, 1}},
+		"unknown direction": {url: "modules/1/graph?direction=sideways", status: http.StatusBadRequest},
+		"negative depth":    {url: "modules/1/graph?depth=-1", status: http.StatusBadRequest},
+		"depth not number":  {url: "modules/1/graph?depth=all", status: http.StatusBadRequest},
+		"missing module":    {url: "modules/42/graph", status: http.StatusNotFound},
+		"closed storage":    {url: "modules/1/graph", status: http.StatusInternalServerError, closed: true},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			if tc.closed {
+				graphDB.Close()
+				defer func() { graphDB.closed = false }()
+			}
+
+			resp, err := http.Get(fmt.Sprintf("%v/%v", srv.URL, tc.url))
+			if err != nil {
+				t.Fatalf("could not send GET request: %v", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tc.status {
+				t.Fatalf("expected: %v, got: %v", tc.status, resp.StatusCode)
+			}
+
+			data := &graphResponse{}
+			if err := json.NewDecoder(resp.Body).Decode(data); err != nil {
+				t.Fatalf("expected a graphResponse, got: %v", err)
+			}
+
+			if tc.status != http.StatusOK {
+				if data.Error == nil {
+					t.Fatal("expected an error, got: nil")
+				}
+				return
+			}
+
+			var nodes []int64
+			for i, n := range data.Nodes {
+				nodes = append(nodes, n.ID)
+				if i > 0 && n.Depth < data.Nodes[i-1].Depth {
+					t.Fatalf("expected nodes sorted by depth, got: %v", data.Nodes)
+				}
+			}
+
+			if fmt.Sprint(nodes) != fmt.Sprint(tc.nodes) {
+				t.Fatalf("expected nodes: %v, got: %v", tc.nodes, nodes)
+			}
+		})
+	}
+}
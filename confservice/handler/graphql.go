@@ -0,0 +1,783 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/Glorforidor/conmansys/confservice/policy"
+	"github.com/Glorforidor/conmansys/confservice/storage"
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/relay"
+)
+
+// schema is the GraphQL schema of the configuration graph. Modules expose their
+// items and the dependencies in both directions, so nested reads take a single
+// request.
+const schema = `
+schema {
+	query: Query
+	mutation: Mutation
+}
+
+type Query {
+	item(id: ID!): Item
+	items: [Item!]!
+	module(id: ID!): Module
+	modules: [Module!]!
+	dependencies: [Dependency!]!
+}
+
+type Mutation {
+	createItem(input: ItemInput!): Item!
+	deleteItem(id: ID!): Boolean!
+	createModule(input: ModuleInput!): Module!
+	# deleteModule refuses a module which is part of module dependencies or
+	# the only module of items like DELETE /modules/:id, cascade deletes the
+	# module dependencies with it and force orphans the items as well
+	deleteModule(id: ID!, cascade: Boolean, force: Boolean): Boolean!
+	createDependency(input: DependencyInput!): Dependency!
+	deleteDependency(dependent: ID!, dependee: ID!): Boolean!
+}
+
+type Label {
+	key: String!
+	value: String!
+}
+
+type Item {
+	id: ID!
+	value: String!
+	type: String!
+	version: String!
+	secret: Boolean!
+	labels: [Label!]!
+	description: String!
+	owner: String!
+	modules: [Module!]!
+}
+
+type Module {
+	id: ID!
+	value: String!
+	version: String!
+	labels: [Label!]!
+	description: String!
+	owner: String!
+	items: [Item!]!
+	# the modules this module depends on
+	dependencies: [Dependency!]!
+	# the modules which depend on this module
+	dependents: [Dependency!]!
+}
+
+type Dependency {
+	dependent: Module!
+	dependee: Module!
+	kind: String!
+}
+
+input ItemInput {
+	value: String!
+	type: String!
+	version: String!
+	secret: Boolean
+}
+
+input ModuleInput {
+	value: String!
+	version: String!
+}
+
+input DependencyInput {
+	dependent: ID!
+	dependee: ID!
+	kind: String
+}
+`
+
+// graphqlHandler serves GraphQL requests against the storage of the handler.
+// Every request gets its own loader, so each level of a query reads each kind
+// of row at most once, and only for the ids it needs.
+func (h handler) graphqlHandler() http.Handler {
+	s := graphql.MustParseSchema(schema, &rootResolver{})
+	rh := &relay.Handler{Schema: s}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		l := newLoader(h, r)
+		rh.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), loaderKey{}, l)))
+	})
+}
+
+type loaderKey struct{}
+
+// batch is the ids of a kind of row which the loader read and the ids which
+// it is to read next.
+type batch struct {
+	read    map[int64]bool
+	pending map[int64]bool
+}
+
+func newBatch() batch {
+	return batch{read: make(map[int64]bool), pending: make(map[int64]bool)}
+}
+
+// want marks the ids which were not read yet to be read next.
+func (b batch) want(ids ...int64) {
+	for _, id := range ids {
+		if !b.read[id] {
+			b.pending[id] = true
+		}
+	}
+}
+
+// next returns the ids to read, sorted so the queries are stable.
+func (b batch) next() []int64 {
+	ids := make([]int64, 0, len(b.pending))
+	for id := range b.pending {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(a, b int) bool { return ids[a] < ids[b] })
+	return ids
+}
+
+// done marks the ids as read.
+func (b batch) done(ids []int64) {
+	for _, id := range ids {
+		delete(b.pending, id)
+		b.read[id] = true
+	}
+}
+
+// loader reads the items, modules, item modules and module dependencies a
+// query asks for and keeps them for the request. The resolvers of a list want
+// the ids of their rows when they are made, and the first of them which needs
+// a kind of row reads the ids all of them want in one query, so resolving the
+// fields of nested modules and items does not query the storage for every one
+// of them. Mutations reset it.
+type loader struct {
+	h handler
+	r *http.Request
+
+	mu sync.Mutex
+
+	// items and modules are nil for the ids which do not exist.
+	items   map[int64]*storage.Item
+	modules map[int64]*storage.Module
+	// itemModules are the modules of the items, moduleItems the items of the
+	// modules.
+	itemModules  map[int64][]int64
+	moduleItems  map[int64][]int64
+	dependencies map[int64][]*storage.ModuleDependency
+	dependents   map[int64][]*storage.ModuleDependency
+
+	itemIDs, moduleIDs, itemModuleIDs, moduleItemIDs, dependencyIDs batch
+}
+
+func newLoader(h handler, r *http.Request) *loader {
+	l := &loader{h: h, r: r}
+	l.clear()
+	return l
+}
+
+func loaderFrom(ctx context.Context) *loader {
+	return ctx.Value(loaderKey{}).(*loader)
+}
+
+func (l *loader) clear() {
+	l.items = make(map[int64]*storage.Item)
+	l.modules = make(map[int64]*storage.Module)
+	l.itemModules = make(map[int64][]int64)
+	l.moduleItems = make(map[int64][]int64)
+	l.dependencies = make(map[int64][]*storage.ModuleDependency)
+	l.dependents = make(map[int64][]*storage.ModuleDependency)
+	l.itemIDs, l.moduleIDs = newBatch(), newBatch()
+	l.itemModuleIDs, l.moduleItemIDs, l.dependencyIDs = newBatch(), newBatch(), newBatch()
+}
+
+// reset makes the next fields read the rows again.
+func (l *loader) reset() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.clear()
+}
+
+// allItems reads every item, for the items field which asks for all of them.
+func (l *loader) allItems() ([]*itemResolver, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	items, err := l.h.storage.GetItems(l.r.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	rs := make([]*itemResolver, len(items))
+	for i, item := range items {
+		l.items[item.ID] = item
+		l.itemIDs.done([]int64{item.ID})
+		rs[i] = l.newItem(item)
+	}
+	sort.Slice(rs, func(a, b int) bool { return rs[a].item.ID < rs[b].item.ID })
+	return rs, nil
+}
+
+// itemResolvers returns the resolvers of the items with the ids which exist,
+// reading them with the other wanted items if they were not read yet.
+func (l *loader) itemResolvers(ids []int64) ([]*itemResolver, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.itemIDs.want(ids...)
+	if next := l.itemIDs.next(); len(next) > 0 {
+		items, err := l.h.storage.GetItemsByIDs(l.r.Context(), next)
+		if err != nil {
+			return nil, err
+		}
+		for _, i := range items {
+			l.items[i.ID] = i
+		}
+		l.itemIDs.done(next)
+	}
+
+	rs := []*itemResolver{}
+	for _, id := range ids {
+		if i := l.items[id]; i != nil {
+			rs = append(rs, l.newItem(i))
+		}
+	}
+	return rs, nil
+}
+
+// newItem returns the resolver of the item and wants its modules for the
+// modules field of it and its siblings.
+func (l *loader) newItem(i *storage.Item) *itemResolver {
+	l.itemModuleIDs.want(i.ID)
+	return &itemResolver{l: l, item: l.h.maskItem(l.r, i)}
+}
+
+// allModules reads every module, for the modules field which asks for all of
+// them.
+func (l *loader) allModules() ([]*moduleResolver, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	modules, err := l.h.storage.GetModules(l.r.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	rs := make([]*moduleResolver, len(modules))
+	for i, m := range modules {
+		l.modules[m.ID] = m
+		l.moduleIDs.done([]int64{m.ID})
+		rs[i] = l.newModule(m)
+	}
+	sort.Slice(rs, func(a, b int) bool { return rs[a].module.ID < rs[b].module.ID })
+	return rs, nil
+}
+
+// moduleResolvers returns the resolvers of the modules with the ids which
+// exist, reading them with the other wanted modules if they were not read yet.
+func (l *loader) moduleResolvers(ids []int64) ([]*moduleResolver, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.moduleIDs.want(ids...)
+	if next := l.moduleIDs.next(); len(next) > 0 {
+		modules, err := l.h.storage.GetModulesByIDs(l.r.Context(), next)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range modules {
+			l.modules[m.ID] = m
+		}
+		l.moduleIDs.done(next)
+	}
+
+	rs := []*moduleResolver{}
+	for _, id := range ids {
+		if m := l.modules[id]; m != nil {
+			rs = append(rs, l.newModule(m))
+		}
+	}
+	return rs, nil
+}
+
+// newModule returns the resolver of the module and wants its items and module
+// dependencies for the fields of it and its siblings.
+func (l *loader) newModule(m *storage.Module) *moduleResolver {
+	l.moduleItemIDs.want(m.ID)
+	l.dependencyIDs.want(m.ID)
+	return &moduleResolver{l: l, module: m}
+}
+
+// modulesOf returns the ids of the modules of the item, reading them with the
+// modules of the other wanted items if they were not read yet.
+func (l *loader) modulesOf(id int64) ([]int64, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.itemModuleIDs.want(id)
+	if next := l.itemModuleIDs.next(); len(next) > 0 {
+		ims, err := l.h.storage.GetItemModulesByIDs(l.r.Context(), next, nil)
+		if err != nil {
+			return nil, err
+		}
+		for _, im := range ims {
+			l.itemModules[im.ItemID] = append(l.itemModules[im.ItemID], im.ModuleID)
+			l.moduleIDs.want(im.ModuleID)
+		}
+		l.itemModuleIDs.done(next)
+	}
+
+	return l.itemModules[id], nil
+}
+
+// itemsOf returns the ids of the items of the module, reading them with the
+// items of the other wanted modules if they were not read yet.
+func (l *loader) itemsOf(id int64) ([]int64, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.moduleItemIDs.want(id)
+	if next := l.moduleItemIDs.next(); len(next) > 0 {
+		ims, err := l.h.storage.GetItemModulesByIDs(l.r.Context(), nil, next)
+		if err != nil {
+			return nil, err
+		}
+		for _, im := range ims {
+			l.moduleItems[im.ModuleID] = append(l.moduleItems[im.ModuleID], im.ItemID)
+			l.itemIDs.want(im.ItemID)
+		}
+		l.moduleItemIDs.done(next)
+	}
+
+	return l.moduleItems[id], nil
+}
+
+// dependenciesOf returns the module dependencies the module is the dependent
+// and the dependee of, reading them with the ones of the other wanted modules
+// if they were not read yet.
+func (l *loader) dependenciesOf(id int64) (dependencies, dependents []*storage.ModuleDependency, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.dependencyIDs.want(id)
+	if next := l.dependencyIDs.next(); len(next) > 0 {
+		deps, err := l.h.storage.GetModuleDependenciesByModuleIDs(l.r.Context(), next)
+		if err != nil {
+			return nil, nil, err
+		}
+		// a module dependency between a module of next and one read before
+		// is known by the other module already
+		for _, d := range deps {
+			if l.dependencyIDs.pending[d.Dependent] {
+				l.dependencies[d.Dependent] = append(l.dependencies[d.Dependent], d)
+			}
+			if l.dependencyIDs.pending[d.Dependee] {
+				l.dependents[d.Dependee] = append(l.dependents[d.Dependee], d)
+			}
+			l.moduleIDs.want(d.Dependent, d.Dependee)
+		}
+		l.dependencyIDs.done(next)
+	}
+
+	return l.dependencies[id], l.dependents[id], nil
+}
+
+// dependencyResolvers returns the resolvers of the module dependencies and
+// wants the modules at their ends.
+func (l *loader) dependencyResolvers(deps []*storage.ModuleDependency) []*dependencyResolver {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	rs := make([]*dependencyResolver, len(deps))
+	for i, d := range deps {
+		l.moduleIDs.want(d.Dependent, d.Dependee)
+		rs[i] = &dependencyResolver{l: l, dep: d}
+	}
+	return rs
+}
+
+// fail logs err with the request id of the query and returns the internal
+// error which is safe to show to the caller.
+func (l *loader) fail(err error) error {
+	logError(l.r, err)
+	return errInternal
+}
+
+// parseID parses a GraphQL ID into the id of a row.
+func parseID(id graphql.ID) (int64, error) {
+	i, err := strconv.ParseInt(string(id), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%v: %q", errNaN, id)
+	}
+	return i, nil
+}
+
+// fieldsError joins field errors into one error.
+func fieldsError(fields []*storage.FieldError) error {
+	msgs := make([]string, len(fields))
+	for i, f := range fields {
+		msgs[i] = fmt.Sprintf("%v: %v", f.Field, f.Message)
+	}
+	return fmt.Errorf("%v: %v", errInvalid, strings.Join(msgs, ", "))
+}
+
+type rootResolver struct{}
+
+func (*rootResolver) Item(ctx context.Context, args struct{ ID graphql.ID }) (*itemResolver, error) {
+	id, err := parseID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	l := loaderFrom(ctx)
+	rs, err := l.itemResolvers([]int64{id})
+	if err != nil {
+		return nil, l.fail(err)
+	}
+	if len(rs) == 0 {
+		return nil, nil
+	}
+	return rs[0], nil
+}
+
+func (*rootResolver) Items(ctx context.Context) ([]*itemResolver, error) {
+	l := loaderFrom(ctx)
+	rs, err := l.allItems()
+	if err != nil {
+		return nil, l.fail(err)
+	}
+	return rs, nil
+}
+
+func (*rootResolver) Module(ctx context.Context, args struct{ ID graphql.ID }) (*moduleResolver, error) {
+	id, err := parseID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	l := loaderFrom(ctx)
+	rs, err := l.moduleResolvers([]int64{id})
+	if err != nil {
+		return nil, l.fail(err)
+	}
+	if len(rs) == 0 {
+		return nil, nil
+	}
+	return rs[0], nil
+}
+
+func (*rootResolver) Modules(ctx context.Context) ([]*moduleResolver, error) {
+	l := loaderFrom(ctx)
+	rs, err := l.allModules()
+	if err != nil {
+		return nil, l.fail(err)
+	}
+	return rs, nil
+}
+
+func (*rootResolver) Dependencies(ctx context.Context) ([]*dependencyResolver, error) {
+	l := loaderFrom(ctx)
+	deps, err := l.h.storage.GetModuleDependencies(ctx)
+	if err != nil {
+		return nil, l.fail(err)
+	}
+
+	return l.dependencyResolvers(deps), nil
+}
+
+type itemInput struct {
+	Value   string
+	Type    string
+	Version string
+	Secret  *bool
+}
+
+// CreateItem creates an item like POST /items.
+func (*rootResolver) CreateItem(ctx context.Context, args struct{ Input itemInput }) (*itemResolver, error) {
+	l := loaderFrom(ctx)
+	item := storage.Item{Value: args.Input.Value, Type: args.Input.Type, Version: args.Input.Version}
+	if args.Input.Secret != nil {
+		item.Secret = *args.Input.Secret
+	}
+
+	if item.Value == "" || item.Type == "" || item.Version == "" {
+		return nil, errMissingValue
+	}
+
+	fields, err := policy.ValidateItem(ctx, l.h.storage, &item)
+	if err != nil {
+		return nil, l.fail(err)
+	}
+	if len(fields) > 0 {
+		return nil, fieldsError(fields)
+	}
+
+	id, err := l.h.storage.CreateItem(ctx, &item)
+	if err == storage.ErrNoKeyring {
+		return nil, err
+	}
+	if err != nil {
+		return nil, l.fail(err)
+	}
+	l.reset()
+
+	item.ID = id
+	return &itemResolver{l: l, item: l.h.maskItem(l.r, &item)}, nil
+}
+
+// DeleteItem deletes an item like DELETE /items/{id}. It returns whether an
+// item was deleted.
+func (*rootResolver) DeleteItem(ctx context.Context, args struct{ ID graphql.ID }) (bool, error) {
+	id, err := parseID(args.ID)
+	if err != nil {
+		return false, err
+	}
+
+	l := loaderFrom(ctx)
+	row, err := l.h.storage.DeleteItem(ctx, id)
+	if err != nil {
+		return false, l.fail(err)
+	}
+	l.reset()
+
+	return row > 0, nil
+}
+
+type moduleInput struct {
+	Value   string
+	Version string
+}
+
+// CreateModule creates a module like POST /modules.
+func (*rootResolver) CreateModule(ctx context.Context, args struct{ Input moduleInput }) (*moduleResolver, error) {
+	l := loaderFrom(ctx)
+	module := storage.Module{Value: args.Input.Value, Version: args.Input.Version}
+
+	if module.Value == "" || module.Version == "" {
+		return nil, errMissingValue
+	}
+
+	id, err := l.h.storage.CreateModule(ctx, &module)
+	if err != nil {
+		return nil, l.fail(err)
+	}
+	l.reset()
+
+	module.ID = id
+	return &moduleResolver{l: l, module: &module}, nil
+}
+
+// DeleteModule deletes a module like DELETE /modules/:id. It returns whether a
+// module was deleted.
+func (*rootResolver) DeleteModule(ctx context.Context, args struct {
+	ID      graphql.ID
+	Cascade *bool
+	Force   *bool
+}) (bool, error) {
+	id, err := parseID(args.ID)
+	if err != nil {
+		return false, err
+	}
+	cascade := args.Cascade != nil && *args.Cascade
+	force := args.Force != nil && *args.Force
+
+	l := loaderFrom(ctx)
+	row, report, err := l.h.removeModule(l.r, id, force, cascade)
+	if err == storage.ErrInUse && report != nil {
+		return false, fmt.Errorf("%v: %v", err, report.Reason())
+	}
+	if err == storage.ErrInUse {
+		return false, err
+	}
+	if err != nil {
+		return false, l.fail(err)
+	}
+	l.reset()
+
+	return row > 0, nil
+}
+
+type dependencyInput struct {
+	Dependent graphql.ID
+	Dependee  graphql.ID
+	Kind      *string
+}
+
+// CreateDependency creates a module dependency like POST /moduledependencies.
+func (*rootResolver) CreateDependency(ctx context.Context, args struct{ Input dependencyInput }) (*dependencyResolver, error) {
+	dependent, err := parseID(args.Input.Dependent)
+	if err != nil {
+		return nil, err
+	}
+
+	dependee, err := parseID(args.Input.Dependee)
+	if err != nil {
+		return nil, err
+	}
+
+	md := storage.ModuleDependency{Dependent: dependent, Dependee: dependee, Kind: storage.Required}
+	if args.Input.Kind != nil {
+		md.Kind = *args.Input.Kind
+	}
+
+	if fields := storage.CheckDependency(&md); len(fields) > 0 {
+		return nil, fieldsError(fields)
+	}
+
+	l := loaderFrom(ctx)
+	if err := l.h.storage.CreateModuleDependency(ctx, &md); err != nil {
+		return nil, l.fail(err)
+	}
+	l.reset()
+
+	return &dependencyResolver{l: l, dep: &md}, nil
+}
+
+// DeleteDependency deletes a module dependency. It returns whether a module
+// dependency was deleted.
+func (*rootResolver) DeleteDependency(ctx context.Context, args struct{ Dependent, Dependee graphql.ID }) (bool, error) {
+	dependent, err := parseID(args.Dependent)
+	if err != nil {
+		return false, err
+	}
+
+	dependee, err := parseID(args.Dependee)
+	if err != nil {
+		return false, err
+	}
+
+	l := loaderFrom(ctx)
+	row, err := l.h.storage.DeleteModuleDependency(ctx, dependent, dependee)
+	if err != nil {
+		return false, l.fail(err)
+	}
+	l.reset()
+
+	return row > 0, nil
+}
+
+type labelResolver struct {
+	key, value string
+}
+
+func (r labelResolver) Key() string   { return r.key }
+func (r labelResolver) Value() string { return r.value }
+
+// labelResolvers returns the labels sorted by key, so the order is stable.
+func labelResolvers(labels map[string]string) []labelResolver {
+	rs := make([]labelResolver, 0, len(labels))
+	for k, v := range labels {
+		rs = append(rs, labelResolver{k, v})
+	}
+	sort.Slice(rs, func(a, b int) bool { return rs[a].key < rs[b].key })
+	return rs
+}
+
+type itemResolver struct {
+	l    *loader
+	item *storage.Item
+}
+
+func (r *itemResolver) ID() graphql.ID          { return graphql.ID(strconv.FormatInt(r.item.ID, 10)) }
+func (r *itemResolver) Value() string           { return r.item.Value }
+func (r *itemResolver) Type() string            { return r.item.Type }
+func (r *itemResolver) Version() string         { return r.item.Version }
+func (r *itemResolver) Secret() bool            { return r.item.Secret }
+func (r *itemResolver) Labels() []labelResolver { return labelResolvers(r.item.Labels) }
+func (r *itemResolver) Description() string     { return r.item.Description }
+func (r *itemResolver) Owner() string           { return r.item.Owner }
+
+func (r *itemResolver) Modules() ([]*moduleResolver, error) {
+	ids, err := r.l.modulesOf(r.item.ID)
+	if err != nil {
+		return nil, r.l.fail(err)
+	}
+
+	rs, err := r.l.moduleResolvers(ids)
+	if err != nil {
+		return nil, r.l.fail(err)
+	}
+	return rs, nil
+}
+
+type moduleResolver struct {
+	l      *loader
+	module *storage.Module
+}
+
+func (r *moduleResolver) ID() graphql.ID          { return graphql.ID(strconv.FormatInt(r.module.ID, 10)) }
+func (r *moduleResolver) Value() string           { return r.module.Value }
+func (r *moduleResolver) Version() string         { return r.module.Version }
+func (r *moduleResolver) Labels() []labelResolver { return labelResolvers(r.module.Labels) }
+func (r *moduleResolver) Description() string     { return r.module.Description }
+func (r *moduleResolver) Owner() string           { return r.module.Owner }
+
+func (r *moduleResolver) Items() ([]*itemResolver, error) {
+	ids, err := r.l.itemsOf(r.module.ID)
+	if err != nil {
+		return nil, r.l.fail(err)
+	}
+
+	rs, err := r.l.itemResolvers(ids)
+	if err != nil {
+		return nil, r.l.fail(err)
+	}
+	return rs, nil
+}
+
+func (r *moduleResolver) Dependencies() ([]*dependencyResolver, error) {
+	deps, _, err := r.l.dependenciesOf(r.module.ID)
+	if err != nil {
+		return nil, r.l.fail(err)
+	}
+
+	return r.l.dependencyResolvers(deps), nil
+}
+
+func (r *moduleResolver) Dependents() ([]*dependencyResolver, error) {
+	_, deps, err := r.l.dependenciesOf(r.module.ID)
+	if err != nil {
+		return nil, r.l.fail(err)
+	}
+
+	return r.l.dependencyResolvers(deps), nil
+}
+
+type dependencyResolver struct {
+	l   *loader
+	dep *storage.ModuleDependency
+}
+
+// errDangling is returned when a module dependency refers to a module which
+// does not exist, which the foreign keys should prevent.
+var errDangling = errors.New("module dependency refers to a missing module")
+
+func (r *dependencyResolver) Dependent() (*moduleResolver, error) {
+	return r.end(r.dep.Dependent)
+}
+
+func (r *dependencyResolver) Dependee() (*moduleResolver, error) {
+	return r.end(r.dep.Dependee)
+}
+
+func (r *dependencyResolver) Kind() string { return r.dep.Kind }
+
+func (r *dependencyResolver) end(id int64) (*moduleResolver, error) {
+	rs, err := r.l.moduleResolvers([]int64{id})
+	if err != nil {
+		return nil, r.l.fail(err)
+	}
+	if len(rs) == 0 {
+		return nil, r.l.fail(fmt.Errorf("%v: %v", errDangling, id))
+	}
+	return rs[0], nil
+}
@@ -0,0 +1,246 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Glorforidor/conmansys/confservice/storage"
+)
+
+// countingDB counts the reads of the graph by method, so the tests can tell
+// whether nested fields query the storage for every node.
+type countingDB struct {
+	*dbmock
+
+	mu    sync.Mutex
+	reads map[string]int
+}
+
+func newCountingDB(db *dbmock) *countingDB {
+	return &countingDB{dbmock: db, reads: make(map[string]int)}
+}
+
+func (c *countingDB) count(method string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.reads[method]++
+}
+
+func (c *countingDB) GetItems(ctx context.Context) ([]*storage.Item, error) {
+	c.count("GetItems")
+	return c.dbmock.GetItems(ctx)
+}
+
+func (c *countingDB) GetItemsByIDs(ctx context.Context, ids []int64) ([]*storage.Item, error) {
+	c.count("GetItemsByIDs")
+	return c.dbmock.GetItemsByIDs(ctx, ids)
+}
+
+func (c *countingDB) GetModules(ctx context.Context) ([]*storage.Module, error) {
+	c.count("GetModules")
+	return c.dbmock.GetModules(ctx)
+}
+
+func (c *countingDB) GetModulesByIDs(ctx context.Context, ids []int64) ([]*storage.Module, error) {
+	c.count("GetModulesByIDs")
+	return c.dbmock.GetModulesByIDs(ctx, ids)
+}
+
+func (c *countingDB) GetItemModules(ctx context.Context) ([]*storage.ItemModule, error) {
+	c.count("GetItemModules")
+	return c.dbmock.GetItemModules(ctx)
+}
+
+func (c *countingDB) GetItemModulesByIDs(ctx context.Context, itemIDs, moduleIDs []int64) ([]*storage.ItemModule, error) {
+	c.count("GetItemModulesByIDs")
+	return c.dbmock.GetItemModulesByIDs(ctx, itemIDs, moduleIDs)
+}
+
+func (c *countingDB) GetModuleDependencies(ctx context.Context) ([]*storage.ModuleDependency, error) {
+	c.count("GetModuleDependencies")
+	return c.dbmock.GetModuleDependencies(ctx)
+}
+
+func (c *countingDB) GetModuleDependenciesByModuleIDs(ctx context.Context, ids []int64) ([]*storage.ModuleDependency, error) {
+	c.count("GetModuleDependenciesByModuleIDs")
+	return c.dbmock.GetModuleDependenciesByModuleIDs(ctx, ids)
+}
+
+type graphqlResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+func postGraphQL(t *testing.T, url, query string) *graphqlResponse {
+	b, err := json.Marshal(map[string]string{"query": query})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := http.Post(url+"/graphql", "application/json", bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status: %v, got: %v", http.StatusOK, resp.StatusCode)
+	}
+
+	var body graphqlResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	return &body
+}
+
+func TestGraphQLQuery(t *testing.T) {
+	tt := map[string]struct {
+		query string
+		want  string
+		err   bool
+		// reads are the most reads of the storage by method, each level of
+		// the query reads each kind of row at most once
+		reads map[string]int
+	}{
+		"nested": {
+			query: `{ module(id: 1) { value items { value } dependencies { kind dependee { value items { value } } } } }`,
+			want:  `{"module":{"value":"A","items":[{"value":"tax"},{"value":"********"}],"dependencies":[{"kind":"required","dependee":{"value":"B","items":[{"value":"payment"}]}}]}}`,
+			reads: map[string]int{"GetModulesByIDs": 2, "GetItemModulesByIDs": 2, "GetItemsByIDs": 2, "GetModuleDependenciesByModuleIDs": 1},
+		},
+		"siblings": {
+			query: `{ modules { items { id } dependencies { dependee { id } } } }`,
+			want:  `{"modules":[{"items":[{"id":"1"},{"id":"3"}],"dependencies":[{"dependee":{"id":"2"}}]},{"items":[{"id":"2"}],"dependencies":[{"dependee":{"id":"3"}}]},{"items":[{"id":"4"}],"dependencies":[]},{"items":[],"dependencies":[{"dependee":{"id":"2"}}]}]}`,
+			reads: map[string]int{"GetModules": 1, "GetItemModulesByIDs": 1, "GetItemsByIDs": 1, "GetModuleDependenciesByModuleIDs": 1},
+		},
+		"dependents": {
+			query: `{ module(id: 2) { dependents { kind dependent { id } } } }`,
+			want:  `{"module":{"dependents":[{"kind":"required","dependent":{"id":"1"}},{"kind":"conflicts","dependent":{"id":"4"}}]}}`,
+		},
+		"item modules": {
+			query: `{ item(id: 2) { value modules { value } } }`,
+			want:  `{"item":{"value":"payment","modules":[{"value":"B"}]}}`,
+			reads: map[string]int{"GetItemsByIDs": 1, "GetItemModulesByIDs": 1, "GetModulesByIDs": 1},
+		},
+		"lists": {
+			query: `{ items { id } modules { id } dependencies { kind } }`,
+			want:  `{"items":[{"id":"1"},{"id":"2"},{"id":"3"},{"id":"4"}],"modules":[{"id":"1"},{"id":"2"},{"id":"3"},{"id":"4"}],"dependencies":[{"kind":"required"},{"kind":"optional"},{"kind":"conflicts"}]}`,
+			reads: map[string]int{"GetItems": 1, "GetModules": 1, "GetModuleDependencies": 1},
+		},
+		"missing":     {query: `{ module(id: 42) { value } }`, want: `{"module":null}`},
+		"invalid id":  {query: `{ module(id: "a") { value } }`, err: true},
+		"bad request": {query: `{ module { nope } }`, err: true},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			db := newCountingDB(newReleaseDB())
+			srv := httptest.NewServer(New(db))
+			defer srv.Close()
+
+			body := postGraphQL(t, srv.URL, tc.query)
+			if tc.err {
+				if len(body.Errors) == 0 {
+					t.Fatal("expected errors, got none")
+				}
+				return
+			}
+
+			if len(body.Errors) > 0 {
+				t.Fatalf("expected no errors, got: %v", body.Errors)
+			}
+			if string(body.Data) != tc.want {
+				t.Fatalf("expected: %v, got: %v", tc.want, string(body.Data))
+			}
+
+			for method, n := range db.reads {
+				if tc.reads != nil && n > tc.reads[method] {
+					t.Fatalf("expected at most reads: %v, got: %v", tc.reads, db.reads)
+				}
+			}
+		})
+	}
+}
+
+func TestGraphQLMutation(t *testing.T) {
+	tt := map[string]struct {
+		query string
+		want  string
+		err   string
+	}{
+		"create item": {
+			query: `mutation { createItem(input: {value: "refund", type: "domain", version: "0.0.1"}) { id value } }`,
+			want:  `{"createItem":{"id":"1","value":"refund"}}`,
+		},
+		"create secret item": {
+			query: `mutation { createItem(input: {value: "hunter2", type: "password", version: "1", secret: true}) { value } }`,
+			err:   storage.ErrNoKeyring.Error(),
+		},
+		"invalid item": {
+			query: `mutation { createItem(input: {value: "http", type: "port", version: "1"}) { id } }`,
+			err:   errInvalid.Error(),
+		},
+		"create module": {
+			query: `mutation { createModule(input: {value: "E", version: "0.0.1"}) { id value } }`,
+			want:  `{"createModule":{"id":"1","value":"E"}}`,
+		},
+		"create dependency": {
+			query: `mutation { createDependency(input: {dependent: 3, dependee: 4, kind: "optional"}) { kind dependee { value } } }`,
+			want:  `{"createDependency":{"kind":"optional","dependee":{"value":"D"}}}`,
+		},
+		"invalid dependency": {
+			query: `mutation { createDependency(input: {dependent: 3, dependee: 4, kind: "maybe"}) { kind } }`,
+			err:   errInvalid.Error(),
+		},
+		"delete item":       {query: `mutation { deleteItem(id: 1) }`, want: `{"deleteItem":true}`},
+		"delete module":     {query: `mutation { deleteModule(id: 1, force: true) }`, want: `{"deleteModule":true}`},
+		"module in use":     {query: `mutation { deleteModule(id: 4) }`, err: storage.ErrInUse.Error()},
+		"cascade":           {query: `mutation { deleteModule(id: 4, cascade: true) }`, want: `{"deleteModule":true}`},
+		"orphaned items":    {query: `mutation { deleteModule(id: 1, cascade: true) }`, err: "the only module of 2 items"},
+		"delete dependency": {query: `mutation { deleteDependency(dependent: 1, dependee: 2) }`, want: `{"deleteDependency":true}`},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			db := newReleaseDB()
+			db.itemTypes = []*storage.ItemType{{Name: "port", Pattern: "^[0-9]+$"}}
+			srv := httptest.NewServer(New(db))
+			defer srv.Close()
+
+			body := postGraphQL(t, srv.URL, tc.query)
+			if tc.err != "" {
+				if len(body.Errors) == 0 || !strings.Contains(body.Errors[0].Message, tc.err) {
+					t.Fatalf("expected error: %v, got: %v", tc.err, body.Errors)
+				}
+				return
+			}
+
+			if len(body.Errors) > 0 {
+				t.Fatalf("expected no errors, got: %v", body.Errors)
+			}
+			if string(body.Data) != tc.want {
+				t.Fatalf("expected: %v, got: %v", tc.want, string(body.Data))
+			}
+		})
+	}
+}
+
+func TestGraphQLClosedStorage(t *testing.T) {
+	db := newReleaseDB()
+	db.closed = true
+	srv := httptest.NewServer(New(db))
+	defer srv.Close()
+
+	body := postGraphQL(t, srv.URL, `{ modules { id } }`)
+	if len(body.Errors) == 0 || body.Errors[0].Message != errInternal.Error() {
+		t.Fatalf("expected error: %v, got: %v", errInternal, body.Errors)
+	}
+}
@@ -0,0 +1,15 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/Glorforidor/conmansys/health"
+)
+
+// WithReadinessCheck makes /readyz report the service as not ready while the
+// check of the dependency with the name fails, e.g. the ping of the database.
+func WithReadinessCheck(name string, fn func(context.Context) error) Option {
+	return func(h *handler) {
+		h.checks = append(h.checks, health.Check{Name: name, Fn: fn})
+	}
+}
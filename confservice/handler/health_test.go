@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Glorforidor/conmansys/health"
+)
+
+func TestReadyz(t *testing.T) {
+	ok := func(context.Context) error { return nil }
+	down := func(context.Context) error { return errors.New("connection refused") }
+	slow := func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	tt := map[string]struct {
+		checks map[string]func(context.Context) error
+		status int
+		failed []string
+	}{
+		"no checks": {status: http.StatusOK},
+		"ready":     {checks: map[string]func(context.Context) error{"database": ok}, status: http.StatusOK},
+		"down": {
+			checks: map[string]func(context.Context) error{"database": down, "other": ok},
+			status: http.StatusServiceUnavailable,
+			failed: []string{"database"},
+		},
+		"timeout": {
+			checks: map[string]func(context.Context) error{"database": slow},
+			status: http.StatusServiceUnavailable,
+			failed: []string{"database"},
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			var opts []Option
+			for n, fn := range tc.checks {
+				opts = append(opts, WithReadinessCheck(n, fn))
+			}
+			h := New(&dbmock{}, opts...)
+
+			start := time.Now()
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+			if time.Since(start) > 2*health.Timeout {
+				t.Fatal("expected the checks to time out")
+			}
+
+			if rec.Code != tc.status {
+				t.Fatalf("expected status: %v, got: %v", tc.status, rec.Code)
+			}
+
+			var resp health.Response
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatal(err)
+			}
+			if len(resp.Checks) != len(tc.checks) {
+				t.Fatalf("expected %v checks, got: %v", len(tc.checks), resp.Checks)
+			}
+			for _, n := range tc.failed {
+				if c := resp.Checks[n]; c == nil || c.Status != "failed" || c.Error == "" {
+					t.Fatalf("expected check %v to fail, got: %+v", n, c)
+				}
+			}
+			if c := resp.Checks["other"]; c != nil && c.Status != "ok" {
+				t.Fatalf("expected the other check to pass, got: %+v", resp.Checks["other"])
+			}
+		})
+	}
+
+	rec := httptest.NewRecorder()
+	New(&dbmock{}, WithReadinessCheck("database", down)).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/livez", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected the service to be live while the database is down, got: %v", rec.Code)
+	}
+}
@@ -0,0 +1,138 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Glorforidor/conmansys/confservice/policy"
+	"github.com/Glorforidor/conmansys/confservice/storage"
+	"github.com/gorilla/mux"
+)
+
+// impact builds the impact of deleting the module with the given id with the
+// orphaned items masked for the caller. It returns nil if the module does not
+// exist.
+func (h handler) impact(r *http.Request, id int64) (*policy.Impact, error) {
+	impact, err := policy.ModuleImpact(r.Context(), h.storage, id)
+	if impact != nil {
+		impact.OrphanedItems = h.maskItems(r, impact.OrphanedItems)
+	}
+	return impact, err
+}
+
+type impactResponse struct {
+	Impact *policy.Impact `json:"impact"`
+	Error  *string        `json:"error"`
+}
+
+// moduleImpact retrieves what is affected if the module is deleted and packs
+// it into a response. It returns the response as an empty interface and a http
+// status.
+func (h handler) moduleImpact(r *http.Request) (data interface{}, status int) {
+	params := mux.Vars(r)
+	id := strings.TrimSpace(params["id"])
+	var resp impactResponse
+	var errMsg string
+
+	// routing should prevent this, but might as well guard it
+	if id == "" {
+		errMsg = errMissingValue.Error()
+		resp.Error = &errMsg
+		return resp, http.StatusBadRequest
+	}
+
+	i, err := strconv.ParseInt(id, 10, 64)
+	// routing should prevent this, but might as well guard it
+	if err != nil {
+		errMsg = errNaN.Error()
+		resp.Error = &errMsg
+		return resp, http.StatusBadRequest
+	}
+
+	report, err := h.impact(r, i)
+	if err != nil {
+		logError(r, err)
+		errMsg = errInternal.Error()
+		resp.Error = &errMsg
+		return resp, http.StatusInternalServerError
+	}
+
+	if report == nil {
+		errMsg = errNotFound.Error()
+		resp.Error = &errMsg
+		return resp, http.StatusNotFound
+	}
+
+	resp.Impact = report
+	return resp, http.StatusOK
+}
+
+const (
+	// cascadeDependencies deletes the module dependencies of the module with
+	// it, but still refuses to orphan items.
+	cascadeDependencies = "dependencies"
+)
+
+// deleteModule deletes the module from storage and packs the deletion
+// information into a response. A module which is part of module dependencies
+// or the only module of items is not deleted, instead the impact report is
+// returned with a conflict status. With ?cascade=dependencies the module
+// dependencies are deleted with the module and with ?force the items are
+// orphaned as well. It returns the response as an empty interface and a http
+// status.
+func (h handler) deleteModule(r *http.Request) (data interface{}, status int) {
+	params := mux.Vars(r)
+	q := r.URL.Query()
+	var resp deleteResponse
+	var errMsg string
+	id := strings.TrimSpace(params["id"])
+	if id == "" {
+		errMsg = errMissingValue.Error()
+		resp.Error = &errMsg
+		return resp, http.StatusBadRequest
+	}
+
+	i, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		errMsg = errNaN.Error()
+		resp.Error = &errMsg
+		return resp, http.StatusBadRequest
+	}
+
+	_, force := q["force"]
+	cascade := q.Get("cascade")
+	if cascade != "" && cascade != cascadeDependencies {
+		errMsg = fmt.Sprintf("cascade must be %q", cascadeDependencies)
+		resp.Error = &errMsg
+		return resp, http.StatusBadRequest
+	}
+
+	row, report, err := h.removeModule(r, i, force, cascade != "")
+	if err == storage.ErrInUse {
+		errMsg = err.Error()
+		resp.Error = &errMsg
+		resp.Impact = report
+		return resp, http.StatusConflict
+	}
+	if err != nil {
+		logError(r, err)
+		errMsg = errInternal.Error()
+		resp.Error = &errMsg
+		return resp, http.StatusInternalServerError
+	}
+
+	resp.RowsAffected = row
+	return resp, http.StatusOK
+}
+
+// removeModule deletes the module with the given id like policy.RemoveModule
+// with the orphaned items of the impact masked for the caller.
+func (h handler) removeModule(r *http.Request, id int64, force, cascade bool) (int64, *policy.Impact, error) {
+	row, impact, err := policy.RemoveModule(r.Context(), h.storage, id, force, cascade)
+	if impact != nil {
+		impact.OrphanedItems = h.maskItems(r, impact.OrphanedItems)
+	}
+	return row, impact, err
+}
@@ -0,0 +1,173 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Glorforidor/conmansys/confservice/storage"
+)
+
+// impactDB holds the dependencies 2 -> 1, 3 -> 2 and 1 -> 4. Item 1 and the
+// secret item 3 are only linked to module 1, item 2 is linked to module 1 and
+// 3. Module 5 is not used at all.
+var impactDB = &dbmock{
+	items: []*storage.Item{
+		{ID: 1, Value: "tax"},
+		{ID: 2, Value: "payment"},
+		{ID: 3, Value: "hunter2", Secret: true},
+	},
+	modules: []*storage.Module{
+		{ID: 1, Value: "A"},
+		{ID: 2, Value: "B"},
+		{ID: 3, Value: "C"},
+		{ID: 4, Value: "D"},
+		{ID: 5, Value: "E"},
+	},
+	itemModules: []*storage.ItemModule{
+		{ID: 1, ItemID: 1, ModuleID: 1},
+		{ID: 2, ItemID: 2, ModuleID: 1},
+		{ID: 3, ItemID: 2, ModuleID: 3},
+		{ID: 4, ItemID: 3, ModuleID: 1},
+	},
+	dependencies: []*storage.ModuleDependency{
+		{Dependent: 2, Dependee: 1},
+		{Dependent: 3, Dependee: 2},
+		{Dependent: 1, Dependee: 4},
+	},
+}
+
+func TestModuleImpact(t *testing.T) {
+	router := New(impactDB)
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+
+	tt := map[string]struct {
+		id           string
+		status       int
+		dependents   []int64
+		dependencies int
+		orphaned     []int64
+		insfiles     []int64
+		closed       bool
+	}{
+		"module 1": {
+			id: "1", status: http.StatusOK,
+			dependents: []int64{2, 3}, dependencies: 2, orphaned: []int64{1, 3}, insfiles: []int64{1, 2, 3},
+		},
+		"module 4": {
+			id: "4", status: http.StatusOK,
+			dependents: []int64{1, 2, 3}, dependencies: 1, orphaned: []int64{}, insfiles: []int64{4, 1, 2, 3},
+		},
+		"unused module": {
+			id: "5", status: http.StatusOK,
+			dependents: []int64{}, dependencies: 0, orphaned: []int64{}, insfiles: []int64{5},
+		},
+		"missing module": {id: "42", status: http.StatusNotFound},
+		"closed storage": {id: "1", status: http.StatusInternalServerError, closed: true},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			if tc.closed {
+				impactDB.Close()
+				defer func() { impactDB.closed = false }()
+			}
+
+			resp, err := http.Get(fmt.Sprintf("%v/modules/%v/impact", srv.URL, tc.id))
+			if err != nil {
+				t.Fatalf("could not send GET request: %v", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tc.status {
+				t.Fatalf("expected: %v, got: %v", tc.status, resp.StatusCode)
+			}
+
+			if tc.status != http.StatusOK {
+				return
+			}
+
+			body := mustRead(t, resp)
+			if bytes.Contains(body, []byte("hunter2")) {
+				t.Fatalf("expected secret value to be masked: %s", body)
+			}
+
+			data := &impactResponse{}
+			if err := json.Unmarshal(body, data); err != nil {
+				t.Fatalf("expected an impactResponse, got: %v", err)
+			}
+
+			report := data.Impact
+			dependents := []int64{}
+			for _, d := range report.Dependents {
+				dependents = append(dependents, d.ID)
+			}
+			orphaned := []int64{}
+			for _, i := range report.OrphanedItems {
+				orphaned = append(orphaned, i.ID)
+			}
+
+			if fmt.Sprint(dependents) != fmt.Sprint(tc.dependents) {
+				t.Errorf("expected dependents: %v, got: %v", tc.dependents, dependents)
+			}
+			if len(report.Dependencies) != tc.dependencies {
+				t.Errorf("expected %v dependencies, got: %v", tc.dependencies, report.Dependencies)
+			}
+			if fmt.Sprint(orphaned) != fmt.Sprint(tc.orphaned) {
+				t.Errorf("expected orphaned items: %v, got: %v", tc.orphaned, orphaned)
+			}
+			if fmt.Sprint(report.Insfiles) != fmt.Sprint(tc.insfiles) {
+				t.Errorf("expected insfiles: %v, got: %v", tc.insfiles, report.Insfiles)
+			}
+		})
+	}
+}
+
+func TestSafeDeleteModule(t *testing.T) {
+	router := New(impactDB)
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+
+	tt := map[string]struct {
+		url    string
+		status int
+	}{
+		"unused module":                {url: "modules/5", status: http.StatusOK},
+		"dependency":                   {url: "modules/4", status: http.StatusConflict},
+		"dependency cascade":           {url: "modules/4?cascade=dependencies", status: http.StatusOK},
+		"dependencies without orphans": {url: "modules/3?cascade=dependencies", status: http.StatusOK},
+		"orphans items":                {url: "modules/1?cascade=dependencies", status: http.StatusConflict},
+		"force":                        {url: "modules/1?force", status: http.StatusOK},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodDelete, fmt.Sprintf("%v/%v", srv.URL, tc.url), nil)
+			resp, err := srv.Client().Do(req)
+			if err != nil {
+				t.Fatalf("could not send DELETE request: %v", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tc.status {
+				t.Fatalf("expected: %v, got: %v", tc.status, resp.StatusCode)
+			}
+
+			data := &deleteResponse{}
+			if err := json.NewDecoder(resp.Body).Decode(data); err != nil {
+				t.Fatalf("expected a deleteResponse, got: %v", err)
+			}
+
+			if tc.status == http.StatusConflict && data.Impact == nil {
+				t.Fatal("expected the impact report with a conflict, got: nil")
+			}
+			if tc.status == http.StatusOK && data.Impact != nil {
+				t.Fatalf("expected no impact report, got: %v", data.Impact)
+			}
+		})
+	}
+}
@@ -0,0 +1,327 @@
+package handler
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Glorforidor/conmansys/confservice/policy"
+	"github.com/Glorforidor/conmansys/confservice/storage"
+)
+
+// importColumns are the columns an import must have. The module column holds
+// the module of the item as value@version.
+var importColumns = []string{"value", "type", "version", "module"}
+
+// The outcomes of a row of an import.
+const (
+	// imported rows created an item, a module or an item module.
+	imported = "created"
+	// unchanged rows only refer to what already exists.
+	unchanged = "unchanged"
+	// invalid rows are refused and make the whole import refused.
+	invalid = "invalid"
+)
+
+// importRow is the outcome of a row of an import. Created lists what the row
+// created, or would create in a dry run, of "item", "module" and "link". The
+// ids are left out for what a dry run would create.
+type importRow struct {
+	Row          int                   `json:"row"`
+	Outcome      string                `json:"outcome"`
+	ItemID       int64                 `json:"item_id,omitempty"`
+	ModuleID     int64                 `json:"module_id,omitempty"`
+	ItemModuleID int64                 `json:"item_module_id,omitempty"`
+	Created      []string              `json:"created,omitempty"`
+	Fields       []*storage.FieldError `json:"fields,omitempty"`
+}
+
+type importResponse struct {
+	DryRun bool         `json:"dry_run"`
+	Rows   []*importRow `json:"rows"`
+	Error  *string      `json:"error"`
+}
+
+// importRecord is a row of an import as read from the CSV.
+type importRecord struct {
+	row          int
+	item         storage.Item
+	module       storage.Module
+	itemKey      string
+	moduleKey    string
+	fields       []*storage.FieldError
+	outcome      *importRow
+	createItem   bool
+	createModule bool
+	createLink   bool
+	// linkID is the id of the item module if it existed before the import
+	linkID int64
+}
+
+// parseModule splits a module column of value@version. The version is after the
+// last @, so the value may hold an @ itself.
+func parseModule(s string) (value, version string, ok bool) {
+	i := strings.LastIndex(s, "@")
+	if i < 0 {
+		return "", "", false
+	}
+
+	value = strings.TrimSpace(s[:i])
+	version = strings.TrimSpace(s[i+1:])
+	return value, version, value != "" && version != ""
+}
+
+// readImport reads the rows of a CSV import with a header row naming the
+// importColumns in any order. Other columns are ignored. The rows are numbered
+// like a spreadsheet, so the first row after the header is row 2.
+func readImport(r io.Reader) ([]*importRecord, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("missing header row")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read CSV: %v", err)
+	}
+
+	column := make(map[string]int)
+	for i, h := range header {
+		column[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+	for _, c := range importColumns {
+		if _, ok := column[c]; !ok {
+			return nil, fmt.Errorf("missing column %q", c)
+		}
+	}
+
+	var records []*importRecord
+	for row := 2; ; row++ {
+		fields, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("could not read CSV: %v", err)
+		}
+
+		get := func(c string) string {
+			if i := column[c]; i < len(fields) {
+				return strings.TrimSpace(fields[i])
+			}
+			return ""
+		}
+
+		rec := &importRecord{row: row}
+		rec.item = storage.Item{Value: get("value"), Type: get("type"), Version: get("version")}
+		for _, c := range importColumns[:3] {
+			if get(c) == "" {
+				rec.fields = append(rec.fields, &storage.FieldError{Field: c, Message: "missing value"})
+			}
+		}
+
+		value, version, ok := parseModule(get("module"))
+		if !ok {
+			rec.fields = append(rec.fields, &storage.FieldError{
+				Field:   "module",
+				Message: fmt.Sprintf("must be value@version, got: %q", get("module")),
+			})
+		}
+		rec.module = storage.Module{Value: value, Version: version}
+
+		rec.itemKey = strings.Join([]string{rec.item.Value, rec.item.Type, rec.item.Version}, "\x00")
+		rec.moduleKey = value + "\x00" + version
+		records = append(records, rec)
+	}
+
+	return records, nil
+}
+
+// importCSV imports the items of a CSV with the columns value, type, version
+// and module, where module is value@version. Every row is validated first and
+// if a row is invalid nothing is imported. Items and modules which do not exist
+// are created and linked in one transaction, so an import which fails writes
+// nothing either. With ?dry_run=true the rows are only validated and
+// the response tells what would be created. It returns the response as an
+// empty interface and a http status.
+func (h handler) importCSV(r *http.Request) (data interface{}, status int) {
+	var resp importResponse
+	var errMsg string
+	// ensure that there is an empty slice
+	resp.Rows = []*importRow{}
+
+	if d := r.URL.Query().Get("dry_run"); d != "" {
+		b, err := strconv.ParseBool(d)
+		if err != nil {
+			errMsg = fmt.Sprintf("dry_run must be true or false, got: %q", d)
+			resp.Error = &errMsg
+			return resp, http.StatusBadRequest
+		}
+		resp.DryRun = b
+	}
+
+	records, err := readImport(r.Body)
+	if err != nil {
+		errMsg = fmt.Sprintf("%v: %v", errWrongFormat, err)
+		resp.Error = &errMsg
+		return resp, http.StatusBadRequest
+	}
+
+	items, err := h.storage.GetItems(r.Context())
+	if err != nil {
+		logError(r, err)
+		errMsg = errInternal.Error()
+		resp.Error = &errMsg
+		return resp, http.StatusInternalServerError
+	}
+
+	modules, err := h.storage.GetModules(r.Context())
+	if err != nil {
+		logError(r, err)
+		errMsg = errInternal.Error()
+		resp.Error = &errMsg
+		return resp, http.StatusInternalServerError
+	}
+
+	itemModules, err := h.storage.GetItemModules(r.Context())
+	if err != nil {
+		logError(r, err)
+		errMsg = errInternal.Error()
+		resp.Error = &errMsg
+		return resp, http.StatusInternalServerError
+	}
+
+	// the ids of what exists and what the import creates by key
+	itemIDs := make(map[string]int64)
+	for _, i := range items {
+		itemIDs[strings.Join([]string{i.Value, i.Type, i.Version}, "\x00")] = i.ID
+	}
+	moduleIDs := make(map[string]int64)
+	for _, m := range modules {
+		moduleIDs[m.Value+"\x00"+m.Version] = m.ID
+	}
+	links := make(map[[2]int64]int64)
+	for _, im := range itemModules {
+		links[[2]int64{im.ItemID, im.ModuleID}] = im.ID
+	}
+
+	// plan every row, so nothing is written if a row is invalid
+	newItems := make(map[string]bool)
+	newModules := make(map[string]bool)
+	newLinks := make(map[string]bool)
+	refused := false
+	for _, rec := range records {
+		rec.outcome = &importRow{Row: rec.row, Outcome: unchanged}
+		resp.Rows = append(resp.Rows, rec.outcome)
+
+		if len(rec.fields) == 0 {
+			fields, err := policy.ValidateItem(r.Context(), h.storage, &rec.item)
+			if err != nil {
+				logError(r, err)
+				errMsg = errInternal.Error()
+				resp.Error = &errMsg
+				return resp, http.StatusInternalServerError
+			}
+			rec.fields = fields
+		}
+
+		if len(rec.fields) > 0 {
+			rec.outcome.Outcome = invalid
+			rec.outcome.Fields = rec.fields
+			refused = true
+			continue
+		}
+
+		itemID, itemOK := itemIDs[rec.itemKey]
+		moduleID, moduleOK := moduleIDs[rec.moduleKey]
+		link := rec.itemKey + "\x00" + rec.moduleKey
+		rec.createItem = !itemOK && !newItems[rec.itemKey]
+		rec.createModule = !moduleOK && !newModules[rec.moduleKey]
+		if itemOK && moduleOK {
+			rec.linkID = links[[2]int64{itemID, moduleID}]
+			rec.createLink = rec.linkID == 0 && !newLinks[link]
+		} else {
+			rec.createLink = !newLinks[link]
+		}
+		newItems[rec.itemKey] = true
+		newModules[rec.moduleKey] = true
+		newLinks[link] = true
+
+		rec.outcome.ItemID, rec.outcome.ModuleID, rec.outcome.ItemModuleID = itemID, moduleID, rec.linkID
+		if rec.createItem {
+			rec.outcome.Created = append(rec.outcome.Created, "item")
+		}
+		if rec.createModule {
+			rec.outcome.Created = append(rec.outcome.Created, "module")
+		}
+		if rec.createLink {
+			rec.outcome.Created = append(rec.outcome.Created, "link")
+		}
+		if len(rec.outcome.Created) > 0 {
+			rec.outcome.Outcome = imported
+		}
+	}
+
+	if refused {
+		errMsg = errInvalid.Error()
+		resp.Error = &errMsg
+		return resp, http.StatusBadRequest
+	}
+
+	if resp.DryRun {
+		return resp, http.StatusOK
+	}
+
+	// rows sharing an item or a module share it in the import, so it is
+	// created once and every row gets its id
+	rowItems := make(map[string]*storage.Item)
+	rowModules := make(map[string]*storage.Module)
+	rows := make([]*storage.ImportRow, len(records))
+	status = http.StatusOK
+	for i, rec := range records {
+		item, ok := rowItems[rec.itemKey]
+		if !ok {
+			item = &rec.item
+			item.ID = itemIDs[rec.itemKey]
+			rowItems[rec.itemKey] = item
+		}
+		module, ok := rowModules[rec.moduleKey]
+		if !ok {
+			module = &rec.module
+			module.ID = moduleIDs[rec.moduleKey]
+			rowModules[rec.moduleKey] = module
+		}
+		rows[i] = &storage.ImportRow{Item: item, Module: module, Link: rec.createLink}
+
+		if len(rec.outcome.Created) > 0 {
+			status = http.StatusCreated
+		}
+	}
+
+	if err := h.storage.Import(r.Context(), rows); err != nil {
+		logError(r, err)
+		errMsg = fmt.Sprintf("%v: nothing was imported", errInternal)
+		resp.Error = &errMsg
+		return resp, http.StatusInternalServerError
+	}
+
+	linkIDs := make(map[string]int64)
+	for i, rec := range records {
+		o, row := rec.outcome, rows[i]
+		o.ItemID, o.ModuleID = row.Item.ID, row.Module.ID
+		link := rec.itemKey + "\x00" + rec.moduleKey
+		if row.Link {
+			linkIDs[link] = row.LinkID
+		}
+		if o.ItemModuleID == 0 {
+			o.ItemModuleID = linkIDs[link]
+		}
+	}
+
+	return resp, status
+}
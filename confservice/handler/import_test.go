@@ -0,0 +1,168 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Glorforidor/conmansys/confservice/storage"
+)
+
+func TestReadImport(t *testing.T) {
+	tt := map[string]struct {
+		csv    string
+		rows   int
+		fields []int
+		err    bool
+	}{
+		"rows":            {csv: "value,type,version,module\ntax,domain,1.0.0,A@0.0.1\nrefund,domain,0.0.1,E@0.0.1\n", rows: 2, fields: []int{0, 0}},
+		"any order":       {csv: "Module, Version, Type, Value, Note\nA@0.0.1,1.0.0,domain,tax,kept\n", rows: 1, fields: []int{0}},
+		"at in value":     {csv: "value,type,version,module\ntax,domain,1.0.0,ops@team@0.0.1\n", rows: 1, fields: []int{0}},
+		"missing values":  {csv: "value,type,version,module\n,,,A@0.0.1\n", rows: 1, fields: []int{3}},
+		"bad module":      {csv: "value,type,version,module\ntax,domain,1.0.0,A\n", rows: 1, fields: []int{1}},
+		"short row":       {csv: "value,type,version,module\ntax,domain\n", rows: 1, fields: []int{2}},
+		"missing column":  {csv: "value,type,version\ntax,domain,1.0.0\n", err: true},
+		"missing header":  {csv: "", err: true},
+		"malformed quote": {csv: "value,type,version,module\n\"tax,domain,1.0.0,A@0.0.1\n", err: true},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			records, err := readImport(bytes.NewBufferString(tc.csv))
+			if tc.err {
+				if err == nil {
+					t.Fatal("expected an error, got: nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(records) != tc.rows {
+				t.Fatalf("expected %v rows, got: %v", tc.rows, len(records))
+			}
+			for i, rec := range records {
+				if rec.row != i+2 {
+					t.Errorf("expected row %v, got: %v", i+2, rec.row)
+				}
+				if len(rec.fields) != tc.fields[i] {
+					t.Errorf("expected %v field errors in row %v, got: %v", tc.fields[i], rec.row, len(rec.fields))
+				}
+			}
+		})
+	}
+}
+
+func TestImportCSV(t *testing.T) {
+	rows := "value,type,version,module\n" +
+		// item 1 is already linked to module 1
+		"tax,domain,1.0.0,A@0.0.1\n" +
+		// item 1 is not linked to module 2
+		"tax,domain,1.0.0,B@0.0.1\n" +
+		// neither the item nor the module exist
+		"refund,domain,0.0.1,E@0.0.1\n" +
+		// the row before creates everything
+		"refund,domain,0.0.1,E@0.0.1\n"
+
+	tt := map[string]struct {
+		url      string
+		csv      string
+		status   int
+		outcomes []string
+		created  []string
+		closed   bool
+		fail     bool
+	}{
+		"import": {
+			url: "/import/csv", csv: rows, status: http.StatusCreated,
+			outcomes: []string{unchanged, imported, imported, unchanged},
+			created:  []string{"[]", "[link]", "[item module link]", "[]"},
+		},
+		"dry run": {
+			url: "/import/csv?dry_run=true", csv: rows, status: http.StatusOK,
+			outcomes: []string{unchanged, imported, imported, unchanged},
+			created:  []string{"[]", "[link]", "[item module link]", "[]"},
+		},
+		"unchanged": {
+			url: "/import/csv", csv: "value,type,version,module\ntax,domain,1.0.0,A@0.0.1\n", status: http.StatusOK,
+			outcomes: []string{unchanged}, created: []string{"[]"},
+		},
+		"invalid row": {
+			url: "/import/csv", csv: rows + "http,port,0.0.1,A@0.0.1\n", status: http.StatusBadRequest,
+			outcomes: []string{unchanged, imported, imported, unchanged, invalid},
+			created:  []string{"[]", "[link]", "[item module link]", "[]", "[]"},
+		},
+		"bad dry run":    {url: "/import/csv?dry_run=maybe", csv: rows, status: http.StatusBadRequest},
+		"missing column": {url: "/import/csv", csv: "value,type\ntax,domain\n", status: http.StatusBadRequest},
+		"closed storage": {url: "/import/csv", csv: rows, status: http.StatusInternalServerError, closed: true},
+		"failed import": {
+			url: "/import/csv", csv: rows, status: http.StatusInternalServerError, fail: true,
+			outcomes: []string{unchanged, imported, imported, unchanged},
+			created:  []string{"[]", "[link]", "[item module link]", "[]"},
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			db := newReleaseDB()
+			db.items[0].Type = "domain"
+			db.itemTypes = []*storage.ItemType{{Name: "port", Pattern: "^[0-9]+$"}}
+			db.closed = tc.closed
+			db.failImport = tc.fail
+			srv := httptest.NewServer(New(db))
+			defer srv.Close()
+
+			resp, err := http.Post(srv.URL+tc.url, "text/csv", bytes.NewBufferString(tc.csv))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tc.status {
+				t.Fatalf("expected status: %v, got: %v", tc.status, resp.StatusCode)
+			}
+
+			var body importResponse
+			if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+				t.Fatal(err)
+			}
+
+			if len(body.Rows) != len(tc.outcomes) {
+				t.Fatalf("expected %v rows, got: %v", len(tc.outcomes), len(body.Rows))
+			}
+			for i, row := range body.Rows {
+				if row.Outcome != tc.outcomes[i] {
+					t.Errorf("expected outcome of row %v: %v, got: %v", row.Row, tc.outcomes[i], row.Outcome)
+				}
+				if c := fmt.Sprint(row.Created); c != tc.created[i] {
+					t.Errorf("expected row %v to create: %v, got: %v", row.Row, tc.created[i], c)
+				}
+				if body.DryRun && len(row.Created) > 0 && row.ItemModuleID != 0 {
+					t.Errorf("expected a dry run to not create item modules, got: %v", row.ItemModuleID)
+				}
+				if !body.DryRun && tc.status == http.StatusCreated && row.ItemModuleID == 0 {
+					t.Errorf("expected row %v to be linked, got no item module", row.Row)
+				}
+			}
+
+			if tc.status == http.StatusCreated {
+				// the last row refers to what the row before created
+				a, b := body.Rows[2], body.Rows[3]
+				if a.ItemID != b.ItemID || a.ModuleID != b.ModuleID || a.ItemModuleID != b.ItemModuleID {
+					t.Errorf("expected rows %v and %v to share the item, module and link, got: %+v, %+v", a.Row, b.Row, a, b)
+				}
+			}
+
+			if tc.status == http.StatusBadRequest && len(tc.outcomes) > 0 {
+				last := body.Rows[len(body.Rows)-1]
+				if len(last.Fields) == 0 || body.Error == nil {
+					t.Fatalf("expected the invalid row to have field errors, got: %+v", last)
+				}
+			}
+		})
+	}
+}
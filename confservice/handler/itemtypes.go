@@ -0,0 +1,172 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/Glorforidor/conmansys/confservice/storage"
+	"github.com/gorilla/mux"
+)
+
+type itemTypeResponse struct {
+	ItemType *storage.ItemType     `json:"item_type"`
+	Error    *string               `json:"error"`
+	Fields   []*storage.FieldError `json:"fields,omitempty"`
+}
+
+// itemType retrieves an item type from storage and packs it into a response.
+// It returns the response as an empty interface and a http status.
+func (h handler) itemType(r *http.Request) (data interface{}, status int) {
+	params := mux.Vars(r)
+	name := strings.TrimSpace(params["name"])
+	var resp itemTypeResponse
+	var errMsg string
+
+	if name == "" {
+		errMsg = errMissingValue.Error()
+		resp.Error = &errMsg
+		return resp, http.StatusBadRequest
+	}
+
+	t, err := h.storage.GetItemType(r.Context(), name)
+	if err != nil {
+		logError(r, err)
+		errMsg = errInternal.Error()
+		resp.Error = &errMsg
+		return resp, http.StatusInternalServerError
+	}
+
+	resp.ItemType = t
+	return resp, http.StatusOK
+}
+
+type itemTypesResponse struct {
+	ItemTypes []*storage.ItemType `json:"item_types"`
+	Error     *string             `json:"error"`
+}
+
+// itemTypes retrieves all item types from storage and packs them into a
+// response. It returns the response as an empty interface and a http status.
+func (h handler) itemTypes(r *http.Request) (data interface{}, status int) {
+	var resp itemTypesResponse
+	// ensure that there is an empty slice
+	resp.ItemTypes = []*storage.ItemType{}
+
+	ts, err := h.storage.GetItemTypes(r.Context())
+	if err != nil {
+		logError(r, err)
+		errMsg := errInternal.Error()
+		resp.Error = &errMsg
+		return resp, http.StatusInternalServerError
+	}
+
+	if ts != nil {
+		resp.ItemTypes = ts
+	}
+	return resp, http.StatusOK
+}
+
+// createItemType registers a new item type in the storage and responds with
+// the created item type and a http status.
+func (h handler) createItemType(r *http.Request) (data interface{}, status int) {
+	var resp itemTypeResponse
+	var errMsg string
+	var t storage.ItemType
+
+	err := json.NewDecoder(r.Body).Decode(&t)
+	if err != nil {
+		errMsg = errWrongFormat.Error()
+		resp.Error = &errMsg
+		return resp, http.StatusBadRequest
+	}
+
+	if fields := t.Check(); len(fields) > 0 {
+		errMsg = errInvalid.Error()
+		resp.Error = &errMsg
+		resp.Fields = fields
+		return resp, http.StatusBadRequest
+	}
+
+	err = h.storage.CreateItemType(r.Context(), &t)
+	if err != nil {
+		logError(r, err)
+		errMsg = errInternal.Error()
+		resp.Error = &errMsg
+		return resp, http.StatusInternalServerError
+	}
+
+	resp.ItemType = &t
+	return resp, http.StatusCreated
+}
+
+// updateItemType replaces the validators of an item type in the storage and
+// responds with the updated item type and a http status. Existing items are not
+// validated again.
+func (h handler) updateItemType(r *http.Request) (data interface{}, status int) {
+	params := mux.Vars(r)
+	name := strings.TrimSpace(params["name"])
+	var resp itemTypeResponse
+	var errMsg string
+	var t storage.ItemType
+
+	err := json.NewDecoder(r.Body).Decode(&t)
+	if err != nil {
+		errMsg = errWrongFormat.Error()
+		resp.Error = &errMsg
+		return resp, http.StatusBadRequest
+	}
+
+	// the name in the path always wins over the name in the body
+	t.Name = name
+	if fields := t.Check(); len(fields) > 0 {
+		errMsg = errInvalid.Error()
+		resp.Error = &errMsg
+		resp.Fields = fields
+		return resp, http.StatusBadRequest
+	}
+
+	row, err := h.storage.UpdateItemType(r.Context(), &t)
+	if err != nil {
+		logError(r, err)
+		errMsg = errInternal.Error()
+		resp.Error = &errMsg
+		return resp, http.StatusInternalServerError
+	}
+
+	if row == 0 {
+		errMsg = errNotFound.Error()
+		resp.Error = &errMsg
+		return resp, http.StatusNotFound
+	}
+
+	resp.ItemType = &t
+	return resp, http.StatusOK
+}
+
+// deleteItemType deletes the item type from storage and packs the deletion
+// information into a response. It returns the response as an empty interface
+// and a http status.
+func (h handler) deleteItemType(r *http.Request) (data interface{}, status int) {
+	params := mux.Vars(r)
+	name := strings.TrimSpace(params["name"])
+	var resp deleteResponse
+	var errMsg string
+
+	if name == "" {
+		errMsg = errMissingValue.Error()
+		resp.Error = &errMsg
+		return resp, http.StatusBadRequest
+	}
+
+	row, err := h.storage.DeleteItemType(r.Context(), name)
+	if err != nil {
+		logError(r, err)
+		errMsg = errInternal.Error()
+		resp.Error = &errMsg
+		return resp, http.StatusInternalServerError
+	}
+
+	resp.RowsAffected = row
+	return resp, http.StatusOK
+}
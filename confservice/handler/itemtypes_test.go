@@ -0,0 +1,201 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestValidateItem(t *testing.T) {
+	router := New(db)
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+
+	tt := map[string]struct {
+		method string
+		url    string
+		input  map[string]interface{}
+		status int
+		fields int
+		closed bool
+	}{
+		"unregistered type": {
+			method: http.MethodPost,
+			url:    "items",
+			input:  map[string]interface{}{"value": "anything", "type": "test", "version": "0.0.1"},
+			status: http.StatusCreated,
+		},
+		"valid enum": {
+			method: http.MethodPost,
+			url:    "items",
+			input:  map[string]interface{}{"value": "red", "type": "color", "version": "0.0.1"},
+			status: http.StatusCreated,
+		},
+		"invalid enum": {
+			method: http.MethodPost,
+			url:    "items",
+			input:  map[string]interface{}{"value": "blue", "type": "color", "version": "0.0.1"},
+			status: http.StatusBadRequest,
+			fields: 1,
+		},
+		"valid range": {
+			method: http.MethodPost,
+			url:    "items",
+			input:  map[string]interface{}{"value": "8080", "type": "port", "version": "0.0.1"},
+			status: http.StatusCreated,
+		},
+		"out of range": {
+			method: http.MethodPost,
+			url:    "items",
+			input:  map[string]interface{}{"value": "70000", "type": "port", "version": "0.0.1"},
+			status: http.StatusBadRequest,
+			fields: 1,
+		},
+		"not an integer": {
+			method: http.MethodPost,
+			url:    "items",
+			input:  map[string]interface{}{"value": "http", "type": "port", "version": "0.0.1"},
+			status: http.StatusBadRequest,
+			fields: 1,
+		},
+		"update valid pattern": {
+			method: http.MethodPut,
+			url:    "items/1",
+			input:  map[string]interface{}{"value": "tax_window", "type": "window", "version": "0.0.2"},
+			status: http.StatusOK,
+		},
+		"update invalid pattern": {
+			method: http.MethodPut,
+			url:    "items/1",
+			input:  map[string]interface{}{"value": "tax", "type": "window", "version": "0.0.2"},
+			status: http.StatusBadRequest,
+			fields: 1,
+		},
+		"update missing item": {
+			method: http.MethodPut,
+			url:    "items/42",
+			input:  map[string]interface{}{"value": "tax_window", "type": "window", "version": "0.0.2"},
+			status: http.StatusNotFound,
+		},
+		"closed storage": {
+			method: http.MethodPut,
+			url:    "items/1",
+			input:  map[string]interface{}{"value": "tax_window", "type": "window", "version": "0.0.2"},
+			status: http.StatusInternalServerError,
+			closed: true,
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			if err := json.NewEncoder(buf).Encode(tc.input); err != nil {
+				t.Fatalf("could not encode input: %v", err)
+			}
+
+			if tc.closed {
+				db.Close()
+				defer reopen()
+			}
+
+			req, _ := http.NewRequest(tc.method, fmt.Sprintf("%v/%v", srv.URL, tc.url), buf)
+			resp, err := srv.Client().Do(req)
+			if err != nil {
+				t.Fatalf("could not send %v request: %v", tc.method, err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tc.status {
+				t.Fatalf("expected: %v, got: %v", tc.status, resp.StatusCode)
+			}
+
+			data := &itemResponse{}
+			if err := json.NewDecoder(resp.Body).Decode(data); err != nil {
+				t.Fatalf("expected a itemResponse, got: %v", err)
+			}
+
+			if len(data.Fields) != tc.fields {
+				t.Fatalf("expected %v field errors, got: %v", tc.fields, data.Fields)
+			}
+
+			for _, f := range data.Fields {
+				if f.Field != "value" {
+					t.Errorf("expected field error on value, got: %v", f)
+				}
+			}
+		})
+	}
+}
+
+func TestItemTypes(t *testing.T) {
+	router := New(db)
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+
+	tt := map[string]struct {
+		method string
+		url    string
+		input  map[string]interface{}
+		status int
+		closed bool
+	}{
+		"list":         {method: http.MethodGet, url: "itemtypes", status: http.StatusOK},
+		"get":          {method: http.MethodGet, url: "itemtypes/color", status: http.StatusOK},
+		"get unknown":  {method: http.MethodGet, url: "itemtypes/unknown", status: http.StatusOK},
+		"delete":       {method: http.MethodDelete, url: "itemtypes/color", status: http.StatusOK},
+		"create":       {method: http.MethodPost, url: "itemtypes", input: map[string]interface{}{"name": "size", "enum": []string{"s", "m"}}, status: http.StatusCreated},
+		"missing name": {method: http.MethodPost, url: "itemtypes", input: map[string]interface{}{"pattern": "^a"}, status: http.StatusBadRequest},
+		"bad pattern":  {method: http.MethodPost, url: "itemtypes", input: map[string]interface{}{"name": "x", "pattern": "("}, status: http.StatusBadRequest},
+		"bad range":    {method: http.MethodPost, url: "itemtypes", input: map[string]interface{}{"name": "x", "min": 10, "max": 1}, status: http.StatusBadRequest},
+		"wrong input":  {method: http.MethodPost, url: "itemtypes", input: map[string]interface{}{"name": 1}, status: http.StatusBadRequest},
+		"update":       {method: http.MethodPut, url: "itemtypes/port", input: map[string]interface{}{"min": 1024}, status: http.StatusOK},
+		"update unknown": {
+			method: http.MethodPut, url: "itemtypes/unknown", input: map[string]interface{}{"min": 1}, status: http.StatusNotFound,
+		},
+		"closed storage": {
+			method: http.MethodGet, url: "itemtypes", status: http.StatusInternalServerError, closed: true,
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			if tc.input != nil {
+				if err := json.NewEncoder(buf).Encode(tc.input); err != nil {
+					t.Fatalf("could not encode input: %v", err)
+				}
+			}
+
+			if tc.closed {
+				db.Close()
+				defer reopen()
+			}
+
+			req, _ := http.NewRequest(tc.method, fmt.Sprintf("%v/%v", srv.URL, tc.url), buf)
+			resp, err := srv.Client().Do(req)
+			if err != nil {
+				t.Fatalf("could not send %v request: %v", tc.method, err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tc.status {
+				t.Fatalf("expected: %v, got: %v", tc.status, resp.StatusCode)
+			}
+
+			if !json.Valid(mustRead(t, resp)) {
+				t.Fatal("expected valid JSON response body")
+			}
+		})
+	}
+}
+
+func mustRead(t *testing.T, resp *http.Response) []byte {
+	buf := &bytes.Buffer{}
+	if _, err := buf.ReadFrom(resp.Body); err != nil {
+		t.Fatalf("could not read response body: %v", err)
+	}
+	return buf.Bytes()
+}
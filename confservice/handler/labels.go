@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Glorforidor/conmansys/confservice/storage"
+	"github.com/gorilla/mux"
+)
+
+type labelsResponse struct {
+	Labels map[string]string     `json:"labels"`
+	Error  *string               `json:"error"`
+	Fields []*storage.FieldError `json:"fields,omitempty"`
+}
+
+// setLabels decodes the labels in the request body and replaces the labels of
+// the item or module with the id in the path by calling set. It returns the
+// response as an empty interface and a http status.
+func setLabels(r *http.Request, set func(context.Context, int64, map[string]string) (int64, error)) (data interface{}, status int) {
+	params := mux.Vars(r)
+	id := strings.TrimSpace(params["id"])
+	var resp labelsResponse
+	var errMsg string
+
+	// routing should prevent this, but might as well guard it
+	if id == "" {
+		errMsg = errMissingValue.Error()
+		resp.Error = &errMsg
+		return resp, http.StatusBadRequest
+	}
+
+	i, err := strconv.ParseInt(id, 10, 64)
+	// routing should prevent this, but might as well guard it
+	if err != nil {
+		errMsg = errNaN.Error()
+		resp.Error = &errMsg
+		return resp, http.StatusBadRequest
+	}
+
+	var l map[string]string
+	err = json.NewDecoder(r.Body).Decode(&l)
+	if err != nil {
+		errMsg = errWrongFormat.Error()
+		resp.Error = &errMsg
+		return resp, http.StatusBadRequest
+	}
+
+	if fields := storage.CheckLabels(l); len(fields) > 0 {
+		errMsg = errInvalid.Error()
+		resp.Error = &errMsg
+		resp.Fields = fields
+		return resp, http.StatusBadRequest
+	}
+
+	row, err := set(r.Context(), i, l)
+	if err != nil {
+		logError(r, err)
+		errMsg = errInternal.Error()
+		resp.Error = &errMsg
+		return resp, http.StatusInternalServerError
+	}
+
+	if row == 0 {
+		errMsg = errNotFound.Error()
+		resp.Error = &errMsg
+		return resp, http.StatusNotFound
+	}
+
+	if l == nil {
+		l = map[string]string{}
+	}
+	resp.Labels = l
+	return resp, http.StatusOK
+}
+
+// setItemLabels replaces the labels of an item with the labels in the request
+// body.
+func (h handler) setItemLabels(r *http.Request) (data interface{}, status int) {
+	return setLabels(r, h.storage.SetItemLabels)
+}
+
+// setModuleLabels replaces the labels of a module with the labels in the
+// request body.
+func (h handler) setModuleLabels(r *http.Request) (data interface{}, status int) {
+	return setLabels(r, h.storage.SetModuleLabels)
+}
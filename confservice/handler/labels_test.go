@@ -0,0 +1,253 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestSelector(t *testing.T) {
+	router := New(db)
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+
+	tt := map[string]struct {
+		url      string
+		selector string
+		status   int
+		want     []int64
+	}{
+		"all items":            {url: "items", status: http.StatusOK, want: []int64{1, 2, 3}},
+		"items by team":        {url: "items", selector: "team=billing", status: http.StatusOK, want: []int64{1}},
+		"items without team":   {url: "items", selector: "!team", status: http.StatusOK, want: []int64{3}},
+		"items none":           {url: "items", selector: "team=payments", status: http.StatusOK, want: []int64{}},
+		"items bad selector":   {url: "items", selector: "team=a b", status: http.StatusBadRequest},
+		"modules by release":   {url: "modules", selector: "release=2026.10", status: http.StatusOK, want: []int64{1}},
+		"modules not excluded": {url: "modules", selector: "release,tier!=experimental", status: http.StatusOK, want: []int64{1}},
+		"modules in":           {url: "modules", selector: "release in (2026.10,2026.11)", status: http.StatusOK, want: []int64{1, 2}},
+		"modules bad selector": {url: "modules", selector: "release in (2026.10", status: http.StatusBadRequest},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			u := fmt.Sprintf("%v/%v?selector=%v", srv.URL, tc.url, url.QueryEscape(tc.selector))
+			resp, err := http.Get(u)
+			if err != nil {
+				t.Fatalf("could not send GET request: %v", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tc.status {
+				t.Fatalf("expected: %v, got: %v", tc.status, resp.StatusCode)
+			}
+
+			if tc.status != http.StatusOK {
+				return
+			}
+
+			var data struct {
+				Items   []struct{ ID int64 } `json:"items"`
+				Modules []struct{ ID int64 } `json:"modules"`
+			}
+			if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+				t.Fatalf("could not decode response: %v", err)
+			}
+
+			var got []int64
+			for _, i := range data.Items {
+				got = append(got, i.ID)
+			}
+			for _, m := range data.Modules {
+				got = append(got, m.ID)
+			}
+
+			if len(got) != len(tc.want) {
+				t.Fatalf("expected: %v, got: %v", tc.want, got)
+			}
+			for i := range got {
+				if got[i] != tc.want[i] {
+					t.Fatalf("expected: %v, got: %v", tc.want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestSetLabels(t *testing.T) {
+	router := New(db)
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+
+	tt := map[string]struct {
+		url    string
+		input  interface{}
+		status int
+		closed bool
+	}{
+		"item labels":      {url: "items/1/labels", input: map[string]string{"tier": "critical"}, status: http.StatusOK},
+		"module labels":    {url: "modules/1/labels", input: map[string]string{"release": "2026.10"}, status: http.StatusOK},
+		"clear labels":     {url: "modules/1/labels", input: map[string]string{}, status: http.StatusOK},
+		"missing item":     {url: "items/42/labels", input: map[string]string{"tier": "critical"}, status: http.StatusNotFound},
+		"missing module":   {url: "modules/42/labels", input: map[string]string{"tier": "critical"}, status: http.StatusNotFound},
+		"invalid key":      {url: "items/1/labels", input: map[string]string{"ti er": "critical"}, status: http.StatusBadRequest},
+		"invalid value":    {url: "items/1/labels", input: map[string]string{"tier": "crit ical"}, status: http.StatusBadRequest},
+		"wrong input":      {url: "items/1/labels", input: []string{"tier"}, status: http.StatusBadRequest},
+		"wrong value type": {url: "items/1/labels", input: map[string]int{"tier": 1}, status: http.StatusBadRequest},
+		"closed storage": {
+			url: "items/1/labels", input: map[string]string{"tier": "critical"}, status: http.StatusInternalServerError, closed: true,
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			if err := json.NewEncoder(buf).Encode(tc.input); err != nil {
+				t.Fatalf("could not encode input: %v", err)
+			}
+
+			if tc.closed {
+				db.Close()
+				defer reopen()
+			}
+
+			req, _ := http.NewRequest(http.MethodPut, fmt.Sprintf("%v/%v", srv.URL, tc.url), buf)
+			resp, err := srv.Client().Do(req)
+			if err != nil {
+				t.Fatalf("could not send PUT request: %v", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tc.status {
+				t.Fatalf("expected: %v, got: %v", tc.status, resp.StatusCode)
+			}
+
+			data := &labelsResponse{}
+			if err := json.NewDecoder(resp.Body).Decode(data); err != nil {
+				t.Fatalf("expected a labelsResponse, got: %v", err)
+			}
+
+			if tc.status == http.StatusOK && data.Labels == nil {
+				t.Fatal("expected labels in response, got: nil")
+			}
+		})
+	}
+}
+
+func TestCreateWithLabels(t *testing.T) {
+	router := New(db)
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+
+	tt := map[string]struct {
+		url    string
+		input  map[string]interface{}
+		status int
+	}{
+		"item": {
+			url:    "items",
+			input:  map[string]interface{}{"value": "v", "type": "test", "version": "1", "labels": map[string]string{"team": "billing"}},
+			status: http.StatusCreated,
+		},
+		"item invalid label": {
+			url:    "items",
+			input:  map[string]interface{}{"value": "v", "type": "test", "version": "1", "labels": map[string]string{"team": "bil ling"}},
+			status: http.StatusBadRequest,
+		},
+		"module": {
+			url:    "modules",
+			input:  map[string]interface{}{"value": "v", "version": "1", "labels": map[string]string{"release": "2026.10"}},
+			status: http.StatusCreated,
+		},
+		"module invalid label": {
+			url:    "modules",
+			input:  map[string]interface{}{"value": "v", "version": "1", "labels": map[string]string{"-release": "2026.10"}},
+			status: http.StatusBadRequest,
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			if err := json.NewEncoder(buf).Encode(tc.input); err != nil {
+				t.Fatalf("could not encode input: %v", err)
+			}
+
+			resp, err := http.Post(fmt.Sprintf("%v/%v", srv.URL, tc.url), "application/json", buf)
+			if err != nil {
+				t.Fatalf("could not send POST request: %v", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tc.status {
+				t.Fatalf("expected: %v, got: %v", tc.status, resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestUpdateKeepsLabels(t *testing.T) {
+	router := New(db)
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+
+	tt := map[string]struct {
+		url   string
+		input map[string]interface{}
+		want  map[string]string
+	}{
+		"item": {
+			url:   "items/1",
+			input: map[string]interface{}{"value": "v", "type": "test", "version": "1", "labels": map[string]string{"team": "payments"}},
+			want:  db.items[0].Labels,
+		},
+		"module": {
+			url:   "modules/1",
+			input: map[string]interface{}{"value": "v", "version": "1", "labels": map[string]string{"release": "2027.01"}},
+			want:  db.modules[0].Labels,
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			if err := json.NewEncoder(buf).Encode(tc.input); err != nil {
+				t.Fatalf("could not encode input: %v", err)
+			}
+
+			req, _ := http.NewRequest(http.MethodPut, fmt.Sprintf("%v/%v", srv.URL, tc.url), buf)
+			resp, err := srv.Client().Do(req)
+			if err != nil {
+				t.Fatalf("could not send PUT request: %v", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("expected status OK, got: %v", resp.StatusCode)
+			}
+
+			// the labels are changed through /labels, so the stored ones are returned
+			var body struct {
+				Item   *struct{ Labels map[string]string } `json:"item"`
+				Module *struct{ Labels map[string]string } `json:"module"`
+			}
+			if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+				t.Fatalf("could not decode response: %v", err)
+			}
+			got := map[string]string(nil)
+			if body.Item != nil {
+				got = body.Item.Labels
+			}
+			if body.Module != nil {
+				got = body.Module.Labels
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("expected labels: %v, got: %v", tc.want, got)
+			}
+		})
+	}
+}
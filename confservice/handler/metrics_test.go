@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Glorforidor/conmansys/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestMetrics(t *testing.T) {
+	h := New(newReleaseDB())
+
+	route := "/items/{id:[0-9]+}"
+	ok := metrics.Requests.WithLabelValues(route, "get", "200")
+	invalid := metrics.Requests.WithLabelValues("/items", "post", "400")
+	okBefore, invalidBefore := testutil.ToFloat64(ok), testutil.ToFloat64(invalid)
+
+	for _, path := range []string{"/items/1", "/items/2"} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status: %v, got: %v", http.StatusOK, rec.Code)
+		}
+	}
+
+	// requests refused by the validation of the document are counted too
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/items", strings.NewReader(`{"value": 1}`)))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected status: %v, got: %v", http.StatusBadRequest, rec.Code)
+	}
+
+	if got := testutil.ToFloat64(ok) - okBefore; got != 2 {
+		t.Errorf("expected 2 requests to %v, got: %v", route, got)
+	}
+	if got := testutil.ToFloat64(invalid) - invalidBefore; got != 1 {
+		t.Errorf("expected 1 invalid request to /items, got: %v", got)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status: %v, got: %v", http.StatusOK, rec.Code)
+	}
+	b, err := ioutil.ReadAll(rec.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body := string(b)
+	for _, want := range []string{
+		`http_requests_total{code="200",method="get",route="/items/{id:[0-9]+}"}`,
+		`http_request_duration_seconds_bucket{method="get",route="/items/{id:[0-9]+}"`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected %v in the metrics", want)
+		}
+	}
+	if strings.Contains(body, `route="/items/1"`) {
+		t.Errorf("expected the requests to be labelled by the route template, got: %v", body)
+	}
+}
@@ -0,0 +1,168 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/Glorforidor/conmansys/confservice/storage"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/gorilla/mux"
+)
+
+func init() {
+	// the CSV of an import is validated as a string, the handler reads the
+	// rows
+	openapi3filter.RegisterBodyDecoder("text/csv", func(body io.Reader, _ http.Header, _ *openapi3.SchemaRef, _ openapi3filter.EncodingFn) (interface{}, error) {
+		b, err := ioutil.ReadAll(body)
+		return string(b), err
+	})
+}
+
+// loadSpec parses and validates the OpenAPI document of the service.
+func loadSpec() (*openapi3.T, error) {
+	doc, err := openapi3.NewLoader().LoadFromData([]byte(spec))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := doc.Validate(context.Background()); err != nil {
+		return nil, err
+	}
+
+	return doc, nil
+}
+
+// openAPI returns a handler serving the OpenAPI document as JSON.
+func openAPI(doc *openapi3.T) http.HandlerFunc {
+	b, err := json.Marshal(doc)
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err != nil {
+			logError(r, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", contentType["json"])
+		w.Write(b)
+	}
+}
+
+// routeVar matches the regular expression of a variable in a route template,
+// e.g. {id:[0-9]+}.
+var routeVar = regexp.MustCompile(`\{([^:}]+):[^}]+\}`)
+
+// validateRequests is a middleware which validates the parameters and body of
+// a request against the operation of the matched route in the document. Routes
+// which are not in the document are not validated. A request without a
+// Content-Type is validated as the only media type of the operation, so clients
+// which never sent one keep working.
+func validateRequests(doc *openapi3.T) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route := mux.CurrentRoute(r)
+			if route == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			tmpl, err := route.GetPathTemplate()
+			if err != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+			tmpl = routeVar.ReplaceAllString(tmpl, "{$1}")
+
+			item := doc.Paths.Find(tmpl)
+			if item == nil || item.GetOperation(r.Method) == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+			op := item.GetOperation(r.Method)
+
+			if rb := op.RequestBody; rb != nil && rb.Value != nil && len(rb.Value.Content) == 1 &&
+				r.Header.Get("Content-Type") == "" {
+				for mime := range rb.Value.Content {
+					r.Header.Set("Content-Type", mime)
+				}
+			}
+
+			input := &openapi3filter.RequestValidationInput{
+				Request:    r,
+				PathParams: mux.Vars(r),
+				Route: &routers.Route{
+					Spec:      doc,
+					Path:      tmpl,
+					PathItem:  item,
+					Method:    r.Method,
+					Operation: op,
+				},
+				Options: &openapi3filter.Options{
+					AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+				},
+			}
+			if err := openapi3filter.ValidateRequest(r.Context(), input); err != nil {
+				responseJSON(func(*http.Request) (interface{}, int) {
+					return requestError(err), http.StatusBadRequest
+				})(w, r)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+type validationResponse struct {
+	Error  *string               `json:"error"`
+	Fields []*storage.FieldError `json:"fields,omitempty"`
+}
+
+// requestError packs why a request does not match the document into a
+// response. A value which does not match its schema is reported as a field
+// error named by the parameter or the path to the value in the body.
+func requestError(err error) validationResponse {
+	var resp validationResponse
+	errMsg := fmt.Sprintf("%v: %v", errWrongFormat, err)
+
+	var re *openapi3filter.RequestError
+	if !errors.As(err, &re) {
+		resp.Error = &errMsg
+		return resp
+	}
+
+	var se *openapi3.SchemaError
+	if !errors.As(re.Err, &se) {
+		reason := re.Reason
+		if reason == "" && re.Err != nil {
+			reason = re.Err.Error()
+		}
+		if re.Parameter != nil {
+			reason = fmt.Sprintf("parameter %q in %v: %v", re.Parameter.Name, re.Parameter.In, reason)
+		}
+		errMsg = fmt.Sprintf("%v: %v", errWrongFormat, reason)
+		resp.Error = &errMsg
+		return resp
+	}
+
+	field := strings.Join(se.JSONPointer(), ".")
+	if re.Parameter != nil {
+		field = re.Parameter.Name
+	}
+	if field == "" {
+		field = "body"
+	}
+
+	errMsg = errInvalid.Error()
+	resp.Error = &errMsg
+	resp.Fields = []*storage.FieldError{{Field: field, Message: se.Reason}}
+	return resp
+}
@@ -0,0 +1,1167 @@
+package handler
+
+// spec is the OpenAPI 3 document of the routes registered by New. It is
+// written in YAML and served as JSON at /openapi.json.
+const spec = `
+openapi: 3.0.3
+info:
+  title: confservice
+  description: Stores the items, modules and module dependencies of conmansys.
+  version: 1.0.0
+paths:
+  /health:
+    get:
+      summary: Reports that the service is running, like /livez.
+      responses:
+        "200":
+          description: The service is running.
+          content:
+            application/json:
+              schema:
+                $ref: "#/components/schemas/Health"
+  /livez:
+    get:
+      summary: Reports that the service is running.
+      responses:
+        "200":
+          description: The service is running.
+          content:
+            application/json:
+              schema:
+                $ref: "#/components/schemas/Health"
+  /readyz:
+    get:
+      summary: Reports whether the service and the dependencies it needs are ready.
+      responses:
+        "200":
+          description: Every dependency is ready.
+          content:
+            application/json:
+              schema:
+                $ref: "#/components/schemas/Health"
+        "503":
+          description: A dependency is not ready.
+          content:
+            application/json:
+              schema:
+                $ref: "#/components/schemas/Health"
+  /metrics:
+    get:
+      summary: The Prometheus metrics of the service.
+      responses:
+        "200":
+          description: The metrics in the Prometheus text format.
+          content:
+            text/plain:
+              schema:
+                type: string
+  /openapi.json:
+    get:
+      summary: This document.
+      responses:
+        "200":
+          description: The OpenAPI document of the service.
+          content:
+            application/json:
+              schema:
+                type: object
+  /graphql:
+    post:
+      summary: Runs a GraphQL query or mutation over the items, modules and module dependencies.
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              type: object
+              required: [query]
+              properties:
+                query:
+                  type: string
+                operationName:
+                  type: string
+                variables:
+                  type: object
+      responses:
+        "200":
+          description: The data and the errors of the query.
+          content:
+            application/json:
+              schema:
+                type: object
+                properties:
+                  data:
+                    type: object
+                  errors:
+                    type: array
+                    items:
+                      type: object
+  /items:
+    get:
+      summary: Lists the items.
+      parameters:
+        - $ref: "#/components/parameters/selector"
+        - $ref: "#/components/parameters/owner"
+        - $ref: "#/components/parameters/search"
+        - $ref: "#/components/parameters/annotation"
+        - $ref: "#/components/parameters/updatedSince"
+        - $ref: "#/components/parameters/reveal"
+        - $ref: "#/components/parameters/revealToken"
+      responses:
+        "200":
+          description: The items.
+          content:
+            application/json:
+              schema:
+                type: object
+                properties:
+                  items:
+                    type: array
+                    items:
+                      $ref: "#/components/schemas/Item"
+                  error:
+                    $ref: "#/components/schemas/ErrorMessage"
+        default:
+          $ref: "#/components/responses/Error"
+    post:
+      summary: Creates an item.
+      requestBody:
+        $ref: "#/components/requestBodies/Item"
+      responses:
+        "201":
+          $ref: "#/components/responses/Item"
+        default:
+          $ref: "#/components/responses/Error"
+  /items/{id}:
+    parameters:
+      - $ref: "#/components/parameters/id"
+    get:
+      summary: Gets an item.
+      parameters:
+        - $ref: "#/components/parameters/reveal"
+        - $ref: "#/components/parameters/revealToken"
+      responses:
+        "200":
+          $ref: "#/components/responses/Item"
+        default:
+          $ref: "#/components/responses/Error"
+    put:
+      summary: Replaces an item. The labels are left untouched.
+      requestBody:
+        $ref: "#/components/requestBodies/Item"
+      responses:
+        "200":
+          $ref: "#/components/responses/Item"
+        default:
+          $ref: "#/components/responses/Error"
+    delete:
+      summary: Deletes an item.
+      responses:
+        "200":
+          $ref: "#/components/responses/Delete"
+        default:
+          $ref: "#/components/responses/Error"
+  /items/{id}/labels:
+    parameters:
+      - $ref: "#/components/parameters/id"
+    put:
+      summary: Replaces the labels of an item.
+      requestBody:
+        $ref: "#/components/requestBodies/Labels"
+      responses:
+        "200":
+          $ref: "#/components/responses/Labels"
+        default:
+          $ref: "#/components/responses/Error"
+  /itemtypes:
+    get:
+      summary: Lists the item types.
+      responses:
+        "200":
+          description: The item types.
+          content:
+            application/json:
+              schema:
+                type: object
+                properties:
+                  item_types:
+                    type: array
+                    items:
+                      $ref: "#/components/schemas/ItemType"
+                  error:
+                    $ref: "#/components/schemas/ErrorMessage"
+        default:
+          $ref: "#/components/responses/Error"
+    post:
+      summary: Creates an item type.
+      requestBody:
+        $ref: "#/components/requestBodies/ItemType"
+      responses:
+        "201":
+          $ref: "#/components/responses/ItemType"
+        default:
+          $ref: "#/components/responses/Error"
+  /itemtypes/{name}:
+    parameters:
+      - $ref: "#/components/parameters/name"
+    get:
+      summary: Gets an item type.
+      responses:
+        "200":
+          $ref: "#/components/responses/ItemType"
+        default:
+          $ref: "#/components/responses/Error"
+    put:
+      summary: Replaces an item type.
+      requestBody:
+        $ref: "#/components/requestBodies/ItemType"
+      responses:
+        "200":
+          $ref: "#/components/responses/ItemType"
+        default:
+          $ref: "#/components/responses/Error"
+    delete:
+      summary: Deletes an item type.
+      responses:
+        "200":
+          $ref: "#/components/responses/Delete"
+        default:
+          $ref: "#/components/responses/Error"
+  /modules:
+    get:
+      summary: Lists the modules.
+      parameters:
+        - $ref: "#/components/parameters/selector"
+        - $ref: "#/components/parameters/owner"
+        - $ref: "#/components/parameters/search"
+        - $ref: "#/components/parameters/annotation"
+        - $ref: "#/components/parameters/updatedSince"
+      responses:
+        "200":
+          description: The modules.
+          content:
+            application/json:
+              schema:
+                type: object
+                properties:
+                  modules:
+                    type: array
+                    items:
+                      $ref: "#/components/schemas/Module"
+                  error:
+                    $ref: "#/components/schemas/ErrorMessage"
+        default:
+          $ref: "#/components/responses/Error"
+    post:
+      summary: Creates a module.
+      requestBody:
+        $ref: "#/components/requestBodies/Module"
+      responses:
+        "201":
+          $ref: "#/components/responses/Module"
+        default:
+          $ref: "#/components/responses/Error"
+  /modules/{id}:
+    parameters:
+      - $ref: "#/components/parameters/id"
+    get:
+      summary: Gets a module.
+      responses:
+        "200":
+          $ref: "#/components/responses/Module"
+        default:
+          $ref: "#/components/responses/Error"
+    put:
+      summary: Replaces a module. The labels are left untouched.
+      requestBody:
+        $ref: "#/components/requestBodies/Module"
+      responses:
+        "200":
+          $ref: "#/components/responses/Module"
+        default:
+          $ref: "#/components/responses/Error"
+    delete:
+      summary: Deletes a module unless it is still in use.
+      parameters:
+        - name: cascade
+          in: query
+          allowEmptyValue: true
+          description: Deletes the module dependencies of the module with it.
+          schema:
+            type: string
+            enum: [dependencies]
+        - name: force
+          in: query
+          description: Deletes the module even if items are only linked to it.
+          allowEmptyValue: true
+          schema:
+            type: string
+      responses:
+        "200":
+          $ref: "#/components/responses/Delete"
+        default:
+          $ref: "#/components/responses/Error"
+  /modules/{id}/labels:
+    parameters:
+      - $ref: "#/components/parameters/id"
+    put:
+      summary: Replaces the labels of a module.
+      requestBody:
+        $ref: "#/components/requestBodies/Labels"
+      responses:
+        "200":
+          $ref: "#/components/responses/Labels"
+        default:
+          $ref: "#/components/responses/Error"
+  /modules/{id}/graph:
+    parameters:
+      - $ref: "#/components/parameters/id"
+    get:
+      summary: Gets the transitive dependencies of a module.
+      parameters:
+        - name: direction
+          in: query
+          allowEmptyValue: true
+          description: down follows the modules it depends on, up the modules depending on it.
+          schema:
+            type: string
+            enum: [down, up]
+            default: down
+        - name: depth
+          in: query
+          allowEmptyValue: true
+          description: The number of dependencies to follow, every one if it is 0.
+          schema:
+            type: integer
+            minimum: 0
+      responses:
+        "200":
+          description: The nodes and edges of the graph.
+          content:
+            application/json:
+              schema:
+                type: object
+                properties:
+                  root:
+                    type: integer
+                    format: int64
+                  direction:
+                    type: string
+                  nodes:
+                    type: array
+                    items:
+                      allOf:
+                        - $ref: "#/components/schemas/Module"
+                        - type: object
+                          properties:
+                            depth:
+                              type: integer
+                  edges:
+                    type: array
+                    items:
+                      $ref: "#/components/schemas/ModuleDependency"
+                  error:
+                    $ref: "#/components/schemas/ErrorMessage"
+        default:
+          $ref: "#/components/responses/Error"
+  /modules/{id}/impact:
+    parameters:
+      - $ref: "#/components/parameters/id"
+    get:
+      summary: Lists what is affected if a module is deleted.
+      responses:
+        "200":
+          description: The impact report.
+          content:
+            application/json:
+              schema:
+                type: object
+                properties:
+                  impact:
+                    type: object
+                  error:
+                    $ref: "#/components/schemas/ErrorMessage"
+        default:
+          $ref: "#/components/responses/Error"
+  /modules/{id}/clone:
+    parameters:
+      - $ref: "#/components/parameters/id"
+    post:
+      summary: Copies a module to a new version with its items and dependencies.
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              type: object
+              properties:
+                version:
+                  type: string
+                rewire:
+                  type: boolean
+      responses:
+        "201":
+          description: The copy.
+          content:
+            application/json:
+              schema:
+                type: object
+                properties:
+                  clone:
+                    type: object
+                    properties:
+                      module:
+                        $ref: "#/components/schemas/Module"
+                      from:
+                        type: integer
+                        format: int64
+                      items:
+                        type: integer
+                        format: int64
+                      dependencies:
+                        type: integer
+                        format: int64
+                      rewired:
+                        type: integer
+                        format: int64
+                  error:
+                    $ref: "#/components/schemas/ErrorMessage"
+        default:
+          $ref: "#/components/responses/Error"
+  /itemmodules:
+    get:
+      summary: Lists the links between items and modules.
+      responses:
+        "200":
+          description: The item modules.
+          content:
+            application/json:
+              schema:
+                type: object
+                properties:
+                  item_modules:
+                    type: array
+                    items:
+                      $ref: "#/components/schemas/ItemModule"
+                  error:
+                    $ref: "#/components/schemas/ErrorMessage"
+        default:
+          $ref: "#/components/responses/Error"
+    post:
+      summary: Links an item to a module.
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              $ref: "#/components/schemas/ItemModule"
+      responses:
+        "201":
+          $ref: "#/components/responses/ItemModule"
+        default:
+          $ref: "#/components/responses/Error"
+  /itemmodules/{id}:
+    parameters:
+      - $ref: "#/components/parameters/id"
+    get:
+      summary: Gets an item module.
+      responses:
+        "200":
+          $ref: "#/components/responses/ItemModule"
+        default:
+          $ref: "#/components/responses/Error"
+    delete:
+      summary: Deletes an item module.
+      responses:
+        "200":
+          $ref: "#/components/responses/Delete"
+        default:
+          $ref: "#/components/responses/Error"
+  /moduledependencies:
+    get:
+      summary: Lists the module dependencies.
+      responses:
+        "200":
+          $ref: "#/components/responses/ModuleDependencies"
+        default:
+          $ref: "#/components/responses/Error"
+    post:
+      summary: Makes a module depend on another.
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              $ref: "#/components/schemas/ModuleDependency"
+      responses:
+        "201":
+          description: The module dependency.
+          content:
+            application/json:
+              schema:
+                type: object
+                properties:
+                  module_dependency:
+                    $ref: "#/components/schemas/ModuleDependency"
+                  error:
+                    $ref: "#/components/schemas/ErrorMessage"
+                  fields:
+                    $ref: "#/components/schemas/FieldErrors"
+        default:
+          $ref: "#/components/responses/Error"
+  /moduledependencies/dependent/{id}:
+    parameters:
+      - $ref: "#/components/parameters/id"
+    get:
+      summary: Lists the module dependencies of a dependent.
+      responses:
+        "200":
+          $ref: "#/components/responses/ModuleDependencies"
+        default:
+          $ref: "#/components/responses/Error"
+    delete:
+      summary: Deletes the module dependencies of a dependent.
+      responses:
+        "200":
+          $ref: "#/components/responses/Delete"
+        default:
+          $ref: "#/components/responses/Error"
+  /moduledependencies/dependee/{id}:
+    parameters:
+      - $ref: "#/components/parameters/id"
+    get:
+      summary: Lists the module dependencies of a dependee.
+      responses:
+        "200":
+          $ref: "#/components/responses/ModuleDependencies"
+        default:
+          $ref: "#/components/responses/Error"
+    delete:
+      summary: Deletes the module dependencies of a dependee.
+      responses:
+        "200":
+          $ref: "#/components/responses/Delete"
+        default:
+          $ref: "#/components/responses/Error"
+  /moduledependencies/dependent/{dependentID}/dependee/{dependeeID}:
+    parameters:
+      - name: dependentID
+        in: path
+        required: true
+        schema:
+          type: integer
+          format: int64
+      - name: dependeeID
+        in: path
+        required: true
+        schema:
+          type: integer
+          format: int64
+    delete:
+      summary: Deletes a module dependency.
+      responses:
+        "200":
+          $ref: "#/components/responses/Delete"
+        default:
+          $ref: "#/components/responses/Error"
+  /import/csv:
+    post:
+      summary: Imports items and links them to their modules from a CSV.
+      parameters:
+        - name: dry_run
+          in: query
+          allowEmptyValue: true
+          description: Only validates the rows and tells what would be created.
+          schema:
+            type: boolean
+      requestBody:
+        required: true
+        content:
+          text/csv:
+            schema:
+              type: string
+      responses:
+        "200":
+          $ref: "#/components/responses/Import"
+        "201":
+          $ref: "#/components/responses/Import"
+        default:
+          $ref: "#/components/responses/Import"
+  /releases:
+    get:
+      summary: Lists the releases.
+      parameters:
+        - $ref: "#/components/parameters/reveal"
+        - $ref: "#/components/parameters/revealToken"
+      responses:
+        "200":
+          description: The releases.
+          content:
+            application/json:
+              schema:
+                type: object
+                properties:
+                  releases:
+                    type: array
+                    items:
+                      $ref: "#/components/schemas/Release"
+                  error:
+                    $ref: "#/components/schemas/ErrorMessage"
+        default:
+          $ref: "#/components/responses/Error"
+    post:
+      summary: Freezes modules and their resolved closure into a release.
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              type: object
+              properties:
+                name:
+                  type: string
+                modules:
+                  type: array
+                  items:
+                    type: integer
+                    format: int64
+                optional:
+                  type: boolean
+      responses:
+        "201":
+          $ref: "#/components/responses/Release"
+        default:
+          $ref: "#/components/responses/Error"
+  /releases/{name}:
+    parameters:
+      - $ref: "#/components/parameters/name"
+    get:
+      summary: Gets a release.
+      parameters:
+        - $ref: "#/components/parameters/reveal"
+        - $ref: "#/components/parameters/revealToken"
+      responses:
+        "200":
+          $ref: "#/components/responses/Release"
+        default:
+          $ref: "#/components/responses/Error"
+  /releases/{name}/promote:
+    parameters:
+      - $ref: "#/components/parameters/name"
+    post:
+      summary: Promotes a release to the next environment.
+      parameters:
+        - $ref: "#/components/parameters/principal"
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              type: object
+              properties:
+                to:
+                  type: string
+      responses:
+        "201":
+          $ref: "#/components/responses/Promotion"
+        "202":
+          $ref: "#/components/responses/Promotion"
+        default:
+          $ref: "#/components/responses/Error"
+  /releases/{name}/promotions:
+    parameters:
+      - $ref: "#/components/parameters/name"
+    get:
+      summary: Lists the promotions of a release.
+      responses:
+        "200":
+          description: The promotions.
+          content:
+            application/json:
+              schema:
+                type: object
+                properties:
+                  promotions:
+                    type: array
+                    items:
+                      $ref: "#/components/schemas/Promotion"
+                  error:
+                    $ref: "#/components/schemas/ErrorMessage"
+        default:
+          $ref: "#/components/responses/Error"
+  /releases/{name}/promotions/{id}/approve:
+    parameters:
+      - $ref: "#/components/parameters/name"
+      - $ref: "#/components/parameters/id"
+    post:
+      summary: Approves a pending promotion.
+      parameters:
+        - $ref: "#/components/parameters/principal"
+      responses:
+        "200":
+          $ref: "#/components/responses/Promotion"
+        default:
+          $ref: "#/components/responses/Error"
+components:
+  parameters:
+    id:
+      name: id
+      in: path
+      required: true
+      schema:
+        type: integer
+        format: int64
+    name:
+      name: name
+      in: path
+      required: true
+      schema:
+        type: string
+    selector:
+      name: selector
+      in: query
+      allowEmptyValue: true
+      description: A label selector, e.g. team=billing,env in (dev,prod).
+      schema:
+        type: string
+    owner:
+      name: owner
+      in: query
+      allowEmptyValue: true
+      schema:
+        type: string
+    search:
+      name: q
+      in: query
+      allowEmptyValue: true
+      description: The value or description must contain this, ignoring case.
+      schema:
+        type: string
+    annotation:
+      name: annotation
+      in: query
+      allowEmptyValue: true
+      description: The annotation with this key must be set.
+      schema:
+        type: string
+    updatedSince:
+      name: updated_since
+      in: query
+      allowEmptyValue: true
+      schema:
+        type: string
+        format: date-time
+    reveal:
+      name: reveal
+      in: query
+      allowEmptyValue: true
+      description: Shows the values of secret items with the reveal token.
+      schema:
+        type: boolean
+    revealToken:
+      name: X-Reveal-Token
+      in: header
+      schema:
+        type: string
+    principal:
+      name: X-Principal
+      in: header
+      description: Who asks, a promotion is refused without it.
+      schema:
+        type: string
+  requestBodies:
+    Item:
+      required: true
+      content:
+        application/json:
+          schema:
+            $ref: "#/components/schemas/Item"
+    ItemType:
+      required: true
+      content:
+        application/json:
+          schema:
+            $ref: "#/components/schemas/ItemType"
+    Module:
+      required: true
+      content:
+        application/json:
+          schema:
+            $ref: "#/components/schemas/Module"
+    Labels:
+      required: true
+      content:
+        application/json:
+          schema:
+            $ref: "#/components/schemas/Labels"
+  responses:
+    Error:
+      description: The request failed.
+      content:
+        application/json:
+          schema:
+            type: object
+            properties:
+              error:
+                $ref: "#/components/schemas/ErrorMessage"
+              fields:
+                $ref: "#/components/schemas/FieldErrors"
+    Delete:
+      description: The number of rows deleted.
+      content:
+        application/json:
+          schema:
+            type: object
+            properties:
+              rows_affected:
+                type: integer
+                format: int64
+              impact:
+                type: object
+              error:
+                $ref: "#/components/schemas/ErrorMessage"
+    Item:
+      description: The item.
+      content:
+        application/json:
+          schema:
+            type: object
+            properties:
+              item:
+                $ref: "#/components/schemas/Item"
+              error:
+                $ref: "#/components/schemas/ErrorMessage"
+              fields:
+                $ref: "#/components/schemas/FieldErrors"
+    ItemType:
+      description: The item type.
+      content:
+        application/json:
+          schema:
+            type: object
+            properties:
+              item_type:
+                $ref: "#/components/schemas/ItemType"
+              error:
+                $ref: "#/components/schemas/ErrorMessage"
+              fields:
+                $ref: "#/components/schemas/FieldErrors"
+    Module:
+      description: The module.
+      content:
+        application/json:
+          schema:
+            type: object
+            properties:
+              module:
+                $ref: "#/components/schemas/Module"
+              error:
+                $ref: "#/components/schemas/ErrorMessage"
+              fields:
+                $ref: "#/components/schemas/FieldErrors"
+    Labels:
+      description: The labels.
+      content:
+        application/json:
+          schema:
+            type: object
+            properties:
+              labels:
+                $ref: "#/components/schemas/Labels"
+              error:
+                $ref: "#/components/schemas/ErrorMessage"
+              fields:
+                $ref: "#/components/schemas/FieldErrors"
+    ItemModule:
+      description: The item module.
+      content:
+        application/json:
+          schema:
+            type: object
+            properties:
+              item_module:
+                $ref: "#/components/schemas/ItemModule"
+              error:
+                $ref: "#/components/schemas/ErrorMessage"
+    ModuleDependencies:
+      description: The module dependencies.
+      content:
+        application/json:
+          schema:
+            type: object
+            properties:
+              module_dependencies:
+                type: array
+                items:
+                  $ref: "#/components/schemas/ModuleDependency"
+              error:
+                $ref: "#/components/schemas/ErrorMessage"
+    Release:
+      description: The release.
+      content:
+        application/json:
+          schema:
+            type: object
+            properties:
+              release:
+                $ref: "#/components/schemas/Release"
+              error:
+                $ref: "#/components/schemas/ErrorMessage"
+              fields:
+                $ref: "#/components/schemas/FieldErrors"
+              module_conflicts:
+                type: array
+                items:
+                  type: object
+    Promotion:
+      description: The promotion.
+      content:
+        application/json:
+          schema:
+            type: object
+            properties:
+              promotion:
+                $ref: "#/components/schemas/Promotion"
+              error:
+                $ref: "#/components/schemas/ErrorMessage"
+              fields:
+                $ref: "#/components/schemas/FieldErrors"
+    Import:
+      description: The outcome of every row of the import.
+      content:
+        application/json:
+          schema:
+            type: object
+            properties:
+              dry_run:
+                type: boolean
+              rows:
+                type: array
+                items:
+                  type: object
+                  properties:
+                    row:
+                      type: integer
+                    outcome:
+                      type: string
+                      enum: [created, unchanged, invalid]
+                    item_id:
+                      type: integer
+                      format: int64
+                    module_id:
+                      type: integer
+                      format: int64
+                    item_module_id:
+                      type: integer
+                      format: int64
+                    created:
+                      type: array
+                      items:
+                        type: string
+                        enum: [item, module, link]
+                    fields:
+                      $ref: "#/components/schemas/FieldErrors"
+              error:
+                $ref: "#/components/schemas/ErrorMessage"
+  schemas:
+    Health:
+      type: object
+      properties:
+        status:
+          type: string
+          enum: [ok, unavailable]
+        checks:
+          type: object
+          additionalProperties:
+            type: object
+            properties:
+              status:
+                type: string
+                enum: [ok, failed]
+              error:
+                type: string
+              duration_ms:
+                type: number
+    ErrorMessage:
+      type: string
+      nullable: true
+    FieldErrors:
+      type: array
+      items:
+        type: object
+        properties:
+          field:
+            type: string
+          message:
+            type: string
+    Labels:
+      type: object
+      additionalProperties:
+        type: string
+    Item:
+      type: object
+      description: >-
+        A configuration value. The value of a secret item is masked unless it
+        is revealed. created_at and updated_at are ignored when it is saved.
+      properties:
+        id:
+          type: integer
+          format: int64
+        value:
+          type: string
+        type:
+          type: string
+        version:
+          type: string
+        secret:
+          type: boolean
+        labels:
+          $ref: "#/components/schemas/Labels"
+        description:
+          type: string
+        owner:
+          type: string
+        homepage:
+          type: string
+        annotations:
+          type: object
+          nullable: true
+        created_at:
+          type: string
+          format: date-time
+        updated_at:
+          type: string
+          format: date-time
+    ItemType:
+      type: object
+      properties:
+        name:
+          type: string
+        pattern:
+          type: string
+        enum:
+          type: array
+          items:
+            type: string
+        min:
+          type: integer
+          format: int64
+          nullable: true
+        max:
+          type: integer
+          format: int64
+          nullable: true
+    Module:
+      type: object
+      description: created_at and updated_at are ignored when it is saved.
+      properties:
+        id:
+          type: integer
+          format: int64
+        value:
+          type: string
+        version:
+          type: string
+        labels:
+          $ref: "#/components/schemas/Labels"
+        description:
+          type: string
+        owner:
+          type: string
+        homepage:
+          type: string
+        annotations:
+          type: object
+          nullable: true
+        created_at:
+          type: string
+          format: date-time
+        updated_at:
+          type: string
+          format: date-time
+    ItemModule:
+      type: object
+      properties:
+        id:
+          type: integer
+          format: int64
+        item_id:
+          type: integer
+          format: int64
+        module_id:
+          type: integer
+          format: int64
+    ModuleDependency:
+      type: object
+      description: The kind is required, optional, conflicts or recommends and required if it is empty.
+      properties:
+        dependent:
+          type: integer
+          format: int64
+        dependee:
+          type: integer
+          format: int64
+        kind:
+          type: string
+    Release:
+      type: object
+      properties:
+        name:
+          type: string
+        modules:
+          type: array
+          items:
+            type: integer
+            format: int64
+        optional:
+          type: boolean
+        closure:
+          type: array
+          items:
+            type: object
+            properties:
+              id:
+                type: integer
+                format: int64
+              value:
+                type: string
+              version:
+                type: string
+              depth:
+                type: integer
+              from:
+                type: integer
+                format: int64
+              kind:
+                type: string
+              items:
+                type: array
+                items:
+                  $ref: "#/components/schemas/Item"
+        created_at:
+          type: string
+          format: date-time
+    Promotion:
+      type: object
+      properties:
+        id:
+          type: integer
+          format: int64
+        release:
+          type: string
+        environment:
+          type: string
+        status:
+          type: string
+          enum: [pending, promoted]
+        requested_by:
+          type: string
+        requested_at:
+          type: string
+          format: date-time
+        approved_by:
+          type: string
+        promoted_at:
+          type: string
+          format: date-time
+`
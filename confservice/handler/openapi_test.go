@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/gorilla/mux"
+)
+
+// TestSpecRoutes makes sure that the document and the routes of the handler
+// describe the same operations.
+func TestSpecRoutes(t *testing.T) {
+	doc, err := loadSpec()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	routes := make(map[string]bool)
+	r := New(newReleaseDB()).(*mux.Router)
+	err = r.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		tmpl, err := route.GetPathTemplate()
+		if err != nil {
+			return err
+		}
+		tmpl = routeVar.ReplaceAllString(tmpl, "{$1}")
+
+		methods, err := route.GetMethods()
+		if err != nil {
+			// routes without methods answer every method, like /health
+			methods = []string{http.MethodGet}
+		}
+
+		for _, m := range methods {
+			routes[m+" "+tmpl] = true
+			if doc.Paths.Find(tmpl) == nil || doc.Paths.Find(tmpl).GetOperation(m) == nil {
+				t.Errorf("expected %v %v to be in the document", m, tmpl)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for path, item := range doc.Paths {
+		for m := range item.Operations() {
+			if !routes[m+" "+path] {
+				t.Errorf("expected %v %v to be routed", m, path)
+			}
+		}
+	}
+}
+
+func TestOpenAPI(t *testing.T) {
+	srv := httptest.NewServer(New(newReleaseDB()))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/openapi.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status: %v, got: %v", http.StatusOK, resp.StatusCode)
+	}
+
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	doc, err := openapi3.NewLoader().LoadFromData(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.Paths.Find("/items/{id}") == nil {
+		t.Fatalf("expected the items to be documented, got: %v", doc.Paths)
+	}
+}
+
+func TestValidateRequests(t *testing.T) {
+	tt := map[string]struct {
+		method      string
+		url         string
+		contentType string
+		body        string
+		status      int
+		field       string
+	}{
+		"valid":              {method: http.MethodPost, url: "/modules", body: `{"value": "E", "version": "0.0.1"}`, status: http.StatusCreated},
+		"no content type":    {method: http.MethodPost, url: "/modules", contentType: "-", body: `{"value": "E", "version": "0.0.1"}`, status: http.StatusCreated},
+		"wrong type":         {method: http.MethodPost, url: "/modules", body: `{"value": 1, "version": "0.0.1"}`, status: http.StatusBadRequest, field: "value"},
+		"nested wrong type":  {method: http.MethodPost, url: "/items", body: `{"value": "a", "type": "b", "version": "1", "labels": {"team": 1}}`, status: http.StatusBadRequest, field: "labels.team"},
+		"malformed":          {method: http.MethodPost, url: "/modules", body: `{"value": `, status: http.StatusBadRequest},
+		"wrong content type": {method: http.MethodPost, url: "/modules", contentType: "text/plain", body: `{}`, status: http.StatusBadRequest},
+		"query":              {method: http.MethodGet, url: "/modules/1/graph?depth=-1", status: http.StatusBadRequest, field: "depth"},
+		"query enum":         {method: http.MethodGet, url: "/modules/1/graph?direction=sideways", status: http.StatusBadRequest, field: "direction"},
+		"empty query":        {method: http.MethodGet, url: "/modules/1/graph?depth=&direction=", status: http.StatusOK},
+		"not routed":         {method: http.MethodGet, url: "/modules/a", status: http.StatusNotFound},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			srv := httptest.NewServer(New(newReleaseDB()))
+			defer srv.Close()
+
+			req, err := http.NewRequest(tc.method, srv.URL+tc.url, bytes.NewBufferString(tc.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			switch tc.contentType {
+			case "":
+				req.Header.Set("Content-Type", "application/json")
+			case "-":
+			default:
+				req.Header.Set("Content-Type", tc.contentType)
+			}
+
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tc.status {
+				t.Fatalf("expected status: %v, got: %v", tc.status, resp.StatusCode)
+			}
+
+			if tc.status != http.StatusBadRequest {
+				return
+			}
+
+			var body validationResponse
+			if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+				t.Fatal(err)
+			}
+			if body.Error == nil {
+				t.Fatal("expected an error, got: nil")
+			}
+
+			if tc.field == "" {
+				if !strings.HasPrefix(*body.Error, errWrongFormat.Error()) {
+					t.Fatalf("expected error: %v, got: %v", errWrongFormat, *body.Error)
+				}
+				return
+			}
+			if len(body.Fields) != 1 || body.Fields[0].Field != tc.field {
+				t.Fatalf("expected a field error for %v, got: %+v", tc.field, body.Fields)
+			}
+		})
+	}
+}
@@ -0,0 +1,361 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Glorforidor/conmansys/confservice/storage"
+	"github.com/gorilla/mux"
+)
+
+// principalHeader is the request header naming who makes the request. It is
+// trusted as is, so it must be set by something which authenticates the
+// caller, like the apigateway.
+const principalHeader = "X-Principal"
+
+// defaultProtected are the environments which need an approval if no other
+// environments are given with WithProtectedEnvironments.
+var defaultProtected = []string{"prod"}
+
+// WithProtectedEnvironments makes promotions into the given environments wait
+// for an approval from another principal than the one who asked for it.
+func WithProtectedEnvironments(envs ...string) Option {
+	return func(h *handler) {
+		h.protected = make(map[string]bool, len(envs))
+		for _, e := range envs {
+			h.protected[e] = true
+		}
+	}
+}
+
+// environment returns the position of the environment in the pipeline and -1
+// if it is not part of it.
+func environment(env string) int {
+	for i, e := range storage.Environments {
+		if e == env {
+			return i
+		}
+	}
+	return -1
+}
+
+// currentPromotion returns the promotion of the release which is in the
+// environment now, which is the one promoted last, and nil if no release was
+// promoted to the environment.
+func currentPromotion(promotions []*storage.Promotion, env string) *storage.Promotion {
+	var current *storage.Promotion
+	for _, p := range promotions {
+		if p.Environment != env || p.Status != storage.Promoted {
+			continue
+		}
+		if current == nil || later(p, current) {
+			current = p
+		}
+	}
+	return current
+}
+
+// later reports whether promotion a was promoted after promotion b.
+func later(a, b *storage.Promotion) bool {
+	if a.PromotedAt != nil && b.PromotedAt != nil && !a.PromotedAt.Equal(*b.PromotedAt) {
+		return a.PromotedAt.After(*b.PromotedAt)
+	}
+	return a.ID > b.ID
+}
+
+type promotionRequest struct {
+	// To is the environment to promote to. It is the environment after the
+	// last one the release was promoted to if it is empty.
+	To string `json:"to"`
+}
+
+type promotionResponse struct {
+	Promotion *storage.Promotion    `json:"promotion"`
+	Error     *string               `json:"error"`
+	Fields    []*storage.FieldError `json:"fields,omitempty"`
+}
+
+type promotionsResponse struct {
+	Promotions []*storage.Promotion `json:"promotions"`
+	Error      *string              `json:"error"`
+}
+
+// promoteRelease promotes the release with the name in the path to the
+// environment in the request body. The release must have been promoted to the
+// environment before it in the pipeline. A promotion into a protected
+// environment is pending until it is approved and is answered with an accepted
+// status. It returns the response as an empty interface and a http status.
+func (h handler) promoteRelease(r *http.Request) (data interface{}, status int) {
+	params := mux.Vars(r)
+	name := strings.TrimSpace(params["name"])
+	principal := strings.TrimSpace(r.Header.Get(principalHeader))
+	var resp promotionResponse
+	var req promotionRequest
+	var errMsg string
+
+	// routing should prevent this, but might as well guard it
+	if name == "" {
+		errMsg = errMissingValue.Error()
+		resp.Error = &errMsg
+		return resp, http.StatusBadRequest
+	}
+
+	if principal == "" {
+		errMsg = fmt.Sprintf("missing %v header", principalHeader)
+		resp.Error = &errMsg
+		return resp, http.StatusUnauthorized
+	}
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		errMsg = errWrongFormat.Error()
+		resp.Error = &errMsg
+		return resp, http.StatusBadRequest
+	}
+
+	release, err := h.storage.GetRelease(r.Context(), name)
+	if err != nil {
+		logError(r, err)
+		errMsg = errInternal.Error()
+		resp.Error = &errMsg
+		return resp, http.StatusInternalServerError
+	}
+
+	if release == nil {
+		errMsg = errNotFound.Error()
+		resp.Error = &errMsg
+		return resp, http.StatusNotFound
+	}
+
+	promotions, err := h.storage.GetPromotions(r.Context(), name)
+	if err != nil {
+		logError(r, err)
+		errMsg = errInternal.Error()
+		resp.Error = &errMsg
+		return resp, http.StatusInternalServerError
+	}
+
+	// the furthest environment the release was promoted to
+	furthest := -1
+	for _, p := range promotions {
+		if i := environment(p.Environment); p.Status == storage.Promoted && i > furthest {
+			furthest = i
+		}
+	}
+
+	to := strings.TrimSpace(req.To)
+	if to == "" {
+		if furthest+1 >= len(storage.Environments) {
+			errMsg = fmt.Sprintf("release %q is already in the last environment", name)
+			resp.Error = &errMsg
+			return resp, http.StatusConflict
+		}
+		to = storage.Environments[furthest+1]
+	}
+
+	i := environment(to)
+	if i < 0 {
+		errMsg = errInvalid.Error()
+		resp.Error = &errMsg
+		resp.Fields = []*storage.FieldError{{
+			Field:   "to",
+			Message: fmt.Sprintf("must be one of %q", storage.Environments),
+		}}
+		return resp, http.StatusBadRequest
+	}
+
+	if i > furthest+1 {
+		errMsg = fmt.Sprintf("release %q must be promoted to %v first", name, storage.Environments[furthest+1])
+		resp.Error = &errMsg
+		return resp, http.StatusConflict
+	}
+
+	all, err := h.storage.GetPromotions(r.Context(), "")
+	if err != nil {
+		logError(r, err)
+		errMsg = errInternal.Error()
+		resp.Error = &errMsg
+		return resp, http.StatusInternalServerError
+	}
+
+	if current := currentPromotion(all, to); current != nil && current.Release == name {
+		errMsg = fmt.Sprintf("release %q is already the release of %v", name, to)
+		resp.Error = &errMsg
+		return resp, http.StatusConflict
+	}
+
+	for _, p := range promotions {
+		if p.Environment == to && p.Status == storage.Pending {
+			errMsg = fmt.Sprintf("promotion %v of release %q to %v is waiting for approval", p.ID, name, to)
+			resp.Error = &errMsg
+			return resp, http.StatusConflict
+		}
+	}
+
+	promotion := &storage.Promotion{
+		Release:     name,
+		Environment: to,
+		Status:      storage.Promoted,
+		RequestedBy: principal,
+	}
+	status = http.StatusCreated
+	if h.protected[to] {
+		promotion.Status = storage.Pending
+		status = http.StatusAccepted
+	}
+
+	// another promotion may pass the checks above at the same time, so the
+	// storage checks again
+	id, err := h.storage.CreatePromotion(r.Context(), promotion)
+	switch err {
+	case storage.ErrCurrent:
+		errMsg = fmt.Sprintf("release %q is already the release of %v", name, to)
+		resp.Error = &errMsg
+		return resp, http.StatusConflict
+	case storage.ErrExists:
+		errMsg = fmt.Sprintf("a promotion of release %q to %v is waiting for approval", name, to)
+		resp.Error = &errMsg
+		return resp, http.StatusConflict
+	}
+	if err != nil {
+		logError(r, err)
+		errMsg = errInternal.Error()
+		resp.Error = &errMsg
+		return resp, http.StatusInternalServerError
+	}
+
+	promotion.ID = id
+	resp.Promotion = promotion
+	return resp, status
+}
+
+// approvePromotion approves the pending promotion with the id in the path of
+// the release with the name in the path, which promotes the release. The
+// promotion must be approved by another principal than the one who asked for
+// it. It returns the response as an empty interface and a http status.
+func (h handler) approvePromotion(r *http.Request) (data interface{}, status int) {
+	params := mux.Vars(r)
+	name := strings.TrimSpace(params["name"])
+	id := strings.TrimSpace(params["id"])
+	principal := strings.TrimSpace(r.Header.Get(principalHeader))
+	var resp promotionResponse
+	var errMsg string
+
+	// routing should prevent this, but might as well guard it
+	if name == "" || id == "" {
+		errMsg = errMissingValue.Error()
+		resp.Error = &errMsg
+		return resp, http.StatusBadRequest
+	}
+
+	i, err := strconv.ParseInt(id, 10, 64)
+	// routing should prevent this, but might as well guard it
+	if err != nil {
+		errMsg = errNaN.Error()
+		resp.Error = &errMsg
+		return resp, http.StatusBadRequest
+	}
+
+	if principal == "" {
+		errMsg = fmt.Sprintf("missing %v header", principalHeader)
+		resp.Error = &errMsg
+		return resp, http.StatusUnauthorized
+	}
+
+	promotion, err := h.storage.GetPromotion(r.Context(), i)
+	if err != nil {
+		logError(r, err)
+		errMsg = errInternal.Error()
+		resp.Error = &errMsg
+		return resp, http.StatusInternalServerError
+	}
+
+	if promotion == nil || promotion.Release != name {
+		errMsg = errNotFound.Error()
+		resp.Error = &errMsg
+		return resp, http.StatusNotFound
+	}
+
+	if promotion.Status != storage.Pending {
+		errMsg = fmt.Sprintf("promotion %v is not waiting for approval", i)
+		resp.Error = &errMsg
+		resp.Promotion = promotion
+		return resp, http.StatusConflict
+	}
+
+	if promotion.RequestedBy == principal {
+		errMsg = "a promotion must be approved by another principal than the one who asked for it"
+		resp.Error = &errMsg
+		resp.Promotion = promotion
+		return resp, http.StatusForbidden
+	}
+
+	promotion.ApprovedBy = principal
+	row, err := h.storage.ApprovePromotion(r.Context(), promotion)
+	if err != nil {
+		logError(r, err)
+		errMsg = errInternal.Error()
+		resp.Error = &errMsg
+		return resp, http.StatusInternalServerError
+	}
+
+	if row == 0 {
+		// approved by someone else since it was read
+		errMsg = fmt.Sprintf("promotion %v is not waiting for approval", i)
+		resp.Error = &errMsg
+		return resp, http.StatusConflict
+	}
+
+	resp.Promotion = promotion
+	return resp, http.StatusOK
+}
+
+// promotions retrieves the promotions of the release with the name in the path
+// and packs them into a response. It returns the response as an empty
+// interface and a http status.
+func (h handler) promotions(r *http.Request) (data interface{}, status int) {
+	params := mux.Vars(r)
+	name := strings.TrimSpace(params["name"])
+	var resp promotionsResponse
+	var errMsg string
+	// ensure that there is an empty slice
+	resp.Promotions = []*storage.Promotion{}
+
+	// routing should prevent this, but might as well guard it
+	if name == "" {
+		errMsg = errMissingValue.Error()
+		resp.Error = &errMsg
+		return resp, http.StatusBadRequest
+	}
+
+	release, err := h.storage.GetRelease(r.Context(), name)
+	if err != nil {
+		logError(r, err)
+		errMsg = errInternal.Error()
+		resp.Error = &errMsg
+		return resp, http.StatusInternalServerError
+	}
+
+	if release == nil {
+		errMsg = errNotFound.Error()
+		resp.Error = &errMsg
+		return resp, http.StatusNotFound
+	}
+
+	promotions, err := h.storage.GetPromotions(r.Context(), name)
+	if err != nil {
+		logError(r, err)
+		errMsg = errInternal.Error()
+		resp.Error = &errMsg
+		return resp, http.StatusInternalServerError
+	}
+
+	if promotions != nil {
+		resp.Promotions = promotions
+	}
+
+	return resp, http.StatusOK
+}
@@ -0,0 +1,327 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/Glorforidor/conmansys/confservice/storage"
+)
+
+// newPromotionDB holds the release 2026.09-prod promoted to dev and the release
+// 2026.10-prod promoted to dev and staging with a pending promotion to prod.
+func newPromotionDB() *dbmock {
+	db := newReleaseDB()
+	db.releases = append(db.releases, &storage.Release{Name: "2026.10-prod", Modules: []int64{1}})
+	db.promotions = []*storage.Promotion{
+		{ID: 1, Release: "2026.09-prod", Environment: "dev", Status: storage.Promoted, RequestedBy: "alice"},
+		{ID: 2, Release: "2026.10-prod", Environment: "dev", Status: storage.Promoted, RequestedBy: "alice"},
+		{ID: 3, Release: "2026.10-prod", Environment: "staging", Status: storage.Promoted, RequestedBy: "alice"},
+		{ID: 4, Release: "2026.10-prod", Environment: "prod", Status: storage.Pending, RequestedBy: "alice"},
+	}
+	return db
+}
+
+func TestPromoteRelease(t *testing.T) {
+	tt := map[string]struct {
+		release     string
+		body        string
+		principal   string
+		status      int
+		environment string
+		closed      bool
+	}{
+		"next": {
+			release: "2026.09-prod", body: `{}`, principal: "alice",
+			status: http.StatusCreated, environment: "staging",
+		},
+		"explicit": {
+			release: "2026.09-prod", body: `{"to": "staging"}`, principal: "alice",
+			status: http.StatusCreated, environment: "staging",
+		},
+		"again": {
+			release: "2026.09-prod", body: `{"to": "dev"}`, principal: "alice",
+			status: http.StatusCreated, environment: "dev",
+		},
+		"current":        {release: "2026.10-prod", body: `{"to": "staging"}`, principal: "alice", status: http.StatusConflict},
+		"skip":           {release: "2026.09-prod", body: `{"to": "prod"}`, principal: "alice", status: http.StatusConflict},
+		"pending":        {release: "2026.10-prod", body: `{}`, principal: "bob", status: http.StatusConflict},
+		"unknown env":    {release: "2026.09-prod", body: `{"to": "qa"}`, principal: "alice", status: http.StatusBadRequest},
+		"missing":        {release: "nope", body: `{}`, principal: "alice", status: http.StatusNotFound},
+		"no principal":   {release: "2026.09-prod", body: `{}`, status: http.StatusUnauthorized},
+		"wrong format":   {release: "2026.09-prod", body: `[]`, principal: "alice", status: http.StatusBadRequest},
+		"closed storage": {release: "2026.09-prod", body: `{}`, principal: "alice", status: http.StatusInternalServerError, closed: true},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			db := newPromotionDB()
+			db.closed = tc.closed
+			srv := httptest.NewServer(New(db))
+			defer srv.Close()
+
+			req, err := http.NewRequest(http.MethodPost, srv.URL+"/releases/"+tc.release+"/promote",
+				bytes.NewBufferString(tc.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tc.principal != "" {
+				req.Header.Set(principalHeader, tc.principal)
+			}
+
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tc.status {
+				t.Fatalf("expected status: %v, got: %v", tc.status, resp.StatusCode)
+			}
+
+			var body promotionResponse
+			if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+				t.Fatal(err)
+			}
+
+			if tc.status != http.StatusCreated {
+				if body.Error == nil {
+					t.Fatal("expected an error, got: nil")
+				}
+				return
+			}
+
+			if body.Promotion.Environment != tc.environment {
+				t.Fatalf("expected environment: %v, got: %v", tc.environment, body.Promotion.Environment)
+			}
+			if body.Promotion.Status != storage.Promoted || body.Promotion.PromotedAt == nil {
+				t.Fatalf("expected the release to be promoted, got: %v", body.Promotion.Status)
+			}
+		})
+	}
+}
+
+func TestPromoteProtected(t *testing.T) {
+	db := newPromotionDB()
+	srv := httptest.NewServer(New(db))
+	defer srv.Close()
+
+	post := func(url, principal, body string) (*promotionResponse, int) {
+		req, err := http.NewRequest(http.MethodPost, srv.URL+url, bytes.NewBufferString(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set(principalHeader, principal)
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		var r promotionResponse
+		if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+			t.Fatal(err)
+		}
+		return &r, resp.StatusCode
+	}
+
+	// 2026.09-prod goes through staging and waits for approval into prod
+	if _, status := post("/releases/2026.09-prod/promote", "alice", `{}`); status != http.StatusCreated {
+		t.Fatalf("expected status: %v, got: %v", http.StatusCreated, status)
+	}
+	body, status := post("/releases/2026.09-prod/promote", "alice", `{}`)
+	if status != http.StatusAccepted {
+		t.Fatalf("expected status: %v, got: %v", http.StatusAccepted, status)
+	}
+	if body.Promotion.Status != storage.Pending || body.Promotion.PromotedAt != nil {
+		t.Fatalf("expected a pending promotion, got: %v", body.Promotion.Status)
+	}
+
+	url := "/releases/2026.09-prod/promotions/" + strconv.FormatInt(body.Promotion.ID, 10) + "/approve"
+	if _, status := post(url, "alice", ``); status != http.StatusForbidden {
+		t.Fatalf("expected the requester to be refused with: %v, got: %v", http.StatusForbidden, status)
+	}
+
+	body, status = post(url, "bob", ``)
+	if status != http.StatusOK {
+		t.Fatalf("expected status: %v, got: %v", http.StatusOK, status)
+	}
+	if body.Promotion.Status != storage.Promoted || body.Promotion.ApprovedBy != "bob" {
+		t.Fatalf("expected the promotion to be approved by bob, got: %+v", body.Promotion)
+	}
+
+	if _, status := post(url, "carol", ``); status != http.StatusConflict {
+		t.Fatalf("expected a second approval to give: %v, got: %v", http.StatusConflict, status)
+	}
+}
+
+// racedDB hides the current releases and the pending promotions from the
+// checks of the handler, as if another promotion was created right after them.
+type racedDB struct {
+	*dbmock
+}
+
+func (d racedDB) GetPromotions(ctx context.Context, release string) ([]*storage.Promotion, error) {
+	if release == "" {
+		return nil, nil
+	}
+
+	all, err := d.dbmock.GetPromotions(ctx, release)
+	var promotions []*storage.Promotion
+	for _, p := range all {
+		if p.Status != storage.Pending {
+			promotions = append(promotions, p)
+		}
+	}
+	return promotions, err
+}
+
+func TestPromoteRaced(t *testing.T) {
+	tt := map[string]struct {
+		body string
+	}{
+		"current": {body: `{"to": "staging"}`},
+		"pending": {body: `{"to": "prod"}`},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			db := newPromotionDB()
+			srv := httptest.NewServer(New(racedDB{db}))
+			defer srv.Close()
+
+			req, err := http.NewRequest(http.MethodPost, srv.URL+"/releases/2026.10-prod/promote",
+				bytes.NewBufferString(tc.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set(principalHeader, "alice")
+
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != http.StatusConflict {
+				t.Fatalf("expected status: %v, got: %v", http.StatusConflict, resp.StatusCode)
+			}
+			if len(db.promotions) != 4 {
+				t.Fatalf("expected no promotion to be created, got: %v", db.promotions[4:])
+			}
+		})
+	}
+}
+
+func TestApprovePromotion(t *testing.T) {
+	tt := map[string]struct {
+		url       string
+		principal string
+		status    int
+		closed    bool
+	}{
+		"approve":        {url: "/releases/2026.10-prod/promotions/4/approve", principal: "bob", status: http.StatusOK},
+		"requester":      {url: "/releases/2026.10-prod/promotions/4/approve", principal: "alice", status: http.StatusForbidden},
+		"not pending":    {url: "/releases/2026.10-prod/promotions/3/approve", principal: "bob", status: http.StatusConflict},
+		"other release":  {url: "/releases/2026.09-prod/promotions/4/approve", principal: "bob", status: http.StatusNotFound},
+		"missing":        {url: "/releases/2026.10-prod/promotions/42/approve", principal: "bob", status: http.StatusNotFound},
+		"no principal":   {url: "/releases/2026.10-prod/promotions/4/approve", status: http.StatusUnauthorized},
+		"closed storage": {url: "/releases/2026.10-prod/promotions/4/approve", principal: "bob", status: http.StatusInternalServerError, closed: true},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			db := newPromotionDB()
+			db.closed = tc.closed
+			srv := httptest.NewServer(New(db))
+			defer srv.Close()
+
+			req, err := http.NewRequest(http.MethodPost, srv.URL+tc.url, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tc.principal != "" {
+				req.Header.Set(principalHeader, tc.principal)
+			}
+
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tc.status {
+				t.Fatalf("expected status: %v, got: %v", tc.status, resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestProtectedEnvironments(t *testing.T) {
+	db := newPromotionDB()
+	srv := httptest.NewServer(New(db, WithProtectedEnvironments("staging")))
+	defer srv.Close()
+
+	req, err := http.NewRequest(http.MethodPost, srv.URL+"/releases/2026.09-prod/promote", bytes.NewBufferString(`{}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set(principalHeader, "alice")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("expected status: %v, got: %v", http.StatusAccepted, resp.StatusCode)
+	}
+}
+
+func TestPromotions(t *testing.T) {
+	db := newPromotionDB()
+	srv := httptest.NewServer(New(db))
+	defer srv.Close()
+
+	tt := map[string]struct {
+		url    string
+		status int
+		count  int
+		closed bool
+	}{
+		"promotions":     {url: "/releases/2026.10-prod/promotions", status: http.StatusOK, count: 3},
+		"missing":        {url: "/releases/nope/promotions", status: http.StatusNotFound},
+		"closed storage": {url: "/releases/2026.10-prod/promotions", status: http.StatusInternalServerError, closed: true},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			db.closed = tc.closed
+			defer func() { db.closed = false }()
+
+			resp, err := http.Get(srv.URL + tc.url)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tc.status {
+				t.Fatalf("expected status: %v, got: %v", tc.status, resp.StatusCode)
+			}
+
+			var body promotionsResponse
+			if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+				t.Fatal(err)
+			}
+			if len(body.Promotions) != tc.count {
+				t.Fatalf("expected %v promotions, got: %v", tc.count, len(body.Promotions))
+			}
+		})
+	}
+}
@@ -0,0 +1,234 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/Glorforidor/conmansys/confservice/storage"
+	"github.com/Glorforidor/conmansys/resolve"
+	"github.com/gorilla/mux"
+)
+
+type releaseResponse struct {
+	Release         *storage.Release      `json:"release"`
+	Error           *string               `json:"error"`
+	Fields          []*storage.FieldError `json:"fields,omitempty"`
+	ModuleConflicts []*resolve.Conflict   `json:"module_conflicts,omitempty"`
+}
+
+type releasesResponse struct {
+	Releases []*storage.Release `json:"releases"`
+	Error    *string            `json:"error"`
+}
+
+// maskRelease returns a copy of the release with the secret items masked like
+// maskItem. The given release is never modified.
+func (h handler) maskRelease(r *http.Request, release *storage.Release) *storage.Release {
+	if release == nil || h.reveal(r) {
+		return release
+	}
+
+	masked := *release
+	masked.Closure = make([]*storage.ReleaseModule, len(release.Closure))
+	for i, m := range release.Closure {
+		c := *m
+		c.Items = h.maskItems(r, m.Items)
+		masked.Closure[i] = &c
+	}
+
+	return &masked
+}
+
+// freeze resolves the closure of the modules of the release and sets it to the
+// modules and items as they are now, read from one snapshot of the storage so
+// that the closure is one which existed. It returns the module conflicts if the
+// modules can not be part of the same insfile and the fields which are wrong if
+// a module does not exist.
+func (h handler) freeze(ctx context.Context, release *storage.Release) ([]*resolve.Conflict, []*storage.FieldError, error) {
+	snap, err := h.storage.GetSnapshot(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	byID := make(map[int64]*storage.Module, len(snap.Modules))
+	for _, m := range snap.Modules {
+		byID[m.ID] = m
+	}
+
+	var fields []*storage.FieldError
+	for _, id := range release.Modules {
+		if byID[id] == nil {
+			fields = append(fields, &storage.FieldError{
+				Field:   "modules",
+				Message: fmt.Sprintf("module %v does not exist", id),
+			})
+		}
+	}
+	if len(fields) > 0 {
+		return nil, fields, nil
+	}
+
+	res := resolve.Resolve(snap.Dependencies, release.Modules, release.Optional)
+	if len(res.Conflicts) > 0 {
+		return res.Conflicts, nil, nil
+	}
+
+	itemByID := make(map[int64]*storage.Item, len(snap.Items))
+	for _, i := range snap.Items {
+		itemByID[i.ID] = i
+	}
+
+	moduleItems := make(map[int64][]*storage.Item)
+	for _, im := range snap.ItemModules {
+		if i := itemByID[im.ItemID]; i != nil {
+			moduleItems[im.ModuleID] = append(moduleItems[im.ModuleID], i)
+		}
+	}
+
+	release.Closure = []*storage.ReleaseModule{}
+	for _, n := range res.Modules {
+		rm := &storage.ReleaseModule{
+			ID: n.ID, Depth: n.Depth, From: n.From, Kind: n.Kind,
+			Items: moduleItems[n.ID],
+		}
+		if m := byID[n.ID]; m != nil {
+			rm.Value = m.Value
+			rm.Version = m.Version
+		}
+		if rm.Items == nil {
+			rm.Items = []*storage.Item{}
+		}
+		// sort to freeze the items in a stable order
+		sort.Slice(rm.Items, func(a, b int) bool { return rm.Items[a].ID < rm.Items[b].ID })
+
+		release.Closure = append(release.Closure, rm)
+	}
+
+	return nil, nil, nil
+}
+
+// createRelease freezes the modules in the request body and their resolved
+// closure into a named release. A name which is taken or modules which
+// conflict give a conflict status. It returns the response as an empty
+// interface and a http status.
+func (h handler) createRelease(r *http.Request) (data interface{}, status int) {
+	var resp releaseResponse
+	var release storage.Release
+	var errMsg string
+
+	err := json.NewDecoder(r.Body).Decode(&release)
+	if err != nil {
+		errMsg = errWrongFormat.Error()
+		resp.Error = &errMsg
+		return resp, http.StatusBadRequest
+	}
+
+	if fields := release.Check(); len(fields) > 0 {
+		errMsg = errInvalid.Error()
+		resp.Error = &errMsg
+		resp.Fields = fields
+		return resp, http.StatusBadRequest
+	}
+
+	conflicts, fields, err := h.freeze(r.Context(), &release)
+	if err != nil {
+		logError(r, err)
+		errMsg = errInternal.Error()
+		resp.Error = &errMsg
+		return resp, http.StatusInternalServerError
+	}
+
+	if len(fields) > 0 {
+		errMsg = errInvalid.Error()
+		resp.Error = &errMsg
+		resp.Fields = fields
+		return resp, http.StatusBadRequest
+	}
+
+	if len(conflicts) > 0 {
+		errMsg = "the modules of the release conflict"
+		resp.Error = &errMsg
+		resp.ModuleConflicts = conflicts
+		return resp, http.StatusConflict
+	}
+
+	err = h.storage.CreateRelease(r.Context(), &release)
+	if err == storage.ErrExists {
+		errMsg = fmt.Sprintf("release %q %v", release.Name, err)
+		resp.Error = &errMsg
+		return resp, http.StatusConflict
+	}
+	if err == storage.ErrNoKeyring {
+		errMsg = err.Error()
+		resp.Error = &errMsg
+		return resp, http.StatusBadRequest
+	}
+	if err != nil {
+		logError(r, err)
+		errMsg = errInternal.Error()
+		resp.Error = &errMsg
+		return resp, http.StatusInternalServerError
+	}
+
+	resp.Release = h.maskRelease(r, &release)
+	return resp, http.StatusCreated
+}
+
+// release retrieves the release with the name in the path and packs it into a
+// response. It returns the response as an empty interface and a http status.
+func (h handler) release(r *http.Request) (data interface{}, status int) {
+	params := mux.Vars(r)
+	name := strings.TrimSpace(params["name"])
+	var resp releaseResponse
+	var errMsg string
+
+	// routing should prevent this, but might as well guard it
+	if name == "" {
+		errMsg = errMissingValue.Error()
+		resp.Error = &errMsg
+		return resp, http.StatusBadRequest
+	}
+
+	release, err := h.storage.GetRelease(r.Context(), name)
+	if err != nil {
+		logError(r, err)
+		errMsg = errInternal.Error()
+		resp.Error = &errMsg
+		return resp, http.StatusInternalServerError
+	}
+
+	if release == nil {
+		errMsg = errNotFound.Error()
+		resp.Error = &errMsg
+		return resp, http.StatusNotFound
+	}
+
+	resp.Release = h.maskRelease(r, release)
+	return resp, http.StatusOK
+}
+
+// releases retrieves every release and packs them into a response. It returns
+// the response as an empty interface and a http status.
+func (h handler) releases(r *http.Request) (data interface{}, status int) {
+	var resp releasesResponse
+	// ensure that there is an empty slice
+	resp.Releases = []*storage.Release{}
+
+	releases, err := h.storage.GetReleases(r.Context())
+	if err != nil {
+		errMsg := errInternal.Error()
+		resp.Error = &errMsg
+		logError(r, err)
+		return resp, http.StatusInternalServerError
+	}
+
+	for _, release := range releases {
+		resp.Releases = append(resp.Releases, h.maskRelease(r, release))
+	}
+
+	return resp, http.StatusOK
+}
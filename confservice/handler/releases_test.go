@@ -0,0 +1,202 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Glorforidor/conmansys/confservice/policy"
+	"github.com/Glorforidor/conmansys/confservice/storage"
+)
+
+// newReleaseDB holds the dependencies 1 -> 2 (required), 2 -> 3 (optional) and
+// 4 conflicts with 2. Module 1 has item 1 and the secret item 3, module 2 has
+// item 2 and module 3 has item 4.
+func newReleaseDB() *dbmock {
+	return &dbmock{
+		items: []*storage.Item{
+			{ID: 1, Value: "tax", Version: "1.0.0"},
+			{ID: 2, Value: "payment", Version: "1.0.0"},
+			{ID: 3, Value: "hunter2", Secret: true},
+			{ID: 4, Value: "invoice", Version: "1.0.0"},
+		},
+		modules: []*storage.Module{
+			{ID: 1, Value: "A", Version: "0.0.1"},
+			{ID: 2, Value: "B", Version: "0.0.1"},
+			{ID: 3, Value: "C", Version: "0.0.1"},
+			{ID: 4, Value: "D", Version: "0.0.1"},
+		},
+		itemModules: []*storage.ItemModule{
+			{ID: 1, ItemID: 1, ModuleID: 1},
+			{ID: 2, ItemID: 3, ModuleID: 1},
+			{ID: 3, ItemID: 2, ModuleID: 2},
+			{ID: 4, ItemID: 4, ModuleID: 3},
+		},
+		dependencies: []*storage.ModuleDependency{
+			{Dependent: 1, Dependee: 2, Kind: storage.Required},
+			{Dependent: 2, Dependee: 3, Kind: storage.Optional},
+			{Dependent: 4, Dependee: 2, Kind: storage.Conflicts},
+		},
+		releases: []*storage.Release{
+			{Name: "2026.09-prod", Modules: []int64{1}, Closure: []*storage.ReleaseModule{}},
+		},
+	}
+}
+
+func TestCreateRelease(t *testing.T) {
+	tt := map[string]struct {
+		body    string
+		status  int
+		closure []int64
+		items   int
+		closed  bool
+	}{
+		"release": {
+			body:   `{"name": "2026.10-prod", "modules": [1]}`,
+			status: http.StatusCreated, closure: []int64{1, 2}, items: 3,
+		},
+		"optional": {
+			body:   `{"name": "2026.10-prod", "modules": [1], "optional": true}`,
+			status: http.StatusCreated, closure: []int64{1, 2, 3}, items: 4,
+		},
+		"name taken":      {body: `{"name": "2026.09-prod", "modules": [1]}`, status: http.StatusConflict},
+		"conflict":        {body: `{"name": "bad", "modules": [1, 4]}`, status: http.StatusConflict},
+		"missing name":    {body: `{"modules": [1]}`, status: http.StatusBadRequest},
+		"invalid name":    {body: `{"name": "2026/10", "modules": [1]}`, status: http.StatusBadRequest},
+		"missing modules": {body: `{"name": "2026.10-prod"}`, status: http.StatusBadRequest},
+		"unknown module":  {body: `{"name": "2026.10-prod", "modules": [42]}`, status: http.StatusBadRequest},
+		"wrong format":    {body: `[1]`, status: http.StatusBadRequest},
+		"closed storage": {
+			body: `{"name": "2026.10-prod", "modules": [1]}`, status: http.StatusInternalServerError, closed: true,
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			db := newReleaseDB()
+			db.closed = tc.closed
+			srv := httptest.NewServer(New(db))
+			defer srv.Close()
+
+			resp, err := http.Post(srv.URL+"/releases", "application/json", bytes.NewBufferString(tc.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tc.status {
+				t.Fatalf("expected status: %v, got: %v", tc.status, resp.StatusCode)
+			}
+
+			var body releaseResponse
+			if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+				t.Fatal(err)
+			}
+
+			if tc.status != http.StatusCreated {
+				if body.Error == nil {
+					t.Fatal("expected an error, got: nil")
+				}
+				return
+			}
+
+			var closure []int64
+			items := 0
+			for _, m := range body.Release.Closure {
+				closure = append(closure, m.ID)
+				for _, i := range m.Items {
+					items++
+					if i.Secret && i.Value != policy.Mask {
+						t.Errorf("expected secret item to be masked, got: %v", i.Value)
+					}
+				}
+			}
+			if fmt.Sprint(closure) != fmt.Sprint(tc.closure) {
+				t.Fatalf("expected closure: %v, got: %v", tc.closure, closure)
+			}
+			if items != tc.items {
+				t.Fatalf("expected %v items, got: %v", tc.items, items)
+			}
+		})
+	}
+}
+
+func TestReleaseFrozen(t *testing.T) {
+	db := newReleaseDB()
+	srv := httptest.NewServer(New(db))
+	defer srv.Close()
+
+	resp, err := http.Post(srv.URL+"/releases", "application/json",
+		bytes.NewBufferString(`{"name": "2026.10-prod", "modules": [1]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected status: %v, got: %v", http.StatusCreated, resp.StatusCode)
+	}
+
+	// the live items and graph change after the release
+	db.items[1].Version = "2.0.0"
+	db.dependencies = append(db.dependencies, &storage.ModuleDependency{Dependent: 1, Dependee: 3})
+
+	resp, err = http.Get(srv.URL + "/releases/2026.10-prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status: %v, got: %v", http.StatusOK, resp.StatusCode)
+	}
+
+	var body releaseResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(body.Release.Closure) != 2 {
+		t.Fatalf("expected the closure of 2 modules, got: %v", len(body.Release.Closure))
+	}
+	if v := body.Release.Closure[1].Items[0].Version; v != "1.0.0" {
+		t.Fatalf("expected the frozen version 1.0.0, got: %v", v)
+	}
+}
+
+func TestRelease(t *testing.T) {
+	db := newReleaseDB()
+	srv := httptest.NewServer(New(db))
+	defer srv.Close()
+
+	tt := map[string]struct {
+		url    string
+		status int
+		closed bool
+	}{
+		"release":        {url: "/releases/2026.09-prod", status: http.StatusOK},
+		"missing":        {url: "/releases/nope", status: http.StatusNotFound},
+		"list":           {url: "/releases", status: http.StatusOK},
+		"closed storage": {url: "/releases/2026.09-prod", status: http.StatusInternalServerError, closed: true},
+		"closed list":    {url: "/releases", status: http.StatusInternalServerError, closed: true},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			db.closed = tc.closed
+			defer func() { db.closed = false }()
+
+			resp, err := http.Get(srv.URL + tc.url)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tc.status {
+				t.Fatalf("expected status: %v, got: %v", tc.status, resp.StatusCode)
+			}
+		})
+	}
+}
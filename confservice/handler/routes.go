@@ -3,34 +3,73 @@ package handler
 import (
 	"encoding/json"
 	"errors"
-	"log"
+	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
 
+	"github.com/Glorforidor/conmansys/confservice/policy"
 	"github.com/Glorforidor/conmansys/confservice/storage"
+	"github.com/Glorforidor/conmansys/health"
+	"github.com/Glorforidor/conmansys/logging"
+	"github.com/Glorforidor/conmansys/metrics"
 	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gorilla/mux/otelmux"
 )
 
 type handler struct {
-	storage storage.Service
+	storage     storage.Service
+	revealToken string
+	protected   map[string]bool
+	checks      []health.Check
 }
 
+// Option configures optional behaviour of the handler.
+type Option func(*handler)
+
 // New registers multiple endpoints, assoiciate the storage.Service to the
 // handler for data creation and retrieval and returns the handler.
-func New(service storage.Service) http.Handler {
+func New(service storage.Service, opts ...Option) http.Handler {
 	r := mux.NewRouter()
 
-	h := handler{service}
+	h := handler{storage: service}
+	WithProtectedEnvironments(defaultProtected...)(&h)
+	for _, opt := range opts {
+		opt(&h)
+	}
+
+	doc, err := loadSpec()
+	if err != nil {
+		panic(fmt.Sprintf("invalid OpenAPI document: %v", err))
+	}
+	r.Use(otelmux.Middleware("confservice"), logging.Middleware, metrics.Instrument, validateRequests(doc))
 
-	r.HandleFunc("/health", health)
+	r.HandleFunc("/health", health.Livez)
+	r.HandleFunc("/livez", health.Livez).Methods(http.MethodGet)
+	r.HandleFunc("/readyz", health.Readyz(h.checks...)).Methods(http.MethodGet)
+	r.Handle("/metrics", promhttp.Handler()).Methods(http.MethodGet)
+	r.HandleFunc("/openapi.json", openAPI(doc)).Methods(http.MethodGet)
+	r.Handle("/graphql", h.graphqlHandler()).Methods(http.MethodPost)
 	r.HandleFunc("/items", responseJSON(h.items)).Methods(http.MethodGet)
 	r.HandleFunc("/items/{id:[0-9]+}", responseJSON(h.item)).Methods(http.MethodGet)
 	r.HandleFunc("/items", responseJSON(h.createItem)).Methods(http.MethodPost)
+	r.HandleFunc("/items/{id:[0-9]+}", responseJSON(h.updateItem)).Methods(http.MethodPut)
+	r.HandleFunc("/items/{id:[0-9]+}/labels", responseJSON(h.setItemLabels)).Methods(http.MethodPut)
 	r.HandleFunc("/items/{id:[0-9]+}", responseJSON(h.deleteItem)).Methods(http.MethodDelete)
+	r.HandleFunc("/itemtypes", responseJSON(h.itemTypes)).Methods(http.MethodGet)
+	r.HandleFunc("/itemtypes/{name}", responseJSON(h.itemType)).Methods(http.MethodGet)
+	r.HandleFunc("/itemtypes", responseJSON(h.createItemType)).Methods(http.MethodPost)
+	r.HandleFunc("/itemtypes/{name}", responseJSON(h.updateItemType)).Methods(http.MethodPut)
+	r.HandleFunc("/itemtypes/{name}", responseJSON(h.deleteItemType)).Methods(http.MethodDelete)
 	r.HandleFunc("/modules", responseJSON(h.modules)).Methods(http.MethodGet)
 	r.HandleFunc("/modules/{id:[0-9]+}", responseJSON(h.module)).Methods(http.MethodGet)
 	r.HandleFunc("/modules", responseJSON(h.createModule)).Methods(http.MethodPost)
+	r.HandleFunc("/modules/{id:[0-9]+}", responseJSON(h.updateModule)).Methods(http.MethodPut)
+	r.HandleFunc("/modules/{id:[0-9]+}/graph", responseJSON(h.graph)).Methods(http.MethodGet)
+	r.HandleFunc("/modules/{id:[0-9]+}/impact", responseJSON(h.moduleImpact)).Methods(http.MethodGet)
+	r.HandleFunc("/modules/{id:[0-9]+}/labels", responseJSON(h.setModuleLabels)).Methods(http.MethodPut)
+	r.HandleFunc("/modules/{id:[0-9]+}/clone", responseJSON(h.cloneModule)).Methods(http.MethodPost)
 	r.HandleFunc("/modules/{id:[0-9]+}", responseJSON(h.deleteModule)).Methods(http.MethodDelete)
 	r.HandleFunc("/itemmodules", responseJSON(h.itemModules)).Methods(http.MethodGet)
 	r.HandleFunc("/itemmodules/{id:[0-9]+}", responseJSON(h.itemModule)).Methods(http.MethodGet)
@@ -58,14 +97,20 @@ func New(service storage.Service) http.Handler {
 		"/moduledependencies/dependee/{id:[0-9]+}",
 		responseJSON(h.deleteModuleDependencyByDependeeID),
 	).Methods(http.MethodDelete)
+	r.HandleFunc("/import/csv", responseJSON(h.importCSV)).Methods(http.MethodPost)
+	r.HandleFunc("/releases", responseJSON(h.releases)).Methods(http.MethodGet)
+	r.HandleFunc("/releases/{name}", responseJSON(h.release)).Methods(http.MethodGet)
+	r.HandleFunc("/releases", responseJSON(h.createRelease)).Methods(http.MethodPost)
+	r.HandleFunc("/releases/{name}/promote", responseJSON(h.promoteRelease)).Methods(http.MethodPost)
+	r.HandleFunc("/releases/{name}/promotions", responseJSON(h.promotions)).Methods(http.MethodGet)
+	r.HandleFunc(
+		"/releases/{name}/promotions/{id:[0-9]+}/approve",
+		responseJSON(h.approvePromotion),
+	).Methods(http.MethodPost)
 
 	return r
 }
 
-func health(w http.ResponseWriter, r *http.Request) {
-	w.WriteHeader(http.StatusOK)
-}
-
 var (
 	contentType = map[string]string{
 		"json": "application/json",
@@ -74,9 +119,17 @@ var (
 	errWrongFormat  = errors.New("wrong input format")
 	errMissingValue = errors.New("missing value")
 	errNaN          = errors.New("not a number")
+	errNotFound     = errors.New("not found")
+	errInvalid      = errors.New("invalid value")
 	errInternal     = errors.New("Ups something went wrong")
 )
 
+// logError logs the error which failed the request, together with the request
+// id of the request.
+func logError(r *http.Request, err error) {
+	logging.FromContext(r.Context()).Error("request failed", "error", err)
+}
+
 func responseJSON(h func(*http.Request) (interface{}, int)) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		data, status := h(r)
@@ -88,8 +141,9 @@ func responseJSON(h func(*http.Request) (interface{}, int)) http.HandlerFunc {
 }
 
 type itemResponse struct {
-	Item  *storage.Item `json:"item"`
-	Error *string       `json:"error"`
+	Item   *storage.Item         `json:"item"`
+	Error  *string               `json:"error"`
+	Fields []*storage.FieldError `json:"fields,omitempty"`
 }
 
 // item retrieves a specifc item from storage packs it into a response and
@@ -115,15 +169,15 @@ func (h handler) item(r *http.Request) (data interface{}, status int) {
 		return resp, http.StatusBadRequest
 	}
 
-	item, err := h.storage.GetItem(i)
+	item, err := h.storage.GetItem(r.Context(), i)
 	if err != nil {
 		errMsg = errInternal.Error()
 		resp.Error = &errMsg
-		log.Println(err)
+		logError(r, err)
 		return resp, http.StatusInternalServerError
 	}
 
-	resp.Item = item
+	resp.Item = h.maskItem(r, item)
 	return resp, http.StatusOK
 }
 
@@ -133,21 +187,29 @@ type itemsResponse struct {
 }
 
 // items retrieves items from storage packs it into a response and returns it as
-// an empty interface with http status.
+// an empty interface with http status. The items can be filtered with a label
+// selector in the selector query parameter.
 func (h handler) items(r *http.Request) (data interface{}, status int) {
 	var resp itemsResponse
 	// ensure that there is an empty slice
 	resp.Items = []*storage.Item{}
 
-	i, err := h.storage.GetItems()
+	f, err := parseFilter(r)
+	if err != nil {
+		errMsg := err.Error()
+		resp.Error = &errMsg
+		return resp, http.StatusBadRequest
+	}
+
+	i, err := h.storage.GetItems(r.Context())
 	if err != nil {
 		errMsg := errInternal.Error()
 		resp.Error = &errMsg
-		log.Println(err)
+		logError(r, err)
 		return resp, http.StatusInternalServerError
 	}
 
-	resp.Items = i
+	resp.Items = h.maskItems(r, filterItems(f, i))
 	return resp, http.StatusOK
 }
 
@@ -170,9 +232,31 @@ func (h handler) createItem(r *http.Request) (data interface{}, status int) {
 		return resp, http.StatusBadRequest
 	}
 
-	i, err := h.storage.CreateItem(item.Value, item.Type, item.Version)
+	fields, err := policy.ValidateItem(r.Context(), h.storage, &item)
+	if err != nil {
+		logError(r, err)
+		errMsg = errInternal.Error()
+		resp.Error = &errMsg
+		return resp, http.StatusInternalServerError
+	}
+
+	fields = append(fields, storage.CheckLabels(item.Labels)...)
+	fields = append(fields, item.Metadata.Check()...)
+	if len(fields) > 0 {
+		errMsg = errInvalid.Error()
+		resp.Error = &errMsg
+		resp.Fields = fields
+		return resp, http.StatusBadRequest
+	}
+
+	i, err := h.storage.CreateItem(r.Context(), &item)
+	if err == storage.ErrNoKeyring {
+		errMsg = err.Error()
+		resp.Error = &errMsg
+		return resp, http.StatusBadRequest
+	}
 	if err != nil {
-		log.Println(err)
+		logError(r, err)
 		errMsg = errInternal.Error()
 		resp.Error = &errMsg
 		return resp, http.StatusInternalServerError
@@ -180,13 +264,107 @@ func (h handler) createItem(r *http.Request) (data interface{}, status int) {
 
 	// TODO: perhaps a better response besides the item?
 	item.ID = i
-	resp.Item = &item
+	resp.Item = h.maskItem(r, &item)
 	return resp, http.StatusCreated
 }
 
+// updateItem replaces the values of an item in the storage and returns the
+// updated item.
+func (h handler) updateItem(r *http.Request) (data interface{}, status int) {
+	params := mux.Vars(r)
+	id := strings.TrimSpace(params["id"])
+	var resp itemResponse
+	var item storage.Item
+	var errMsg string
+
+	// routing should prevent this, but might as well guard it
+	if id == "" {
+		errMsg = errMissingValue.Error()
+		resp.Error = &errMsg
+		return resp, http.StatusBadRequest
+	}
+
+	i, err := strconv.ParseInt(id, 10, 64)
+	// routing should prevent this, but might as well guard it
+	if err != nil {
+		errMsg = errNaN.Error()
+		resp.Error = &errMsg
+		return resp, http.StatusBadRequest
+	}
+
+	err = json.NewDecoder(r.Body).Decode(&item)
+	if err != nil {
+		errMsg = errWrongFormat.Error()
+		resp.Error = &errMsg
+		return resp, http.StatusBadRequest
+	}
+
+	if item.Value == "" || item.Type == "" || item.Version == "" {
+		errMsg = "missing values"
+		resp.Error = &errMsg
+		return resp, http.StatusBadRequest
+	}
+
+	if err := policy.Unmask(r.Context(), h.storage, i, &item); err != nil {
+		logError(r, err)
+		errMsg = errInternal.Error()
+		resp.Error = &errMsg
+		return resp, http.StatusInternalServerError
+	}
+
+	fields, err := policy.ValidateItem(r.Context(), h.storage, &item)
+	if err != nil {
+		logError(r, err)
+		errMsg = errInternal.Error()
+		resp.Error = &errMsg
+		return resp, http.StatusInternalServerError
+	}
+
+	fields = append(fields, item.Metadata.Check()...)
+	if len(fields) > 0 {
+		errMsg = errInvalid.Error()
+		resp.Error = &errMsg
+		resp.Fields = fields
+		return resp, http.StatusBadRequest
+	}
+
+	item.ID = i
+	row, err := h.storage.UpdateItem(r.Context(), &item)
+	if err == storage.ErrNoKeyring {
+		errMsg = err.Error()
+		resp.Error = &errMsg
+		return resp, http.StatusBadRequest
+	}
+	if err != nil {
+		logError(r, err)
+		errMsg = errInternal.Error()
+		resp.Error = &errMsg
+		return resp, http.StatusInternalServerError
+	}
+
+	if row == 0 {
+		errMsg = errNotFound.Error()
+		resp.Error = &errMsg
+		return resp, http.StatusNotFound
+	}
+
+	// the labels and timestamps are not part of the update
+	stored, err := h.storage.GetItem(r.Context(), i)
+	if err != nil {
+		logError(r, err)
+		errMsg = errInternal.Error()
+		resp.Error = &errMsg
+		return resp, http.StatusInternalServerError
+	}
+
+	resp.Item = h.maskItem(r, stored)
+	return resp, http.StatusOK
+}
+
 type deleteResponse struct {
-	RowsAffected int64   `json:"rows_affected"`
-	Error        *string `json:"error"`
+	RowsAffected int64          `json:"rows_affected"`
+	Error        *string        `json:"error"`
+	Impact       *policy.Impact `json:"impact,omitempty"`
 }
 
 // deleteItem deletes the item in storage and packs the information about the
@@ -213,9 +391,9 @@ func (h handler) deleteItem(r *http.Request) (data interface{}, status int) {
 		return resp, http.StatusBadRequest
 	}
 
-	row, err := h.storage.DeleteItem(i)
+	row, err := h.storage.DeleteItem(r.Context(), i)
 	if err != nil {
-		log.Println(err)
+		logError(r, err)
 		errMsg = errInternal.Error()
 		resp.Error = &errMsg
 		return resp, http.StatusInternalServerError
@@ -226,8 +404,9 @@ func (h handler) deleteItem(r *http.Request) (data interface{}, status int) {
 }
 
 type moduleResponse struct {
-	Module *storage.Module `json:"module"`
-	Error  *string         `json:"error"`
+	Module *storage.Module       `json:"module"`
+	Error  *string               `json:"error"`
+	Fields []*storage.FieldError `json:"fields,omitempty"`
 }
 
 // module retrieves a module from storage. It packs the information about the
@@ -251,9 +430,9 @@ func (h handler) module(r *http.Request) (data interface{}, status int) {
 		return resp, http.StatusBadRequest
 	}
 
-	module, err := h.storage.GetModule(i)
+	module, err := h.storage.GetModule(r.Context(), i)
 	if err != nil {
-		log.Println(err)
+		logError(r, err)
 		errMsg = errInternal.Error()
 		resp.Error = &errMsg
 		return resp, http.StatusInternalServerError
@@ -270,18 +449,27 @@ type modulesResponse struct {
 
 // modules retrieve all modules from storage and packs the information about the
 // retrieval into a response. It returns the response as an empty interface and
-// a http status.
+// a http status. The modules can be filtered with a label selector in the
+// selector query parameter.
 func (h handler) modules(r *http.Request) (data interface{}, status int) {
 	var resp modulesResponse
-	modules, err := h.storage.GetModules()
+
+	f, err := parseFilter(r)
 	if err != nil {
-		log.Println(err)
+		errMsg := err.Error()
+		resp.Error = &errMsg
+		return resp, http.StatusBadRequest
+	}
+
+	modules, err := h.storage.GetModules(r.Context())
+	if err != nil {
+		logError(r, err)
 		errMsg := errInternal.Error()
 		resp.Error = &errMsg
 		return resp, http.StatusInternalServerError
 	}
 
-	resp.Modules = modules
+	resp.Modules = filterModules(f, modules)
 	return resp, http.StatusOK
 }
 
@@ -304,9 +492,17 @@ func (h handler) createModule(r *http.Request) (data interface{}, status int) {
 		return resp, http.StatusBadRequest
 	}
 
-	i, err := h.storage.CreateModule(module.Value, module.Version)
+	fields := append(storage.CheckLabels(module.Labels), module.Metadata.Check()...)
+	if len(fields) > 0 {
+		errMsg = errInvalid.Error()
+		resp.Error = &errMsg
+		resp.Fields = fields
+		return resp, http.StatusBadRequest
+	}
+
+	i, err := h.storage.CreateModule(r.Context(), &module)
 	if err != nil {
-		log.Println(err)
+		logError(r, err)
 		errMsg = errInternal.Error()
 		resp.Error = &errMsg
 		return resp, http.StatusInternalServerError
@@ -317,14 +513,17 @@ func (h handler) createModule(r *http.Request) (data interface{}, status int) {
 	return resp, http.StatusCreated
 }
 
-// deleteModule deletes the item from storage and packs the deletion information
-// into a response. It returns the response as an empty interface and a http
-// status.
-func (h handler) deleteModule(r *http.Request) (data interface{}, status int) {
+// updateModule replaces the module with the id in the path with the module in
+// the request body. The labels of the module are left untouched. It returns the
+// response as an empty interface and a http status.
+func (h handler) updateModule(r *http.Request) (data interface{}, status int) {
 	params := mux.Vars(r)
-	var resp deleteResponse
-	var errMsg string
 	id := strings.TrimSpace(params["id"])
+	var resp moduleResponse
+	var module storage.Module
+	var errMsg string
+
+	// routing should prevent this, but might as well guard it
 	if id == "" {
 		errMsg = errMissingValue.Error()
 		resp.Error = &errMsg
@@ -332,21 +531,58 @@ func (h handler) deleteModule(r *http.Request) (data interface{}, status int) {
 	}
 
 	i, err := strconv.ParseInt(id, 10, 64)
+	// routing should prevent this, but might as well guard it
 	if err != nil {
 		errMsg = errNaN.Error()
 		resp.Error = &errMsg
 		return resp, http.StatusBadRequest
 	}
 
-	row, err := h.storage.DeleteModule(i)
+	err = json.NewDecoder(r.Body).Decode(&module)
 	if err != nil {
-		log.Println(err)
+		errMsg = errWrongFormat.Error()
+		resp.Error = &errMsg
+		return resp, http.StatusBadRequest
+	}
+
+	if module.Value == "" || module.Version == "" {
+		errMsg = errMissingValue.Error()
+		resp.Error = &errMsg
+		return resp, http.StatusBadRequest
+	}
+
+	if fields := module.Metadata.Check(); len(fields) > 0 {
+		errMsg = errInvalid.Error()
+		resp.Error = &errMsg
+		resp.Fields = fields
+		return resp, http.StatusBadRequest
+	}
+
+	module.ID = i
+	row, err := h.storage.UpdateModule(r.Context(), &module)
+	if err != nil {
+		logError(r, err)
 		errMsg = errInternal.Error()
 		resp.Error = &errMsg
 		return resp, http.StatusInternalServerError
 	}
 
-	resp.RowsAffected = row
+	if row == 0 {
+		errMsg = errNotFound.Error()
+		resp.Error = &errMsg
+		return resp, http.StatusNotFound
+	}
+
+	// the labels and timestamps are not part of the update
+	stored, err := h.storage.GetModule(r.Context(), i)
+	if err != nil {
+		logError(r, err)
+		errMsg = errInternal.Error()
+		resp.Error = &errMsg
+		return resp, http.StatusInternalServerError
+	}
+
+	resp.Module = stored
 	return resp, http.StatusOK
 }
 
@@ -376,9 +612,9 @@ func (h handler) itemModule(r *http.Request) (data interface{}, status int) {
 		return resp, http.StatusBadRequest
 	}
 
-	im, err := h.storage.GetItemModule(i)
+	im, err := h.storage.GetItemModule(r.Context(), i)
 	if err != nil {
-		log.Println(err)
+		logError(r, err)
 		errMsg = errInternal.Error()
 		resp.Error = &errMsg
 		return resp, http.StatusInternalServerError
@@ -398,9 +634,9 @@ type itemModulesResponse struct {
 // and a http status.
 func (h handler) itemModules(r *http.Request) (data interface{}, status int) {
 	var resp itemModulesResponse
-	ims, err := h.storage.GetItemModules()
+	ims, err := h.storage.GetItemModules(r.Context())
 	if err != nil {
-		log.Println(err)
+		logError(r, err)
 		errMsg := errInternal.Error()
 		resp.Error = &errMsg
 		return resp, http.StatusInternalServerError
@@ -430,9 +666,9 @@ func (h handler) createItemModule(r *http.Request) (data interface{}, status int
 		return resp, http.StatusBadRequest
 	}
 
-	id, err := h.storage.CreateItemModule(im.ItemID, im.ModuleID)
+	id, err := h.storage.CreateItemModule(r.Context(), im.ItemID, im.ModuleID)
 	if err != nil {
-		log.Println(err)
+		logError(r, err)
 		errMsg = errInternal.Error()
 		resp.Error = &errMsg
 		return resp, http.StatusInternalServerError
@@ -464,9 +700,9 @@ func (h handler) deleteItemModule(r *http.Request) (data interface{}, status int
 		return resp, http.StatusBadRequest
 	}
 
-	row, err := h.storage.DeleteItemModule(i)
+	row, err := h.storage.DeleteItemModule(r.Context(), i)
 	if err != nil {
-		log.Println(err)
+		logError(r, err)
 		errMsg = errInternal.Error()
 		resp.Error = &errMsg
 		return resp, http.StatusInternalServerError
@@ -486,9 +722,9 @@ type moduleDependenciesResponse struct {
 // empty interface and a http status.
 func (h handler) moduleDependencies(r *http.Request) (data interface{}, status int) {
 	var resp moduleDependenciesResponse
-	moddeps, err := h.storage.GetModuleDependencies()
+	moddeps, err := h.storage.GetModuleDependencies(r.Context())
 	if err != nil {
-		log.Println(err)
+		logError(r, err)
 		errMsg := errInternal.Error()
 		resp.Error = &errMsg
 		return resp, http.StatusInternalServerError
@@ -519,9 +755,9 @@ func (h handler) moduleDependenciesByDependentID(r *http.Request) (data interfac
 		return resp, http.StatusBadRequest
 	}
 
-	moddeps, err := h.storage.GetModuleDependenciesByDependentID(i)
+	moddeps, err := h.storage.GetModuleDependenciesByDependentID(r.Context(), i)
 	if err != nil {
-		log.Println(err)
+		logError(r, err)
 		errMsg = errInternal.Error()
 		resp.Error = &errMsg
 		return resp, http.StatusInternalServerError
@@ -552,9 +788,9 @@ func (h handler) moduleDependenciesByDependeeID(r *http.Request) (data interface
 		return resp, http.StatusBadRequest
 	}
 
-	moddeps, err := h.storage.GetModuleDependenciesByDependeeID(i)
+	moddeps, err := h.storage.GetModuleDependenciesByDependeeID(r.Context(), i)
 	if err != nil {
-		log.Println(err)
+		logError(r, err)
 		errMsg = errInternal.Error()
 		resp.Error = &errMsg
 		return resp, http.StatusInternalServerError
@@ -567,6 +803,7 @@ func (h handler) moduleDependenciesByDependeeID(r *http.Request) (data interface
 type moduleDependencyResponse struct {
 	ModuleDependency *storage.ModuleDependency `json:"module_dependency"`
 	Error            *string                   `json:"error"`
+	Fields           []*storage.FieldError     `json:"fields,omitempty"`
 }
 
 // createModuleDependency inserts a new module dependency into storage and packs
@@ -589,9 +826,20 @@ func (h handler) createModuleDependency(r *http.Request) (data interface{}, stat
 		return resp, http.StatusBadRequest
 	}
 
-	err = h.storage.CreateModuleDependency(md.Dependent, md.Dependee)
+	if md.Kind == "" {
+		md.Kind = storage.Required
+	}
+
+	if fields := storage.CheckDependency(&md); len(fields) > 0 {
+		errMsg = errInvalid.Error()
+		resp.Error = &errMsg
+		resp.Fields = fields
+		return resp, http.StatusBadRequest
+	}
+
+	err = h.storage.CreateModuleDependency(r.Context(), &md)
 	if err != nil {
-		log.Println(err)
+		logError(r, err)
 		errMsg = errInternal.Error()
 		resp.Error = &errMsg
 		return resp, http.StatusInternalServerError
@@ -631,9 +879,9 @@ func (h handler) deleteModuleDependency(r *http.Request) (data interface{}, stat
 		return resp, http.StatusBadRequest
 	}
 
-	row, err := h.storage.DeleteModuleDependency(i, j)
+	row, err := h.storage.DeleteModuleDependency(r.Context(), i, j)
 	if err != nil {
-		log.Println(err)
+		logError(r, err)
 		errMsg = errInternal.Error()
 		resp.Error = &errMsg
 		return resp, http.StatusInternalServerError
@@ -663,9 +911,9 @@ func (h handler) deleteModuleDependencyByDependentID(r *http.Request) (data inte
 		return resp, http.StatusBadRequest
 	}
 
-	rows, err := h.storage.DeleteModuleDependencyByDependentID(i)
+	rows, err := h.storage.DeleteModuleDependencyByDependentID(r.Context(), i)
 	if err != nil {
-		log.Println(err)
+		logError(r, err)
 		errMsg = errInternal.Error()
 		resp.Error = &errMsg
 		return resp, http.StatusInternalServerError
@@ -695,9 +943,9 @@ func (h handler) deleteModuleDependencyByDependeeID(r *http.Request) (data inter
 		return resp, http.StatusBadRequest
 	}
 
-	rows, err := h.storage.DeleteModuleDependencyByDependeeID(i)
+	rows, err := h.storage.DeleteModuleDependencyByDependeeID(r.Context(), i)
 	if err != nil {
-		log.Println(err)
+		logError(r, err)
 		errMsg = errInternal.Error()
 		resp.Error = &errMsg
 		return resp, http.StatusInternalServerError
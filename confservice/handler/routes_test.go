@@ -2,6 +2,7 @@ package handler
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -9,103 +10,324 @@ import (
 	"net/http/httptest"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/Glorforidor/conmansys/confservice/storage"
 )
 
 type dbmock struct {
 	items        []*storage.Item
+	itemTypes    []*storage.ItemType
 	modules      []*storage.Module
 	itemModules  []*storage.ItemModule
 	dependencies []*storage.ModuleDependency
+	releases     []*storage.Release
+	promotions   []*storage.Promotion
 	closed       bool
+	keyring      bool
+	failImport   bool
+
+	// updated is the last item passed to UpdateItem.
+	updated *storage.Item
 }
 
-func (d *dbmock) GetItem(id int64) (*storage.Item, error) {
+func (d *dbmock) GetItem(_ context.Context, id int64) (*storage.Item, error) {
 	if d.closed {
 		return nil, errors.New("")
 	}
 	return d.items[id-1], nil
 }
 
-func (d *dbmock) GetItems() ([]*storage.Item, error) {
+func (d *dbmock) GetItems(_ context.Context) ([]*storage.Item, error) {
 	if d.closed {
 		return nil, errors.New("")
 	}
 	return d.items, nil
 }
 
-func (d *dbmock) CreateItem(value string, iType string, version string) (int64, error) {
+func (d *dbmock) GetItemsByIDs(_ context.Context, ids []int64) ([]*storage.Item, error) {
+	if d.closed {
+		return nil, errors.New("")
+	}
+
+	var items []*storage.Item
+	for _, i := range d.items {
+		if hasID(ids, i.ID) {
+			items = append(items, i)
+		}
+	}
+	return items, nil
+}
+
+func (d *dbmock) CreateItem(_ context.Context, item *storage.Item) (int64, error) {
+	if d.closed {
+		return 0, errors.New("")
+	}
+	if item.Secret && !d.keyring {
+		return 0, storage.ErrNoKeyring
+	}
+	return 1, nil
+}
+
+func (d *dbmock) UpdateItem(_ context.Context, item *storage.Item) (int64, error) {
+	if d.closed {
+		return 0, errors.New("")
+	}
+	if item.Secret && !d.keyring {
+		return 0, storage.ErrNoKeyring
+	}
+	d.updated = item
+	if item.ID > int64(len(d.items)) {
+		return 0, nil
+	}
+	return 1, nil
+}
+
+func (d *dbmock) SetItemLabels(_ context.Context, id int64, labels map[string]string) (int64, error) {
+	if d.closed {
+		return 0, errors.New("")
+	}
+	if id > int64(len(d.items)) {
+		return 0, nil
+	}
+	return 1, nil
+}
+
+func (d *dbmock) GetItemType(_ context.Context, name string) (*storage.ItemType, error) {
+	if d.closed {
+		return nil, errors.New("")
+	}
+	for _, t := range d.itemTypes {
+		if t.Name == name {
+			return t, nil
+		}
+	}
+	return nil, nil
+}
+
+func (d *dbmock) GetItemTypes(_ context.Context) ([]*storage.ItemType, error) {
+	if d.closed {
+		return nil, errors.New("")
+	}
+	return d.itemTypes, nil
+}
+
+func (d *dbmock) CreateItemType(_ context.Context, t *storage.ItemType) error {
+	if d.closed {
+		return errors.New("")
+	}
+	return nil
+}
+
+func (d *dbmock) UpdateItemType(_ context.Context, t *storage.ItemType) (int64, error) {
+	if d.closed {
+		return 0, errors.New("")
+	}
+	for _, it := range d.itemTypes {
+		if it.Name == t.Name {
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
+
+func (d *dbmock) DeleteItemType(_ context.Context, name string) (int64, error) {
 	if d.closed {
 		return 0, errors.New("")
 	}
 	return 1, nil
 }
 
-func (d *dbmock) DeleteItem(id int64) (int64, error) {
+func (d *dbmock) DeleteItem(_ context.Context, id int64) (int64, error) {
 	if d.closed {
 		return 0, errors.New("")
 	}
 	return 1, nil
 }
 
-func (d *dbmock) GetModule(id int64) (*storage.Module, error) {
+func (d *dbmock) GetModule(_ context.Context, id int64) (*storage.Module, error) {
 	if d.closed {
 		return nil, errors.New("")
 	}
 	return d.modules[id-1], nil
 }
 
-func (d *dbmock) GetModules() ([]*storage.Module, error) {
+func (d *dbmock) GetModules(_ context.Context) ([]*storage.Module, error) {
 	if d.closed {
 		return nil, errors.New("")
 	}
 	return d.modules, nil
 }
 
-func (d *dbmock) CreateModule(value string, version string) (int64, error) {
+func (d *dbmock) GetModulesByIDs(_ context.Context, ids []int64) ([]*storage.Module, error) {
+	if d.closed {
+		return nil, errors.New("")
+	}
+
+	var modules []*storage.Module
+	for _, m := range d.modules {
+		if hasID(ids, m.ID) {
+			modules = append(modules, m)
+		}
+	}
+	return modules, nil
+}
+
+func (d *dbmock) CreateModule(_ context.Context, module *storage.Module) (int64, error) {
+	if d.closed {
+		return 0, errors.New("")
+	}
+	return 1, nil
+}
+
+func (d *dbmock) UpdateModule(_ context.Context, module *storage.Module) (int64, error) {
+	if d.closed {
+		return 0, errors.New("")
+	}
+	if module.ID > int64(len(d.modules)) {
+		return 0, nil
+	}
+	return 1, nil
+}
+
+func (d *dbmock) SetModuleLabels(_ context.Context, id int64, labels map[string]string) (int64, error) {
+	if d.closed {
+		return 0, errors.New("")
+	}
+	if id > int64(len(d.modules)) {
+		return 0, nil
+	}
+	return 1, nil
+}
+
+func (d *dbmock) DeleteModule(_ context.Context, id int64) (int64, error) {
 	if d.closed {
 		return 0, errors.New("")
 	}
 	return 1, nil
 }
 
-func (d *dbmock) DeleteModule(id int64) (int64, error) {
+func (d *dbmock) DeleteModuleCascade(_ context.Context, id int64) (int64, error) {
 	if d.closed {
 		return 0, errors.New("")
 	}
 	return 1, nil
 }
 
-func (d *dbmock) GetItemModule(id int64) (*storage.ItemModule, error) {
+func (d *dbmock) CloneModule(_ context.Context, id int64, version string, rewire bool) (*storage.ModuleClone, error) {
+	if d.closed {
+		return nil, errors.New("")
+	}
+
+	var module *storage.Module
+	for _, m := range d.modules {
+		if m.ID == id {
+			module = m
+		}
+	}
+	if module == nil {
+		return nil, nil
+	}
+
+	m := *module
+	m.ID = int64(len(d.modules) + 1)
+	m.Version = version
+	d.modules = append(d.modules, &m)
+	clone := &storage.ModuleClone{Module: &m, From: id}
+
+	for _, im := range d.itemModules {
+		if im.ModuleID == id {
+			d.itemModules = append(d.itemModules, &storage.ItemModule{
+				ID: int64(len(d.itemModules) + 1), ItemID: im.ItemID, ModuleID: m.ID,
+			})
+			clone.Items++
+		}
+	}
+
+	for _, md := range d.dependencies {
+		if md.Dependent == id {
+			d.dependencies = append(d.dependencies, &storage.ModuleDependency{
+				Dependent: m.ID, Dependee: md.Dependee, Kind: md.Kind,
+			})
+			clone.Dependencies++
+		}
+	}
+
+	if rewire {
+		for _, md := range d.dependencies {
+			if md.Dependee == id && md.Kind != storage.Conflicts {
+				md.Dependee = m.ID
+				clone.Rewired++
+			}
+		}
+	}
+
+	return clone, nil
+}
+
+func (d *dbmock) GetItemModule(_ context.Context, id int64) (*storage.ItemModule, error) {
 	if d.closed {
 		return nil, errors.New("")
 	}
 	return d.itemModules[id-1], nil
 }
 
-func (d *dbmock) GetItemModules() ([]*storage.ItemModule, error) {
+func (d *dbmock) GetItemModules(_ context.Context) ([]*storage.ItemModule, error) {
 	if d.closed {
 		return nil, errors.New("")
 	}
 	return d.itemModules, nil
 }
 
-func (d *dbmock) CreateItemModule(itemID int64, moduleID int64) (int64, error) {
+func (d *dbmock) GetItemModulesByIDs(_ context.Context, itemIDs, moduleIDs []int64) ([]*storage.ItemModule, error) {
+	if d.closed {
+		return nil, errors.New("")
+	}
+
+	var ims []*storage.ItemModule
+	for _, im := range d.itemModules {
+		if hasID(itemIDs, im.ItemID) || hasID(moduleIDs, im.ModuleID) {
+			ims = append(ims, im)
+		}
+	}
+	return ims, nil
+}
+
+func (d *dbmock) CreateItemModule(_ context.Context, itemID int64, moduleID int64) (int64, error) {
 	if d.closed {
 		return 0, errors.New("")
 	}
 	return 1, nil
 }
 
-func (d *dbmock) DeleteItemModule(id int64) (int64, error) {
+func (d *dbmock) DeleteItemModule(_ context.Context, id int64) (int64, error) {
 	if d.closed {
 		return 0, errors.New("")
 	}
 	return 1, nil
 }
 
-func (d *dbmock) GetModuleDependencies() ([]*storage.ModuleDependency, error) {
+// Import gives what the rows create ids from 100 on.
+func (d *dbmock) Import(_ context.Context, rows []*storage.ImportRow) error {
+	if d.closed || d.failImport {
+		return errors.New("")
+	}
+	id := int64(100)
+	for _, r := range rows {
+		if r.Item.ID == 0 {
+			r.Item.ID, id = id, id+1
+		}
+		if r.Module.ID == 0 {
+			r.Module.ID, id = id, id+1
+		}
+		if r.Link {
+			r.LinkID, id = id, id+1
+		}
+	}
+	return nil
+}
+
+func (d *dbmock) GetModuleDependencies(_ context.Context) ([]*storage.ModuleDependency, error) {
 	if d.closed {
 		return nil, errors.New("")
 	}
@@ -113,7 +335,30 @@ func (d *dbmock) GetModuleDependencies() ([]*storage.ModuleDependency, error) {
 	return d.dependencies, nil
 }
 
-func (d *dbmock) GetModuleDependenciesByDependentID(dependentID int64) ([]*storage.ModuleDependency, error) {
+func (d *dbmock) GetModuleDependenciesByModuleIDs(_ context.Context, ids []int64) ([]*storage.ModuleDependency, error) {
+	if d.closed {
+		return nil, errors.New("")
+	}
+
+	var deps []*storage.ModuleDependency
+	for _, md := range d.dependencies {
+		if hasID(ids, md.Dependent) || hasID(ids, md.Dependee) {
+			deps = append(deps, md)
+		}
+	}
+	return deps, nil
+}
+
+func hasID(ids []int64, id int64) bool {
+	for _, i := range ids {
+		if i == id {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *dbmock) GetModuleDependenciesByDependentID(_ context.Context, dependentID int64) ([]*storage.ModuleDependency, error) {
 	if d.closed {
 		return nil, errors.New("")
 	}
@@ -128,7 +373,7 @@ func (d *dbmock) GetModuleDependenciesByDependentID(dependentID int64) ([]*stora
 	return deps, nil
 }
 
-func (d *dbmock) GetModuleDependenciesByDependeeID(dependeeID int64) ([]*storage.ModuleDependency, error) {
+func (d *dbmock) GetModuleDependenciesByDependeeID(_ context.Context, dependeeID int64) ([]*storage.ModuleDependency, error) {
 	if d.closed {
 		return nil, errors.New("")
 	}
@@ -143,14 +388,14 @@ func (d *dbmock) GetModuleDependenciesByDependeeID(dependeeID int64) ([]*storage
 	return deps, nil
 }
 
-func (d *dbmock) CreateModuleDependency(dependentID int64, dependeeID int64) error {
+func (d *dbmock) CreateModuleDependency(_ context.Context, md *storage.ModuleDependency) error {
 	if d.closed {
 		return errors.New("")
 	}
 	return nil
 }
 
-func (d *dbmock) DeleteModuleDependency(dependentID, dependeeID int64) (int64, error) {
+func (d *dbmock) DeleteModuleDependency(_ context.Context, dependentID, dependeeID int64) (int64, error) {
 	if d.closed {
 		return 0, errors.New("")
 	}
@@ -158,7 +403,7 @@ func (d *dbmock) DeleteModuleDependency(dependentID, dependeeID int64) (int64, e
 	return 1, nil
 }
 
-func (d *dbmock) DeleteModuleDependencyByDependentID(id int64) (int64, error) {
+func (d *dbmock) DeleteModuleDependencyByDependentID(_ context.Context, id int64) (int64, error) {
 	if d.closed {
 		return 0, errors.New("")
 	}
@@ -166,7 +411,7 @@ func (d *dbmock) DeleteModuleDependencyByDependentID(id int64) (int64, error) {
 	return 1, nil
 }
 
-func (d *dbmock) DeleteModuleDependencyByDependeeID(id int64) (int64, error) {
+func (d *dbmock) DeleteModuleDependencyByDependeeID(_ context.Context, id int64) (int64, error) {
 	if d.closed {
 		return 0, errors.New("")
 	}
@@ -174,19 +419,182 @@ func (d *dbmock) DeleteModuleDependencyByDependeeID(id int64) (int64, error) {
 	return 1, nil
 }
 
+func (d *dbmock) GetRelease(_ context.Context, name string) (*storage.Release, error) {
+	if d.closed {
+		return nil, errors.New("")
+	}
+
+	for _, r := range d.releases {
+		if r.Name == name {
+			return r, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (d *dbmock) GetSnapshot(_ context.Context) (*storage.Snapshot, error) {
+	if d.closed {
+		return nil, errors.New("")
+	}
+
+	return &storage.Snapshot{
+		Modules:      d.modules,
+		Dependencies: d.dependencies,
+		ItemModules:  d.itemModules,
+		Items:        d.items,
+	}, nil
+}
+
+func (d *dbmock) GetReleases(_ context.Context) ([]*storage.Release, error) {
+	if d.closed {
+		return nil, errors.New("")
+	}
+
+	return d.releases, nil
+}
+
+func (d *dbmock) CreateRelease(_ context.Context, release *storage.Release) error {
+	if d.closed {
+		return errors.New("")
+	}
+
+	for _, r := range d.releases {
+		if r.Name == release.Name {
+			return storage.ErrExists
+		}
+	}
+
+	// store a copy like the database does, so later changes to the items
+	// do not reach the release
+	b, err := json.Marshal(release)
+	if err != nil {
+		return err
+	}
+	var r storage.Release
+	if err := json.Unmarshal(b, &r); err != nil {
+		return err
+	}
+
+	d.releases = append(d.releases, &r)
+	return nil
+}
+
+func (d *dbmock) GetPromotion(_ context.Context, id int64) (*storage.Promotion, error) {
+	if d.closed {
+		return nil, errors.New("")
+	}
+
+	for _, p := range d.promotions {
+		if p.ID == id {
+			c := *p
+			return &c, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (d *dbmock) GetPromotions(_ context.Context, release string) ([]*storage.Promotion, error) {
+	if d.closed {
+		return nil, errors.New("")
+	}
+
+	var promotions []*storage.Promotion
+	for _, p := range d.promotions {
+		if release == "" || p.Release == release {
+			promotions = append(promotions, p)
+		}
+	}
+
+	return promotions, nil
+}
+
+func (d *dbmock) CreatePromotion(_ context.Context, promotion *storage.Promotion) (int64, error) {
+	if d.closed {
+		return 0, errors.New("")
+	}
+
+	// the rules of the database
+	if current := currentPromotion(d.promotions, promotion.Environment); current != nil && current.Release == promotion.Release {
+		return 0, storage.ErrCurrent
+	}
+	for _, p := range d.promotions {
+		if p.Release == promotion.Release && p.Environment == promotion.Environment && p.Status == storage.Pending {
+			return 0, storage.ErrExists
+		}
+	}
+
+	p := *promotion
+	p.ID = int64(len(d.promotions) + 1)
+	p.RequestedAt = time.Now()
+	if p.Status == storage.Promoted {
+		p.PromotedAt = &p.RequestedAt
+	}
+	d.promotions = append(d.promotions, &p)
+	promotion.RequestedAt = p.RequestedAt
+	promotion.PromotedAt = p.PromotedAt
+
+	return p.ID, nil
+}
+
+func (d *dbmock) ApprovePromotion(_ context.Context, promotion *storage.Promotion) (int64, error) {
+	if d.closed {
+		return 0, errors.New("")
+	}
+
+	for _, p := range d.promotions {
+		if p.ID == promotion.ID && p.Status == storage.Pending {
+			now := time.Now()
+			p.Status = storage.Promoted
+			p.ApprovedBy = promotion.ApprovedBy
+			p.PromotedAt = &now
+			promotion.Status = p.Status
+			promotion.PromotedAt = p.PromotedAt
+			return 1, nil
+		}
+	}
+
+	return 0, nil
+}
+
 func (d *dbmock) Close() {
 	d.closed = true
 }
 
 var (
+	portMin, portMax = int64(1), int64(65535)
+
 	db = &dbmock{
 		items: []*storage.Item{
-			{ID: 1, Value: "httptest", Type: "test", Version: "0.0.1"},
-			{ID: 2, Value: "httptest2", Type: "test", Version: "0.0.2"},
+			{
+				ID: 1, Value: "httptest", Type: "test", Version: "0.0.1", Labels: map[string]string{"team": "billing"},
+				Metadata: storage.Metadata{
+					Description: "Test endpoint", Owner: "billing",
+					UpdatedAt: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC),
+				},
+			},
+			{
+				ID: 2, Value: "httptest2", Type: "test", Version: "0.0.2", Labels: map[string]string{"team": "tax"},
+				Metadata: storage.Metadata{
+					Owner:       "tax",
+					Annotations: map[string]interface{}{"runbook": "https://example.com/runbook"},
+					UpdatedAt:   time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC),
+				},
+			},
+			{ID: 3, Value: "hunter2", Type: "password", Version: "0.0.1", Secret: true},
+		},
+		itemTypes: []*storage.ItemType{
+			{Name: "color", Enum: []string{"red", "green"}},
+			{Name: "port", Min: &portMin, Max: &portMax},
+			{Name: "window", Pattern: "_window$"},
 		},
 		modules: []*storage.Module{
-			{ID: 1, Value: "A", Version: "0.0.1"},
-			{ID: 2, Value: "B", Version: "0.0.2"},
+			{
+				ID: 1, Value: "A", Version: "0.0.1", Labels: map[string]string{"release": "2026.10"},
+				Metadata: storage.Metadata{Description: "Billing module", Owner: "billing"},
+			},
+			{ID: 2, Value: "B", Version: "0.0.2", Labels: map[string]string{"release": "2026.11", "tier": "experimental"}},
 		},
 		itemModules: []*storage.ItemModule{
 			{ID: 1, ItemID: 1, ModuleID: 1},
@@ -195,6 +603,7 @@ var (
 		dependencies: []*storage.ModuleDependency{
 			{Dependent: 1, Dependee: 2},
 		},
+		keyring: true,
 	}
 )
 
@@ -674,7 +1083,16 @@ func TestDeleteModule(t *testing.T) {
 		err    bool
 		closed bool
 	}{
-		"module 1": {input: "1"},
+		"module 1 forced": {input: "1?force"},
+		"module 1 in use": {
+			input: "1", status: http.StatusConflict, err: true,
+		},
+		"module 1 cascade orphans items": {
+			input: "1?cascade=dependencies", status: http.StatusConflict, err: true,
+		},
+		"unknown cascade": {
+			input: "1?cascade=items", status: http.StatusBadRequest, err: true,
+		},
 		"wrong input": {
 			input: "woop woop", status: http.StatusNotFound, err: true,
 		},
@@ -1113,6 +1531,18 @@ func TestCreateModuleDependency(t *testing.T) {
 				"dependent": 1, "dependee": 1,
 			},
 		},
+		"optional kind": {
+			input: map[string]interface{}{
+				"dependent": 1, "dependee": 1, "kind": "optional",
+			},
+		},
+		"unknown kind": {
+			input: map[string]interface{}{
+				"dependent": 1, "dependee": 1, "kind": "maybe",
+			},
+			status: http.StatusBadRequest,
+			err:    true,
+		},
 		"missing values": {
 			input:  nil,
 			status: http.StatusBadRequest,
@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Glorforidor/conmansys/confservice/policy"
+	"github.com/Glorforidor/conmansys/confservice/storage"
+)
+
+// revealHeader is the request header holding the token which permits the
+// caller to see the values of secret items.
+const revealHeader = "X-Reveal-Token"
+
+// WithRevealToken permits callers which send the token in the X-Reveal-Token
+// header and ask for ?reveal=true to see the values of secret items. Without
+// this option secret values are always masked.
+func WithRevealToken(token string) Option {
+	return func(h *handler) {
+		h.revealToken = token
+	}
+}
+
+// reveal reports whether the caller is permitted to and asks to see the values
+// of secret items.
+func (h handler) reveal(r *http.Request) bool {
+	return policy.Reveal(h.revealToken, r.Header.Get(revealHeader), r.URL.Query().Get("reveal") == "true")
+}
+
+// maskItem returns the item with its value masked if it is secret and the
+// caller may not see it. The given item is never modified.
+func (h handler) maskItem(r *http.Request, item *storage.Item) *storage.Item {
+	return policy.MaskItem(item, h.reveal(r))
+}
+
+// maskItems masks every secret item in the slice like maskItem.
+func (h handler) maskItems(r *http.Request, items []*storage.Item) []*storage.Item {
+	return policy.MaskItems(items, h.reveal(r))
+}
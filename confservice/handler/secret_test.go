@@ -0,0 +1,158 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Glorforidor/conmansys/confservice/policy"
+)
+
+func TestSecretItems(t *testing.T) {
+	router := New(db, WithRevealToken("letmein"))
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+
+	tt := map[string]struct {
+		url    string
+		token  string
+		masked bool
+	}{
+		"get masked":            {url: "items/3", masked: true},
+		"get without reveal":    {url: "items/3", token: "letmein", masked: true},
+		"get with wrong token":  {url: "items/3?reveal=true", token: "guess", masked: true},
+		"get revealed":          {url: "items/3?reveal=true", token: "letmein"},
+		"list masked":           {url: "items", masked: true},
+		"list revealed":         {url: "items?reveal=true", token: "letmein"},
+		"list with wrong token": {url: "items?reveal=true", token: "guess", masked: true},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("%v/%v", srv.URL, tc.url), nil)
+			if tc.token != "" {
+				req.Header.Set(revealHeader, tc.token)
+			}
+
+			resp, err := srv.Client().Do(req)
+			if err != nil {
+				t.Fatalf("could not send GET request: %v", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("expected status OK, got: %v", resp.StatusCode)
+			}
+
+			want := "hunter2"
+			if tc.masked {
+				want = policy.Mask
+			}
+
+			body := mustRead(t, resp)
+			if !bytes.Contains(body, []byte(fmt.Sprintf("%q", want))) {
+				t.Fatalf("expected value %q in body: %s", want, body)
+			}
+			if tc.masked && bytes.Contains(body, []byte("hunter2")) {
+				t.Fatalf("expected secret value to be masked: %s", body)
+			}
+
+			// masking must not modify the items held by the storage
+			if db.items[2].Value != "hunter2" {
+				t.Fatalf("storage item was modified: %v", db.items[2])
+			}
+		})
+	}
+}
+
+func TestCreateSecretItem(t *testing.T) {
+	router := New(db)
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+
+	tt := map[string]struct {
+		keyring bool
+		status  int
+	}{
+		"with keyring":    {keyring: true, status: http.StatusCreated},
+		"without keyring": {keyring: false, status: http.StatusBadRequest},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			db.keyring = tc.keyring
+			defer func() { db.keyring = true }()
+
+			buf := &bytes.Buffer{}
+			json.NewEncoder(buf).Encode(map[string]interface{}{
+				"value": "hunter2", "type": "password", "version": "0.0.1", "secret": true,
+			})
+
+			resp, err := http.Post(fmt.Sprintf("%v/items", srv.URL), "application/json", buf)
+			if err != nil {
+				t.Fatalf("could not send POST request: %v", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tc.status {
+				t.Fatalf("expected: %v, got: %v", tc.status, resp.StatusCode)
+			}
+
+			if body := mustRead(t, resp); bytes.Contains(body, []byte("hunter2")) {
+				t.Fatalf("expected secret value to be masked: %s", body)
+			}
+		})
+	}
+}
+
+func TestUpdateMaskedItem(t *testing.T) {
+	router := New(db)
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+
+	tt := map[string]struct {
+		url    string
+		method string
+		value  string
+		status int
+		stored string
+	}{
+		"mask sent back":   {url: "items/3", method: http.MethodPut, value: policy.Mask, status: http.StatusOK, stored: "hunter2"},
+		"new value":        {url: "items/3", method: http.MethodPut, value: "hunter3", status: http.StatusOK, stored: "hunter3"},
+		"mask of new item": {url: "items", method: http.MethodPost, value: policy.Mask, status: http.StatusBadRequest},
+		"mask of non-secret item": {
+			url: "items/1", method: http.MethodPut, value: policy.Mask, status: http.StatusBadRequest,
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			db.updated = nil
+
+			buf := &bytes.Buffer{}
+			json.NewEncoder(buf).Encode(map[string]interface{}{
+				"value": tc.value, "type": "password", "version": "0.0.1", "secret": true,
+			})
+
+			req, _ := http.NewRequest(tc.method, fmt.Sprintf("%v/%v", srv.URL, tc.url), buf)
+			resp, err := srv.Client().Do(req)
+			if err != nil {
+				t.Fatalf("could not send %v request: %v", tc.method, err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tc.status {
+				t.Fatalf("expected: %v, got: %v: %s", tc.status, resp.StatusCode, mustRead(t, resp))
+			}
+			if tc.stored == "" {
+				return
+			}
+			if db.updated == nil || db.updated.Value != tc.stored {
+				t.Fatalf("expected value %q to be stored, got: %+v", tc.stored, db.updated)
+			}
+		})
+	}
+}
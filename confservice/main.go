@@ -2,51 +2,199 @@ package main
 
 import (
 	"context"
-	"log"
+	"encoding/base64"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"time"
 
+	"github.com/Glorforidor/conmansys/config"
 	"github.com/Glorforidor/conmansys/confservice/handler"
+	"github.com/Glorforidor/conmansys/confservice/rpc"
+	"github.com/Glorforidor/conmansys/confservice/secret"
+	"github.com/Glorforidor/conmansys/confservice/storage"
+	"github.com/Glorforidor/conmansys/confservice/storage/cache"
 	"github.com/Glorforidor/conmansys/confservice/storage/postgres"
+	"github.com/Glorforidor/conmansys/database"
+	"github.com/Glorforidor/conmansys/logging"
+	"github.com/Glorforidor/conmansys/tracing"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
-const (
-	dbhost = "DBHOST"
-	dbport = "DBPORT"
-	dbuser = "DBUSER"
-	dbpass = "DBPASS"
-	dbname = "DBNAME"
-)
+// configuration of the service. Every setting is read from the YAML file of
+// -config, the environment variable and the flag of its field, as described
+// in the config package.
+type configuration struct {
+	Addr            string        `yaml:"addr" env:"ADDR" flag:"addr" usage:"address of the HTTP server"`
+	GRPCPort        string        `yaml:"grpc_port" env:"GRPC_PORT" flag:"grpc-port" usage:"port of the gRPC server, which runs next to the HTTP server"`
+	ReadTimeout     time.Duration `yaml:"read_timeout" env:"READ_TIMEOUT" flag:"read-timeout" usage:"maximum duration of reading a request"`
+	WriteTimeout    time.Duration `yaml:"write_timeout" env:"WRITE_TIMEOUT" flag:"write-timeout" usage:"maximum duration of writing a response"`
+	IdleTimeout     time.Duration `yaml:"idle_timeout" env:"IDLE_TIMEOUT" flag:"idle-timeout" usage:"how long an idle connection is kept open"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT" flag:"shutdown-timeout" usage:"how long running requests may take to finish at shutdown"`
+	LogLevel        string        `yaml:"log_level" env:"LOG_LEVEL" flag:"log-level" usage:"level of the logs: debug, info, warn or error"`
+	TracesExporter  string        `yaml:"traces_exporter" env:"OTEL_TRACES_EXPORTER" flag:"traces-exporter" usage:"exporter of the spans: otlp, stdout or none"`
+
+	// DB is the database, whose keys like db_host are inlined
+	DB database.Config `yaml:",inline"`
+
+	SecretKeyring         string        `yaml:"secret_keyring" env:"SECRET_KEYRING" flag:"secret-keyring" usage:"keyring used for secret items, which are disabled without one"`
+	RevealToken           string        `yaml:"reveal_token" env:"REVEAL_TOKEN" flag:"reveal-token" secret:"true" usage:"token which permits callers to reveal the values of secret items"`
+	ProtectedEnvironments []string      `yaml:"protected_environments" env:"PROTECTED_ENVIRONMENTS" flag:"protected-environments" usage:"environments whose promotions need an approval, prod when not set"`
+	CacheSize             int           `yaml:"cache_size" env:"CACHE_SIZE" flag:"cache-size" usage:"number of entries in the read-through cache of the storage, 0 disables the cache"`
+	CacheTTL              time.Duration `yaml:"cache_ttl" env:"CACHE_TTL" flag:"cache-ttl" usage:"how long entries of the cache are used, 0 for the default of the cache"`
+}
+
+// defaults returns the configuration used for what is not set.
+func defaults() configuration {
+	return configuration{
+		Addr:            ":80",
+		GRPCPort:        "9090",
+		ReadTimeout:     15 * time.Second,
+		WriteTimeout:    15 * time.Second,
+		IdleTimeout:     60 * time.Second,
+		ShutdownTimeout: 15 * time.Second,
+		LogLevel:        "info",
+		TracesExporter:  tracing.None,
+
+		DB: database.Defaults(),
+	}
+}
 
 func main() {
-	conf := dbConfig()
-	p, err := postgres.New(
-		conf[dbhost], conf[dbport],
-		conf[dbuser], conf[dbpass],
-		conf[dbname],
+	rotate := flag.Bool(
+		"rotate-keys", false,
+		"re-encrypt every secret item with the primary key of the keyring and exit",
+	)
+	generate := flag.String(
+		"generate-key", "",
+		"print a new keyring line with the given key id and exit",
 	)
+
+	cfg := defaults()
+	printConfig, err := config.Load(flag.CommandLine, os.Args[1:], &cfg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if printConfig {
+		if err := config.Print(os.Stdout, &cfg); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if err := logging.Setup(cfg.LogLevel); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	shutdownTracing, err := tracing.Setup(context.Background(), "confservice", cfg.TracesExporter)
 	if err != nil {
-		panic(err)
+		slog.Error("could not set up tracing", "error", err)
+		os.Exit(1)
+	}
+
+	if *generate != "" {
+		k, err := secret.GenerateKey()
+		if err != nil {
+			slog.Error("could not generate key", "error", err)
+			os.Exit(1)
+		}
+		fmt.Printf("%v:%v\n", *generate, base64.StdEncoding.EncodeToString(k))
+		return
+	}
+
+	dsn, dbOpts, err := cfg.DB.Options()
+	if err != nil {
+		slog.Error("invalid database configuration", "error", err)
+		os.Exit(2)
+	}
+	p, err := postgres.Open(dsn, dbOpts...)
+	if err != nil {
+		slog.Error("could not connect to database", "error", err)
+		os.Exit(1)
 	}
 	defer p.Close()
 
-	r := handler.New(p)
+	opts := []handler.Option{handler.WithReadinessCheck("database", p.Ping)}
+	var rpcOpts []rpc.Option
+	if cfg.SecretKeyring != "" {
+		k, err := secret.LoadKeyring(cfg.SecretKeyring)
+		if err != nil {
+			slog.Error("could not load keyring", "error", err)
+			os.Exit(1)
+		}
+		p.UseKeyring(k)
+	}
+
+	if *rotate {
+		n, err := p.RotateKeys(context.Background())
+		if err != nil {
+			slog.Error("could not rotate keys", "error", err)
+			os.Exit(1)
+		}
+		slog.Info("re-encrypted secret items", "count", n)
+		return
+	}
+
+	if cfg.RevealToken != "" {
+		opts = append(opts, handler.WithRevealToken(cfg.RevealToken))
+		rpcOpts = append(rpcOpts, rpc.WithRevealToken(cfg.RevealToken))
+	}
+
+	// an empty list protects no environment
+	if cfg.ProtectedEnvironments != nil {
+		opts = append(opts, handler.WithProtectedEnvironments(cfg.ProtectedEnvironments...))
+	}
+
+	prometheus.MustRegister(newDBStats(p.Stats))
+
+	var s storage.Service = p
+	if cfg.CacheSize != 0 {
+		c, err := newCache(p, p, cfg.CacheSize, cfg.CacheTTL)
+		if err != nil {
+			slog.Error("could not create cache", "error", err)
+			os.Exit(1)
+		}
+		prometheus.MustRegister(newCacheStats(c.Stats))
+		s = c
+	}
+
+	r := handler.New(s, opts...)
 
 	srv := &http.Server{
-		Addr:    "",
+		Addr:    cfg.Addr,
 		Handler: r,
 		// lets make sure we timeout
-		ReadTimeout:  15 * time.Second,
-		WriteTimeout: 15 * time.Second,
-		IdleTimeout:  60 * time.Second,
+		ReadTimeout:  cfg.ReadTimeout,
+		WriteTimeout: cfg.WriteTimeout,
+		IdleTimeout:  cfg.IdleTimeout,
 	}
 
 	// run server in own go routine.
 	go func() {
-		if err := srv.ListenAndServe(); err != nil {
-			log.Println(err)
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			slog.Error("http server failed", "error", err)
+		}
+	}()
+
+	lis, err := net.Listen("tcp", ":"+cfg.GRPCPort)
+	if err != nil {
+		slog.Error("could not listen for grpc", "error", err)
+		os.Exit(1)
+	}
+
+	g := rpc.New(s, rpcOpts...)
+
+	// run the gRPC server in own go routine.
+	go func() {
+		if err := g.Serve(lis); err != nil {
+			slog.Error("grpc server failed", "error", err)
 		}
 	}()
 
@@ -60,51 +208,37 @@ func main() {
 	<-c
 
 	// wait some time before shutting down to finish work.
-	wait := 15 * time.Second
-	ctx, cancel := context.WithTimeout(context.Background(), wait)
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
 	defer cancel()
 
 	// if there are no connections, then it shutsdown immediately otherwise
 	// block until wait time is over.
 	srv.Shutdown(ctx)
+	g.GracefulStop()
 
-	log.Println("shutting down")
-	os.Exit(0)
-}
-
-func dbConfig() map[string]string {
-	conf := make(map[string]string)
-
-	host, ok := os.LookupEnv(dbhost)
-	if !ok {
-		panic("DBHOST environment variable required but not set")
+	if err := shutdownTracing(ctx); err != nil {
+		slog.Error("could not export spans", "error", err)
 	}
 
-	port, ok := os.LookupEnv(dbport)
-	if !ok {
-		panic("DBPORT environment variable required but not set")
-	}
-
-	user, ok := os.LookupEnv(dbuser)
-	if !ok {
-		panic("DBUSER environment variable required but not set")
-	}
+	slog.Info("shutting down")
+	os.Exit(0)
+}
 
-	password, ok := os.LookupEnv(dbpass)
-	if !ok {
-		panic("DBPASS environment variable required but not set")
+// newCache returns the cache in front of the storage with the size and ttl of
+// the configuration. The caches of every instance are kept in sync through the
+// database.
+func newCache(s storage.Service, notifier cache.Notifier, size int, ttl time.Duration) (*cache.Cache, error) {
+	if size < 1 {
+		return nil, fmt.Errorf("cache_size must be a positive number, got: %v", size)
 	}
+	opts := []cache.Option{cache.WithSize(size), cache.WithNotifier(notifier)}
 
-	name, ok := os.LookupEnv(dbname)
-	if !ok {
-		panic("DBNAME environment variable required but not set")
+	if ttl != 0 {
+		if ttl < 0 {
+			return nil, fmt.Errorf("cache_ttl must be a positive duration, got: %v", ttl)
+		}
+		opts = append(opts, cache.WithTTL(ttl))
 	}
 
-	conf[dbhost] = host
-	conf[dbport] = port
-	conf[dbuser] = user
-	conf[dbpass] = password
-	conf[dbname] = name
-
-	return conf
+	return cache.New(s, opts...)
 }
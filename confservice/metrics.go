@@ -0,0 +1,96 @@
+package main
+
+import (
+	"database/sql"
+
+	"github.com/Glorforidor/conmansys/confservice/storage/cache"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// dbStats collects the statistics of the connection pool of the database as
+// reported by sql.DB.Stats.
+type dbStats struct {
+	stats func() sql.DBStats
+
+	maxOpen           *prometheus.Desc
+	open              *prometheus.Desc
+	inUse             *prometheus.Desc
+	idle              *prometheus.Desc
+	waitCount         *prometheus.Desc
+	waitDuration      *prometheus.Desc
+	maxIdleClosed     *prometheus.Desc
+	maxLifetimeClosed *prometheus.Desc
+}
+
+func newDBStats(stats func() sql.DBStats) *dbStats {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc("db_"+name, help, nil, nil)
+	}
+
+	return &dbStats{
+		stats:             stats,
+		maxOpen:           desc("max_open_connections", "Maximum number of open connections to the database."),
+		open:              desc("open_connections", "Number of established connections, both in use and idle."),
+		inUse:             desc("in_use_connections", "Number of connections currently in use."),
+		idle:              desc("idle_connections", "Number of idle connections."),
+		waitCount:         desc("wait_count_total", "Number of connections waited for."),
+		waitDuration:      desc("wait_duration_seconds_total", "Time spent waiting for a connection."),
+		maxIdleClosed:     desc("max_idle_closed_total", "Number of connections closed because of the maximum of idle connections."),
+		maxLifetimeClosed: desc("max_lifetime_closed_total", "Number of connections closed because of their maximum lifetime."),
+	}
+}
+
+func (s *dbStats) Describe(ch chan<- *prometheus.Desc) {
+	ch <- s.maxOpen
+	ch <- s.open
+	ch <- s.inUse
+	ch <- s.idle
+	ch <- s.waitCount
+	ch <- s.waitDuration
+	ch <- s.maxIdleClosed
+	ch <- s.maxLifetimeClosed
+}
+
+func (s *dbStats) Collect(ch chan<- prometheus.Metric) {
+	st := s.stats()
+	ch <- prometheus.MustNewConstMetric(s.maxOpen, prometheus.GaugeValue, float64(st.MaxOpenConnections))
+	ch <- prometheus.MustNewConstMetric(s.open, prometheus.GaugeValue, float64(st.OpenConnections))
+	ch <- prometheus.MustNewConstMetric(s.inUse, prometheus.GaugeValue, float64(st.InUse))
+	ch <- prometheus.MustNewConstMetric(s.idle, prometheus.GaugeValue, float64(st.Idle))
+	ch <- prometheus.MustNewConstMetric(s.waitCount, prometheus.CounterValue, float64(st.WaitCount))
+	ch <- prometheus.MustNewConstMetric(s.waitDuration, prometheus.CounterValue, st.WaitDuration.Seconds())
+	ch <- prometheus.MustNewConstMetric(s.maxIdleClosed, prometheus.CounterValue, float64(st.MaxIdleClosed))
+	ch <- prometheus.MustNewConstMetric(s.maxLifetimeClosed, prometheus.CounterValue, float64(st.MaxLifetimeClosed))
+}
+
+// cacheStats collects the hits, misses and entries of the cache of the
+// storage.
+type cacheStats struct {
+	stats func() cache.Stats
+
+	hits    *prometheus.Desc
+	misses  *prometheus.Desc
+	entries *prometheus.Desc
+}
+
+func newCacheStats(stats func() cache.Stats) *cacheStats {
+	return &cacheStats{
+		stats:   stats,
+		hits:    prometheus.NewDesc("cache_hits_total", "Number of reads answered by the cache.", nil, nil),
+		misses:  prometheus.NewDesc("cache_misses_total", "Number of reads which went to the database.", nil, nil),
+		entries: prometheus.NewDesc("cache_entries", "Number of entries in the cache.", nil, nil),
+	}
+}
+
+func (s *cacheStats) Describe(ch chan<- *prometheus.Desc) {
+	ch <- s.hits
+	ch <- s.misses
+	ch <- s.entries
+}
+
+func (s *cacheStats) Collect(ch chan<- prometheus.Metric) {
+	st := s.stats()
+	ch <- prometheus.MustNewConstMetric(s.hits, prometheus.CounterValue, float64(st.Hits))
+	ch <- prometheus.MustNewConstMetric(s.misses, prometheus.CounterValue, float64(st.Misses))
+	ch <- prometheus.MustNewConstMetric(s.entries, prometheus.GaugeValue, float64(st.Entries))
+}
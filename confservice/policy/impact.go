@@ -0,0 +1,157 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/Glorforidor/conmansys/confservice/storage"
+	"github.com/Glorforidor/conmansys/resolve"
+)
+
+// Impact lists what is affected if a module is deleted.
+type Impact struct {
+	Module *storage.Module `json:"module"`
+	// Dependents are the modules depending on the module, directly or
+	// through other modules.
+	Dependents []*Dependent `json:"dependents"`
+	// Dependencies are the module dependencies the module is part of, which
+	// must be deleted with the module.
+	Dependencies []*storage.ModuleDependency `json:"dependencies"`
+	// OrphanedItems are the items which are not linked to any other module.
+	// Secret items are not masked.
+	OrphanedItems []*storage.Item `json:"orphaned_items"`
+	// Insfiles are the ids of the modules whose insfile changes, which are
+	// the module and its dependents.
+	Insfiles []int64 `json:"insfiles"`
+}
+
+// Dependent is a module depending on the module and the number of module
+// dependencies between them.
+type Dependent struct {
+	*storage.Module
+	Depth int `json:"depth"`
+}
+
+// Reason tells why the module can not be deleted without cascade or force.
+func (i *Impact) Reason() string {
+	return fmt.Sprintf("it is part of %v module dependencies and the only module of %v items",
+		len(i.Dependencies), len(i.OrphanedItems))
+}
+
+// ModuleImpact builds the impact of deleting the module with the given id. It
+// returns nil if the module does not exist.
+func ModuleImpact(ctx context.Context, s storage.Service, id int64) (*Impact, error) {
+	modules, err := s.GetModules(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[int64]*storage.Module, len(modules))
+	for _, m := range modules {
+		byID[m.ID] = m
+	}
+
+	if byID[id] == nil {
+		return nil, nil
+	}
+
+	deps, err := s.GetModuleDependencies(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	itemModules, err := s.GetItemModules(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := s.GetItems(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	impact := &Impact{
+		Module:        byID[id],
+		Dependents:    []*Dependent{},
+		Dependencies:  []*storage.ModuleDependency{},
+		OrphanedItems: []*storage.Item{},
+		Insfiles:      []int64{id},
+	}
+
+	for _, d := range deps {
+		if d.Dependent == id || d.Dependee == id {
+			impact.Dependencies = append(impact.Dependencies, d)
+		}
+	}
+
+	depths, _ := resolve.Walk(deps, id, resolve.Up, 0)
+	for m, d := range depths {
+		if m == id {
+			continue
+		}
+
+		module := byID[m]
+		if module == nil {
+			module = &storage.Module{ID: m}
+		}
+		impact.Dependents = append(impact.Dependents, &Dependent{Module: module, Depth: d})
+		impact.Insfiles = append(impact.Insfiles, m)
+	}
+
+	sort.Slice(impact.Dependents, func(a, b int) bool {
+		if impact.Dependents[a].Depth != impact.Dependents[b].Depth {
+			return impact.Dependents[a].Depth < impact.Dependents[b].Depth
+		}
+		return impact.Dependents[a].ID < impact.Dependents[b].ID
+	})
+	sort.Slice(impact.Insfiles[1:], func(a, b int) bool {
+		return impact.Insfiles[a+1] < impact.Insfiles[b+1]
+	})
+
+	// an item is orphaned if every module it is linked to is the module
+	linked := make(map[int64]bool)
+	others := make(map[int64]bool)
+	for _, im := range itemModules {
+		if im.ModuleID == id {
+			linked[im.ItemID] = true
+		} else {
+			others[im.ItemID] = true
+		}
+	}
+
+	for _, i := range items {
+		if linked[i.ID] && !others[i.ID] {
+			impact.OrphanedItems = append(impact.OrphanedItems, i)
+		}
+	}
+
+	return impact, nil
+}
+
+// RemoveModule deletes the module with the given id and returns the affected
+// rows. A module which is part of module dependencies or the only module of
+// items is not deleted, instead storage.ErrInUse is returned with the impact.
+// With cascade the module dependencies are deleted with the module and with
+// force the items are orphaned as well.
+func RemoveModule(ctx context.Context, s storage.Service, id int64, force, cascade bool) (int64, *Impact, error) {
+	impact, err := ModuleImpact(ctx, s, id)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	conflict := impact != nil && !force &&
+		(len(impact.OrphanedItems) > 0 || (len(impact.Dependencies) > 0 && !cascade))
+	if conflict {
+		return 0, impact, storage.ErrInUse
+	}
+
+	// storage.ErrInUse without an impact means a dependency was added since
+	// the impact was built
+	if force || cascade {
+		row, err := s.DeleteModuleCascade(ctx, id)
+		return row, nil, err
+	}
+	row, err := s.DeleteModule(ctx, id)
+	return row, nil, err
+}
@@ -0,0 +1,99 @@
+// Package policy implements the rules the HTTP handlers and the gRPC server of
+// the confservice share, so both APIs treat the same request the same way:
+// who may see the values of secret items, which items may be saved and when a
+// module may be deleted.
+package policy
+
+import (
+	"context"
+	"crypto/subtle"
+
+	"github.com/Glorforidor/conmansys/confservice/storage"
+)
+
+// Mask replaces the value of secret items in responses.
+const Mask = "********"
+
+// MaskField is the field error of a secret item with the mask as its value,
+// which is never stored.
+var MaskField = &storage.FieldError{
+	Field:   "value",
+	Message: "is the mask of secret values, send the value itself",
+}
+
+// Reveal reports whether a caller which asks to see the values of secret items
+// and sent the given token may see them. Without a configured token secret
+// values are never revealed.
+func Reveal(token, sent string, asked bool) bool {
+	if token == "" || !asked {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare([]byte(sent), []byte(token)) == 1
+}
+
+// MaskItem returns the item with its value masked if it is secret and not
+// revealed. The given item is never modified.
+func MaskItem(item *storage.Item, reveal bool) *storage.Item {
+	if item == nil || !item.Secret || reveal {
+		return item
+	}
+
+	masked := *item
+	masked.Value = Mask
+	return &masked
+}
+
+// MaskItems masks every secret item in the slice like MaskItem.
+func MaskItems(items []*storage.Item, reveal bool) []*storage.Item {
+	if reveal {
+		return items
+	}
+
+	masked := make([]*storage.Item, len(items))
+	for i, item := range items {
+		masked[i] = MaskItem(item, false)
+	}
+
+	return masked
+}
+
+// Unmask keeps the stored value of a secret item which is written back with
+// the mask as its value, as it was read without reveal, so the secret is not
+// replaced by the mask.
+func Unmask(ctx context.Context, s storage.ItemService, id int64, item *storage.Item) error {
+	if !item.Secret || item.Value != Mask {
+		return nil
+	}
+
+	stored, err := s.GetItem(ctx, id)
+	if err != nil {
+		return err
+	}
+	if stored != nil && stored.Secret {
+		item.Value = stored.Value
+	}
+
+	return nil
+}
+
+// ValidateItem checks the value of the item against the validators of its
+// item type. Items with a type which is not registered are always valid,
+// unless they are secret with the mask as their value. It returns the field
+// errors found or an error if the item type could not be retrieved.
+func ValidateItem(ctx context.Context, s storage.ItemTypeService, item *storage.Item) ([]*storage.FieldError, error) {
+	if item.Secret && item.Value == Mask {
+		return []*storage.FieldError{MaskField}, nil
+	}
+
+	t, err := s.GetItemType(ctx, item.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	if t == nil {
+		return nil, nil
+	}
+
+	return t.Validate(item.Value), nil
+}
@@ -0,0 +1,57 @@
+package policy
+
+import (
+	"testing"
+
+	"github.com/Glorforidor/conmansys/confservice/storage"
+)
+
+func TestReveal(t *testing.T) {
+	tt := map[string]struct {
+		token string
+		sent  string
+		asked bool
+		want  bool
+	}{
+		"token":          {token: "t0k3n", sent: "t0k3n", asked: true, want: true},
+		"not asked":      {token: "t0k3n", sent: "t0k3n"},
+		"wrong token":    {token: "t0k3n", sent: "guess", asked: true},
+		"no token":       {token: "t0k3n", asked: true},
+		"not configured": {sent: "t0k3n", asked: true},
+		"empty tokens":   {asked: true},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			if got := Reveal(tc.token, tc.sent, tc.asked); got != tc.want {
+				t.Fatalf("expected: %v, got: %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestMaskItem(t *testing.T) {
+	secret := &storage.Item{ID: 1, Value: "hunter2", Secret: true}
+	plain := &storage.Item{ID: 2, Value: "tax"}
+
+	if got := MaskItem(secret, false); got.Value != Mask {
+		t.Fatalf("expected the value to be masked, got: %v", got.Value)
+	}
+	if secret.Value != "hunter2" {
+		t.Fatalf("expected the item not to be modified, got: %v", secret.Value)
+	}
+	if got := MaskItem(secret, true); got.Value != "hunter2" {
+		t.Fatalf("expected the value to be revealed, got: %v", got.Value)
+	}
+	if got := MaskItem(plain, false); got != plain {
+		t.Fatalf("expected the item as is, got: %v", got)
+	}
+	if MaskItem(nil, false) != nil {
+		t.Fatal("expected nil")
+	}
+
+	masked := MaskItems([]*storage.Item{plain, secret}, false)
+	if masked[0].Value != "tax" || masked[1].Value != Mask {
+		t.Fatalf("expected only the secret item to be masked, got: %v %v", masked[0].Value, masked[1].Value)
+	}
+}
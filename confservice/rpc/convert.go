@@ -0,0 +1,121 @@
+package rpc
+
+import (
+	"time"
+
+	"github.com/Glorforidor/conmansys/confservice/confpb"
+	"github.com/Glorforidor/conmansys/confservice/policy"
+	"github.com/Glorforidor/conmansys/confservice/storage"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// timestamp returns nil for the zero time, so unsaved timestamps are left out.
+func timestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+func toMetadata(m *storage.Metadata) (*confpb.Metadata, error) {
+	md := &confpb.Metadata{
+		Description: m.Description,
+		Owner:       m.Owner,
+		Homepage:    m.Homepage,
+		CreatedAt:   timestamp(m.CreatedAt),
+		UpdatedAt:   timestamp(m.UpdatedAt),
+	}
+
+	if m.Annotations != nil {
+		a, err := structpb.NewStruct(m.Annotations)
+		if err != nil {
+			return nil, internal(err)
+		}
+		md.Annotations = a
+	}
+
+	return md, nil
+}
+
+func fromMetadata(m *confpb.Metadata) storage.Metadata {
+	if m == nil {
+		return storage.Metadata{}
+	}
+
+	md := storage.Metadata{
+		Description: m.Description,
+		Owner:       m.Owner,
+		Homepage:    m.Homepage,
+	}
+	if m.Annotations != nil {
+		md.Annotations = m.Annotations.AsMap()
+	}
+
+	return md
+}
+
+// toItem converts the item to its message with its value masked if it is
+// secret and not revealed.
+func toItem(item *storage.Item, reveal bool) (*confpb.Item, error) {
+	item = policy.MaskItem(item, reveal)
+	md, err := toMetadata(&item.Metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	return &confpb.Item{
+		Id:       item.ID,
+		Value:    item.Value,
+		Type:     item.Type,
+		Version:  item.Version,
+		Secret:   item.Secret,
+		Labels:   item.Labels,
+		Metadata: md,
+	}, nil
+}
+
+func fromItem(i *confpb.Item) *storage.Item {
+	return &storage.Item{
+		ID:       i.Id,
+		Value:    i.Value,
+		Type:     i.Type,
+		Version:  i.Version,
+		Secret:   i.Secret,
+		Labels:   i.Labels,
+		Metadata: fromMetadata(i.Metadata),
+	}
+}
+
+func toModule(module *storage.Module) (*confpb.Module, error) {
+	md, err := toMetadata(&module.Metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	return &confpb.Module{
+		Id:       module.ID,
+		Value:    module.Value,
+		Version:  module.Version,
+		Labels:   module.Labels,
+		Metadata: md,
+	}, nil
+}
+
+func fromModule(m *confpb.Module) *storage.Module {
+	return &storage.Module{
+		ID:       m.Id,
+		Value:    m.Value,
+		Version:  m.Version,
+		Labels:   m.Labels,
+		Metadata: fromMetadata(m.Metadata),
+	}
+}
+
+func toItemModule(im *storage.ItemModule) *confpb.ItemModule {
+	return &confpb.ItemModule{Id: im.ID, ItemId: im.ItemID, ModuleId: im.ModuleID}
+}
+
+func toModuleDependency(md *storage.ModuleDependency) *confpb.ModuleDependency {
+	return &confpb.ModuleDependency{Dependent: md.Dependent, Dependee: md.Dependee, Kind: md.Kind}
+}
@@ -0,0 +1,441 @@
+// Package rpc serves the CRUD operations of the confservice over gRPC with the
+// same storage as the HTTP handlers. The messages and the service are defined
+// in the confpb package.
+package rpc
+
+import (
+	"context"
+
+	"github.com/Glorforidor/conmansys/confservice/confpb"
+	"github.com/Glorforidor/conmansys/confservice/policy"
+	"github.com/Glorforidor/conmansys/confservice/storage"
+	"github.com/Glorforidor/conmansys/labels"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+)
+
+// revealKey is the metadata key holding the token which permits the caller to
+// see the values of secret items.
+const revealKey = "x-reveal-token"
+
+var (
+	errMissingValue = status.Error(codes.InvalidArgument, "missing value")
+	errNotFound     = status.Error(codes.NotFound, "not found")
+	errInternal     = status.Error(codes.Internal, "Ups something went wrong")
+)
+
+type server struct {
+	confpb.UnimplementedConfServiceServer
+	storage     storage.Service
+	revealToken string
+}
+
+// Option configures optional behaviour of the server.
+type Option func(*server)
+
+// WithRevealToken permits callers which send the token in the x-reveal-token
+// metadata and ask for reveal to see the values of secret items. Without this
+// option secret values are always masked.
+func WithRevealToken(token string) Option {
+	return func(s *server) {
+		s.revealToken = token
+	}
+}
+
+// New registers the ConfService backed by the storage.Service and the server
+// reflection to a gRPC server and returns it.
+func New(service storage.Service, opts ...Option) *grpc.Server {
+	s := &server{storage: service}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	srv := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(otelgrpc.UnaryServerInterceptor(), logUnary),
+		grpc.ChainStreamInterceptor(otelgrpc.StreamServerInterceptor(), logStream),
+	)
+	confpb.RegisterConfServiceServer(srv, s)
+	reflection.Register(srv)
+	return srv
+}
+
+// invalid returns an INVALID_ARGUMENT error with the field errors as a
+// BadRequest detail.
+func invalid(fields []*storage.FieldError) error {
+	st := status.New(codes.InvalidArgument, "invalid value")
+	br := &errdetails.BadRequest{}
+	for _, f := range fields {
+		br.FieldViolations = append(br.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       f.Field,
+			Description: f.Message,
+		})
+	}
+
+	d, err := st.WithDetails(br)
+	if err != nil {
+		return st.Err()
+	}
+	return d.Err()
+}
+
+// reveal reports whether the caller is permitted to and asks to see the values
+// of secret items.
+func (s *server) reveal(ctx context.Context, asked bool) bool {
+	md, _ := metadata.FromIncomingContext(ctx)
+	var token string
+	if tokens := md.Get(revealKey); len(tokens) > 0 {
+		token = tokens[0]
+	}
+	return policy.Reveal(s.revealToken, token, asked)
+}
+
+func (s *server) GetItem(ctx context.Context, req *confpb.GetItemRequest) (*confpb.Item, error) {
+	item, err := s.storage.GetItem(ctx, req.Id)
+	if err != nil {
+		return nil, internal(err)
+	}
+
+	if item == nil {
+		return nil, errNotFound
+	}
+
+	return toItem(item, s.reveal(ctx, req.Reveal))
+}
+
+func (s *server) ListItems(req *confpb.ListItemsRequest, stream confpb.ConfService_ListItemsServer) error {
+	sel, err := labels.Parse(req.Selector)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	items, err := s.storage.GetItems(stream.Context())
+	if err != nil {
+		return internal(err)
+	}
+
+	reveal := s.reveal(stream.Context(), req.Reveal)
+	for _, item := range items {
+		if !sel.Matches(item.Labels) {
+			continue
+		}
+
+		i, err := toItem(item, reveal)
+		if err != nil {
+			return err
+		}
+		if err := stream.Send(i); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkItem checks an item which is about to be saved. Labels are only checked
+// when the item is created, as an update leaves them untouched.
+func (s *server) checkItem(ctx context.Context, item *storage.Item, create bool) error {
+	if item.Value == "" || item.Type == "" || item.Version == "" {
+		return status.Error(codes.InvalidArgument, "missing values")
+	}
+
+	fields, err := policy.ValidateItem(ctx, s.storage, item)
+	if err != nil {
+		return internal(err)
+	}
+
+	if create {
+		fields = append(fields, storage.CheckLabels(item.Labels)...)
+	}
+	fields = append(fields, item.Metadata.Check()...)
+	if len(fields) > 0 {
+		return invalid(fields)
+	}
+
+	return nil
+}
+
+func (s *server) CreateItem(ctx context.Context, req *confpb.Item) (*confpb.Item, error) {
+	item := fromItem(req)
+	if err := s.checkItem(ctx, item, true); err != nil {
+		return nil, err
+	}
+
+	id, err := s.storage.CreateItem(ctx, item)
+	if err == storage.ErrNoKeyring {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	if err != nil {
+		return nil, internal(err)
+	}
+
+	item.ID = id
+	return toItem(item, false)
+}
+
+// UpdateItem replaces the item with the id of the request. The labels of the
+// item are left untouched.
+func (s *server) UpdateItem(ctx context.Context, req *confpb.Item) (*confpb.Item, error) {
+	item := fromItem(req)
+	if err := policy.Unmask(ctx, s.storage, item.ID, item); err != nil {
+		return nil, internal(err)
+	}
+	if err := s.checkItem(ctx, item, false); err != nil {
+		return nil, err
+	}
+
+	row, err := s.storage.UpdateItem(ctx, item)
+	if err == storage.ErrNoKeyring {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	if err != nil {
+		return nil, internal(err)
+	}
+
+	if row == 0 {
+		return nil, errNotFound
+	}
+
+	// the labels and timestamps are not part of the update
+	stored, err := s.storage.GetItem(ctx, item.ID)
+	if err != nil {
+		return nil, internal(err)
+	}
+
+	return toItem(stored, false)
+}
+
+func (s *server) DeleteItem(ctx context.Context, req *confpb.IDRequest) (*confpb.DeleteResponse, error) {
+	row, err := s.storage.DeleteItem(ctx, req.Id)
+	if err != nil {
+		return nil, internal(err)
+	}
+
+	return &confpb.DeleteResponse{RowsAffected: row}, nil
+}
+
+func (s *server) GetModule(ctx context.Context, req *confpb.IDRequest) (*confpb.Module, error) {
+	module, err := s.storage.GetModule(ctx, req.Id)
+	if err != nil {
+		return nil, internal(err)
+	}
+
+	if module == nil {
+		return nil, errNotFound
+	}
+
+	return toModule(module)
+}
+
+func (s *server) ListModules(req *confpb.ListModulesRequest, stream confpb.ConfService_ListModulesServer) error {
+	sel, err := labels.Parse(req.Selector)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	modules, err := s.storage.GetModules(stream.Context())
+	if err != nil {
+		return internal(err)
+	}
+
+	for _, module := range modules {
+		if !sel.Matches(module.Labels) {
+			continue
+		}
+
+		m, err := toModule(module)
+		if err != nil {
+			return err
+		}
+		if err := stream.Send(m); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *server) CreateModule(ctx context.Context, req *confpb.Module) (*confpb.Module, error) {
+	module := fromModule(req)
+	if module.Value == "" || module.Version == "" {
+		return nil, errMissingValue
+	}
+
+	fields := append(storage.CheckLabels(module.Labels), module.Metadata.Check()...)
+	if len(fields) > 0 {
+		return nil, invalid(fields)
+	}
+
+	id, err := s.storage.CreateModule(ctx, module)
+	if err != nil {
+		return nil, internal(err)
+	}
+
+	module.ID = id
+	return toModule(module)
+}
+
+// UpdateModule replaces the module with the id of the request. The labels of
+// the module are left untouched.
+func (s *server) UpdateModule(ctx context.Context, req *confpb.Module) (*confpb.Module, error) {
+	module := fromModule(req)
+	if module.Value == "" || module.Version == "" {
+		return nil, errMissingValue
+	}
+
+	if fields := module.Metadata.Check(); len(fields) > 0 {
+		return nil, invalid(fields)
+	}
+
+	row, err := s.storage.UpdateModule(ctx, module)
+	if err != nil {
+		return nil, internal(err)
+	}
+
+	if row == 0 {
+		return nil, errNotFound
+	}
+
+	// the labels and timestamps are not part of the update
+	stored, err := s.storage.GetModule(ctx, module.ID)
+	if err != nil {
+		return nil, internal(err)
+	}
+
+	return toModule(stored)
+}
+
+// DeleteModule deletes the module like DELETE /modules/:id. A module which is
+// part of module dependencies or the only module of items is refused, with
+// cascade the module dependencies are deleted with it, but items are never
+// orphaned.
+func (s *server) DeleteModule(ctx context.Context, req *confpb.DeleteModuleRequest) (*confpb.DeleteResponse, error) {
+	row, impact, err := policy.RemoveModule(ctx, s.storage, req.Id, false, req.Cascade)
+	if err == storage.ErrInUse && impact != nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "%v: %v", err, impact.Reason())
+	}
+	if err == storage.ErrInUse {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	if err != nil {
+		return nil, internal(err)
+	}
+
+	return &confpb.DeleteResponse{RowsAffected: row}, nil
+}
+
+func (s *server) GetItemModule(ctx context.Context, req *confpb.IDRequest) (*confpb.ItemModule, error) {
+	im, err := s.storage.GetItemModule(ctx, req.Id)
+	if err != nil {
+		return nil, internal(err)
+	}
+
+	if im == nil {
+		return nil, errNotFound
+	}
+
+	return toItemModule(im), nil
+}
+
+func (s *server) ListItemModules(req *confpb.ListItemModulesRequest, stream confpb.ConfService_ListItemModulesServer) error {
+	ims, err := s.storage.GetItemModules(stream.Context())
+	if err != nil {
+		return internal(err)
+	}
+
+	for _, im := range ims {
+		if err := stream.Send(toItemModule(im)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *server) CreateItemModule(ctx context.Context, req *confpb.ItemModule) (*confpb.ItemModule, error) {
+	if req.ItemId == 0 || req.ModuleId == 0 {
+		return nil, errMissingValue
+	}
+
+	id, err := s.storage.CreateItemModule(ctx, req.ItemId, req.ModuleId)
+	if err != nil {
+		return nil, internal(err)
+	}
+
+	return &confpb.ItemModule{Id: id, ItemId: req.ItemId, ModuleId: req.ModuleId}, nil
+}
+
+func (s *server) DeleteItemModule(ctx context.Context, req *confpb.IDRequest) (*confpb.DeleteResponse, error) {
+	row, err := s.storage.DeleteItemModule(ctx, req.Id)
+	if err != nil {
+		return nil, internal(err)
+	}
+
+	return &confpb.DeleteResponse{RowsAffected: row}, nil
+}
+
+func (s *server) ListModuleDependencies(req *confpb.ListModuleDependenciesRequest, stream confpb.ConfService_ListModuleDependenciesServer) error {
+	var mds []*storage.ModuleDependency
+	var err error
+	switch {
+	case req.Dependent != 0:
+		mds, err = s.storage.GetModuleDependenciesByDependentID(stream.Context(), req.Dependent)
+	case req.Dependee != 0:
+		mds, err = s.storage.GetModuleDependenciesByDependeeID(stream.Context(), req.Dependee)
+	default:
+		mds, err = s.storage.GetModuleDependencies(stream.Context())
+	}
+	if err != nil {
+		return internal(err)
+	}
+
+	for _, md := range mds {
+		// both the dependent and the dependee may be asked for
+		if req.Dependee != 0 && md.Dependee != req.Dependee {
+			continue
+		}
+		if err := stream.Send(toModuleDependency(md)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *server) CreateModuleDependency(ctx context.Context, req *confpb.ModuleDependency) (*confpb.ModuleDependency, error) {
+	md := storage.ModuleDependency{Dependent: req.Dependent, Dependee: req.Dependee, Kind: req.Kind}
+	if md.Dependent == 0 || md.Dependee == 0 {
+		return nil, errMissingValue
+	}
+
+	if md.Kind == "" {
+		md.Kind = storage.Required
+	}
+
+	if fields := storage.CheckDependency(&md); len(fields) > 0 {
+		return nil, invalid(fields)
+	}
+
+	if err := s.storage.CreateModuleDependency(ctx, &md); err != nil {
+		return nil, internal(err)
+	}
+
+	return toModuleDependency(&md), nil
+}
+
+func (s *server) DeleteModuleDependency(ctx context.Context, req *confpb.DeleteModuleDependencyRequest) (*confpb.DeleteResponse, error) {
+	if req.Dependent == 0 || req.Dependee == 0 {
+		return nil, errMissingValue
+	}
+
+	row, err := s.storage.DeleteModuleDependency(ctx, req.Dependent, req.Dependee)
+	if err != nil {
+		return nil, internal(err)
+	}
+
+	return &confpb.DeleteResponse{RowsAffected: row}, nil
+}
@@ -0,0 +1,411 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/Glorforidor/conmansys/confservice/confpb"
+	"github.com/Glorforidor/conmansys/confservice/policy"
+	"github.com/Glorforidor/conmansys/confservice/storage"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// dbmock keeps the items, modules, item modules and module dependencies in
+// memory. The
+// methods the server does not use panic through the nil storage.Service.
+type dbmock struct {
+	storage.Service
+	items        []*storage.Item
+	itemTypes    []*storage.ItemType
+	modules      []*storage.Module
+	itemModules  []*storage.ItemModule
+	dependencies []*storage.ModuleDependency
+	closed       bool
+}
+
+var errClosed = errors.New("storage is closed")
+
+func newDB() *dbmock {
+	return &dbmock{
+		items: []*storage.Item{
+			{ID: 1, Value: "tax", Type: "domain", Version: "1.0.0", Labels: map[string]string{"team": "billing"}},
+			{ID: 2, Value: "hunter2", Type: "password", Version: "1.0.0", Secret: true},
+		},
+		itemTypes: []*storage.ItemType{{Name: "port", Pattern: "^[0-9]+$"}},
+		modules: []*storage.Module{
+			{ID: 1, Value: "A", Version: "0.0.1"},
+			{ID: 2, Value: "B", Version: "0.0.1"},
+			{ID: 3, Value: "C", Version: "0.0.1"},
+		},
+		// item 1 is only linked to module 3
+		itemModules: []*storage.ItemModule{
+			{ID: 1, ItemID: 1, ModuleID: 3},
+			{ID: 2, ItemID: 2, ModuleID: 2},
+			{ID: 3, ItemID: 2, ModuleID: 3},
+		},
+		dependencies: []*storage.ModuleDependency{
+			{Dependent: 1, Dependee: 2, Kind: storage.Required},
+			{Dependent: 1, Dependee: 3, Kind: storage.Optional},
+			{Dependent: 2, Dependee: 3, Kind: storage.Required},
+		},
+	}
+}
+
+func (db *dbmock) GetItem(_ context.Context, id int64) (*storage.Item, error) {
+	if db.closed {
+		return nil, errClosed
+	}
+	for _, i := range db.items {
+		if i.ID == id {
+			return i, nil
+		}
+	}
+	return nil, nil
+}
+
+func (db *dbmock) GetItems(_ context.Context) ([]*storage.Item, error) {
+	if db.closed {
+		return nil, errClosed
+	}
+	return db.items, nil
+}
+
+func (db *dbmock) CreateItem(_ context.Context, item *storage.Item) (int64, error) {
+	if item.Secret {
+		return 0, storage.ErrNoKeyring
+	}
+	item.ID = int64(len(db.items) + 1)
+	db.items = append(db.items, item)
+	return item.ID, nil
+}
+
+func (db *dbmock) GetItemType(_ context.Context, name string) (*storage.ItemType, error) {
+	for _, t := range db.itemTypes {
+		if t.Name == name {
+			return t, nil
+		}
+	}
+	return nil, nil
+}
+
+func (db *dbmock) GetModules(_ context.Context) ([]*storage.Module, error) {
+	return db.modules, nil
+}
+
+func (db *dbmock) DeleteModule(_ context.Context, id int64) (int64, error) {
+	for _, md := range db.dependencies {
+		if md.Dependent == id || md.Dependee == id {
+			return 0, storage.ErrInUse
+		}
+	}
+	return 1, nil
+}
+
+func (db *dbmock) DeleteModuleCascade(_ context.Context, id int64) (int64, error) {
+	return 1, nil
+}
+
+func (db *dbmock) GetItemModules(_ context.Context) ([]*storage.ItemModule, error) {
+	return db.itemModules, nil
+}
+
+func (db *dbmock) GetModuleDependencies(_ context.Context) ([]*storage.ModuleDependency, error) {
+	return db.dependencies, nil
+}
+
+func (db *dbmock) GetModuleDependenciesByDependentID(_ context.Context, id int64) ([]*storage.ModuleDependency, error) {
+	var mds []*storage.ModuleDependency
+	for _, md := range db.dependencies {
+		if md.Dependent == id {
+			mds = append(mds, md)
+		}
+	}
+	return mds, nil
+}
+
+func (db *dbmock) GetModuleDependenciesByDependeeID(_ context.Context, id int64) ([]*storage.ModuleDependency, error) {
+	var mds []*storage.ModuleDependency
+	for _, md := range db.dependencies {
+		if md.Dependee == id {
+			mds = append(mds, md)
+		}
+	}
+	return mds, nil
+}
+
+func (db *dbmock) CreateModuleDependency(_ context.Context, md *storage.ModuleDependency) error {
+	db.dependencies = append(db.dependencies, md)
+	return nil
+}
+
+// dial serves the storage over an in-memory connection and returns a client of
+// it.
+func dial(t *testing.T, db storage.Service, opts ...Option) confpb.ConfServiceClient {
+	lis := bufconn.Listen(1 << 20)
+	srv := New(db, opts...)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.Dial(
+		"bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+			return lis.Dial()
+		}),
+		grpc.WithInsecure(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return confpb.NewConfServiceClient(conn)
+}
+
+func TestGetItem(t *testing.T) {
+	tt := map[string]struct {
+		id     int64
+		reveal bool
+		token  string
+		value  string
+		code   codes.Code
+		closed bool
+	}{
+		"item":           {id: 1, value: "tax", code: codes.OK},
+		"secret":         {id: 2, value: policy.Mask, code: codes.OK},
+		"reveal":         {id: 2, reveal: true, token: "open", value: "hunter2", code: codes.OK},
+		"wrong token":    {id: 2, reveal: true, token: "nope", value: policy.Mask, code: codes.OK},
+		"not asked":      {id: 2, token: "open", value: policy.Mask, code: codes.OK},
+		"missing":        {id: 42, code: codes.NotFound},
+		"closed storage": {id: 1, code: codes.Internal, closed: true},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			db := newDB()
+			db.closed = tc.closed
+			client := dial(t, db, WithRevealToken("open"))
+
+			ctx := metadata.AppendToOutgoingContext(context.Background(), revealKey, tc.token)
+			item, err := client.GetItem(ctx, &confpb.GetItemRequest{Id: tc.id, Reveal: tc.reveal})
+			if status.Code(err) != tc.code {
+				t.Fatalf("expected code: %v, got: %v", tc.code, err)
+			}
+			if err != nil {
+				return
+			}
+
+			if item.Value != tc.value {
+				t.Fatalf("expected value: %v, got: %v", tc.value, item.Value)
+			}
+		})
+	}
+}
+
+func TestListItems(t *testing.T) {
+	tt := map[string]struct {
+		selector string
+		values   []string
+		code     codes.Code
+	}{
+		"every item":       {values: []string{"tax", policy.Mask}},
+		"selector":         {selector: "team=billing", values: []string{"tax"}},
+		"invalid selector": {selector: "team in billing", code: codes.InvalidArgument},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			client := dial(t, newDB())
+
+			stream, err := client.ListItems(context.Background(), &confpb.ListItemsRequest{Selector: tc.selector})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var values []string
+			for {
+				item, err := stream.Recv()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					if status.Code(err) != tc.code {
+						t.Fatalf("expected code: %v, got: %v", tc.code, err)
+					}
+					return
+				}
+				values = append(values, item.Value)
+			}
+
+			if tc.code != codes.OK {
+				t.Fatalf("expected code: %v, got: OK", tc.code)
+			}
+			if len(values) != len(tc.values) {
+				t.Fatalf("expected: %v, got: %v", tc.values, values)
+			}
+			for i := range values {
+				if values[i] != tc.values[i] {
+					t.Fatalf("expected: %v, got: %v", tc.values, values)
+				}
+			}
+		})
+	}
+}
+
+func TestCreateItem(t *testing.T) {
+	tt := map[string]struct {
+		item   *confpb.Item
+		code   codes.Code
+		fields []string
+	}{
+		"item": {
+			item: &confpb.Item{Value: "8080", Type: "port", Version: "1", Metadata: &confpb.Metadata{Owner: "ops"}},
+			code: codes.OK,
+		},
+		"missing values": {item: &confpb.Item{Value: "8080"}, code: codes.InvalidArgument},
+		"invalid value": {
+			item:   &confpb.Item{Value: "http", Type: "port", Version: "1"},
+			code:   codes.InvalidArgument,
+			fields: []string{"value"},
+		},
+		"mask as value": {
+			item:   &confpb.Item{Value: policy.Mask, Type: "password", Version: "1", Secret: true},
+			code:   codes.InvalidArgument,
+			fields: []string{"value"},
+		},
+		"secret without keyring": {
+			item: &confpb.Item{Value: "hunter2", Type: "password", Version: "1", Secret: true},
+			code: codes.FailedPrecondition,
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			client := dial(t, newDB())
+
+			item, err := client.CreateItem(context.Background(), tc.item)
+			if status.Code(err) != tc.code {
+				t.Fatalf("expected code: %v, got: %v", tc.code, err)
+			}
+
+			if err != nil {
+				var fields []string
+				for _, d := range status.Convert(err).Details() {
+					if br, ok := d.(*errdetails.BadRequest); ok {
+						for _, v := range br.FieldViolations {
+							fields = append(fields, v.Field)
+						}
+					}
+				}
+				if len(fields) != len(tc.fields) {
+					t.Fatalf("expected field errors: %v, got: %v", tc.fields, fields)
+				}
+				return
+			}
+
+			if item.Id != 3 || item.Metadata.Owner != "ops" {
+				t.Fatalf("expected the created item, got: %v", item)
+			}
+		})
+	}
+}
+
+func TestDeleteModule(t *testing.T) {
+	tt := map[string]struct {
+		req  *confpb.DeleteModuleRequest
+		code codes.Code
+	}{
+		"in use":  {req: &confpb.DeleteModuleRequest{Id: 2}, code: codes.FailedPrecondition},
+		"cascade": {req: &confpb.DeleteModuleRequest{Id: 2, Cascade: true}, code: codes.OK},
+		// cascade deletes the module dependencies but never orphans items
+		"orphans items": {req: &confpb.DeleteModuleRequest{Id: 3, Cascade: true}, code: codes.FailedPrecondition},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			client := dial(t, newDB())
+
+			_, err := client.DeleteModule(context.Background(), tc.req)
+			if status.Code(err) != tc.code {
+				t.Fatalf("expected code: %v, got: %v", tc.code, err)
+			}
+		})
+	}
+}
+
+func TestListModuleDependencies(t *testing.T) {
+	tt := map[string]struct {
+		req  *confpb.ListModuleDependenciesRequest
+		want int
+	}{
+		"every dependency": {req: &confpb.ListModuleDependenciesRequest{}, want: 3},
+		"dependent":        {req: &confpb.ListModuleDependenciesRequest{Dependent: 1}, want: 2},
+		"dependee":         {req: &confpb.ListModuleDependenciesRequest{Dependee: 3}, want: 2},
+		"both":             {req: &confpb.ListModuleDependenciesRequest{Dependent: 1, Dependee: 3}, want: 1},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			client := dial(t, newDB())
+
+			stream, err := client.ListModuleDependencies(context.Background(), tc.req)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var n int
+			for {
+				_, err := stream.Recv()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				n++
+			}
+
+			if n != tc.want {
+				t.Fatalf("expected %v dependencies, got: %v", tc.want, n)
+			}
+		})
+	}
+}
+
+func TestCreateModuleDependency(t *testing.T) {
+	tt := map[string]struct {
+		req  *confpb.ModuleDependency
+		kind string
+		code codes.Code
+	}{
+		"default kind":  {req: &confpb.ModuleDependency{Dependent: 3, Dependee: 1}, kind: storage.Required},
+		"kind":          {req: &confpb.ModuleDependency{Dependent: 3, Dependee: 1, Kind: storage.Conflicts}, kind: storage.Conflicts},
+		"invalid kind":  {req: &confpb.ModuleDependency{Dependent: 3, Dependee: 1, Kind: "maybe"}, code: codes.InvalidArgument},
+		"missing value": {req: &confpb.ModuleDependency{Dependent: 3}, code: codes.InvalidArgument},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			client := dial(t, newDB())
+
+			md, err := client.CreateModuleDependency(context.Background(), tc.req)
+			if status.Code(err) != tc.code {
+				t.Fatalf("expected code: %v, got: %v", tc.code, err)
+			}
+			if err != nil {
+				return
+			}
+
+			if md.Kind != tc.kind {
+				t.Fatalf("expected kind: %v, got: %v", tc.kind, md.Kind)
+			}
+		})
+	}
+}
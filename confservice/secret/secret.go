@@ -0,0 +1,279 @@
+// Package secret implements envelope encryption of secret item values.
+//
+// Every value is encrypted with its own random data key and the data key is
+// encrypted with a key encryption key from a Keyring. Rotating the key
+// encryption key therefore only needs to re-encrypt the data keys and never the
+// values themselves.
+package secret
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// prefix marks a value as sealed by this package. The version makes it
+// possible to change the format without breaking already sealed values.
+const prefix = "enc:v1:"
+
+// keySize is the size in bytes of both the key encryption keys and the data
+// keys. 32 bytes selects AES-256.
+const keySize = 32
+
+var (
+	errNotSealed  = errors.New("value is not sealed")
+	errUnknownKey = errors.New("value is sealed with an unknown key")
+)
+
+type key struct {
+	id   string
+	aead cipher.AEAD
+}
+
+// Keyring holds the key encryption keys. The first key is the primary key and
+// is used to seal new values. Every key in the keyring can open values.
+type Keyring struct {
+	keys []*key
+}
+
+// NewKeyring creates a keyring from the given keys in order of precedence. The
+// keys must be 32 bytes long.
+func NewKeyring(ids []string, keys [][]byte) (*Keyring, error) {
+	if len(ids) != len(keys) {
+		return nil, fmt.Errorf("got %v key ids but %v keys", len(ids), len(keys))
+	}
+
+	if len(keys) == 0 {
+		return nil, errors.New("keyring must contain at least one key")
+	}
+
+	k := &Keyring{}
+	for i, id := range ids {
+		if id == "" || strings.Contains(id, ":") {
+			return nil, fmt.Errorf("key id %q must be non empty and without ':'", id)
+		}
+
+		if len(keys[i]) != keySize {
+			return nil, fmt.Errorf("key %q must be %v bytes, got: %v", id, keySize, len(keys[i]))
+		}
+
+		aead, err := newAEAD(keys[i])
+		if err != nil {
+			return nil, fmt.Errorf("could not use key %q: %v", id, err)
+		}
+
+		k.keys = append(k.keys, &key{id: id, aead: aead})
+	}
+
+	return k, nil
+}
+
+// LoadKeyring reads a keyring from the file at path. Every non empty line,
+// which does not start with '#', holds a key as "id:base64 encoded key". The
+// first key in the file is the primary key.
+func LoadKeyring(path string) (*Keyring, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not open keyring: %v", err)
+	}
+	defer f.Close()
+
+	var ids []string
+	var keys [][]byte
+
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("keyring line %q is not of the form id:key", line)
+		}
+
+		b, err := base64.StdEncoding.DecodeString(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("could not decode key %q: %v", parts[0], err)
+		}
+
+		ids = append(ids, parts[0])
+		keys = append(keys, b)
+	}
+
+	if err := s.Err(); err != nil {
+		return nil, fmt.Errorf("could not read keyring: %v", err)
+	}
+
+	return NewKeyring(ids, keys)
+}
+
+// GenerateKey returns a new random key suitable for a Keyring.
+func GenerateKey() ([]byte, error) {
+	b := make([]byte, keySize)
+	if _, err := io.ReadFull(rand.Reader, b); err != nil {
+		return nil, fmt.Errorf("could not generate key: %v", err)
+	}
+	return b, nil
+}
+
+// IsSealed reports whether the value has been sealed by a Keyring.
+func IsSealed(value string) bool {
+	return strings.HasPrefix(value, prefix)
+}
+
+// Seal encrypts the plaintext with a new data key and encrypts the data key
+// with the primary key. It returns the sealed value.
+func (k *Keyring) Seal(plaintext string) (string, error) {
+	dek, err := GenerateKey()
+	if err != nil {
+		return "", err
+	}
+
+	aead, err := newAEAD(dek)
+	if err != nil {
+		return "", err
+	}
+
+	ct, err := encrypt(aead, []byte(plaintext))
+	if err != nil {
+		return "", err
+	}
+
+	primary := k.keys[0]
+	wrapped, err := encrypt(primary.aead, dek)
+	if err != nil {
+		return "", err
+	}
+
+	return format(primary.id, wrapped, ct), nil
+}
+
+// Open decrypts a value sealed by Seal with any key in the keyring and returns
+// the plaintext.
+func (k *Keyring) Open(sealed string) (string, error) {
+	kk, wrapped, ct, err := k.parse(sealed)
+	if err != nil {
+		return "", err
+	}
+
+	dek, err := decrypt(kk.aead, wrapped)
+	if err != nil {
+		return "", fmt.Errorf("could not decrypt data key: %v", err)
+	}
+
+	aead, err := newAEAD(dek)
+	if err != nil {
+		return "", err
+	}
+
+	pt, err := decrypt(aead, ct)
+	if err != nil {
+		return "", fmt.Errorf("could not decrypt value: %v", err)
+	}
+
+	return string(pt), nil
+}
+
+// Rewrap encrypts the data key of the sealed value with the primary key. The
+// value itself is left untouched. It reports whether the value changed, which
+// is not the case if it already is sealed with the primary key.
+func (k *Keyring) Rewrap(sealed string) (string, bool, error) {
+	kk, wrapped, ct, err := k.parse(sealed)
+	if err != nil {
+		return "", false, err
+	}
+
+	primary := k.keys[0]
+	if kk == primary {
+		return sealed, false, nil
+	}
+
+	dek, err := decrypt(kk.aead, wrapped)
+	if err != nil {
+		return "", false, fmt.Errorf("could not decrypt data key: %v", err)
+	}
+
+	wrapped, err = encrypt(primary.aead, dek)
+	if err != nil {
+		return "", false, err
+	}
+
+	return format(primary.id, wrapped, ct), true, nil
+}
+
+func (k *Keyring) parse(sealed string) (*key, []byte, []byte, error) {
+	if !IsSealed(sealed) {
+		return nil, nil, nil, errNotSealed
+	}
+
+	parts := strings.Split(strings.TrimPrefix(sealed, prefix), ":")
+	if len(parts) != 3 {
+		return nil, nil, nil, fmt.Errorf("sealed value is malformed")
+	}
+
+	var kk *key
+	for _, key := range k.keys {
+		if key.id == parts[0] {
+			kk = key
+			break
+		}
+	}
+	if kk == nil {
+		return nil, nil, nil, errUnknownKey
+	}
+
+	wrapped, err := base64.StdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("could not decode data key: %v", err)
+	}
+
+	ct, err := base64.StdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("could not decode value: %v", err)
+	}
+
+	return kk, wrapped, ct, nil
+}
+
+func format(id string, wrapped, ct []byte) string {
+	return prefix + id + ":" +
+		base64.StdEncoding.EncodeToString(wrapped) + ":" +
+		base64.StdEncoding.EncodeToString(ct)
+}
+
+func newAEAD(k []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(k)
+	if err != nil {
+		return nil, fmt.Errorf("could not create cipher: %v", err)
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// encrypt returns the nonce followed by the ciphertext.
+func encrypt(aead cipher.AEAD, plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, fmt.Errorf("could not generate nonce: %v", err)
+	}
+
+	return aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func decrypt(aead cipher.AEAD, data []byte) ([]byte, error) {
+	n := aead.NonceSize()
+	if len(data) < n {
+		return nil, errors.New("ciphertext too short")
+	}
+
+	return aead.Open(nil, data[:n], data[n:], nil)
+}
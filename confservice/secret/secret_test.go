@@ -0,0 +1,163 @@
+package secret
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+func testKeyring(t *testing.T, ids []string, keys [][]byte) *Keyring {
+	k, err := NewKeyring(ids, keys)
+	if err != nil {
+		t.Fatalf("could not create keyring: %v", err)
+	}
+	return k
+}
+
+func testKey(t *testing.T) []byte {
+	k, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return k
+}
+
+func TestSealOpen(t *testing.T) {
+	k := testKeyring(t, []string{"a"}, [][]byte{testKey(t)})
+
+	tt := map[string]string{
+		"password": "hunter2",
+		"empty":    "",
+		"colons":   "user:pass:word",
+	}
+
+	for name, plaintext := range tt {
+		t.Run(name, func(t *testing.T) {
+			sealed, err := k.Seal(plaintext)
+			if err != nil {
+				t.Fatalf("could not seal: %v", err)
+			}
+
+			if !IsSealed(sealed) {
+				t.Fatalf("expected sealed value, got: %v", sealed)
+			}
+
+			if plaintext != "" && strings.Contains(sealed, plaintext) {
+				t.Fatalf("sealed value contains the plaintext: %v", sealed)
+			}
+
+			got, err := k.Open(sealed)
+			if err != nil {
+				t.Fatalf("could not open: %v", err)
+			}
+
+			if got != plaintext {
+				t.Fatalf("expected: %q, got: %q", plaintext, got)
+			}
+		})
+	}
+}
+
+func TestRewrap(t *testing.T) {
+	oldKey, newKey := testKey(t), testKey(t)
+	old := testKeyring(t, []string{"old"}, [][]byte{oldKey})
+	rotated := testKeyring(t, []string{"new", "old"}, [][]byte{newKey, oldKey})
+	current := testKeyring(t, []string{"new"}, [][]byte{newKey})
+
+	sealed, err := old.Seal("hunter2")
+	if err != nil {
+		t.Fatalf("could not seal: %v", err)
+	}
+
+	if _, err := current.Open(sealed); err != errUnknownKey {
+		t.Fatalf("expected: %v, got: %v", errUnknownKey, err)
+	}
+
+	rewrapped, changed, err := rotated.Rewrap(sealed)
+	if err != nil {
+		t.Fatalf("could not rewrap: %v", err)
+	}
+	if !changed {
+		t.Fatal("expected value to change")
+	}
+
+	// the encrypted value is untouched, only the data key is re-encrypted.
+	if sealed[strings.LastIndex(sealed, ":"):] != rewrapped[strings.LastIndex(rewrapped, ":"):] {
+		t.Fatalf("expected same ciphertext, got: %v and %v", sealed, rewrapped)
+	}
+
+	got, err := current.Open(rewrapped)
+	if err != nil {
+		t.Fatalf("could not open rewrapped value: %v", err)
+	}
+	if got != "hunter2" {
+		t.Fatalf("expected: hunter2, got: %v", got)
+	}
+
+	_, changed, err = rotated.Rewrap(rewrapped)
+	if err != nil {
+		t.Fatalf("could not rewrap: %v", err)
+	}
+	if changed {
+		t.Fatal("expected value sealed with the primary key to be unchanged")
+	}
+}
+
+func TestLoadKeyring(t *testing.T) {
+	a, b := testKey(t), testKey(t)
+
+	tt := map[string]struct {
+		content string
+		err     bool
+	}{
+		"two keys": {
+			content: fmt.Sprintf(
+				"# primary key first\n2:%v\n\n1:%v\n",
+				base64.StdEncoding.EncodeToString(a),
+				base64.StdEncoding.EncodeToString(b),
+			),
+		},
+		"empty":         {content: "# nothing here\n", err: true},
+		"missing id":    {content: base64.StdEncoding.EncodeToString(a), err: true},
+		"short key":     {content: "1:" + base64.StdEncoding.EncodeToString(a[:16]), err: true},
+		"invalid key":   {content: "1:not base64!", err: true},
+		"duplicate sep": {content: "1:2:" + base64.StdEncoding.EncodeToString(a), err: true},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			f, err := ioutil.TempFile("", "keyring")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.Remove(f.Name())
+
+			if _, err := f.WriteString(tc.content); err != nil {
+				t.Fatal(err)
+			}
+			f.Close()
+
+			k, err := LoadKeyring(f.Name())
+			if err != nil {
+				if !tc.err {
+					t.Fatalf("expected no error, got: %v", err)
+				}
+				return
+			}
+			if tc.err {
+				t.Fatal("expected an error, got: nil")
+			}
+
+			sealed, err := k.Seal("value")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasPrefix(sealed, prefix+"2:") {
+				t.Fatalf("expected value sealed with the first key, got: %v", sealed)
+			}
+		})
+	}
+}
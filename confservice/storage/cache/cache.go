@@ -0,0 +1,297 @@
+// Package cache implements a read-through cache in front of a storage.Service.
+//
+// The items, item types, modules, item modules and module dependencies read
+// from the storage are kept in a least recently used cache whose entries expire
+// after a time to live. Every write through the cache empties it, as a single
+// write may change many of the cached lists, and with a Notifier the other
+// instances of the service are told to empty theirs as well. Releases and
+// promotions are read from the storage every time.
+package cache
+
+import (
+	"container/list"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/Glorforidor/conmansys/confservice/storage"
+)
+
+// Channel is the channel the instances of the cache notify each other on.
+const Channel = "conf_cache"
+
+// The defaults of a cache made without options.
+const (
+	DefaultSize = 1000
+	DefaultTTL  = 30 * time.Second
+)
+
+// Notifier sends and receives the notifications which keep the caches of
+// several instances in sync. The postgres storage is a Notifier through
+// LISTEN/NOTIFY.
+type Notifier interface {
+	Notify(channel, payload string) error
+	// Listen calls f with the payload of every notification on the channel,
+	// or with an empty payload when notifications may have been missed.
+	Listen(channel string, f func(payload string)) error
+}
+
+// Stats are the counters of a cache.
+type Stats struct {
+	Hits    uint64 `json:"hits"`
+	Misses  uint64 `json:"misses"`
+	Entries int    `json:"entries"`
+}
+
+// Cache is a storage.Service which caches the reads of another.
+type Cache struct {
+	storage.Service
+
+	size     int
+	ttl      time.Duration
+	notifier Notifier
+	// id tells the notifications of this cache from those of the others.
+	id  string
+	now func() time.Time
+
+	mu      sync.Mutex
+	lru     *list.List
+	entries map[string]*list.Element
+	// gen is incremented on every purge, so a value loaded before a purge is
+	// not cached after it.
+	gen    uint64
+	hits   uint64
+	misses uint64
+}
+
+type entry struct {
+	key     string
+	value   interface{}
+	expires time.Time
+}
+
+// Option configures a Cache.
+type Option func(*Cache)
+
+// WithSize sets the number of entries kept in the cache. A size less than one
+// uses DefaultSize.
+func WithSize(n int) Option {
+	return func(c *Cache) {
+		if n > 0 {
+			c.size = n
+		}
+	}
+}
+
+// WithTTL sets how long an entry is used before it is read again. A ttl less
+// than or equal to zero uses DefaultTTL.
+func WithTTL(ttl time.Duration) Option {
+	return func(c *Cache) {
+		if ttl > 0 {
+			c.ttl = ttl
+		}
+	}
+}
+
+// WithNotifier makes the cache tell the other instances about its writes and
+// empty itself on theirs.
+func WithNotifier(n Notifier) Option {
+	return func(c *Cache) {
+		c.notifier = n
+	}
+}
+
+// New returns a cache in front of s. It returns an error when it can not
+// listen for the notifications of the other instances.
+func New(s storage.Service, opts ...Option) (*Cache, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return nil, fmt.Errorf("could not generate cache id: %v", err)
+	}
+
+	c := &Cache{
+		Service: s,
+		size:    DefaultSize,
+		ttl:     DefaultTTL,
+		id:      hex.EncodeToString(b),
+		now:     time.Now,
+		lru:     list.New(),
+		entries: make(map[string]*list.Element),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if c.notifier != nil {
+		err := c.notifier.Listen(Channel, func(payload string) {
+			if payload != c.id {
+				c.Purge()
+			}
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+// Stats returns the hits and misses of the cache so far and the number of
+// entries in it.
+func (c *Cache) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return Stats{Hits: c.hits, Misses: c.misses, Entries: c.lru.Len()}
+}
+
+// Purge empties the cache.
+func (c *Cache) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lru.Init()
+	c.entries = make(map[string]*list.Element)
+	c.gen++
+}
+
+// get returns the value cached under key, or loads and caches it. Errors are
+// not cached.
+func (c *Cache) get(key string, load func() (interface{}, error)) (interface{}, error) {
+	c.mu.Lock()
+	if el, ok := c.entries[key]; ok {
+		e := el.Value.(*entry)
+		if c.now().Before(e.expires) {
+			c.lru.MoveToFront(el)
+			c.hits++
+			c.mu.Unlock()
+			return e.value, nil
+		}
+		c.lru.Remove(el)
+		delete(c.entries, key)
+	}
+	c.misses++
+	gen := c.gen
+	c.mu.Unlock()
+
+	v, err := load()
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.gen != gen {
+		return v, nil
+	}
+	if el, ok := c.entries[key]; ok {
+		// loaded by another caller meanwhile
+		c.lru.Remove(el)
+	}
+	c.entries[key] = c.lru.PushFront(&entry{key: key, value: v, expires: c.now().Add(c.ttl)})
+	for c.lru.Len() > c.size {
+		el := c.lru.Back()
+		c.lru.Remove(el)
+		delete(c.entries, el.Value.(*entry).key)
+	}
+
+	return v, nil
+}
+
+// invalidate empties the cache after a write and tells the other instances to
+// do the same. The write has happened, so a failed notification is only
+// logged; the other instances catch up when their entries expire.
+func (c *Cache) invalidate() {
+	c.Purge()
+	if c.notifier == nil {
+		return
+	}
+	if err := c.notifier.Notify(Channel, c.id); err != nil {
+		slog.Error("could not notify the other caches", "error", err)
+	}
+}
+
+// The values returned by the storage are shared by every reader of the cache,
+// so the readers get copies which they can change.
+
+func copyLabels(l map[string]string) map[string]string {
+	if l == nil {
+		return nil
+	}
+	c := make(map[string]string, len(l))
+	for k, v := range l {
+		c[k] = v
+	}
+	return c
+}
+
+func copyMetadata(m storage.Metadata) storage.Metadata {
+	if m.Annotations != nil {
+		a := make(map[string]interface{}, len(m.Annotations))
+		for k, v := range m.Annotations {
+			a[k] = v
+		}
+		m.Annotations = a
+	}
+	return m
+}
+
+func copyItem(i *storage.Item) *storage.Item {
+	if i == nil {
+		return nil
+	}
+	c := *i
+	c.Labels = copyLabels(i.Labels)
+	c.Metadata = copyMetadata(i.Metadata)
+	return &c
+}
+
+func copyItemType(t *storage.ItemType) *storage.ItemType {
+	if t == nil {
+		return nil
+	}
+	c := *t
+	if t.Enum != nil {
+		c.Enum = append([]string{}, t.Enum...)
+	}
+	if t.Min != nil {
+		min := *t.Min
+		c.Min = &min
+	}
+	if t.Max != nil {
+		max := *t.Max
+		c.Max = &max
+	}
+	return &c
+}
+
+func copyModule(m *storage.Module) *storage.Module {
+	if m == nil {
+		return nil
+	}
+	c := *m
+	c.Labels = copyLabels(m.Labels)
+	c.Metadata = copyMetadata(m.Metadata)
+	return &c
+}
+
+func copyItemModule(im *storage.ItemModule) *storage.ItemModule {
+	if im == nil {
+		return nil
+	}
+	c := *im
+	return &c
+}
+
+func copyModuleDependencies(mds []*storage.ModuleDependency) []*storage.ModuleDependency {
+	if mds == nil {
+		return nil
+	}
+	c := make([]*storage.ModuleDependency, len(mds))
+	for i, md := range mds {
+		d := *md
+		c[i] = &d
+	}
+	return c
+}
@@ -0,0 +1,319 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Glorforidor/conmansys/confservice/storage"
+)
+
+// counting is a storage which counts the reads of the items and modules. The
+// methods which are not needed are left to the embedded interface.
+type counting struct {
+	storage.Service
+
+	mu    sync.Mutex
+	reads map[string]int
+	items []*storage.Item
+	err   error
+}
+
+func newCounting() *counting {
+	return &counting{
+		reads: make(map[string]int),
+		items: []*storage.Item{
+			{ID: 1, Value: "window", Labels: map[string]string{"team": "billing"}},
+			{ID: 2, Value: "refund"},
+		},
+	}
+}
+
+func (s *counting) read(name string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.reads[name]
+}
+
+func (s *counting) GetItem(_ context.Context, id int64) (*storage.Item, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reads["item"]++
+	for _, i := range s.items {
+		if i.ID == id {
+			return i, nil
+		}
+	}
+	return nil, s.err
+}
+
+func (s *counting) GetItems(_ context.Context) ([]*storage.Item, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reads["items"]++
+	if s.err != nil {
+		return nil, s.err
+	}
+	return s.items, nil
+}
+
+func (s *counting) CreateItem(_ context.Context, item *storage.Item) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	item.ID = int64(len(s.items) + 1)
+	s.items = append(s.items, item)
+	return item.ID, nil
+}
+
+func (s *counting) GetModules(_ context.Context) ([]*storage.Module, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reads["modules"]++
+	return []*storage.Module{{ID: 1, Value: "billing"}}, nil
+}
+
+// notifier delivers notifications to every cache listening on it, like
+// Postgres does to every connection.
+type notifier struct {
+	mu        sync.Mutex
+	listeners []func(string)
+	sent      []string
+}
+
+func (n *notifier) Notify(channel, payload string) error {
+	n.mu.Lock()
+	n.sent = append(n.sent, payload)
+	listeners := n.listeners
+	n.mu.Unlock()
+
+	for _, f := range listeners {
+		f(payload)
+	}
+	return nil
+}
+
+func (n *notifier) Listen(channel string, f func(payload string)) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.listeners = append(n.listeners, f)
+	return nil
+}
+
+func TestReadThrough(t *testing.T) {
+	s := newCounting()
+	c, err := New(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 3; i++ {
+		items, err := c.GetItems(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(items) != 2 {
+			t.Fatalf("expected 2 items, got: %v", len(items))
+		}
+	}
+	if n := s.read("items"); n != 1 {
+		t.Fatalf("expected the items to be read once, got: %v", n)
+	}
+
+	// missing rows are cached as well
+	for i := 0; i < 2; i++ {
+		if item, err := c.GetItem(context.Background(), 42); err != nil || item != nil {
+			t.Fatalf("expected no item and no error, got: %v, %v", item, err)
+		}
+	}
+	if n := s.read("item"); n != 1 {
+		t.Fatalf("expected the missing item to be read once, got: %v", n)
+	}
+
+	want := Stats{Hits: 3, Misses: 2, Entries: 2}
+	if got := c.Stats(); got != want {
+		t.Fatalf("expected stats: %+v, got: %+v", want, got)
+	}
+}
+
+func TestCopies(t *testing.T) {
+	s := newCounting()
+	c, err := New(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	item, err := c.GetItem(context.Background(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	item.Value = "changed"
+	item.Labels["team"] = "changed"
+
+	item, err = c.GetItem(context.Background(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item.Value != "window" || item.Labels["team"] != "billing" {
+		t.Fatalf("expected the cached item to be unchanged, got: %+v", item)
+	}
+}
+
+func TestErrorsNotCached(t *testing.T) {
+	s := newCounting()
+	s.err = errors.New("connection refused")
+	c, err := New(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := c.GetItems(context.Background()); err == nil {
+			t.Fatal("expected an error")
+		}
+	}
+	if n := s.read("items"); n != 2 {
+		t.Fatalf("expected the storage to be read on every error, got: %v", n)
+	}
+}
+
+func TestTTL(t *testing.T) {
+	s := newCounting()
+	c, err := New(s, WithTTL(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	c.now = func() time.Time { return now }
+
+	c.GetItems(context.Background())
+	now = now.Add(59 * time.Second)
+	c.GetItems(context.Background())
+	if n := s.read("items"); n != 1 {
+		t.Fatalf("expected the items to be cached within the ttl, got %v reads", n)
+	}
+
+	now = now.Add(time.Second)
+	c.GetItems(context.Background())
+	if n := s.read("items"); n != 2 {
+		t.Fatalf("expected the items to expire after the ttl, got %v reads", n)
+	}
+}
+
+func TestLRU(t *testing.T) {
+	s := newCounting()
+	c, err := New(s, WithSize(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c.GetItem(context.Background(), 1)
+	c.GetItem(context.Background(), 2)
+	// item 1 is used, so item 2 is the least recently used
+	c.GetItem(context.Background(), 1)
+	c.GetItems(context.Background())
+	if got := c.Stats().Entries; got != 2 {
+		t.Fatalf("expected 2 entries, got: %v", got)
+	}
+
+	c.GetItem(context.Background(), 1)
+	if n := s.read("item"); n != 2 {
+		t.Fatalf("expected item 1 to be kept, got %v reads", n)
+	}
+	c.GetItem(context.Background(), 2)
+	if n := s.read("item"); n != 3 {
+		t.Fatalf("expected item 2 to be evicted, got %v reads", n)
+	}
+}
+
+func TestInvalidate(t *testing.T) {
+	n := &notifier{}
+	s := newCounting()
+	c, err := New(s, WithNotifier(n))
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := New(s, WithNotifier(n))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c.GetItems(context.Background())
+	c.GetModules(context.Background())
+	other.GetItems(context.Background())
+	if got := s.read("items"); got != 2 {
+		t.Fatalf("expected both caches to read the items, got: %v", got)
+	}
+
+	if _, err := c.CreateItem(context.Background(), &storage.Item{Value: "limit"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(n.sent) != 1 {
+		t.Fatalf("expected the write to be notified, got: %v", n.sent)
+	}
+
+	for _, cache := range []*Cache{c, other} {
+		items, err := cache.GetItems(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(items) != 3 {
+			t.Fatalf("expected the created item, got: %v items", len(items))
+		}
+	}
+	if got := s.read("items"); got != 4 {
+		t.Fatalf("expected both caches to read the items again, got: %v", got)
+	}
+
+	// a write purges every entry, not only those of the written table
+	c.GetModules(context.Background())
+	if got := s.read("modules"); got != 2 {
+		t.Fatalf("expected the modules to be read again, got: %v", got)
+	}
+
+	// an empty payload is sent when notifications may have been missed
+	other.GetItems(context.Background())
+	n.Notify(Channel, "")
+	other.GetItems(context.Background())
+	if got := s.read("items"); got != 5 {
+		t.Fatalf("expected the items to be read after a reconnect, got: %v", got)
+	}
+}
+
+// blocking is a storage whose GetItems waits until it is released.
+type blocking struct {
+	*counting
+	started chan struct{}
+	release chan struct{}
+}
+
+func (s *blocking) GetItems(_ context.Context) ([]*storage.Item, error) {
+	items, err := s.counting.GetItems(context.Background())
+	close(s.started)
+	<-s.release
+	return items, err
+}
+
+func TestPurgeDuringLoad(t *testing.T) {
+	s := &blocking{counting: newCounting(), started: make(chan struct{}), release: make(chan struct{})}
+	c, err := New(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		c.GetItems(context.Background())
+		close(done)
+	}()
+
+	<-s.started
+	c.Purge()
+	close(s.release)
+	<-done
+
+	if got := c.Stats().Entries; got != 0 {
+		t.Fatalf("expected the items loaded before the purge to not be cached, got %v entries", got)
+	}
+}
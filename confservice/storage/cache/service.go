@@ -0,0 +1,265 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Glorforidor/conmansys/confservice/storage"
+)
+
+// GetItem returns the item with the given id.
+func (c *Cache) GetItem(ctx context.Context, id int64) (*storage.Item, error) {
+	v, err := c.get(fmt.Sprintf("item:%v", id), func() (interface{}, error) {
+		return c.Service.GetItem(ctx, id)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return copyItem(v.(*storage.Item)), nil
+}
+
+// GetItems returns every item.
+func (c *Cache) GetItems(ctx context.Context) ([]*storage.Item, error) {
+	v, err := c.get("items", func() (interface{}, error) {
+		return c.Service.GetItems(ctx)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	items := v.([]*storage.Item)
+	if items == nil {
+		return nil, nil
+	}
+	cp := make([]*storage.Item, len(items))
+	for i, item := range items {
+		cp[i] = copyItem(item)
+	}
+	return cp, nil
+}
+
+func (c *Cache) CreateItem(ctx context.Context, item *storage.Item) (int64, error) {
+	defer c.invalidate()
+	return c.Service.CreateItem(ctx, item)
+}
+
+func (c *Cache) UpdateItem(ctx context.Context, item *storage.Item) (int64, error) {
+	defer c.invalidate()
+	return c.Service.UpdateItem(ctx, item)
+}
+
+func (c *Cache) SetItemLabels(ctx context.Context, id int64, labels map[string]string) (int64, error) {
+	defer c.invalidate()
+	return c.Service.SetItemLabels(ctx, id, labels)
+}
+
+func (c *Cache) DeleteItem(ctx context.Context, id int64) (int64, error) {
+	defer c.invalidate()
+	return c.Service.DeleteItem(ctx, id)
+}
+
+// GetItemType returns the item type with the given name.
+func (c *Cache) GetItemType(ctx context.Context, name string) (*storage.ItemType, error) {
+	v, err := c.get("item_type:"+name, func() (interface{}, error) {
+		return c.Service.GetItemType(ctx, name)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return copyItemType(v.(*storage.ItemType)), nil
+}
+
+// GetItemTypes returns every item type.
+func (c *Cache) GetItemTypes(ctx context.Context) ([]*storage.ItemType, error) {
+	v, err := c.get("item_types", func() (interface{}, error) {
+		return c.Service.GetItemTypes(ctx)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	types := v.([]*storage.ItemType)
+	if types == nil {
+		return nil, nil
+	}
+	cp := make([]*storage.ItemType, len(types))
+	for i, t := range types {
+		cp[i] = copyItemType(t)
+	}
+	return cp, nil
+}
+
+func (c *Cache) CreateItemType(ctx context.Context, t *storage.ItemType) error {
+	defer c.invalidate()
+	return c.Service.CreateItemType(ctx, t)
+}
+
+func (c *Cache) UpdateItemType(ctx context.Context, t *storage.ItemType) (int64, error) {
+	defer c.invalidate()
+	return c.Service.UpdateItemType(ctx, t)
+}
+
+func (c *Cache) DeleteItemType(ctx context.Context, name string) (int64, error) {
+	defer c.invalidate()
+	return c.Service.DeleteItemType(ctx, name)
+}
+
+// GetModule returns the module with the given id.
+func (c *Cache) GetModule(ctx context.Context, id int64) (*storage.Module, error) {
+	v, err := c.get(fmt.Sprintf("module:%v", id), func() (interface{}, error) {
+		return c.Service.GetModule(ctx, id)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return copyModule(v.(*storage.Module)), nil
+}
+
+// GetModules returns every module.
+func (c *Cache) GetModules(ctx context.Context) ([]*storage.Module, error) {
+	v, err := c.get("modules", func() (interface{}, error) {
+		return c.Service.GetModules(ctx)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	modules := v.([]*storage.Module)
+	if modules == nil {
+		return nil, nil
+	}
+	cp := make([]*storage.Module, len(modules))
+	for i, m := range modules {
+		cp[i] = copyModule(m)
+	}
+	return cp, nil
+}
+
+func (c *Cache) CreateModule(ctx context.Context, module *storage.Module) (int64, error) {
+	defer c.invalidate()
+	return c.Service.CreateModule(ctx, module)
+}
+
+func (c *Cache) UpdateModule(ctx context.Context, module *storage.Module) (int64, error) {
+	defer c.invalidate()
+	return c.Service.UpdateModule(ctx, module)
+}
+
+func (c *Cache) SetModuleLabels(ctx context.Context, id int64, labels map[string]string) (int64, error) {
+	defer c.invalidate()
+	return c.Service.SetModuleLabels(ctx, id, labels)
+}
+
+func (c *Cache) DeleteModule(ctx context.Context, id int64) (int64, error) {
+	defer c.invalidate()
+	return c.Service.DeleteModule(ctx, id)
+}
+
+func (c *Cache) DeleteModuleCascade(ctx context.Context, id int64) (int64, error) {
+	defer c.invalidate()
+	return c.Service.DeleteModuleCascade(ctx, id)
+}
+
+func (c *Cache) CloneModule(ctx context.Context, id int64, version string, rewire bool) (*storage.ModuleClone, error) {
+	defer c.invalidate()
+	return c.Service.CloneModule(ctx, id, version, rewire)
+}
+
+// GetItemModule returns the item module with the given id.
+func (c *Cache) GetItemModule(ctx context.Context, id int64) (*storage.ItemModule, error) {
+	v, err := c.get(fmt.Sprintf("item_module:%v", id), func() (interface{}, error) {
+		return c.Service.GetItemModule(ctx, id)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return copyItemModule(v.(*storage.ItemModule)), nil
+}
+
+// GetItemModules returns every item module.
+func (c *Cache) GetItemModules(ctx context.Context) ([]*storage.ItemModule, error) {
+	v, err := c.get("item_modules", func() (interface{}, error) {
+		return c.Service.GetItemModules(ctx)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ims := v.([]*storage.ItemModule)
+	if ims == nil {
+		return nil, nil
+	}
+	cp := make([]*storage.ItemModule, len(ims))
+	for i, im := range ims {
+		cp[i] = copyItemModule(im)
+	}
+	return cp, nil
+}
+
+func (c *Cache) CreateItemModule(ctx context.Context, itemID, moduleID int64) (int64, error) {
+	defer c.invalidate()
+	return c.Service.CreateItemModule(ctx, itemID, moduleID)
+}
+
+func (c *Cache) DeleteItemModule(ctx context.Context, id int64) (int64, error) {
+	defer c.invalidate()
+	return c.Service.DeleteItemModule(ctx, id)
+}
+
+func (c *Cache) Import(ctx context.Context, rows []*storage.ImportRow) error {
+	defer c.invalidate()
+	return c.Service.Import(ctx, rows)
+}
+
+// GetModuleDependencies returns every module dependency.
+func (c *Cache) GetModuleDependencies(ctx context.Context) ([]*storage.ModuleDependency, error) {
+	v, err := c.get("module_dependencies", func() (interface{}, error) {
+		return c.Service.GetModuleDependencies(ctx)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return copyModuleDependencies(v.([]*storage.ModuleDependency)), nil
+}
+
+// GetModuleDependenciesByDependentID returns the dependencies of the dependent.
+func (c *Cache) GetModuleDependenciesByDependentID(ctx context.Context, dependentID int64) ([]*storage.ModuleDependency, error) {
+	v, err := c.get(fmt.Sprintf("dependent:%v", dependentID), func() (interface{}, error) {
+		return c.Service.GetModuleDependenciesByDependentID(ctx, dependentID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return copyModuleDependencies(v.([]*storage.ModuleDependency)), nil
+}
+
+// GetModuleDependenciesByDependeeID returns the dependencies on the dependee.
+func (c *Cache) GetModuleDependenciesByDependeeID(ctx context.Context, dependeeID int64) ([]*storage.ModuleDependency, error) {
+	v, err := c.get(fmt.Sprintf("dependee:%v", dependeeID), func() (interface{}, error) {
+		return c.Service.GetModuleDependenciesByDependeeID(ctx, dependeeID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return copyModuleDependencies(v.([]*storage.ModuleDependency)), nil
+}
+
+func (c *Cache) CreateModuleDependency(ctx context.Context, md *storage.ModuleDependency) error {
+	defer c.invalidate()
+	return c.Service.CreateModuleDependency(ctx, md)
+}
+
+func (c *Cache) DeleteModuleDependency(ctx context.Context, dependentID, dependeeID int64) (int64, error) {
+	defer c.invalidate()
+	return c.Service.DeleteModuleDependency(ctx, dependentID, dependeeID)
+}
+
+func (c *Cache) DeleteModuleDependencyByDependentID(ctx context.Context, id int64) (int64, error) {
+	defer c.invalidate()
+	return c.Service.DeleteModuleDependencyByDependentID(ctx, id)
+}
+
+func (c *Cache) DeleteModuleDependencyByDependeeID(ctx context.Context, id int64) (int64, error) {
+	defer c.invalidate()
+	return c.Service.DeleteModuleDependencyByDependeeID(ctx, id)
+}
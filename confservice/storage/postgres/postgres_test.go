@@ -3,36 +3,70 @@ package postgres
 // TODO: rename some "want" variables since they don't reflect their intend.
 
 import (
+	"context"
 	"os"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/Glorforidor/conmansys/confservice/secret"
 	"github.com/Glorforidor/conmansys/confservice/storage"
 )
 
 var p *postgres
 
 const dataschema = `
+DROP TABLE IF EXISTS conf_promotion;
+DROP TABLE IF EXISTS conf_release;
 DROP TABLE IF EXISTS conf_item_module;
 DROP TABLE IF EXISTS conf_item;
-DROP TABLE IF EXISTS conf_module;
 DROP TABLE IF EXISTS conf_module_dependency;
+DROP TABLE IF EXISTS conf_module;
+DROP TABLE IF EXISTS conf_item_type;
 
 CREATE TABLE conf_item(
 	conf_item_id SERIAL PRIMARY KEY,
 	conf_item_value TEXT NOT NULL,
 	conf_item_type TEXT NOT NULL,
-	conf_item_version TEXT NOT NULL
+	conf_item_version TEXT NOT NULL,
+	conf_item_secret BOOLEAN NOT NULL DEFAULT false,
+	conf_item_labels JSONB NOT NULL DEFAULT '{}',
+	conf_item_description TEXT NOT NULL DEFAULT '',
+	conf_item_owner TEXT NOT NULL DEFAULT '',
+	conf_item_homepage TEXT NOT NULL DEFAULT '',
+	conf_item_annotations JSONB NOT NULL DEFAULT '{}',
+	conf_item_created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	conf_item_updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE conf_item_type(
+	conf_item_type_name TEXT PRIMARY KEY,
+	conf_item_type_pattern TEXT NOT NULL DEFAULT '',
+	conf_item_type_enum TEXT[],
+	conf_item_type_min BIGINT,
+	conf_item_type_max BIGINT
 );
 
 CREATE TABLE conf_module(
 	conf_module_id SERIAL PRIMARY KEY,
 	conf_module_value TEXT NOT NULL,
-	conf_module_version TEXT NOT NULL
+	conf_module_version TEXT NOT NULL,
+	conf_module_labels JSONB NOT NULL DEFAULT '{}',
+	conf_module_description TEXT NOT NULL DEFAULT '',
+	conf_module_owner TEXT NOT NULL DEFAULT '',
+	conf_module_homepage TEXT NOT NULL DEFAULT '',
+	conf_module_annotations JSONB NOT NULL DEFAULT '{}',
+	conf_module_created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	conf_module_updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
 );
 
 CREATE TABLE conf_module_dependency(
 	dependent int,
 	dependee int,
+	kind TEXT NOT NULL DEFAULT 'required',
+	FOREIGN KEY (dependent) REFERENCES conf_module (conf_module_id),
+	FOREIGN KEY (dependee) REFERENCES conf_module (conf_module_id),
 	PRIMARY KEY (dependent, dependee)
 );
 
@@ -43,6 +77,29 @@ CREATE TABLE conf_item_module(
 	FOREIGN KEY (conf_item_id) REFERENCES conf_item(conf_item_id) ON DELETE CASCADE,
 	FOREIGN KEY (conf_module_id) REFERENCES conf_module(conf_module_id) ON DELETE CASCADE
 );
+
+CREATE TABLE conf_release(
+	conf_release_name TEXT PRIMARY KEY,
+	conf_release_modules JSONB NOT NULL,
+	conf_release_optional BOOLEAN NOT NULL DEFAULT false,
+	conf_release_closure JSONB NOT NULL,
+	conf_release_created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE conf_promotion(
+	conf_promotion_id SERIAL PRIMARY KEY,
+	conf_promotion_release TEXT NOT NULL REFERENCES conf_release (conf_release_name),
+	conf_promotion_environment TEXT NOT NULL,
+	conf_promotion_status TEXT NOT NULL CHECK (conf_promotion_status IN ('pending', 'promoted')),
+	conf_promotion_requested_by TEXT NOT NULL,
+	conf_promotion_requested_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	conf_promotion_approved_by TEXT NOT NULL DEFAULT '',
+	conf_promotion_promoted_at TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX conf_promotion_pending
+	ON conf_promotion (conf_promotion_release, conf_promotion_environment)
+	WHERE conf_promotion_status = 'pending';
 `
 
 // there must be a better way?
@@ -124,7 +181,493 @@ func TestNew(t *testing.T) {
 	}
 }
 
+func TestItemTypes(t *testing.T) {
+	min, max := int64(1), int64(10)
+	want := &storage.ItemType{
+		Name:    "posty_type",
+		Pattern: "^[a-z]+$",
+		Enum:    []string{"a", "b"},
+		Min:     &min,
+		Max:     &max,
+	}
+
+	if err := p.CreateItemType(context.Background(), want); err != nil {
+		t.Fatalf("could not create item type: %v", err)
+	}
+
+	got, err := p.GetItemType(context.Background(), want.Name)
+	if err != nil {
+		t.Fatalf("could not get item type %q: %v", want.Name, err)
+	}
+	if got == nil {
+		t.Fatalf("expected item type %q, got: nil", want.Name)
+	}
+	if got.Pattern != want.Pattern || len(got.Enum) != len(want.Enum) {
+		t.Errorf("expected: %v, got: %v", want, got)
+	}
+	if got.Min == nil || *got.Min != min || got.Max == nil || *got.Max != max {
+		t.Errorf("expected range [%v, %v], got: [%v, %v]", min, max, got.Min, got.Max)
+	}
+
+	want.Pattern = ""
+	want.Enum = nil
+	want.Max = nil
+	row, err := p.UpdateItemType(context.Background(), want)
+	if err != nil {
+		t.Fatalf("could not update item type: %v", err)
+	}
+	if row != 1 {
+		t.Errorf("expected: 1, got: %v", row)
+	}
+
+	got, err = p.GetItemType(context.Background(), want.Name)
+	if err != nil {
+		t.Fatalf("could not get item type %q: %v", want.Name, err)
+	}
+	if got.Pattern != "" || len(got.Enum) != 0 || got.Max != nil {
+		t.Errorf("expected validators to be cleared, got: %v", got)
+	}
+
+	types, err := p.GetItemTypes(context.Background())
+	if err != nil {
+		t.Fatalf("could not get item types: %v", err)
+	}
+	if len(types) != 1 {
+		t.Errorf("expected: 1 item type, got: %v", len(types))
+	}
+
+	row, err = p.DeleteItemType(context.Background(), want.Name)
+	if err != nil {
+		t.Fatalf("could not delete item type: %v", err)
+	}
+	if row != 1 {
+		t.Errorf("expected: 1, got: %v", row)
+	}
+
+	got, err = p.GetItemType(context.Background(), want.Name)
+	if err != nil {
+		t.Fatalf("could not get item type %q: %v", want.Name, err)
+	}
+	if got != nil {
+		t.Errorf("expected nil, got: %v", got)
+	}
+}
+
+func testKey(t *testing.T) []byte {
+	k, err := secret.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return k
+}
+
+func testKeyring(t *testing.T, ids []string, keys [][]byte) *secret.Keyring {
+	k, err := secret.NewKeyring(ids, keys)
+	if err != nil {
+		t.Fatalf("could not create keyring: %v", err)
+	}
+	return k
+}
+
+func testRawValue(t *testing.T, id int64) string {
+	var v string
+	q := "SELECT conf_item_value FROM conf_item WHERE conf_item_id = $1"
+	if err := p.db.QueryRow(q, id).Scan(&v); err != nil {
+		t.Fatalf("could not get raw value of item with id %v: %v", id, err)
+	}
+	return v
+}
+
+func TestSecretItems(t *testing.T) {
+	defer p.UseKeyring(nil)
+
+	item := &storage.Item{Value: "hunter2", Type: "password", Version: "0.0.1", Secret: true}
+
+	p.UseKeyring(nil)
+	if _, err := p.CreateItem(context.Background(), item); err != storage.ErrNoKeyring {
+		t.Fatalf("expected: %v, got: %v", storage.ErrNoKeyring, err)
+	}
+
+	oldKey, newKey := testKey(t), testKey(t)
+	p.UseKeyring(testKeyring(t, []string{"old"}, [][]byte{oldKey}))
+
+	id, err := p.CreateItem(context.Background(), item)
+	if err != nil {
+		t.Fatalf("could not create secret item: %v", err)
+	}
+
+	raw := testRawValue(t, id)
+	if !strings.HasPrefix(raw, "enc:v1:old:") || strings.Contains(raw, item.Value) {
+		t.Fatalf("expected value sealed with key old, got: %v", raw)
+	}
+
+	got := testGetItem(t, id)
+	if got.Value != item.Value || !got.Secret {
+		t.Fatalf("expected: %v, got: %v", item, got)
+	}
+
+	// the new key is primary, but the old key is kept to open existing values
+	p.UseKeyring(testKeyring(t, []string{"new", "old"}, [][]byte{newKey, oldKey}))
+
+	n, err := p.RotateKeys(context.Background())
+	if err != nil {
+		t.Fatalf("could not rotate keys: %v", err)
+	}
+	if n != 1 {
+		t.Errorf("expected: 1 rotated item, got: %v", n)
+	}
+
+	raw = testRawValue(t, id)
+	if !strings.HasPrefix(raw, "enc:v1:new:") {
+		t.Fatalf("expected value sealed with key new, got: %v", raw)
+	}
+
+	// after rotation the old key is no longer needed
+	p.UseKeyring(testKeyring(t, []string{"new"}, [][]byte{newKey}))
+
+	got = testGetItem(t, id)
+	if got.Value != item.Value {
+		t.Fatalf("expected: %v, got: %v", item.Value, got.Value)
+	}
+
+	if row := testDeleteItem(t, id); row != 1 {
+		t.Errorf("expected: 1, got: %v", row)
+	}
+}
+
+func TestReleases(t *testing.T) {
+	defer p.UseKeyring(nil)
+
+	key := testKey(t)
+	p.UseKeyring(testKeyring(t, []string{"old"}, [][]byte{key}))
+
+	release := &storage.Release{
+		Name:    "2026.10-prod",
+		Modules: []int64{1},
+		Closure: []*storage.ReleaseModule{
+			{
+				ID: 1, Value: "billing", Version: "0.0.1",
+				Items: []*storage.Item{
+					{ID: 1, Value: "payment_window", Type: "config", Version: "1.0.0"},
+					{ID: 2, Value: "hunter2", Type: "password", Version: "0.0.1", Secret: true},
+					// a value which only looks sealed is sealed all the same
+					{ID: 3, Value: "enc:v1:hunter3", Type: "password", Version: "0.0.1", Secret: true},
+				},
+			},
+		},
+	}
+
+	if err := p.CreateRelease(context.Background(), release); err != nil {
+		t.Fatalf("could not create release: %v", err)
+	}
+	if release.CreatedAt.IsZero() {
+		t.Error("expected the creation time to be set")
+	}
+	if release.Closure[0].Items[1].Value != "hunter2" {
+		t.Errorf("expected the release to keep the open value, got: %v", release.Closure[0].Items[1].Value)
+	}
+
+	if err := p.CreateRelease(context.Background(), release); err != storage.ErrExists {
+		t.Fatalf("expected: %v, got: %v", storage.ErrExists, err)
+	}
+
+	var raw string
+	q := "SELECT conf_release_closure::text FROM conf_release WHERE conf_release_name = $1"
+	if err := p.db.QueryRow(q, release.Name).Scan(&raw); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(raw, "hunter2") || strings.Contains(raw, "hunter3") || !strings.Contains(raw, "enc:v1:old:") {
+		t.Fatalf("expected the secret items sealed with key old, got: %v", raw)
+	}
+
+	// rotating the keys reaches the releases
+	newKey := testKey(t)
+	p.UseKeyring(testKeyring(t, []string{"new", "old"}, [][]byte{newKey, key}))
+	if _, err := p.RotateKeys(context.Background()); err != nil {
+		t.Fatalf("could not rotate keys: %v", err)
+	}
+	p.UseKeyring(testKeyring(t, []string{"new"}, [][]byte{newKey}))
+
+	got, err := p.GetRelease(context.Background(), release.Name)
+	if err != nil {
+		t.Fatalf("could not get release: %v", err)
+	}
+	if !reflect.DeepEqual(got.Closure, release.Closure) || !reflect.DeepEqual(got.Modules, release.Modules) {
+		t.Fatalf("expected: %v, got: %v", release, got)
+	}
+
+	if got, err := p.GetRelease(context.Background(), "nope"); err != nil || got != nil {
+		t.Fatalf("expected no release and no error, got: %v, %v", got, err)
+	}
+
+	releases, err := p.GetReleases(context.Background())
+	if err != nil {
+		t.Fatalf("could not get releases: %v", err)
+	}
+	if len(releases) != 1 || releases[0].Name != release.Name {
+		t.Fatalf("expected release %v, got: %v", release.Name, releases)
+	}
+}
+
+func TestPromotions(t *testing.T) {
+	release := &storage.Release{Name: "2026.11-prod", Modules: []int64{}, Closure: []*storage.ReleaseModule{}}
+	if err := p.CreateRelease(context.Background(), release); err != nil {
+		t.Fatalf("could not create release: %v", err)
+	}
+
+	promoted := &storage.Promotion{
+		Release: release.Name, Environment: "dev", Status: storage.Promoted, RequestedBy: "alice",
+	}
+	id, err := p.CreatePromotion(context.Background(), promoted)
+	if err != nil {
+		t.Fatalf("could not create promotion: %v", err)
+	}
+	if id == 0 || promoted.RequestedAt.IsZero() || promoted.PromotedAt == nil {
+		t.Fatalf("expected the promotion to be promoted, got: %+v", promoted)
+	}
+
+	pending := &storage.Promotion{
+		Release: release.Name, Environment: "prod", Status: storage.Pending, RequestedBy: "alice",
+	}
+	id, err = p.CreatePromotion(context.Background(), pending)
+	if err != nil {
+		t.Fatalf("could not create promotion: %v", err)
+	}
+	if pending.PromotedAt != nil {
+		t.Fatalf("expected a pending promotion, got: %v", pending.PromotedAt)
+	}
+
+	again := *pending
+	if _, err := p.CreatePromotion(context.Background(), &again); err != storage.ErrExists {
+		t.Fatalf("expected a second pending promotion to give: %v, got: %v", storage.ErrExists, err)
+	}
+
+	current := *promoted
+	if _, err := p.CreatePromotion(context.Background(), &current); err != storage.ErrCurrent {
+		t.Fatalf("expected promoting the current release to give: %v, got: %v", storage.ErrCurrent, err)
+	}
+
+	pending.ID = id
+	pending.ApprovedBy = "bob"
+	if row, err := p.ApprovePromotion(context.Background(), pending); err != nil || row != 1 {
+		t.Fatalf("could not approve promotion: %v, %v", row, err)
+	}
+	if row, err := p.ApprovePromotion(context.Background(), pending); err != nil || row != 0 {
+		t.Fatalf("expected a promoted promotion to not be approved again, got: %v, %v", row, err)
+	}
+
+	got, err := p.GetPromotion(context.Background(), id)
+	if err != nil {
+		t.Fatalf("could not get promotion: %v", err)
+	}
+	if got.Status != storage.Promoted || got.ApprovedBy != "bob" || got.PromotedAt == nil {
+		t.Fatalf("expected the promotion approved by bob, got: %+v", got)
+	}
+
+	if got, err := p.GetPromotion(context.Background(), 42); err != nil || got != nil {
+		t.Fatalf("expected no promotion and no error, got: %v, %v", got, err)
+	}
+
+	promotions, err := p.GetPromotions(context.Background(), release.Name)
+	if err != nil {
+		t.Fatalf("could not get promotions: %v", err)
+	}
+	if len(promotions) != 2 {
+		t.Fatalf("expected 2 promotions, got: %v", len(promotions))
+	}
+}
+
+func TestCloneModule(t *testing.T) {
+	item := &storage.Item{Value: "refund_window", Type: "window", Version: "1.0.0"}
+	itemID, err := p.CreateItem(context.Background(), item)
+	if err != nil {
+		t.Fatalf("could not create item: %v", err)
+	}
+
+	// the dependent depends on the module, which depends on the dependee, and
+	// the rival conflicts with the module
+	var ids []int64
+	for _, v := range []string{"dependent", "module", "dependee", "rival"} {
+		id, err := p.CreateModule(context.Background(), &storage.Module{
+			Value: v, Version: "0.0.12", Labels: map[string]string{"team": "billing"},
+		})
+		if err != nil {
+			t.Fatalf("could not create module: %v", err)
+		}
+		ids = append(ids, id)
+	}
+	dependent, module, dependee, rival := ids[0], ids[1], ids[2], ids[3]
+
+	if _, err := p.CreateItemModule(context.Background(), itemID, module); err != nil {
+		t.Fatalf("could not create item module: %v", err)
+	}
+	for _, md := range []*storage.ModuleDependency{
+		{Dependent: dependent, Dependee: module, Kind: storage.Required},
+		{Dependent: module, Dependee: dependee, Kind: storage.Optional},
+		{Dependent: rival, Dependee: module, Kind: storage.Conflicts},
+	} {
+		if err := p.CreateModuleDependency(context.Background(), md); err != nil {
+			t.Fatalf("could not create module dependency: %v", err)
+		}
+	}
+
+	clone, err := p.CloneModule(context.Background(), module, "0.0.13", true)
+	if err != nil {
+		t.Fatalf("could not clone module: %v", err)
+	}
+
+	m := clone.Module
+	if m.Value != "module" || m.Version != "0.0.13" || m.Labels["team"] != "billing" || m.ID == module {
+		t.Fatalf("expected a copy of the module at 0.0.13, got: %v", m)
+	}
+	if clone.Items != 1 || clone.Dependencies != 1 || clone.Rewired != 1 {
+		t.Fatalf("expected 1 item, 1 dependency and 1 rewired, got: %+v", clone)
+	}
+
+	deps, err := p.GetModuleDependenciesByDependentID(context.Background(), m.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deps) != 1 || deps[0].Dependee != dependee || deps[0].Kind != storage.Optional {
+		t.Fatalf("expected the copy to depend on the dependee, got: %v", deps)
+	}
+
+	deps, err = p.GetModuleDependenciesByDependentID(context.Background(), dependent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deps) != 1 || deps[0].Dependee != m.ID {
+		t.Fatalf("expected the dependent to depend on the copy, got: %v", deps)
+	}
+
+	deps, err = p.GetModuleDependenciesByDependentID(context.Background(), rival)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deps) != 1 || deps[0].Dependee != module || deps[0].Kind != storage.Conflicts {
+		t.Fatalf("expected the rival to conflict with the module only, got: %v", deps)
+	}
+
+	if clone, err := p.CloneModule(context.Background(), -1, "0.0.13", false); err != nil || clone != nil {
+		t.Fatalf("expected no clone and no error, got: %v, %v", clone, err)
+	}
+}
+
+func TestImport(t *testing.T) {
+	item := &storage.Item{Value: "import_window", Type: "window", Version: "1.0.0"}
+	module := &storage.Module{Value: "import", Version: "0.0.1"}
+	rows := []*storage.ImportRow{
+		{Item: item, Module: module, Link: true},
+		// the row before creates the item and the module
+		{Item: item, Module: module},
+	}
+	if err := p.Import(context.Background(), rows); err != nil {
+		t.Fatalf("could not import: %v", err)
+	}
+
+	if item.ID == 0 || module.ID == 0 || rows[0].LinkID == 0 {
+		t.Fatalf("expected the ids to be set, got: %+v", rows[0])
+	}
+	im, err := p.GetItemModule(context.Background(), rows[0].LinkID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if im == nil || im.ItemID != item.ID || im.ModuleID != module.ID {
+		t.Fatalf("expected the item to be linked to the module, got: %v", im)
+	}
+
+	// the link to a module which does not exist fails after the item is
+	// created, which must be rolled back
+	failed := &storage.Item{Value: "import_rollback", Type: "window", Version: "1.0.0"}
+	err = p.Import(context.Background(), []*storage.ImportRow{
+		{Item: failed, Module: &storage.Module{ID: -1}, Link: true},
+	})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+
+	items, err := p.GetItems(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, i := range items {
+		if i.Value == failed.Value {
+			t.Fatalf("expected the item to be rolled back, got: %v", i)
+		}
+	}
+}
+
+func TestGetByIDs(t *testing.T) {
+	ctx := context.Background()
+	item := &storage.Item{Value: "byids_window", Type: "window", Version: "1.0.0"}
+	dependent := &storage.Module{Value: "byids_dependent", Version: "0.0.1"}
+	dependee := &storage.Module{Value: "byids_dependee", Version: "0.0.1"}
+	if err := p.Import(ctx, []*storage.ImportRow{
+		{Item: item, Module: dependent, Link: true},
+		{Item: item, Module: dependee},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	md := &storage.ModuleDependency{Dependent: dependent.ID, Dependee: dependee.ID, Kind: storage.Required}
+	if err := p.CreateModuleDependency(ctx, md); err != nil {
+		t.Fatal(err)
+	}
+
+	// ids which do not exist are left out
+	items, err := p.GetItemsByIDs(ctx, []int64{item.ID, -1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 || items[0].Value != item.Value {
+		t.Fatalf("expected the item %v, got: %v", item.Value, items)
+	}
+
+	modules, err := p.GetModulesByIDs(ctx, []int64{dependent.ID, dependee.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(modules) != 2 {
+		t.Fatalf("expected 2 modules, got: %v", modules)
+	}
+
+	ims, err := p.GetItemModulesByIDs(ctx, nil, []int64{dependent.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ims) != 1 || ims[0].ItemID != item.ID {
+		t.Fatalf("expected the item module of %v, got: %v", item.ID, ims)
+	}
+
+	deps, err := p.GetModuleDependenciesByModuleIDs(ctx, []int64{dependee.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deps) != 1 || deps[0].Dependent != dependent.ID {
+		t.Fatalf("expected the module dependency of %v, got: %v", dependent.ID, deps)
+	}
+}
+
 // integration test! seems easier for database testing
+func TestNotify(t *testing.T) {
+	got := make(chan string, 1)
+	if err := p.Listen("conf_test", func(payload string) { got <- payload }); err != nil {
+		t.Fatalf("could not listen: %v", err)
+	}
+
+	if err := p.Notify("conf_test", "hello"); err != nil {
+		t.Fatalf("could not notify: %v", err)
+	}
+
+	select {
+	case payload := <-got:
+		if payload != "hello" {
+			t.Fatalf("expected payload hello, got: %v", payload)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected a notification")
+	}
+}
+
 func TestEverything(t *testing.T) {
 	tt := []struct {
 		iValue   string
@@ -157,11 +700,25 @@ func TestEverything(t *testing.T) {
 			t.Errorf("expected: %v, got: %v", tc.iValue, item.Value)
 		}
 
+		row := testUpdateItem(t, itemID, tc.iValue, tc.iType, tc.iVersion)
+		if row != 1 {
+			t.Errorf("expected: %v, got: %v", 1, row)
+		}
+
+		updated := testGetItem(t, itemID)
+		if !updated.CreatedAt.Equal(item.CreatedAt) {
+			t.Errorf("expected created at: %v, got: %v", item.CreatedAt, updated.CreatedAt)
+		}
+		if updated.UpdatedAt.Before(item.UpdatedAt) {
+			t.Errorf("expected updated at after: %v, got: %v", item.UpdatedAt, updated.UpdatedAt)
+		}
+		item = updated
+
 		items := testGetItems(t)
 
 		found := false
 		for _, i := range items {
-			if *i == *item {
+			if reflect.DeepEqual(i, item) {
 				found = true
 				break
 			}
@@ -190,7 +747,7 @@ func TestEverything(t *testing.T) {
 
 		found = false
 		for _, m := range modules {
-			if *m == *module {
+			if reflect.DeepEqual(m, module) {
 				found = true
 				break
 			}
@@ -199,6 +756,48 @@ func TestEverything(t *testing.T) {
 			t.Errorf("expected: %v, was not in list: %v", module, modules)
 		}
 
+		labels := map[string]string{"team": "billing", "release": "2026.10"}
+		row = testSetItemLabels(t, itemID, labels)
+		if row != 1 {
+			t.Errorf("expected: %v, got: %v", 1, row)
+		}
+		if item := testGetItem(t, itemID); !reflect.DeepEqual(item.Labels, labels) {
+			t.Errorf("expected: %v, got: %v", labels, item.Labels)
+		}
+
+		row = testSetModuleLabels(t, moduleID1, labels)
+		if row != 1 {
+			t.Errorf("expected: %v, got: %v", 1, row)
+		}
+		if module := testGetModule(t, moduleID1); !reflect.DeepEqual(module.Labels, labels) {
+			t.Errorf("expected: %v, got: %v", labels, module.Labels)
+		}
+
+		metadata := storage.Metadata{
+			Description: "posty module",
+			Owner:       "billing",
+			Homepage:    "https://example.com/posty",
+			Annotations: map[string]interface{}{"runbook": "https://example.com/runbook"},
+		}
+		module = testGetModule(t, moduleID1)
+		module.Metadata = metadata
+		row = testUpdateModule(t, module)
+		if row != 1 {
+			t.Errorf("expected: %v, got: %v", 1, row)
+		}
+		got := testGetModule(t, moduleID1)
+		if got.Description != metadata.Description || got.Owner != metadata.Owner ||
+			got.Homepage != metadata.Homepage || !reflect.DeepEqual(got.Annotations, metadata.Annotations) {
+			t.Errorf("expected metadata: %v, got: %v", metadata, got.Metadata)
+		}
+		if got.UpdatedAt.Before(got.CreatedAt) {
+			t.Errorf("expected updated at: %v not to be before created at: %v", got.UpdatedAt, got.CreatedAt)
+		}
+
+		if row := testUpdateModule(t, &storage.Module{ID: -1, Value: "x", Version: "x"}); row != 0 {
+			t.Errorf("expected: %v, got: %v", 0, row)
+		}
+
 		itemModuleID := testCreateItemModule(t, itemID, moduleID1)
 
 		itemModule := testGetItemModule(t, itemModuleID)
@@ -238,6 +837,13 @@ func TestEverything(t *testing.T) {
 			}
 		}
 
+		// a module dependency created without a kind is required
+		for _, moddep := range moddeps1 {
+			if moddep.Kind != storage.Required {
+				t.Errorf("expected: %v, got: %v", storage.Required, moddep.Kind)
+			}
+		}
+
 		moddeps2 := testGetModuleDependenciesByDependentID(t, moduleID1)
 		moddeps3 := testGetModuleDependenciesByDependeeID(t, moduleID2)
 
@@ -247,7 +853,7 @@ func TestEverything(t *testing.T) {
 			}
 		}
 
-		row := testDeleteItemModule(t, itemModuleID)
+		row = testDeleteItemModule(t, itemModuleID)
 		if row != 1 {
 			t.Errorf("expected: %v, got: %v", 1, row)
 		}
@@ -257,9 +863,8 @@ func TestEverything(t *testing.T) {
 			t.Errorf("expected: %v, got: %v", 1, row)
 		}
 
-		row = testDeleteModule(t, moduleID1)
-		if row != 1 {
-			t.Errorf("expected: %v, got: %v", 1, row)
+		if _, err := p.DeleteModule(context.Background(), moduleID1); err != storage.ErrInUse {
+			t.Errorf("expected: %v, got: %v", storage.ErrInUse, err)
 		}
 
 		row = testDeleteDependency(t, moduleID1, moduleID2)
@@ -267,6 +872,11 @@ func TestEverything(t *testing.T) {
 			t.Errorf("expected: 1, got: %v", row)
 		}
 
+		row = testDeleteModule(t, moduleID1)
+		if row != 1 {
+			t.Errorf("expected: %v, got: %v", 1, row)
+		}
+
 		row = testDeleteModuleDependecyByDependentID(t, moduleID3)
 		if row != 1 {
 			t.Errorf("expected: 1, got: %v", row)
@@ -275,13 +885,26 @@ func TestEverything(t *testing.T) {
 		if row != 1 {
 			t.Errorf("expected: 1, got: %v", row)
 		}
+
+		testCreateModuleDependency(t, moduleID2, moduleID4)
+		testCreateModuleDependency(t, moduleID4, moduleID5)
+		row = testDeleteModuleCascade(t, moduleID4)
+		if row != 1 {
+			t.Errorf("expected: 1, got: %v", row)
+		}
+		if deps := testGetModuleDependenciesByDependentID(t, moduleID2); len(deps) != 0 {
+			t.Errorf("expected no dependencies of deleted module, got: %v", deps)
+		}
+		if deps := testGetModuleDependenciesByDependeeID(t, moduleID5); len(deps) != 0 {
+			t.Errorf("expected no dependencies of deleted module, got: %v", deps)
+		}
 	}
 
 	testClose(t)
 }
 
 func testGetItem(t *testing.T, id int64) *storage.Item {
-	item, err := p.GetItem(id)
+	item, err := p.GetItem(context.Background(), id)
 	if err != nil {
 		t.Fatalf("could not get item with id:%v: %v", id, err)
 	}
@@ -289,7 +912,7 @@ func testGetItem(t *testing.T, id int64) *storage.Item {
 }
 
 func testGetItems(t *testing.T) []*storage.Item {
-	items, err := p.GetItems()
+	items, err := p.GetItems(context.Background())
 	if err != nil {
 		t.Fatalf("could not get items: %v", err)
 	}
@@ -297,7 +920,7 @@ func testGetItems(t *testing.T) []*storage.Item {
 }
 
 func testCreateItem(t *testing.T, value, iType, version string) int64 {
-	id, err := p.CreateItem(value, iType, version)
+	id, err := p.CreateItem(context.Background(), &storage.Item{Value: value, Type: iType, Version: version})
 	if err != nil {
 		t.Fatalf(
 			"could not create item with values (%v, %v, %v): %v",
@@ -307,8 +930,24 @@ func testCreateItem(t *testing.T, value, iType, version string) int64 {
 	return id
 }
 
+func testUpdateItem(t *testing.T, id int64, value, iType, version string) int64 {
+	row, err := p.UpdateItem(context.Background(), &storage.Item{ID: id, Value: value, Type: iType, Version: version})
+	if err != nil {
+		t.Fatalf("could not update item with id %v: %v", id, err)
+	}
+	return row
+}
+
+func testSetItemLabels(t *testing.T, id int64, labels map[string]string) int64 {
+	row, err := p.SetItemLabels(context.Background(), id, labels)
+	if err != nil {
+		t.Fatalf("could not set labels of item with id %v: %v", id, err)
+	}
+	return row
+}
+
 func testDeleteItem(t *testing.T, id int64) int64 {
-	row, err := p.DeleteItem(id)
+	row, err := p.DeleteItem(context.Background(), id)
 	if err != nil {
 		t.Fatalf("could not delete item with id %v: %v", id, err)
 	}
@@ -316,7 +955,7 @@ func testDeleteItem(t *testing.T, id int64) int64 {
 }
 
 func testGetModule(t *testing.T, id int64) *storage.Module {
-	m, err := p.GetModule(id)
+	m, err := p.GetModule(context.Background(), id)
 	if err != nil {
 		t.Fatalf("could not get module with id %v: %v", id, err)
 	}
@@ -324,7 +963,7 @@ func testGetModule(t *testing.T, id int64) *storage.Module {
 }
 
 func testGetModules(t *testing.T) []*storage.Module {
-	mm, err := p.GetModules()
+	mm, err := p.GetModules(context.Background())
 	if err != nil {
 		t.Fatalf("could not get modules: %v", err)
 	}
@@ -332,7 +971,7 @@ func testGetModules(t *testing.T) []*storage.Module {
 }
 
 func testCreateModule(t *testing.T, value, version string) int64 {
-	i, err := p.CreateModule(value, version)
+	i, err := p.CreateModule(context.Background(), &storage.Module{Value: value, Version: version})
 	if err != nil {
 		t.Fatalf(
 			"could not create module with values (%v, %v): %v",
@@ -344,8 +983,32 @@ func testCreateModule(t *testing.T, value, version string) int64 {
 	return i
 }
 
+func testUpdateModule(t *testing.T, module *storage.Module) int64 {
+	row, err := p.UpdateModule(context.Background(), module)
+	if err != nil {
+		t.Fatalf("could not update module with id %v: %v", module.ID, err)
+	}
+	return row
+}
+
+func testSetModuleLabels(t *testing.T, id int64, labels map[string]string) int64 {
+	row, err := p.SetModuleLabels(context.Background(), id, labels)
+	if err != nil {
+		t.Fatalf("could not set labels of module with id %v: %v", id, err)
+	}
+	return row
+}
+
 func testDeleteModule(t *testing.T, id int64) int64 {
-	row, err := p.DeleteModule(id)
+	row, err := p.DeleteModule(context.Background(), id)
+	if err != nil {
+		t.Fatalf("could not delete module with id %v: %v", id, err)
+	}
+	return row
+}
+
+func testDeleteModuleCascade(t *testing.T, id int64) int64 {
+	row, err := p.DeleteModuleCascade(context.Background(), id)
 	if err != nil {
 		t.Fatalf("could not delete module with id %v: %v", id, err)
 	}
@@ -353,7 +1016,7 @@ func testDeleteModule(t *testing.T, id int64) int64 {
 }
 
 func testGetItemModule(t *testing.T, id int64) *storage.ItemModule {
-	im, err := p.GetItemModule(id)
+	im, err := p.GetItemModule(context.Background(), id)
 	if err != nil {
 		t.Fatalf("could not get item_module with id %v: %v", id, err)
 	}
@@ -361,7 +1024,7 @@ func testGetItemModule(t *testing.T, id int64) *storage.ItemModule {
 }
 
 func testGetItemModules(t *testing.T) []*storage.ItemModule {
-	ims, err := p.GetItemModules()
+	ims, err := p.GetItemModules(context.Background())
 	if err != nil {
 		t.Fatalf("could not get item_modules: %v", err)
 	}
@@ -369,7 +1032,7 @@ func testGetItemModules(t *testing.T) []*storage.ItemModule {
 }
 
 func testCreateItemModule(t *testing.T, itemID, moduleID int64) int64 {
-	id, err := p.CreateItemModule(itemID, moduleID)
+	id, err := p.CreateItemModule(context.Background(), itemID, moduleID)
 	if err != nil {
 		t.Fatalf(
 			"could not create item_module with values (%v, %v): %v",
@@ -382,7 +1045,7 @@ func testCreateItemModule(t *testing.T, itemID, moduleID int64) int64 {
 }
 
 func testDeleteItemModule(t *testing.T, id int64) int64 {
-	row, err := p.DeleteItemModule(id)
+	row, err := p.DeleteItemModule(context.Background(), id)
 	if err != nil {
 		t.Errorf("could not delete item_module with id %v: %v", id, err)
 	}
@@ -390,7 +1053,7 @@ func testDeleteItemModule(t *testing.T, id int64) int64 {
 }
 
 func testGetModuleDependecies(t *testing.T) []*storage.ModuleDependency {
-	m, err := p.GetModuleDependencies()
+	m, err := p.GetModuleDependencies(context.Background())
 	if err != nil {
 		t.Fatalf("could not get module_dependencies: %v", err)
 	}
@@ -399,7 +1062,7 @@ func testGetModuleDependecies(t *testing.T) []*storage.ModuleDependency {
 }
 
 func testGetModuleDependenciesByDependentID(t *testing.T, id int64) []*storage.ModuleDependency {
-	m, err := p.GetModuleDependenciesByDependentID(id)
+	m, err := p.GetModuleDependenciesByDependentID(context.Background(), id)
 	if err != nil {
 		t.Fatalf("could not get module_dependencies: %v", err)
 	}
@@ -408,7 +1071,7 @@ func testGetModuleDependenciesByDependentID(t *testing.T, id int64) []*storage.M
 }
 
 func testGetModuleDependenciesByDependeeID(t *testing.T, id int64) []*storage.ModuleDependency {
-	m, err := p.GetModuleDependenciesByDependeeID(id)
+	m, err := p.GetModuleDependenciesByDependeeID(context.Background(), id)
 	if err != nil {
 		t.Fatalf("could not get module_dependencies: %v", err)
 	}
@@ -417,14 +1080,14 @@ func testGetModuleDependenciesByDependeeID(t *testing.T, id int64) []*storage.Mo
 }
 
 func testCreateModuleDependency(t *testing.T, depedentID, dependeeID int64) {
-	err := p.CreateModuleDependency(depedentID, dependeeID)
+	err := p.CreateModuleDependency(context.Background(), &storage.ModuleDependency{Dependent: depedentID, Dependee: dependeeID})
 	if err != nil {
 		t.Fatalf("could not create module_dependency: %v", err)
 	}
 }
 
 func testDeleteDependency(t *testing.T, dependentID, dependeeID int64) int64 {
-	i, err := p.DeleteModuleDependency(dependentID, dependeeID)
+	i, err := p.DeleteModuleDependency(context.Background(), dependentID, dependeeID)
 	if err != nil {
 		t.Fatalf("could not delete module_dependency: %v", err)
 	}
@@ -433,7 +1096,7 @@ func testDeleteDependency(t *testing.T, dependentID, dependeeID int64) int64 {
 }
 
 func testDeleteModuleDependecyByDependentID(t *testing.T, id int64) int64 {
-	i, err := p.DeleteModuleDependencyByDependentID(id)
+	i, err := p.DeleteModuleDependencyByDependentID(context.Background(), id)
 	if err != nil {
 		t.Fatalf("could not delete module_dependency: %v", err)
 	}
@@ -442,7 +1105,7 @@ func testDeleteModuleDependecyByDependentID(t *testing.T, id int64) int64 {
 }
 
 func testDeleteModuleDependecyByDependeeID(t *testing.T, id int64) int64 {
-	i, err := p.DeleteModuleDependencyByDependeeID(id)
+	i, err := p.DeleteModuleDependencyByDependeeID(context.Background(), id)
 	if err != nil {
 		t.Fatalf("could not delete module_dependency: %v", err)
 	}
@@ -456,3 +1119,34 @@ func testClose(t *testing.T) {
 		t.Fatalf("could not close database: %v", err)
 	}
 }
+
+func TestGetSnapshot(t *testing.T) {
+	ctx := context.Background()
+	snap, err := p.GetSnapshot(ctx)
+	if err != nil {
+		t.Fatalf("could not get snapshot: %v", err)
+	}
+
+	items, err := p.GetItems(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	modules, err := p.GetModules(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ims, err := p.GetItemModules(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	deps, err := p.GetModuleDependencies(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(snap.Items) != len(items) || len(snap.Modules) != len(modules) ||
+		len(snap.ItemModules) != len(ims) || len(snap.Dependencies) != len(deps) {
+		t.Fatalf("expected the snapshot to hold every row, got: %v items, %v modules, %v item modules and %v dependencies",
+			len(snap.Items), len(snap.Modules), len(snap.ItemModules), len(snap.Dependencies))
+	}
+}
@@ -1,54 +1,160 @@
 package storage
 
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Glorforidor/conmansys/resolve"
+)
+
+// ErrNoKeyring is returned when a secret item is stored but no keyring has been
+// configured to encrypt it with.
+var ErrNoKeyring = errors.New("secret items require a keyring")
+
+// ErrInUse is returned when a module can not be deleted because other rows
+// still reference it.
+var ErrInUse = errors.New("module is still in use")
+
+// ErrExists is returned when a release is created with a name which is already
+// taken or a promotion while the same one is pending.
+var ErrExists = errors.New("already exists")
+
+// ErrCurrent is returned when a release is promoted to the environment it is
+// already the release of.
+var ErrCurrent = errors.New("is already the release of the environment")
+
 type ItemService interface {
-	GetItem(id int64) (*Item, error)
-	GetItems() ([]*Item, error)
-	CreateItem(value, iType, version string) (int64, error)
-	DeleteItem(id int64) (int64, error)
+	GetItem(ctx context.Context, id int64) (*Item, error)
+	GetItems(ctx context.Context) ([]*Item, error)
+	GetItemsByIDs(ctx context.Context, ids []int64) ([]*Item, error)
+	CreateItem(ctx context.Context, item *Item) (int64, error)
+	UpdateItem(ctx context.Context, item *Item) (int64, error)
+	SetItemLabels(ctx context.Context, id int64, labels map[string]string) (int64, error)
+	DeleteItem(ctx context.Context, id int64) (int64, error)
+}
+
+type ItemTypeService interface {
+	GetItemType(ctx context.Context, name string) (*ItemType, error)
+	GetItemTypes(ctx context.Context) ([]*ItemType, error)
+	CreateItemType(ctx context.Context, t *ItemType) error
+	UpdateItemType(ctx context.Context, t *ItemType) (int64, error)
+	DeleteItemType(ctx context.Context, name string) (int64, error)
 }
 
 type ModuleService interface {
-	GetModule(id int64) (*Module, error)
-	GetModules() ([]*Module, error)
-	CreateModule(value, version string) (int64, error)
-	DeleteModule(id int64) (int64, error)
+	GetModule(ctx context.Context, id int64) (*Module, error)
+	GetModules(ctx context.Context) ([]*Module, error)
+	GetModulesByIDs(ctx context.Context, ids []int64) ([]*Module, error)
+	CreateModule(ctx context.Context, module *Module) (int64, error)
+	UpdateModule(ctx context.Context, module *Module) (int64, error)
+	SetModuleLabels(ctx context.Context, id int64, labels map[string]string) (int64, error)
+	DeleteModule(ctx context.Context, id int64) (int64, error)
+	DeleteModuleCascade(ctx context.Context, id int64) (int64, error)
+	CloneModule(ctx context.Context, id int64, version string, rewire bool) (*ModuleClone, error)
 }
 
 type ItemModuleService interface {
-	GetItemModule(id int64) (*ItemModule, error)
-	GetItemModules() ([]*ItemModule, error)
-	CreateItemModule(itemID, moduleID int64) (int64, error)
-	DeleteItemModule(id int64) (int64, error)
+	GetItemModule(ctx context.Context, id int64) (*ItemModule, error)
+	GetItemModules(ctx context.Context) ([]*ItemModule, error)
+	GetItemModulesByIDs(ctx context.Context, itemIDs, moduleIDs []int64) ([]*ItemModule, error)
+	CreateItemModule(ctx context.Context, itemID, moduleID int64) (int64, error)
+	DeleteItemModule(ctx context.Context, id int64) (int64, error)
+}
+
+// ImportService writes the rows of an import together.
+type ImportService interface {
+	Import(ctx context.Context, rows []*ImportRow) error
 }
 
 type ModuleDependencyService interface {
-	GetModuleDependencies() ([]*ModuleDependency, error)
-	GetModuleDependenciesByDependentID(dependentID int64) ([]*ModuleDependency, error)
-	GetModuleDependenciesByDependeeID(dependeeID int64) ([]*ModuleDependency, error)
-	CreateModuleDependency(dependentID, dependeeID int64) error
-	DeleteModuleDependency(dependentID, dependeeID int64) (int64, error)
-	DeleteModuleDependencyByDependentID(id int64) (int64, error)
-	DeleteModuleDependencyByDependeeID(id int64) (int64, error)
+	GetModuleDependencies(ctx context.Context) ([]*ModuleDependency, error)
+	GetModuleDependenciesByModuleIDs(ctx context.Context, ids []int64) ([]*ModuleDependency, error)
+	GetModuleDependenciesByDependentID(ctx context.Context, dependentID int64) ([]*ModuleDependency, error)
+	GetModuleDependenciesByDependeeID(ctx context.Context, dependeeID int64) ([]*ModuleDependency, error)
+	CreateModuleDependency(ctx context.Context, md *ModuleDependency) error
+	DeleteModuleDependency(ctx context.Context, dependentID, dependeeID int64) (int64, error)
+	DeleteModuleDependencyByDependentID(ctx context.Context, id int64) (int64, error)
+	DeleteModuleDependencyByDependeeID(ctx context.Context, id int64) (int64, error)
+}
+
+type ReleaseService interface {
+	GetRelease(ctx context.Context, name string) (*Release, error)
+	GetReleases(ctx context.Context) ([]*Release, error)
+	GetSnapshot(ctx context.Context) (*Snapshot, error)
+	CreateRelease(ctx context.Context, release *Release) error
+}
+
+type PromotionService interface {
+	GetPromotion(ctx context.Context, id int64) (*Promotion, error)
+	GetPromotions(ctx context.Context, release string) ([]*Promotion, error)
+	CreatePromotion(ctx context.Context, promotion *Promotion) (int64, error)
+	ApprovePromotion(ctx context.Context, promotion *Promotion) (int64, error)
 }
 
 type Service interface {
 	ItemService
+	ItemTypeService
 	ModuleService
 	ItemModuleService
+	ImportService
 	ModuleDependencyService
+	ReleaseService
+	PromotionService
+}
+
+// Metadata describes an item or a module. Annotations hold any JSON the
+// clients want to attach. CreatedAt and UpdatedAt are maintained by the storage
+// and ignored when an item or module is saved.
+type Metadata struct {
+	Description string                 `json:"description"`
+	Owner       string                 `json:"owner"`
+	Homepage    string                 `json:"homepage"`
+	Annotations map[string]interface{} `json:"annotations"`
+	CreatedAt   time.Time              `json:"created_at"`
+	UpdatedAt   time.Time              `json:"updated_at"`
 }
 
+// Item is a single configuration value. The value of a secret item is
+// encrypted at rest and masked in responses.
 type Item struct {
-	ID      int64  `json:"id"`
-	Value   string `json:"value"`
-	Type    string `json:"type"`
-	Version string `json:"version"`
+	ID      int64             `json:"id"`
+	Value   string            `json:"value"`
+	Type    string            `json:"type"`
+	Version string            `json:"version"`
+	Secret  bool              `json:"secret"`
+	Labels  map[string]string `json:"labels"`
+	Metadata
+}
+
+// ItemType describes the values an item of a given type may hold. Every
+// validator left empty is not checked, so a type with only a name accepts any
+// value.
+type ItemType struct {
+	Name    string   `json:"name"`
+	Pattern string   `json:"pattern,omitempty"`
+	Enum    []string `json:"enum,omitempty"`
+	Min     *int64   `json:"min,omitempty"`
+	Max     *int64   `json:"max,omitempty"`
 }
 
 type Module struct {
-	ID      int64  `json:"id"`
-	Value   string `json:"value"`
-	Version string `json:"version"`
+	ID      int64             `json:"id"`
+	Value   string            `json:"value"`
+	Version string            `json:"version"`
+	Labels  map[string]string `json:"labels"`
+	Metadata
+}
+
+// ModuleClone is a copy of a module at a new version together with how many of
+// the item modules and module dependencies of the module were copied and how
+// many dependents were moved to the copy.
+type ModuleClone struct {
+	Module       *Module `json:"module"`
+	From         int64   `json:"from"`
+	Items        int64   `json:"items"`
+	Dependencies int64   `json:"dependencies"`
+	Rewired      int64   `json:"rewired"`
 }
 
 type ItemModule struct {
@@ -57,7 +163,85 @@ type ItemModule struct {
 	ModuleID int64 `json:"module_id"`
 }
 
-type ModuleDependency struct {
-	Dependent int64 `json:"dependent"`
-	Dependee  int64 `json:"dependee"`
+// ImportRow is a row of an import. The item and the module are created if
+// their id is 0 and get the id they are created with, so rows sharing an item
+// or a module create it once. If Link is set the item module of the item and
+// the module is created and its id set in LinkID.
+type ImportRow struct {
+	Item   *Item
+	Module *Module
+	Link   bool
+	LinkID int64
+}
+
+// The kinds of module dependencies, see the resolve module for what they mean.
+const (
+	Required   = resolve.Required
+	Optional   = resolve.Optional
+	Conflicts  = resolve.Conflicts
+	Recommends = resolve.Recommends
+)
+
+// ModuleDependency makes the dependent depend on the dependee in the way given
+// by the kind. A dependency without a kind is required.
+type ModuleDependency = resolve.Dependency
+
+// Snapshot is every module, module dependency, item module and item as they
+// were at one point in time, which releases are frozen from.
+type Snapshot struct {
+	Modules      []*Module
+	Dependencies []*ModuleDependency
+	ItemModules  []*ItemModule
+	Items        []*Item
+}
+
+// Release is a named set of modules frozen together with the modules and items
+// of their resolved closure as they were when the release was created. A
+// release is never changed.
+type Release struct {
+	Name string `json:"name"`
+	// Modules are the ids of the modules the release was created from.
+	Modules []int64 `json:"modules"`
+	// Optional tells whether optional dependencies were followed.
+	Optional  bool             `json:"optional"`
+	Closure   []*ReleaseModule `json:"closure"`
+	CreatedAt time.Time        `json:"created_at"`
+}
+
+// ReleaseModule is a module of the closure of a release with its items.
+type ReleaseModule struct {
+	ID      int64  `json:"id"`
+	Value   string `json:"value"`
+	Version string `json:"version"`
+	// Depth, From and Kind tell how the module was reached from the modules
+	// of the release, see the resolve package.
+	Depth int     `json:"depth"`
+	From  int64   `json:"from,omitempty"`
+	Kind  string  `json:"kind,omitempty"`
+	Items []*Item `json:"items"`
+}
+
+// Environments is the pipeline releases are promoted through in order.
+var Environments = []string{"dev", "staging", "prod"}
+
+// The states of a promotion.
+const (
+	// Pending promotions wait for an approval.
+	Pending = "pending"
+	// Promoted releases are in the environment until another release is
+	// promoted to it.
+	Promoted = "promoted"
+)
+
+// Promotion records that a release was asked to move into an environment. The
+// release which was promoted last to an environment is the one in it.
+type Promotion struct {
+	ID          int64      `json:"id"`
+	Release     string     `json:"release"`
+	Environment string     `json:"environment"`
+	Status      string     `json:"status"`
+	RequestedBy string     `json:"requested_by"`
+	RequestedAt time.Time  `json:"requested_at"`
+	ApprovedBy  string     `json:"approved_by,omitempty"`
+	PromotedAt  *time.Time `json:"promoted_at,omitempty"`
 }
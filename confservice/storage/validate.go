@@ -0,0 +1,189 @@
+package storage
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Glorforidor/conmansys/labels"
+)
+
+// FieldError reports why the value of a single field was rejected.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (f *FieldError) String() string {
+	return fmt.Sprintf("%v: %v", f.Field, f.Message)
+}
+
+// Check verifies that the item type itself is well formed. It returns a
+// FieldError for every field which is wrong and nil if the type can be used.
+func (t *ItemType) Check() []*FieldError {
+	var errs []*FieldError
+
+	if strings.TrimSpace(t.Name) == "" {
+		errs = append(errs, &FieldError{Field: "name", Message: "missing value"})
+	}
+
+	if t.Pattern != "" {
+		if _, err := regexp.Compile(t.Pattern); err != nil {
+			errs = append(errs, &FieldError{
+				Field:   "pattern",
+				Message: fmt.Sprintf("not a valid regular expression: %v", err),
+			})
+		}
+	}
+
+	if t.Min != nil && t.Max != nil && *t.Min > *t.Max {
+		errs = append(errs, &FieldError{
+			Field:   "min",
+			Message: fmt.Sprintf("must not be greater than max %v", *t.Max),
+		})
+	}
+
+	return errs
+}
+
+// Validate checks value against every validator set on the item type. It
+// returns a FieldError for every validator the value does not satisfy and nil
+// if the value is accepted.
+func (t *ItemType) Validate(value string) []*FieldError {
+	var errs []*FieldError
+
+	if t.Pattern != "" {
+		re, err := regexp.Compile(t.Pattern)
+		if err != nil {
+			errs = append(errs, &FieldError{
+				Field:   "type",
+				Message: fmt.Sprintf("item type %q has an invalid pattern", t.Name),
+			})
+		} else if !re.MatchString(value) {
+			errs = append(errs, &FieldError{
+				Field:   "value",
+				Message: fmt.Sprintf("must match pattern %q", t.Pattern),
+			})
+		}
+	}
+
+	if len(t.Enum) > 0 {
+		found := false
+		for _, e := range t.Enum {
+			if e == value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			errs = append(errs, &FieldError{
+				Field:   "value",
+				Message: fmt.Sprintf("must be one of %q", t.Enum),
+			})
+		}
+	}
+
+	if t.Min != nil || t.Max != nil {
+		i, err := strconv.ParseInt(value, 10, 64)
+		switch {
+		case err != nil:
+			errs = append(errs, &FieldError{Field: "value", Message: "must be an integer"})
+		case t.Min != nil && i < *t.Min:
+			errs = append(errs, &FieldError{
+				Field:   "value",
+				Message: fmt.Sprintf("must be greater than or equal to %v", *t.Min),
+			})
+		case t.Max != nil && i > *t.Max:
+			errs = append(errs, &FieldError{
+				Field:   "value",
+				Message: fmt.Sprintf("must be less than or equal to %v", *t.Max),
+			})
+		}
+	}
+
+	return errs
+}
+
+// CheckLabels verifies that every key and value of the labels can be used in a
+// label selector. It returns a FieldError for every label which is wrong.
+func CheckLabels(l map[string]string) []*FieldError {
+	keys := make([]string, 0, len(l))
+	for k := range l {
+		keys = append(keys, k)
+	}
+	// sort to report the errors in a stable order
+	sort.Strings(keys)
+
+	var errs []*FieldError
+	for _, k := range keys {
+		field := "labels." + k
+		if !labels.ValidKey(k) {
+			errs = append(errs, &FieldError{Field: field, Message: "invalid label key"})
+			continue
+		}
+
+		if !labels.ValidValue(l[k]) {
+			errs = append(errs, &FieldError{Field: field, Message: "invalid label value"})
+		}
+	}
+
+	return errs
+}
+
+// Check verifies that the metadata is well formed. It returns a FieldError for
+// every field which is wrong.
+func (m *Metadata) Check() []*FieldError {
+	var errs []*FieldError
+
+	if m.Homepage != "" {
+		u, err := url.Parse(m.Homepage)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, &FieldError{Field: "homepage", Message: "must be an absolute http or https URL"})
+		}
+	}
+
+	return errs
+}
+
+// CheckDependency verifies that the kind of the module dependency is known. It
+// returns a FieldError for every field which is wrong.
+func CheckDependency(md *ModuleDependency) []*FieldError {
+	switch md.Kind {
+	case Required, Optional, Conflicts, Recommends:
+		return nil
+	}
+
+	return []*FieldError{{
+		Field:   "kind",
+		Message: fmt.Sprintf("must be one of %q", []string{Required, Optional, Conflicts, Recommends}),
+	}}
+}
+
+// releaseName is what a release name may look like, so it can be used in a
+// path without escaping.
+var releaseName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// Check verifies that the release can be created. It returns a FieldError for
+// every field which is wrong.
+func (r *Release) Check() []*FieldError {
+	var errs []*FieldError
+
+	switch {
+	case r.Name == "":
+		errs = append(errs, &FieldError{Field: "name", Message: "missing value"})
+	case !releaseName.MatchString(r.Name):
+		errs = append(errs, &FieldError{
+			Field:   "name",
+			Message: "must start with a letter or digit and only contain letters, digits, '.', '_' and '-'",
+		})
+	}
+
+	if len(r.Modules) == 0 {
+		errs = append(errs, &FieldError{Field: "modules", Message: "missing value"})
+	}
+
+	return errs
+}
@@ -0,0 +1,686 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Glorforidor/conmansys/client"
+)
+
+// command is a command of conmansysctl. A command either has sub commands or
+// is run: setup defines the flags of the command and returns the action which
+// runs it with the positional arguments.
+type command struct {
+	name    string
+	args    string
+	summary string
+	sub     []*command
+	setup   func(c *cli, fs *flag.FlagSet) func(args []string) error
+}
+
+// find returns the command with the name or nil.
+func find(cmds []*command, name string) *command {
+	for _, cmd := range cmds {
+		if cmd.name == name {
+			return cmd
+		}
+	}
+	return nil
+}
+
+// commands returns the commands of conmansysctl.
+func commands() []*command {
+	return []*command{
+		{
+			name:    "items",
+			summary: "manage items",
+			sub: []*command{
+				{name: "list", summary: "list the items", setup: listItems},
+				{name: "get", args: "<id>", summary: "get an item", setup: getItem},
+				{name: "create", summary: "create an item", setup: createItem},
+				{name: "delete", args: "<id>", summary: "delete an item", setup: deleteItem},
+			},
+		},
+		{
+			name:    "modules",
+			summary: "manage modules",
+			sub: []*command{
+				{name: "list", summary: "list the modules", setup: listModules},
+				{name: "get", args: "<id>", summary: "get a module", setup: getModule},
+				{name: "create", summary: "create a module", setup: createModule},
+				{name: "delete", args: "<id>", summary: "delete a module", setup: deleteModule},
+				{name: "graph", args: "<id>", summary: "show the dependencies of a module", setup: moduleGraph},
+			},
+		},
+		{name: "link", args: "<item id> <module id>", summary: "link an item to a module", setup: link},
+		{name: "unlink", args: "<item module id>", summary: "delete the link of an item to a module", setup: unlink},
+		{name: "depend", args: "<dependent id> <dependee id>", summary: "make a module depend on another", setup: depend},
+		{name: "undepend", args: "<dependent id> <dependee id>", summary: "delete the dependency of a module on another", setup: undepend},
+		{name: "insfile", summary: "render the insfile of modules, a release or an environment", setup: insfile},
+		{
+			name:    "config",
+			summary: "manage the contexts of the config file",
+			sub: []*command{
+				{name: "get-contexts", summary: "list the contexts", setup: getContexts},
+				{name: "current-context", summary: "print the current context", setup: currentContext},
+				{name: "use-context", args: "<name>", summary: "set the current context", setup: useContext},
+				{name: "set-context", args: "<name>", summary: "create or change a context", setup: setContext},
+			},
+		},
+		{name: "completion", args: "bash|zsh", summary: "print the shell completion script", setup: completion},
+	}
+}
+
+// parseID parses the id argument named name.
+func parseID(name, s string) (int64, error) {
+	id, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("%v must be a positive number, got: %q", name, s)
+	}
+	return id, nil
+}
+
+// labelsFlag is a repeatable key=value flag.
+type labelsFlag map[string]string
+
+func (l labelsFlag) String() string {
+	return labels(l)
+}
+
+func (l labelsFlag) Set(s string) error {
+	kv := strings.SplitN(s, "=", 2)
+	if len(kv) != 2 || kv[0] == "" {
+		return fmt.Errorf("label must be key=value, got: %q", s)
+	}
+	l[kv[0]] = kv[1]
+	return nil
+}
+
+// listFlags defines the filters of a list.
+func listFlags(fs *flag.FlagSet) *client.ListOptions {
+	var opts client.ListOptions
+	fs.StringVar(&opts.Selector, "selector", "", "a label `selector`, e.g. team=billing,tier!=experimental")
+	fs.StringVar(&opts.Owner, "owner", "", "only with this `owner`")
+	fs.StringVar(&opts.Search, "q", "", "only with this `text` in the value or description")
+	fs.StringVar(&opts.Annotation, "annotation", "", "only with the annotation `key` set")
+	return &opts
+}
+
+// metadataFlags defines the flags of the metadata of an item or module.
+func metadataFlags(fs *flag.FlagSet) *client.Metadata {
+	var m client.Metadata
+	fs.StringVar(&m.Description, "description", "", "the `description`")
+	fs.StringVar(&m.Owner, "owner", "", "the `owner`")
+	fs.StringVar(&m.Homepage, "homepage", "", "the `url` of the homepage")
+	return &m
+}
+
+func listItems(c *cli, fs *flag.FlagSet) func([]string) error {
+	opts := listFlags(fs)
+	return func(args []string) error {
+		if len(args) != 0 {
+			return errUsage
+		}
+
+		cl, err := c.client()
+		if err != nil {
+			return err
+		}
+		ctx, cancel := c.ctx()
+		defer cancel()
+
+		items, err := cl.ListItems(ctx, opts)
+		if err != nil {
+			return err
+		}
+		return c.print(items, itemsTable(items...))
+	}
+}
+
+func getItem(c *cli, fs *flag.FlagSet) func([]string) error {
+	return func(args []string) error {
+		if len(args) != 1 {
+			return errUsage
+		}
+		id, err := parseID("id", args[0])
+		if err != nil {
+			return err
+		}
+
+		cl, err := c.client()
+		if err != nil {
+			return err
+		}
+		ctx, cancel := c.ctx()
+		defer cancel()
+
+		item, err := cl.GetItem(ctx, id)
+		if err != nil {
+			return err
+		}
+		return c.print(item, itemsTable(item))
+	}
+}
+
+func createItem(c *cli, fs *flag.FlagSet) func([]string) error {
+	var item client.Item
+	l := labelsFlag{}
+	fs.StringVar(&item.Value, "value", "", "the `value` of the item")
+	fs.StringVar(&item.Type, "type", "", "the `type` of the item")
+	fs.StringVar(&item.Version, "version", "", "the `version` of the item")
+	fs.BoolVar(&item.Secret, "secret", false, "store the value encrypted")
+	fs.Var(l, "label", "a `key=value` label, can be repeated")
+	m := metadataFlags(fs)
+	return func(args []string) error {
+		if len(args) != 0 {
+			return errUsage
+		}
+		item.Labels = l
+		item.Metadata = *m
+
+		cl, err := c.client()
+		if err != nil {
+			return err
+		}
+		ctx, cancel := c.ctx()
+		defer cancel()
+
+		created, err := cl.CreateItem(ctx, &item)
+		if err != nil {
+			return err
+		}
+		return c.print(created, itemsTable(created))
+	}
+}
+
+func deleteItem(c *cli, fs *flag.FlagSet) func([]string) error {
+	return func(args []string) error {
+		if len(args) != 1 {
+			return errUsage
+		}
+		id, err := parseID("id", args[0])
+		if err != nil {
+			return err
+		}
+
+		cl, err := c.client()
+		if err != nil {
+			return err
+		}
+		ctx, cancel := c.ctx()
+		defer cancel()
+
+		n, err := cl.DeleteItem(ctx, id)
+		if err != nil {
+			return err
+		}
+		return c.print(deleted{n}, deletedTable(n))
+	}
+}
+
+func listModules(c *cli, fs *flag.FlagSet) func([]string) error {
+	opts := listFlags(fs)
+	return func(args []string) error {
+		if len(args) != 0 {
+			return errUsage
+		}
+
+		cl, err := c.client()
+		if err != nil {
+			return err
+		}
+		ctx, cancel := c.ctx()
+		defer cancel()
+
+		modules, err := cl.ListModules(ctx, opts)
+		if err != nil {
+			return err
+		}
+		return c.print(modules, modulesTable(modules...))
+	}
+}
+
+func getModule(c *cli, fs *flag.FlagSet) func([]string) error {
+	return func(args []string) error {
+		if len(args) != 1 {
+			return errUsage
+		}
+		id, err := parseID("id", args[0])
+		if err != nil {
+			return err
+		}
+
+		cl, err := c.client()
+		if err != nil {
+			return err
+		}
+		ctx, cancel := c.ctx()
+		defer cancel()
+
+		m, err := cl.GetModule(ctx, id)
+		if err != nil {
+			return err
+		}
+		return c.print(m, modulesTable(m))
+	}
+}
+
+func createModule(c *cli, fs *flag.FlagSet) func([]string) error {
+	var module client.Module
+	l := labelsFlag{}
+	fs.StringVar(&module.Value, "value", "", "the `value` of the module")
+	fs.StringVar(&module.Version, "version", "", "the `version` of the module")
+	fs.Var(l, "label", "a `key=value` label, can be repeated")
+	m := metadataFlags(fs)
+	return func(args []string) error {
+		if len(args) != 0 {
+			return errUsage
+		}
+		module.Labels = l
+		module.Metadata = *m
+
+		cl, err := c.client()
+		if err != nil {
+			return err
+		}
+		ctx, cancel := c.ctx()
+		defer cancel()
+
+		created, err := cl.CreateModule(ctx, &module)
+		if err != nil {
+			return err
+		}
+		return c.print(created, modulesTable(created))
+	}
+}
+
+func deleteModule(c *cli, fs *flag.FlagSet) func([]string) error {
+	var opts client.DeleteModuleOptions
+	fs.BoolVar(&opts.CascadeDependencies, "cascade", false, "delete the module dependencies of the module with it")
+	fs.BoolVar(&opts.Force, "force", false, "orphan the items only linked to the module")
+	return func(args []string) error {
+		if len(args) != 1 {
+			return errUsage
+		}
+		id, err := parseID("id", args[0])
+		if err != nil {
+			return err
+		}
+
+		cl, err := c.client()
+		if err != nil {
+			return err
+		}
+		ctx, cancel := c.ctx()
+		defer cancel()
+
+		n, err := cl.DeleteModule(ctx, id, &opts)
+		if err != nil {
+			return err
+		}
+		return c.print(deleted{n}, deletedTable(n))
+	}
+}
+
+func moduleGraph(c *cli, fs *flag.FlagSet) func([]string) error {
+	var opts client.GraphOptions
+	fs.StringVar(&opts.Direction, "direction", client.Down, "down to the modules it depends on, up to the modules depending on it")
+	fs.IntVar(&opts.Depth, "depth", 0, "the number of dependencies to follow, every one if 0")
+	return func(args []string) error {
+		if len(args) != 1 {
+			return errUsage
+		}
+		id, err := parseID("id", args[0])
+		if err != nil {
+			return err
+		}
+
+		cl, err := c.client()
+		if err != nil {
+			return err
+		}
+		ctx, cancel := c.ctx()
+		defer cancel()
+
+		g, err := cl.ModuleGraph(ctx, id, &opts)
+		if err != nil {
+			return err
+		}
+		return c.print(g, graphTable(g))
+	}
+}
+
+func link(c *cli, fs *flag.FlagSet) func([]string) error {
+	return func(args []string) error {
+		if len(args) != 2 {
+			return errUsage
+		}
+		itemID, err := parseID("item id", args[0])
+		if err != nil {
+			return err
+		}
+		moduleID, err := parseID("module id", args[1])
+		if err != nil {
+			return err
+		}
+
+		cl, err := c.client()
+		if err != nil {
+			return err
+		}
+		ctx, cancel := c.ctx()
+		defer cancel()
+
+		im, err := cl.CreateItemModule(ctx, itemID, moduleID)
+		if err != nil {
+			return err
+		}
+		return c.print(im, itemModulesTable(im))
+	}
+}
+
+func unlink(c *cli, fs *flag.FlagSet) func([]string) error {
+	return func(args []string) error {
+		if len(args) != 1 {
+			return errUsage
+		}
+		id, err := parseID("item module id", args[0])
+		if err != nil {
+			return err
+		}
+
+		cl, err := c.client()
+		if err != nil {
+			return err
+		}
+		ctx, cancel := c.ctx()
+		defer cancel()
+
+		n, err := cl.DeleteItemModule(ctx, id)
+		if err != nil {
+			return err
+		}
+		return c.print(deleted{n}, deletedTable(n))
+	}
+}
+
+// parseDependency parses the dependent and dependee arguments.
+func parseDependency(args []string) (int64, int64, error) {
+	if len(args) != 2 {
+		return 0, 0, errUsage
+	}
+	dependent, err := parseID("dependent id", args[0])
+	if err != nil {
+		return 0, 0, err
+	}
+	dependee, err := parseID("dependee id", args[1])
+	if err != nil {
+		return 0, 0, err
+	}
+	return dependent, dependee, nil
+}
+
+func depend(c *cli, fs *flag.FlagSet) func([]string) error {
+	kind := fs.String("kind", client.Required, "the `kind` of the dependency: required, optional, recommends or conflicts")
+	return func(args []string) error {
+		dependent, dependee, err := parseDependency(args)
+		if err != nil {
+			return err
+		}
+
+		cl, err := c.client()
+		if err != nil {
+			return err
+		}
+		ctx, cancel := c.ctx()
+		defer cancel()
+
+		d, err := cl.CreateModuleDependency(ctx, &client.ModuleDependency{Dependent: dependent, Dependee: dependee, Kind: *kind})
+		if err != nil {
+			return err
+		}
+		return c.print(d, dependenciesTable(d))
+	}
+}
+
+func undepend(c *cli, fs *flag.FlagSet) func([]string) error {
+	return func(args []string) error {
+		dependent, dependee, err := parseDependency(args)
+		if err != nil {
+			return err
+		}
+
+		cl, err := c.client()
+		if err != nil {
+			return err
+		}
+		ctx, cancel := c.ctx()
+		defer cancel()
+
+		n, err := cl.DeleteModuleDependency(ctx, dependent, dependee)
+		if err != nil {
+			return err
+		}
+		return c.print(deleted{n}, deletedTable(n))
+	}
+}
+
+// parseIDs parses a comma separated list of ids.
+func parseIDs(s string) ([]int64, error) {
+	var ids []int64
+	for _, f := range strings.Split(s, ",") {
+		id, err := parseID("module id", strings.TrimSpace(f))
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func insfile(c *cli, fs *flag.FlagSet) func([]string) error {
+	modules := fs.String("modules", "", "comma separated `ids` of the modules")
+	selector := fs.String("selector", "", "a label `selector` choosing the modules")
+	release := fs.String("release", "", "the `name` of a release")
+	env := fs.String("environment", "", "the `environment` whose release is rendered")
+	traverse := fs.Bool("traverse", false, "only render the items of the modules")
+	var opts client.InsfileOptions
+	fs.BoolVar(&opts.Optional, "optional", false, "follow the optional dependencies too")
+	fs.StringVar(&opts.Policy, "policy", "", "the item conflict `policy`: nearest-module, highest-version or error")
+	return func(args []string) error {
+		if len(args) != 0 {
+			return errUsage
+		}
+
+		given := 0
+		for _, s := range []string{*modules, *selector, *release, *env} {
+			if s != "" {
+				given++
+			}
+		}
+		if given != 1 {
+			return fmt.Errorf("give exactly one of -modules, -selector, -release and -environment")
+		}
+
+		var mods client.Modules
+		if *modules != "" {
+			ids, err := parseIDs(*modules)
+			if err != nil {
+				return err
+			}
+			mods.IDs = ids
+		}
+		mods.Selector = *selector
+
+		cl, err := c.client()
+		if err != nil {
+			return err
+		}
+		ctx, cancel := c.ctx()
+		defer cancel()
+
+		if c.format == formatText {
+			var s string
+			switch {
+			case *release != "":
+				s, err = cl.ReleaseInsfileText(ctx, *release, &opts)
+			case *env != "":
+				s, err = cl.EnvironmentInsfileText(ctx, *env, &opts)
+			case *traverse:
+				s, err = cl.TraverseInsfileText(ctx, mods, &opts)
+			default:
+				s, err = cl.InsfileText(ctx, mods, &opts)
+			}
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Fprint(c.stdout, strings.Replace(s, "\r\n", "\n", -1))
+			return err
+		}
+
+		var ins *client.Insfile
+		switch {
+		case *release != "":
+			ins, err = cl.ReleaseInsfile(ctx, *release, &opts)
+		case *env != "":
+			ins, err = cl.EnvironmentInsfile(ctx, *env, &opts)
+		case *traverse:
+			ins, err = cl.TraverseInsfile(ctx, mods, &opts)
+		default:
+			ins, err = cl.Insfile(ctx, mods, &opts)
+		}
+		if err != nil {
+			return err
+		}
+		return c.print(ins, insfileTable(ins))
+	}
+}
+
+// loadCLIConfig loads the config file of the cli and returns it with its
+// path.
+func (c *cli) loadConfig() (*config, string, error) {
+	path, err := configPath(c.configFile)
+	if err != nil {
+		return nil, "", err
+	}
+	conf, err := loadConfig(path)
+	return conf, path, err
+}
+
+// contextEntry is a context as it is printed.
+type contextEntry struct {
+	Name    string `json:"name"`
+	Current bool   `json:"current"`
+	*cliContext
+}
+
+func getContexts(c *cli, fs *flag.FlagSet) func([]string) error {
+	return func(args []string) error {
+		if len(args) != 0 {
+			return errUsage
+		}
+		conf, _, err := c.loadConfig()
+		if err != nil {
+			return err
+		}
+
+		entries := []*contextEntry{}
+		t := &table{header: []string{"CURRENT", "NAME", "SERVER", "INSSERVICE"}}
+		for _, name := range conf.names() {
+			ctx := conf.Contexts[name]
+			current := name == conf.CurrentContext
+			entries = append(entries, &contextEntry{name, current, ctx})
+
+			mark := ""
+			if current {
+				mark = "*"
+			}
+			t.add(mark, name, ctx.Server, orDash(ctx.Insservice))
+		}
+		return c.print(entries, t)
+	}
+}
+
+func currentContext(c *cli, fs *flag.FlagSet) func([]string) error {
+	return func(args []string) error {
+		if len(args) != 0 {
+			return errUsage
+		}
+		conf, _, err := c.loadConfig()
+		if err != nil {
+			return err
+		}
+
+		if conf.CurrentContext == "" {
+			return fmt.Errorf("no current context")
+		}
+		_, err = fmt.Fprintln(c.stdout, conf.CurrentContext)
+		return err
+	}
+}
+
+func useContext(c *cli, fs *flag.FlagSet) func([]string) error {
+	return func(args []string) error {
+		if len(args) != 1 {
+			return errUsage
+		}
+		conf, path, err := c.loadConfig()
+		if err != nil {
+			return err
+		}
+
+		if _, ok := conf.Contexts[args[0]]; !ok {
+			return fmt.Errorf("context %q not found", args[0])
+		}
+		conf.CurrentContext = args[0]
+		return conf.save(path)
+	}
+}
+
+// setContext takes the server of the context from the -server flag every
+// command has.
+func setContext(c *cli, fs *flag.FlagSet) func([]string) error {
+	insservice := fs.String("insservice", "", "the `url` of the insservice if the server is not the apigateway")
+	revealToken := fs.String("reveal-token", "", "the `token` which reveals secret items")
+	principal := fs.String("principal", "", "the `name` promoting and approving releases when the server is the confservice")
+	token := fs.String("token", "", "the `token` authenticating with the apigateway")
+	return func(args []string) error {
+		if len(args) != 1 {
+			return errUsage
+		}
+		conf, path, err := c.loadConfig()
+		if err != nil {
+			return err
+		}
+
+		ctx, ok := conf.Contexts[args[0]]
+		if !ok {
+			ctx = &cliContext{}
+			conf.Contexts[args[0]] = ctx
+		}
+
+		fs.Visit(func(f *flag.Flag) {
+			switch f.Name {
+			case "server":
+				ctx.Server = c.server
+			case "insservice":
+				ctx.Insservice = *insservice
+			case "reveal-token":
+				ctx.RevealToken = *revealToken
+			case "principal":
+				ctx.Principal = *principal
+			case "token":
+				ctx.Token = *token
+			}
+		})
+		if ctx.Server == "" {
+			return fmt.Errorf("context %q needs a server, use -server", args[0])
+		}
+		if conf.CurrentContext == "" {
+			conf.CurrentContext = args[0]
+		}
+		return conf.save(path)
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+)
+
+func completion(c *cli, fs *flag.FlagSet) func([]string) error {
+	return func(args []string) error {
+		if len(args) != 1 {
+			return errUsage
+		}
+
+		switch args[0] {
+		case "bash":
+			return writeBashCompletion(c.stdout)
+		case "zsh":
+			// zsh runs the bash completion through bashcompinit
+			fmt.Fprintln(c.stdout, "autoload -U +X bashcompinit && bashcompinit")
+			return writeBashCompletion(c.stdout)
+		}
+		return fmt.Errorf("no completion for shell %q, use bash or zsh", args[0])
+	}
+}
+
+// flagNames returns the flags of the command as they are typed.
+func flagNames(cmd *command) []string {
+	fs := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+	c := &cli{}
+	c.commonFlags(fs)
+	if cmd.setup != nil {
+		cmd.setup(c, fs)
+	}
+
+	var names []string
+	fs.VisitAll(func(f *flag.Flag) {
+		names = append(names, "-"+f.Name)
+	})
+	sort.Strings(names)
+	return names
+}
+
+// writeBashCompletion writes the bash completion of the commands. The words
+// completed depend on the command typed so far: its sub commands, or its
+// flags once a flag is started.
+func writeBashCompletion(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString(`# bash completion for conmansysctl, load it with
+#   source <(conmansysctl completion bash)
+_conmansysctl() {
+    local cur cmd i
+    cur="${COMP_WORDS[COMP_CWORD]}"
+    cmd=""
+    for ((i = 1; i < COMP_CWORD; i++)); do
+        case "${COMP_WORDS[i]}" in
+            -*) ;;
+            *) cmd="${cmd:+$cmd }${COMP_WORDS[i]}" ;;
+        esac
+    done
+
+    local words="" flags=""
+    case "$cmd" in
+`)
+
+	var top []string
+	for _, cmd := range commands() {
+		top = append(top, cmd.name)
+		writeCase(&b, cmd.name, cmd)
+		for _, sub := range cmd.sub {
+			writeCase(&b, cmd.name+" "+sub.name, sub)
+		}
+	}
+	fmt.Fprintf(&b, "        \"\") words=%q ;;\n", strings.Join(append(top, "help"), " "))
+
+	b.WriteString(`    esac
+
+    case "$cur" in
+        -*) words="$flags" ;;
+    esac
+    COMPREPLY=($(compgen -W "$words" -- "$cur"))
+}
+complete -F _conmansysctl conmansysctl
+`)
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeCase writes the completion of the command typed as name. Commands with
+// a choice of arguments, like bash|zsh, complete them.
+func writeCase(b *strings.Builder, name string, cmd *command) {
+	if len(cmd.sub) > 0 {
+		var subs []string
+		for _, sub := range cmd.sub {
+			subs = append(subs, sub.name)
+		}
+		fmt.Fprintf(b, "        %q) words=%q ;;\n", name, strings.Join(subs, " "))
+		return
+	}
+
+	// the positional arguments and flag values follow the name of a command
+	var words string
+	if strings.Contains(cmd.args, "|") {
+		words = strings.Replace(cmd.args, "|", " ", -1)
+	}
+	fmt.Fprintf(b, "        %q|%q*) words=%q flags=%q ;;\n", name, name+" ", words, strings.Join(flagNames(cmd), " "))
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"gopkg.in/yaml.v2"
+)
+
+// configEnv is the environment variable naming the config file, which is
+// ~/.conmansys/config.yaml if it is not set.
+const configEnv = "CONMANSYSCTL_CONFIG"
+
+// config is the config file of conmansysctl. It holds a context for every
+// conmansys installation and which of them is used by default, e.g.
+//
+//	current-context: local
+//	contexts:
+//	  local:
+//	    server: http://localhost:8079/api
+//	    token: a1b2c3
+//	  direct:
+//	    server: http://localhost:8080
+//	    insservice: http://localhost:8081
+//	    reveal-token: secret
+//	    principal: alice
+type config struct {
+	CurrentContext string                 `yaml:"current-context"`
+	Contexts       map[string]*cliContext `yaml:"contexts"`
+}
+
+// cliContext is where the commands are sent and as whom.
+type cliContext struct {
+	// Server is the /api of the apigateway or the confservice.
+	Server string `yaml:"server"`
+	// Insservice is the insservice if the server is not the apigateway.
+	Insservice  string `yaml:"insservice,omitempty"`
+	RevealToken string `yaml:"reveal-token,omitempty"`
+	// Principal is sent as is to the services, Token authenticates with the
+	// apigateway, which takes the principal from it.
+	Principal string `yaml:"principal,omitempty"`
+	Token     string `yaml:"token,omitempty"`
+}
+
+// defaultServer is the apigateway of docker-compose, which is used if there is
+// no config file.
+const defaultServer = "http://localhost:8079/api"
+
+// configPath returns the path of the config file.
+func configPath(flagPath string) (string, error) {
+	if flagPath != "" {
+		return flagPath, nil
+	}
+	if p, ok := os.LookupEnv(configEnv); ok {
+		return p, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("could not find the config file: %v", err)
+	}
+	return filepath.Join(home, ".conmansys", "config.yaml"), nil
+}
+
+// loadConfig reads the config file at path. A file which does not exist is an
+// empty config.
+func loadConfig(path string) (*config, error) {
+	conf := &config{Contexts: make(map[string]*cliContext)}
+
+	b, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return conf, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read config: %v", err)
+	}
+
+	if err := yaml.UnmarshalStrict(b, conf); err != nil {
+		return nil, fmt.Errorf("could not decode config %v: %v", path, err)
+	}
+	if conf.Contexts == nil {
+		conf.Contexts = make(map[string]*cliContext)
+	}
+
+	return conf, nil
+}
+
+// save writes the config to path, creating its directory if needed.
+func (c *config) save(path string) error {
+	b, err := yaml.Marshal(c)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("could not write config: %v", err)
+	}
+
+	// the file may hold a reveal token
+	if err := ioutil.WriteFile(path, b, 0600); err != nil {
+		return fmt.Errorf("could not write config: %v", err)
+	}
+	return nil
+}
+
+// context returns the context with the name, or the current context if name
+// is empty. Without a config file the default server is used.
+func (c *config) context(name string) (*cliContext, error) {
+	if name == "" {
+		name = c.CurrentContext
+	}
+
+	if name == "" {
+		if len(c.Contexts) == 0 {
+			return &cliContext{Server: defaultServer}, nil
+		}
+		return nil, fmt.Errorf("no current context, use 'conmansysctl config use-context'")
+	}
+
+	ctx, ok := c.Contexts[name]
+	if !ok {
+		return nil, fmt.Errorf("context %q not found", name)
+	}
+	return ctx, nil
+}
+
+// names returns the names of the contexts in order.
+func (c *config) names() []string {
+	names := make([]string, 0, len(c.Contexts))
+	for name := range c.Contexts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,56 @@
+module github.com/Glorforidor/conmansys/conmansysctl
+
+go 1.21
+
+require (
+	github.com/Glorforidor/conmansys/client v0.0.0
+	github.com/Glorforidor/conmansys/confservice v0.0.0-00010101000000-000000000000
+	github.com/Glorforidor/conmansys/insservice v0.0.0-00010101000000-000000000000
+	github.com/Glorforidor/conmansys/labels v0.0.0
+	gopkg.in/yaml.v2 v2.3.0
+)
+
+require (
+	github.com/Glorforidor/conmansys/health v0.0.0 // indirect
+	github.com/Glorforidor/conmansys/logging v0.0.0 // indirect
+	github.com/Glorforidor/conmansys/metrics v0.0.0 // indirect
+	github.com/Glorforidor/conmansys/resolve v0.0.0 // indirect
+	github.com/beorn7/perks v1.0.1 // indirect
+	github.com/cespare/xxhash/v2 v2.1.1 // indirect
+	github.com/felixge/httpsnoop v1.0.2 // indirect
+	github.com/getkin/kin-openapi v0.94.0 // indirect
+	github.com/ghodss/yaml v1.0.0 // indirect
+	github.com/go-logr/logr v1.2.3 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	github.com/go-openapi/jsonpointer v0.19.5 // indirect
+	github.com/go-openapi/swag v0.19.5 // indirect
+	github.com/golang/protobuf v1.5.2 // indirect
+	github.com/gorilla/mux v1.8.0 // indirect
+	github.com/graph-gophers/graphql-go v1.3.0 // indirect
+	github.com/mailru/easyjson v0.0.0-20190626092158-b2ccc519800e // indirect
+	github.com/matttproud/golang_protobuf_extensions v1.0.1 // indirect
+	github.com/opentracing/opentracing-go v1.1.0 // indirect
+	github.com/prometheus/client_golang v1.11.1 // indirect
+	github.com/prometheus/client_model v0.2.0 // indirect
+	github.com/prometheus/common v0.26.0 // indirect
+	github.com/prometheus/procfs v0.6.0 // indirect
+	go.opentelemetry.io/contrib/instrumentation/github.com/gorilla/mux/otelmux v0.31.0 // indirect
+	go.opentelemetry.io/otel v1.6.3 // indirect
+	go.opentelemetry.io/otel/trace v1.6.3 // indirect
+	golang.org/x/sys v0.0.0-20210603081109-ebe580a85c40 // indirect
+	google.golang.org/protobuf v1.28.0 // indirect
+)
+
+replace (
+	github.com/Glorforidor/conmansys/client => ../client
+	github.com/Glorforidor/conmansys/config => ../config
+	github.com/Glorforidor/conmansys/confservice => ../confservice
+	github.com/Glorforidor/conmansys/database => ../database
+	github.com/Glorforidor/conmansys/health => ../health
+	github.com/Glorforidor/conmansys/insservice => ../insservice
+	github.com/Glorforidor/conmansys/labels => ../labels
+	github.com/Glorforidor/conmansys/logging => ../logging
+	github.com/Glorforidor/conmansys/metrics => ../metrics
+	github.com/Glorforidor/conmansys/resolve => ../resolve
+	github.com/Glorforidor/conmansys/tracing => ../tracing
+)
@@ -0,0 +1,221 @@
+// Command conmansysctl manages the items, modules and module dependencies of
+// conmansys and renders insfiles from the command line.
+//
+//	conmansysctl items list --selector team=billing
+//	conmansysctl modules create --value billing --version 0.0.1
+//	conmansysctl link 3 1
+//	conmansysctl depend 1 4 --kind optional
+//	conmansysctl insfile --modules 1,4 --format text
+//
+// The services are taken from the current context of the config file, see
+// 'conmansysctl config'.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Glorforidor/conmansys/client"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// The exit codes of conmansysctl.
+const (
+	exitOK    = 0
+	exitError = 1
+	exitUsage = 2
+)
+
+// cli holds the flags every command takes and where it writes.
+type cli struct {
+	stdout io.Writer
+	stderr io.Writer
+
+	configFile string
+	context    string
+	server     string
+	format     string
+	timeout    time.Duration
+}
+
+// errUsage is returned by a command which is called with wrong arguments. The
+// usage of the command is printed.
+var errUsage = errors.New("usage")
+
+// run runs the command of the arguments and returns the exit code.
+func run(args []string, stdout, stderr io.Writer) int {
+	c := &cli{stdout: stdout, stderr: stderr, format: formatTable, timeout: 30 * time.Second}
+
+	fs := flag.NewFlagSet("conmansysctl", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	c.commonFlags(fs)
+	fs.Usage = func() { c.usage(nil, fs) }
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return exitOK
+		}
+		return exitUsage
+	}
+
+	return c.run(commands(), nil, fs.Args())
+}
+
+// commonFlags defines the flags of every command. They are bound to the cli,
+// so they can be given before and after the command.
+func (c *cli) commonFlags(fs *flag.FlagSet) {
+	fs.StringVar(&c.configFile, "config", c.configFile, fmt.Sprintf("the config `file`, $%v or ~/.conmansys/config.yaml by default", configEnv))
+	fs.StringVar(&c.context, "context", c.context, "the `name` of the context to use instead of the current context")
+	fs.StringVar(&c.server, "server", c.server, "the `url` of the apigateway to use instead of the context")
+	fs.StringVar(&c.format, "format", c.format, "the output `format`: table, json or yaml")
+	fs.StringVar(&c.format, "o", c.format, "short for -format")
+	fs.DurationVar(&c.timeout, "timeout", c.timeout, "how long a command may take")
+}
+
+// run finds the command of the arguments below parent and runs it.
+func (c *cli) run(cmds []*command, parent []string, args []string) int {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		c.printCommands(parent, cmds)
+		if len(args) == 0 {
+			return exitUsage
+		}
+		return exitOK
+	}
+
+	cmd := find(cmds, args[0])
+	if cmd == nil {
+		fmt.Fprintf(c.stderr, "unknown command %q\n", strings.Join(append(parent, args[0]), " "))
+		c.printCommands(parent, cmds)
+		return exitUsage
+	}
+	path := append(parent, cmd.name)
+
+	if len(cmd.sub) > 0 {
+		return c.run(cmd.sub, path, args[1:])
+	}
+
+	fs := flag.NewFlagSet(strings.Join(path, " "), flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	c.commonFlags(fs)
+	action := cmd.setup(c, fs)
+	fs.Usage = func() { c.usage(cmd, fs) }
+
+	pos, err := parseInterspersed(fs, args[1:])
+	if err != nil {
+		if err == flag.ErrHelp {
+			return exitOK
+		}
+		return exitUsage
+	}
+
+	err = action(pos)
+	switch {
+	case err == nil:
+		return exitOK
+	case err == errUsage:
+		c.usage(cmd, fs)
+		return exitUsage
+	}
+
+	c.printError(err)
+	return exitError
+}
+
+// parseInterspersed parses the flags of fs in args, which may come before,
+// between or after the positional arguments, and returns the positional
+// arguments.
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var pos []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		if fs.NArg() == 0 {
+			return pos, nil
+		}
+		pos = append(pos, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
+
+// client returns a client of the services of the context.
+func (c *cli) client() (*client.Client, error) {
+	path, err := configPath(c.configFile)
+	if err != nil {
+		return nil, err
+	}
+	conf, err := loadConfig(path)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := &cliContext{Server: c.server}
+	if c.server == "" {
+		ctx, err = conf.context(c.context)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	opts := []client.Option{client.WithRevealToken(ctx.RevealToken), client.WithPrincipal(ctx.Principal), client.WithToken(ctx.Token)}
+	if ctx.Insservice != "" {
+		opts = append(opts, client.WithInsserviceURL(ctx.Insservice))
+	}
+	return client.New(ctx.Server, opts...)
+}
+
+// ctx returns the context of a command, which ends when the timeout passes.
+func (c *cli) ctx() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), c.timeout)
+}
+
+// printError prints the error with the field errors and conflicts the service
+// responded with.
+func (c *cli) printError(err error) {
+	fmt.Fprintf(c.stderr, "error: %v\n", err)
+
+	var e *client.Error
+	if !errors.As(err, &e) {
+		return
+	}
+	for _, mc := range e.ModuleConflicts {
+		fmt.Fprintf(c.stderr, "  %v\n", mc.Reason)
+	}
+	for _, ic := range e.Conflicts {
+		fmt.Fprintf(c.stderr, "  %v\n", ic.Reason)
+	}
+	if e.Impact != nil {
+		for _, d := range e.Impact.Dependents {
+			fmt.Fprintf(c.stderr, "  module %v %v@%v depends on it\n", d.ID, d.Value, d.Version)
+		}
+	}
+}
+
+// usage prints the usage of the command and its flags.
+func (c *cli) usage(cmd *command, fs *flag.FlagSet) {
+	if cmd == nil {
+		fmt.Fprintf(c.stderr, "usage: conmansysctl [flags] <command>\n\n")
+		c.printCommands(nil, commands())
+		return
+	}
+
+	fmt.Fprintf(c.stderr, "usage: conmansysctl %v [flags] %v\n\n%v\n\nflags:\n", fs.Name(), cmd.args, cmd.summary)
+	fs.PrintDefaults()
+}
+
+// printCommands prints the commands below parent.
+func (c *cli) printCommands(parent []string, cmds []*command) {
+	fmt.Fprintln(c.stderr, "commands:")
+	for _, cmd := range cmds {
+		name := strings.Join(append(append([]string{}, parent...), cmd.name), " ")
+		fmt.Fprintf(c.stderr, "  %-28v %v\n", name+" "+cmd.args, cmd.summary)
+	}
+}
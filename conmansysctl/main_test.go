@@ -0,0 +1,410 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	confhandler "github.com/Glorforidor/conmansys/confservice/handler"
+	confstorage "github.com/Glorforidor/conmansys/confservice/storage"
+	inshandler "github.com/Glorforidor/conmansys/insservice/handler"
+	insstorage "github.com/Glorforidor/conmansys/insservice/storage"
+	labelsel "github.com/Glorforidor/conmansys/labels"
+	"gopkg.in/yaml.v2"
+)
+
+// memory stores the items, modules and their links for both services. The
+// methods which are not needed by the commands are left to the embedded
+// interface.
+type memory struct {
+	confstorage.Service
+
+	mu          sync.Mutex
+	items       []*confstorage.Item
+	modules     []*confstorage.Module
+	itemModules []*confstorage.ItemModule
+	deps        []*confstorage.ModuleDependency
+}
+
+func (m *memory) GetItem(_ context.Context, id int64) (*confstorage.Item, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, i := range m.items {
+		if i.ID == id {
+			return i, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *memory) GetItems(_ context.Context) ([]*confstorage.Item, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]*confstorage.Item{}, m.items...), nil
+}
+
+func (m *memory) CreateItem(_ context.Context, item *confstorage.Item) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	item.ID = int64(len(m.items) + 1)
+	m.items = append(m.items, item)
+	return item.ID, nil
+}
+
+func (m *memory) DeleteItem(_ context.Context, id int64) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, item := range m.items {
+		if item.ID == id {
+			m.items = append(m.items[:i], m.items[i+1:]...)
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
+
+func (m *memory) GetItemType(_ context.Context, name string) (*confstorage.ItemType, error) {
+	return nil, nil
+}
+
+func (m *memory) GetModule(_ context.Context, id int64) (*confstorage.Module, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, mod := range m.modules {
+		if mod.ID == id {
+			return mod, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *memory) GetModules(_ context.Context) ([]*confstorage.Module, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]*confstorage.Module{}, m.modules...), nil
+}
+
+func (m *memory) CreateModule(_ context.Context, module *confstorage.Module) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	module.ID = int64(len(m.modules) + 1)
+	m.modules = append(m.modules, module)
+	return module.ID, nil
+}
+
+func (m *memory) GetItemModules(_ context.Context) ([]*confstorage.ItemModule, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]*confstorage.ItemModule{}, m.itemModules...), nil
+}
+
+func (m *memory) CreateItemModule(_ context.Context, itemID, moduleID int64) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	id := int64(len(m.itemModules) + 1)
+	m.itemModules = append(m.itemModules, &confstorage.ItemModule{ID: id, ItemID: itemID, ModuleID: moduleID})
+	return id, nil
+}
+
+func (m *memory) GetModuleDependencies(_ context.Context) ([]*confstorage.ModuleDependency, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]*confstorage.ModuleDependency{}, m.deps...), nil
+}
+
+func (m *memory) CreateModuleDependency(_ context.Context, md *confstorage.ModuleDependency) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.deps = append(m.deps, md)
+	return nil
+}
+
+// insservice returns the storage of the insservice over the same memory.
+func (m *memory) insservice() insstorage.Service {
+	return insMemory{m}
+}
+
+type insMemory struct {
+	m *memory
+}
+
+func (s insMemory) GetModules(_ context.Context, ids ...int64) ([]*insstorage.Module, error) {
+	modules, _ := s.m.GetModules(context.Background())
+	var ms []*insstorage.Module
+	for _, m := range modules {
+		for _, id := range ids {
+			if m.ID == id {
+				ms = append(ms, &insstorage.Module{ID: m.ID, Value: m.Value, Version: m.Version, Labels: m.Labels})
+				break
+			}
+		}
+	}
+	return ms, nil
+}
+
+func (s insMemory) SelectModules(_ context.Context, sel labelsel.Selector) ([]*insstorage.Module, error) {
+	modules, _ := s.m.GetModules(context.Background())
+	var ms []*insstorage.Module
+	for _, m := range modules {
+		if sel.Matches(m.Labels) {
+			ms = append(ms, &insstorage.Module{ID: m.ID, Value: m.Value, Version: m.Version, Labels: m.Labels})
+		}
+	}
+	return ms, nil
+}
+
+// GetModuleDependencies returns every dependency, which the resolver does not
+// mind as it only follows the ones reached from the ids.
+func (s insMemory) GetModuleDependencies(_ context.Context, _ ...int64) ([]*insstorage.ModuleDependency, error) {
+	deps, _ := s.m.GetModuleDependencies(context.Background())
+	var ds []*insstorage.ModuleDependency
+	for _, d := range deps {
+		ds = append(ds, &insstorage.ModuleDependency{Dependent: d.Dependent, Dependee: d.Dependee, Kind: d.Kind})
+	}
+	return ds, nil
+}
+
+func (s insMemory) GetModuleItems(_ context.Context, ids ...int64) (map[int64][]*insstorage.Item, error) {
+	ims, _ := s.m.GetItemModules(context.Background())
+	byModule := make(map[int64][]*insstorage.Item)
+	for _, id := range ids {
+		for _, im := range ims {
+			if im.ModuleID != id {
+				continue
+			}
+			i, _ := s.m.GetItem(context.Background(), im.ItemID)
+			if i == nil {
+				continue
+			}
+			byModule[id] = append(byModule[id], &insstorage.Item{ID: i.ID, Value: i.Value, Type: i.Type, Version: i.Version})
+		}
+	}
+	return byModule, nil
+}
+
+func (s insMemory) GetRelease(_ context.Context, name string) (*insstorage.Release, error) {
+	return nil, nil
+}
+
+func (s insMemory) GetEnvironmentPromotion(_ context.Context, env string) (*insstorage.Promotion, error) {
+	return nil, nil
+}
+
+// newServer returns the services over memory behind one server, like the
+// apigateway.
+func newServer() *httptest.Server {
+	m := &memory{}
+	conf := confhandler.New(m)
+	ins := inshandler.New(m.insservice())
+
+	mux := http.NewServeMux()
+	mux.Handle("/", conf)
+	mux.Handle("/insfile", ins)
+	mux.Handle("/insfile/", ins)
+	return httptest.NewServer(mux)
+}
+
+// ctl runs conmansysctl with the arguments and returns the exit code, stdout
+// and stderr.
+func ctl(args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := run(args, &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestCommands(t *testing.T) {
+	srv := newServer()
+	defer srv.Close()
+
+	// the commands build on each other, so they run in order
+	steps := []struct {
+		args   []string
+		code   int
+		stdout []string
+		stderr []string
+	}{
+		{
+			args:   []string{"modules", "create", "-value", "billing", "-version", "0.0.1", "-label", "team=billing"},
+			stdout: []string{"ID", "billing", "0.0.1", "team=billing"},
+		},
+		{
+			args:   []string{"modules", "create", "-value", "payment", "-version", "0.0.2"},
+			stdout: []string{"2", "payment"},
+		},
+		{
+			args:   []string{"items", "create", "-value", "window", "-type", "payment_window", "-version", "1.0.0"},
+			stdout: []string{"window", "payment_window"},
+		},
+		{
+			args:   []string{"items", "create", "-value", "refund", "-type", "refund", "-version", "1.0.0"},
+			stdout: []string{"refund"},
+		},
+		{args: []string{"link", "1", "1"}, stdout: []string{"ITEM", "MODULE"}},
+		{args: []string{"link", "2", "2"}},
+		{args: []string{"depend", "1", "2"}, stdout: []string{"DEPENDENT", "required"}},
+		{
+			args:   []string{"insfile", "--modules", "1", "--format", "text"},
+			stdout: []string{"items", "window", "modules"},
+		},
+		{args: []string{"insfile", "-modules", "1"}, stdout: []string{"VALUE", "window"}},
+		{args: []string{"insfile", "-selector", "team=billing", "-traverse"}, stdout: []string{"window", "refund"}},
+		{args: []string{"modules", "list", "-selector", "team=billing"}, stdout: []string{"billing"}},
+		{args: []string{"modules", "graph", "1"}, stdout: []string{"DEPTH", "payment"}},
+		{args: []string{"items", "get", "2", "-o", "json"}, stdout: []string{`"value": "refund"`}},
+		{args: []string{"-o", "yaml", "items", "get", "1"}, stdout: []string{"value: window", "type: payment_window"}},
+		{args: []string{"items", "delete", "2"}, stdout: []string{"ROWS AFFECTED", "1"}},
+		{args: []string{"items", "get", "2"}, code: exitError, stderr: []string{"not found"}},
+		{args: []string{"modules", "create", "-version", "1"}, code: exitError, stderr: []string{"missing value", "400"}},
+		{args: []string{"items", "get"}, code: exitUsage, stderr: []string{"usage: conmansysctl items get"}},
+		{args: []string{"items", "get", "a"}, code: exitError, stderr: []string{"id must be a positive number"}},
+		{args: []string{"items", "list", "-o", "xml"}, code: exitError, stderr: []string{"unknown format"}},
+		{args: []string{"insfile"}, code: exitError, stderr: []string{"exactly one"}},
+		{args: []string{"items", "fetch"}, code: exitUsage, stderr: []string{`unknown command "items fetch"`}},
+	}
+
+	for _, s := range steps {
+		args := append(s.args, "-server", srv.URL, "-config", filepath.Join(t.TempDir(), "none.yaml"))
+		code, stdout, stderr := ctl(args...)
+		if code != s.code {
+			t.Fatalf("%v: expected exit code: %v, got: %v\nstdout: %v\nstderr: %v", s.args, s.code, code, stdout, stderr)
+		}
+		for _, want := range s.stdout {
+			if !strings.Contains(stdout, want) {
+				t.Errorf("%v: expected %q in stdout: %v", s.args, want, stdout)
+			}
+		}
+		for _, want := range s.stderr {
+			if !strings.Contains(stderr, want) {
+				t.Errorf("%v: expected %q in stderr: %v", s.args, want, stderr)
+			}
+		}
+	}
+}
+
+func TestFormats(t *testing.T) {
+	srv := newServer()
+	defer srv.Close()
+
+	flags := []string{"-server", srv.URL}
+	for _, v := range []string{"a", "b"} {
+		if code, _, stderr := ctl(append([]string{"modules", "create", "-value", v, "-version", "1"}, flags...)...); code != exitOK {
+			t.Fatal(stderr)
+		}
+	}
+
+	tt := map[string]struct {
+		format string
+		decode func([]byte, interface{}) error
+	}{
+		"json": {format: "json", decode: json.Unmarshal},
+		"yaml": {format: "yaml", decode: yaml.Unmarshal},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			code, stdout, stderr := ctl(append([]string{"modules", "list", "-format", tc.format}, flags...)...)
+			if code != exitOK {
+				t.Fatal(stderr)
+			}
+
+			var modules []struct {
+				ID    int64  `json:"id" yaml:"id"`
+				Value string `json:"value" yaml:"value"`
+			}
+			if err := tc.decode([]byte(stdout), &modules); err != nil {
+				t.Fatalf("expected %v, got: %v: %v", tc.format, stdout, err)
+			}
+			if len(modules) != 2 || modules[1].Value != "b" {
+				t.Fatalf("expected the modules, got: %+v", modules)
+			}
+		})
+	}
+}
+
+func TestConfig(t *testing.T) {
+	srv := newServer()
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "conmansys", "config.yaml")
+	os.Setenv(configEnv, path)
+	defer os.Unsetenv(configEnv)
+
+	if code, _, stderr := ctl("config", "set-context", "local", "-server", srv.URL, "-token", "t1"); code != exitOK {
+		t.Fatal(stderr)
+	}
+	if code, _, stderr := ctl("config", "set-context", "down", "-server", "http://127.0.0.1:1", "-principal", "alice"); code != exitOK {
+		t.Fatal(stderr)
+	}
+
+	code, stdout, _ := ctl("config", "get-contexts")
+	if code != exitOK || !strings.Contains(stdout, "*        local") || !strings.Contains(stdout, "down") {
+		t.Fatalf("expected both contexts with local as the current, got: %v", stdout)
+	}
+
+	if code, _, stderr := ctl("modules", "list"); code != exitOK {
+		t.Fatalf("expected the current context to be used, got: %v", stderr)
+	}
+
+	if code, _, stderr := ctl("config", "use-context", "down"); code != exitOK {
+		t.Fatal(stderr)
+	}
+	if _, stdout, _ := ctl("config", "current-context"); strings.TrimSpace(stdout) != "down" {
+		t.Fatalf("expected down to be the current context, got: %v", stdout)
+	}
+	if code, _, _ := ctl("modules", "list", "-timeout", "1s"); code != exitError {
+		t.Fatalf("expected the down context to fail, got: %v", code)
+	}
+	if code, _, stderr := ctl("modules", "list", "-context", "local"); code != exitOK {
+		t.Fatalf("expected the context flag to win, got: %v", stderr)
+	}
+
+	if code, _, stderr := ctl("config", "use-context", "missing"); code != exitError || !strings.Contains(stderr, "not found") {
+		t.Fatalf("expected a missing context to fail, got: %v %v", code, stderr)
+	}
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var conf config
+	if err := yaml.Unmarshal(b, &conf); err != nil {
+		t.Fatal(err)
+	}
+	if conf.CurrentContext != "down" || conf.Contexts["down"].Principal != "alice" || conf.Contexts["local"].Token != "t1" {
+		t.Fatalf("expected the config to be saved, got: %+v", conf)
+	}
+}
+
+func TestCompletion(t *testing.T) {
+	tt := map[string]struct {
+		shell string
+		code  int
+		want  []string
+	}{
+		"bash":    {shell: "bash", want: []string{"complete -F _conmansysctl conmansysctl", `"items") words="list get create delete"`, "-selector"}},
+		"zsh":     {shell: "zsh", want: []string{"bashcompinit", "complete -F"}},
+		"unknown": {shell: "fish", code: exitError},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			code, stdout, _ := ctl("completion", tc.shell)
+			if code != tc.code {
+				t.Fatalf("expected exit code: %v, got: %v", tc.code, code)
+			}
+			for _, want := range tc.want {
+				if !strings.Contains(stdout, want) {
+					t.Errorf("expected %q in: %v", want, stdout)
+				}
+			}
+		})
+	}
+}
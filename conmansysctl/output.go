@@ -0,0 +1,156 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/Glorforidor/conmansys/client"
+	"gopkg.in/yaml.v2"
+)
+
+// The output formats.
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+	formatYAML  = "yaml"
+	// formatText is the text of the insfile routes, only insfile supports it.
+	formatText = "text"
+)
+
+// table is the table format of a result.
+type table struct {
+	header []string
+	rows   [][]string
+}
+
+func (t *table) add(cells ...interface{}) {
+	row := make([]string, len(cells))
+	for i, c := range cells {
+		row[i] = fmt.Sprint(c)
+	}
+	t.rows = append(t.rows, row)
+}
+
+// print writes v in the format of the cli. t is v as a table.
+func (c *cli) print(v interface{}, t *table) error {
+	switch c.format {
+	case formatTable:
+		w := tabwriter.NewWriter(c.stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, strings.Join(t.header, "\t"))
+		for _, row := range t.rows {
+			fmt.Fprintln(w, strings.Join(row, "\t"))
+		}
+		return w.Flush()
+	case formatJSON:
+		b, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(c.stdout, string(b))
+		return err
+	case formatYAML:
+		// the types only have JSON tags, so v goes through JSON to keep the
+		// names of the API
+		b, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		var y interface{}
+		if err := yaml.Unmarshal(b, &y); err != nil {
+			return err
+		}
+		b, err = yaml.Marshal(y)
+		if err != nil {
+			return err
+		}
+		_, err = c.stdout.Write(b)
+		return err
+	case formatText:
+		return fmt.Errorf("format %q is only supported by insfile", c.format)
+	}
+	return fmt.Errorf("unknown format %q, use table, json or yaml", c.format)
+}
+
+// labels formats labels as a selector.
+func labels(l map[string]string) string {
+	if len(l) == 0 {
+		return "-"
+	}
+
+	pairs := make([]string, 0, len(l))
+	for k, v := range l {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// orDash returns "-" for an empty cell.
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func itemsTable(items ...*client.Item) *table {
+	t := &table{header: []string{"ID", "VALUE", "TYPE", "VERSION", "SECRET", "LABELS", "OWNER"}}
+	for _, i := range items {
+		t.add(i.ID, orDash(i.Value), orDash(i.Type), orDash(i.Version), i.Secret, labels(i.Labels), orDash(i.Owner))
+	}
+	return t
+}
+
+func modulesTable(modules ...*client.Module) *table {
+	t := &table{header: []string{"ID", "VALUE", "VERSION", "LABELS", "OWNER"}}
+	for _, m := range modules {
+		t.add(m.ID, orDash(m.Value), orDash(m.Version), labels(m.Labels), orDash(m.Owner))
+	}
+	return t
+}
+
+func itemModulesTable(ims ...*client.ItemModule) *table {
+	t := &table{header: []string{"ID", "ITEM", "MODULE"}}
+	for _, im := range ims {
+		t.add(im.ID, im.ItemID, im.ModuleID)
+	}
+	return t
+}
+
+func dependenciesTable(deps ...*client.ModuleDependency) *table {
+	t := &table{header: []string{"DEPENDENT", "DEPENDEE", "KIND"}}
+	for _, d := range deps {
+		t.add(d.Dependent, d.Dependee, orDash(d.Kind))
+	}
+	return t
+}
+
+func graphTable(g *client.Graph) *table {
+	t := &table{header: []string{"ID", "VALUE", "VERSION", "DEPTH"}}
+	for _, n := range g.Nodes {
+		t.add(n.ID, orDash(n.Value), orDash(n.Version), n.Depth)
+	}
+	return t
+}
+
+func insfileTable(ins *client.Insfile) *table {
+	t := &table{header: []string{"VALUE", "TYPE", "VERSION"}}
+	for _, i := range ins.Items {
+		t.add(orDash(i.Value), orDash(i.Type), orDash(i.Version))
+	}
+	return t
+}
+
+// deleted is the result of a delete.
+type deleted struct {
+	RowsAffected int64 `json:"rows_affected"`
+}
+
+func deletedTable(n int64) *table {
+	t := &table{header: []string{"ROWS AFFECTED"}}
+	t.add(n)
+	return t
+}
@@ -1,24 +1,59 @@
 -- Drop tables if they exists. Useful to flush data
+DROP TABLE IF EXISTS conf_promotion;
+DROP TABLE IF EXISTS conf_release;
 DROP TABLE IF EXISTS conf_module_dependency;
 DROP TABLE IF EXISTS conf_item_module;
 DROP TABLE IF EXISTS conf_item;
 DROP TABLE IF EXISTS conf_module;
+DROP TABLE IF EXISTS conf_item_type;
 
 -- Create conf_item table. 
 -- Table can not consist of null data since it would not make since.
+-- The value of a secret item is stored envelope encrypted.
+-- Labels are a flat JSON object of strings which label selectors match on.
+-- Annotations are free-form JSON. The timestamps are maintained by the
+-- confservice.
 CREATE TABLE conf_item(
 	conf_item_id SERIAL PRIMARY KEY,
 	conf_item_value TEXT NOT NULL,
 	conf_item_type TEXT NOT NULL,
-	conf_item_version TEXT NOT NULL
+	conf_item_version TEXT NOT NULL,
+	conf_item_secret BOOLEAN NOT NULL DEFAULT false,
+	conf_item_labels JSONB NOT NULL DEFAULT '{}',
+	conf_item_description TEXT NOT NULL DEFAULT '',
+	conf_item_owner TEXT NOT NULL DEFAULT '',
+	conf_item_homepage TEXT NOT NULL DEFAULT '',
+	conf_item_annotations JSONB NOT NULL DEFAULT '{}',
+	conf_item_created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	conf_item_updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Create conf_item_type table.
+-- This table holds the validators for the values of items with the given type.
+-- A validator which is empty or null is not checked and items with a type
+-- which is not in this table are not validated at all.
+CREATE TABLE conf_item_type(
+	conf_item_type_name TEXT PRIMARY KEY,
+	conf_item_type_pattern TEXT NOT NULL DEFAULT '',
+	conf_item_type_enum TEXT[],
+	conf_item_type_min BIGINT,
+	conf_item_type_max BIGINT
 );
 
 -- Create conf_module table.
 -- Table can not consist of null data since it would not make since.
+-- The labels, annotations and timestamps are the same as for conf_item.
 CREATE TABLE conf_module(
 	conf_module_id SERIAL PRIMARY KEY,
 	conf_module_value TEXT NOT NULL,
-	conf_module_version TEXT NOT NULL
+	conf_module_version TEXT NOT NULL,
+	conf_module_labels JSONB NOT NULL DEFAULT '{}',
+	conf_module_description TEXT NOT NULL DEFAULT '',
+	conf_module_owner TEXT NOT NULL DEFAULT '',
+	conf_module_homepage TEXT NOT NULL DEFAULT '',
+	conf_module_annotations JSONB NOT NULL DEFAULT '{}',
+	conf_module_created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	conf_module_updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
 );
 
 -- Create conf_item_module table.
@@ -37,11 +72,47 @@ CREATE TABLE conf_item_module(
 -- This is a weak entity making it possible for modules depend on one another.
 -- It checks for that dependent and dependee are not the same, since this will
 -- make a module depend on itself.
+-- The kind decides how the dependee is part of the insfile of the dependent.
 CREATE TABLE conf_module_dependency(
 	dependent int,
 	dependee int,
+	kind TEXT NOT NULL DEFAULT 'required'
+		CHECK (kind IN ('required', 'optional', 'conflicts', 'recommends')),
     FOREIGN KEY (dependent) REFERENCES conf_module (conf_module_id),
     FOREIGN KEY (dependee) REFERENCES conf_module (conf_module_id),
 	PRIMARY KEY (dependent, dependee),
     CONSTRAINT must_be_different CHECK (dependent != dependee)
 );
+
+-- Create conf_release table.
+-- A release freezes the requested modules and the modules and items of their
+-- resolved closure as JSON, so it does not change with the live tables and
+-- has no foreign keys into them. Releases are never updated. The values of
+-- secret items are stored envelope encrypted like in conf_item.
+CREATE TABLE conf_release(
+	conf_release_name TEXT PRIMARY KEY,
+	conf_release_modules JSONB NOT NULL,
+	conf_release_optional BOOLEAN NOT NULL DEFAULT false,
+	conf_release_closure JSONB NOT NULL,
+	conf_release_created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Create conf_promotion table.
+-- Every promotion of a release to an environment is kept. A promotion into a
+-- protected environment is pending until it is approved. The release which was
+-- promoted last to an environment is the one in it.
+CREATE TABLE conf_promotion(
+	conf_promotion_id SERIAL PRIMARY KEY,
+	conf_promotion_release TEXT NOT NULL REFERENCES conf_release (conf_release_name),
+	conf_promotion_environment TEXT NOT NULL,
+	conf_promotion_status TEXT NOT NULL CHECK (conf_promotion_status IN ('pending', 'promoted')),
+	conf_promotion_requested_by TEXT NOT NULL,
+	conf_promotion_requested_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	conf_promotion_approved_by TEXT NOT NULL DEFAULT '',
+	conf_promotion_promoted_at TIMESTAMPTZ
+);
+
+-- Only one promotion of a release to an environment can wait for approval.
+CREATE UNIQUE INDEX conf_promotion_pending
+	ON conf_promotion (conf_promotion_release, conf_promotion_environment)
+	WHERE conf_promotion_status = 'pending';
@@ -0,0 +1,74 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Config is the database of a service as it is configured. The services inline
+// it into their configuration, so the keys, variables and flags are the same
+// for all of them.
+type Config struct {
+	Host            string        `yaml:"db_host" env:"DBHOST" flag:"db-host" usage:"host of the database"`
+	Port            string        `yaml:"db_port" env:"DBPORT" flag:"db-port" usage:"port of the database"`
+	User            string        `yaml:"db_user" env:"DBUSER" flag:"db-user" usage:"user of the database"`
+	Pass            string        `yaml:"db_pass" env:"DBPASS" flag:"db-pass" secret:"true" usage:"password of the database user"`
+	Name            string        `yaml:"db_name" env:"DBNAME" flag:"db-name" usage:"name of the database"`
+	DSN             string        `yaml:"db_dsn" env:"DBDSN" flag:"db-dsn" secret:"true" usage:"connection string of the database, used instead of the host, port, user, password and name"`
+	SSLMode         string        `yaml:"db_sslmode" env:"DBSSLMODE" flag:"db-sslmode" usage:"sslmode of the database connection: disable, require, verify-ca or verify-full"`
+	SSLRootCert     string        `yaml:"db_sslrootcert" env:"DBSSLROOTCERT" flag:"db-sslrootcert" usage:"file of the certificate authorities the database is verified against"`
+	MaxOpenConns    int           `yaml:"db_max_open_conns" env:"DB_MAX_OPEN_CONNS" flag:"db-max-open-conns" usage:"maximum number of open database connections, 0 for no limit"`
+	MaxIdleConns    int           `yaml:"db_max_idle_conns" env:"DB_MAX_IDLE_CONNS" flag:"db-max-idle-conns" usage:"maximum number of idle database connections"`
+	ConnMaxLifetime time.Duration `yaml:"db_conn_max_lifetime" env:"DB_CONN_MAX_LIFETIME" flag:"db-conn-max-lifetime" usage:"how long a database connection is reused, 0 for forever"`
+	ConnectRetries  int           `yaml:"db_connect_retries" env:"DB_CONNECT_RETRIES" flag:"db-connect-retries" usage:"how often connecting to the database is retried at startup"`
+	ConnectBackoff  time.Duration `yaml:"db_connect_backoff" env:"DB_CONNECT_BACKOFF" flag:"db-connect-backoff" usage:"wait before the first retry, doubled for every next one"`
+}
+
+// Defaults returns the configuration used for what is not set.
+func Defaults() Config {
+	return Config{
+		Port:           "5432",
+		MaxIdleConns:   2,
+		ConnectRetries: 10,
+		ConnectBackoff: time.Second,
+	}
+}
+
+// Options returns the connection string and the options of Open of the
+// configuration.
+func (c Config) Options() (string, []Option, error) {
+	var opts []Option
+
+	dsn := c.DSN
+	if dsn == "" {
+		if c.Host == "" || c.User == "" || c.Name == "" {
+			return "", nil, errors.New("db_host, db_user and db_name or db_dsn required but not set")
+		}
+		dsn = DSN(c.Host, c.Port, c.User, c.Pass, c.Name)
+		opts = append(opts, WithSSLMode("disable"))
+	}
+
+	if c.SSLMode != "" {
+		opts = append(opts, WithSSLMode(c.SSLMode))
+	}
+	if c.SSLRootCert != "" {
+		opts = append(opts, WithSSLRootCert(c.SSLRootCert))
+	}
+
+	if c.ConnectRetries < 0 {
+		return "", nil, fmt.Errorf("db_connect_retries must be at least 0, got: %v", c.ConnectRetries)
+	}
+	if c.ConnectBackoff <= 0 {
+		return "", nil, fmt.Errorf("db_connect_backoff must be a positive duration, got: %v", c.ConnectBackoff)
+	}
+
+	opts = append(opts,
+		WithMaxOpenConns(c.MaxOpenConns),
+		WithMaxIdleConns(c.MaxIdleConns),
+		WithConnMaxLifetime(c.ConnMaxLifetime),
+		WithRetry(c.ConnectRetries, c.ConnectBackoff),
+	)
+
+	return dsn, opts, nil
+}
@@ -0,0 +1,116 @@
+package database
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConnString(t *testing.T) {
+	tt := map[string]struct {
+		dsn  string
+		opts options
+		want string
+	}{
+		"keyword/value": {
+			dsn:  DSN("db", "5432", "postgres", "it's secret", ""),
+			want: `host=db port=5432 user=postgres password='it\'s secret' dbname=''`,
+		},
+		"url": {
+			dsn:  "postgres://postgres:secret@db:5432/conmansys?sslmode=require",
+			want: "dbname=conmansys host=db password=secret port=5432 sslmode=require user=postgres",
+		},
+		"ssl overrides": {
+			dsn:  "host=db sslmode=disable",
+			opts: options{sslMode: "verify-full", sslRootCert: "/etc/ssl/root ca.crt"},
+			want: "host=db sslmode=disable sslmode=verify-full sslrootcert='/etc/ssl/root ca.crt'",
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			got, err := connString(tc.dsn, tc.opts)
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if got != tc.want {
+				t.Fatalf("expected: %q, got: %q", tc.want, got)
+			}
+		})
+	}
+
+	if _, err := connString("postgres://postgres:secret@db:port", options{}); err == nil || strings.Contains(err.Error(), "secret") {
+		t.Fatalf("expected an error without the password, got: %v", err)
+	}
+}
+
+func TestOpenRetry(t *testing.T) {
+	backoff := 10 * time.Millisecond
+	start := time.Now()
+	// nothing listens on port 1
+	dsn := DSN("localhost", "1", "postgres", "secret", "postgres")
+	_, _, err := Open(dsn, WithSSLMode("disable"), WithRetry(2, backoff))
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if took := time.Since(start); took < 3*backoff {
+		t.Fatalf("expected to wait %v before giving up, got: %v", 3*backoff, took)
+	}
+}
+
+func TestConfigOptions(t *testing.T) {
+	withHost := Defaults()
+	withHost.Host, withHost.User, withHost.Name = "db", "postgres", "conmansys"
+
+	withDSN := Defaults()
+	withDSN.DSN = "postgres://postgres:secret@db/conmansys"
+
+	noBackoff := withHost
+	noBackoff.ConnectBackoff = 0
+
+	tt := map[string]struct {
+		cfg  Config
+		dsn  string
+		opts options
+		err  bool
+	}{
+		"host": {
+			cfg:  withHost,
+			dsn:  "host=db port=5432 user=postgres password='' dbname=conmansys",
+			opts: options{sslMode: "disable", maxIdle: 2, retries: 10, backoff: time.Second},
+		},
+		"dsn": {
+			cfg:  withDSN,
+			dsn:  withDSN.DSN,
+			opts: options{maxIdle: 2, retries: 10, backoff: time.Second},
+		},
+		"missing host": {cfg: Defaults(), err: true},
+		"no backoff":   {cfg: noBackoff, err: true},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			dsn, opts, err := tc.cfg.Options()
+			if err != nil {
+				if !tc.err {
+					t.Fatalf("expected no error, got: %v", err)
+				}
+				return
+			}
+			if tc.err {
+				t.Fatal("expected an error")
+			}
+
+			if dsn != tc.dsn {
+				t.Fatalf("expected dsn: %q, got: %q", tc.dsn, dsn)
+			}
+			var o options
+			for _, opt := range opts {
+				opt(&o)
+			}
+			if o != tc.opts {
+				t.Fatalf("expected options: %+v, got: %+v", tc.opts, o)
+			}
+		})
+	}
+}
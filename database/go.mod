@@ -0,0 +1,16 @@
+module github.com/Glorforidor/conmansys/database
+
+go 1.21
+
+require (
+	github.com/XSAM/otelsql v0.13.0
+	github.com/lib/pq v1.1.1
+)
+
+require (
+	github.com/go-logr/logr v1.2.3 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	go.opentelemetry.io/otel v1.6.1 // indirect
+	go.opentelemetry.io/otel/metric v0.28.0 // indirect
+	go.opentelemetry.io/otel/trace v1.6.1 // indirect
+)
@@ -2,18 +2,30 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"flag"
 	"fmt"
 	"html/template"
 	"io/ioutil"
-	"log"
+	"log/slog"
 	"net/http"
+	neturl "net/url"
 	"os"
+	"os/signal"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/Glorforidor/conmansys/config"
+	"github.com/Glorforidor/conmansys/health"
+	"github.com/Glorforidor/conmansys/logging"
+	"github.com/Glorforidor/conmansys/metrics"
+	"github.com/Glorforidor/conmansys/tracing"
 	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gorilla/mux/otelmux"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 )
 
 // helper structs since for code clearness. The struct are not used in the
@@ -21,16 +33,23 @@ import (
 // map[string]interface{} for templates - seems easier to do.. for now.
 
 type item struct {
-	ID      int64  `json:"id"`
-	Value   string `json:"value"`
-	Type    string `json:"type"`
-	Version string `json:"version"`
+	ID          int64  `json:"id"`
+	Value       string `json:"value"`
+	Type        string `json:"type"`
+	Version     string `json:"version"`
+	Secret      bool   `json:"secret"`
+	Description string `json:"description"`
+	Owner       string `json:"owner"`
+	Homepage    string `json:"homepage"`
 }
 
 type module struct {
-	ID      int64  `json:"id"`
-	Value   string `json:"value"`
-	Version string `json:"version"`
+	ID          int64  `json:"id"`
+	Value       string `json:"value"`
+	Version     string `json:"version"`
+	Description string `json:"description"`
+	Owner       string `json:"owner"`
+	Homepage    string `json:"homepage"`
 }
 
 type itemModule struct {
@@ -39,8 +58,9 @@ type itemModule struct {
 }
 
 type moduleDependency struct {
-	Dependent int64 `json:"dependent"`
-	Dependee  int64 `json:"dependee"`
+	Dependent int64  `json:"dependent"`
+	Dependee  int64  `json:"dependee"`
+	Kind      string `json:"kind,omitempty"`
 }
 
 // lets define a new type which can be used as an enum.
@@ -62,6 +82,10 @@ const (
 	moduleDependencyType
 )
 
+// client sends the requests to the apigateway with the request id and the trace
+// context of the request they are made for, and traces them.
+var client = &http.Client{Transport: otelhttp.NewTransport(logging.Transport{})}
+
 func save(c conmansys, r *http.Request) ([]byte, int, error) {
 	val := strings.TrimSpace(r.FormValue("value"))
 	t := strings.TrimSpace(r.FormValue("type"))
@@ -70,6 +94,11 @@ func save(c conmansys, r *http.Request) ([]byte, int, error) {
 	moduleID := strings.TrimSpace(r.FormValue("module_id"))
 	dependentID := strings.TrimSpace(r.FormValue("dependent_id"))
 	dependeeID := strings.TrimSpace(r.FormValue("dependee_id"))
+	kind := strings.TrimSpace(r.FormValue("kind"))
+	secret := r.FormValue("secret") == "true"
+	description := strings.TrimSpace(r.FormValue("description"))
+	owner := strings.TrimSpace(r.FormValue("owner"))
+	homepage := strings.TrimSpace(r.FormValue("homepage"))
 
 	var data interface{}
 	switch c {
@@ -81,14 +110,20 @@ func save(c conmansys, r *http.Request) ([]byte, int, error) {
 			)
 		}
 
-		data = item{Value: val, Type: t, Version: ver}
+		data = item{
+			Value: val, Type: t, Version: ver, Secret: secret,
+			Description: description, Owner: owner, Homepage: homepage,
+		}
 	case moduleType:
 		if len(val) == 0 || len(ver) == 0 {
 			return nil, http.StatusBadRequest, fmt.Errorf(
 				"May not have empty input: Value: %q, Version: %q", val, ver)
 		}
 
-		data = module{Value: val, Version: ver}
+		data = module{
+			Value: val, Version: ver,
+			Description: description, Owner: owner, Homepage: homepage,
+		}
 	case itemModuleType:
 		if len(itemID) == 0 || len(moduleID) == 0 {
 			return nil, http.StatusBadRequest, fmt.Errorf(
@@ -112,12 +147,12 @@ func save(c conmansys, r *http.Request) ([]byte, int, error) {
 		d, _ := strconv.ParseInt(dependentID, 10, 64)
 		dd, _ := strconv.ParseInt(dependeeID, 10, 64)
 
-		data = moduleDependency{Dependent: d, Dependee: dd}
+		data = moduleDependency{Dependent: d, Dependee: dd, Kind: kind}
 	}
 
 	b, err := json.Marshal(data)
 	if err != nil {
-		log.Printf("could not marshal data: %v, error: %v", data, err)
+		logging.FromContext(r.Context()).Error("could not marshal data", "data", data, "error", err)
 		return nil, http.StatusInternalServerError, err
 	}
 
@@ -132,13 +167,13 @@ func saveHandler(c conmansys, target string) http.HandlerFunc {
 			return
 		}
 
-		req, err := http.NewRequest(http.MethodPost, target, bytes.NewReader(b))
+		req, err := http.NewRequestWithContext(r.Context(), http.MethodPost, target, bytes.NewReader(b))
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
 
-		resp, err := http.DefaultClient.Do(req)
+		resp, err := client.Do(req)
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
@@ -164,16 +199,16 @@ func saveHandler(c conmansys, target string) http.HandlerFunc {
 		}
 
 		m["string"] = c.String()
-		log.Printf("%#v", m)
+		logging.FromContext(r.Context()).Debug("saved", "response", m)
 
-		renderTemplate(w, "save.html", m)
+		renderTemplate(w, r, "save.html", m)
 	}
 }
 
 func createHandler(c conmansys) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		m := map[string]interface{}{"string": c.String()}
-		renderTemplate(w, "create.html", m)
+		renderTemplate(w, r, "create.html", m)
 	}
 }
 
@@ -205,16 +240,16 @@ func deleteModDepHandler(c conmansys, target string) http.HandlerFunc {
 			return
 		}
 
-		req, err := http.NewRequest(http.MethodDelete, url, nil)
+		req, err := http.NewRequestWithContext(r.Context(), http.MethodDelete, url, nil)
 		if err != nil {
-			log.Printf("could not create DELETE request: %v", err)
+			logging.FromContext(r.Context()).Error("could not create DELETE request", "error", err)
 			http.NotFound(w, r)
 			return
 		}
 
-		resp, err := http.DefaultClient.Do(req)
+		resp, err := client.Do(req)
 		if err != nil {
-			log.Printf("could not create DELETE request: %v", err)
+			logging.FromContext(r.Context()).Error("could not create DELETE request", "error", err)
 			http.NotFound(w, r)
 			return
 		}
@@ -222,11 +257,11 @@ func deleteModDepHandler(c conmansys, target string) http.HandlerFunc {
 
 		b, err := ioutil.ReadAll(resp.Body)
 		if err != nil {
-			log.Println(err.Error())
+			logging.FromContext(r.Context()).Error("could not read response body", "error", err)
 			return
 		}
 
-		log.Println(string(b))
+		logging.FromContext(r.Context()).Debug("deleted", "response", string(b))
 
 		m := make(map[string]interface{})
 		m["id"] = fmt.Sprintf("%v %v", dependentID, dependeeID)
@@ -234,12 +269,12 @@ func deleteModDepHandler(c conmansys, target string) http.HandlerFunc {
 		//err = json.NewDecoder(resp.Body).Decode(&m)
 		err = json.Unmarshal(b, &m)
 		if err != nil {
-			log.Printf("could not decode response body: %v", err)
+			logging.FromContext(r.Context()).Error("could not decode response body", "error", err)
 			http.NotFound(w, r)
 			return
 		}
 
-		renderTemplate(w, "delete.html", m)
+		renderTemplate(w, r, "delete.html", m)
 	}
 }
 
@@ -249,16 +284,21 @@ func deleteHandler(c conmansys, target string) http.HandlerFunc {
 		id := params["id"]
 
 		url := fmt.Sprintf("%v/%v", target, id)
-		req, err := http.NewRequest(http.MethodDelete, url, nil)
+		// pass options like ?force on to the api gateway
+		if r.URL.RawQuery != "" {
+			url = url + "?" + r.URL.RawQuery
+		}
+
+		req, err := http.NewRequestWithContext(r.Context(), http.MethodDelete, url, nil)
 		if err != nil {
-			log.Printf("could not create DELETE request: %v", err)
+			logging.FromContext(r.Context()).Error("could not create DELETE request", "error", err)
 			http.NotFound(w, r)
 			return
 		}
 
-		resp, err := http.DefaultClient.Do(req)
+		resp, err := client.Do(req)
 		if err != nil {
-			log.Printf("could not create DELETE request: %v", err)
+			logging.FromContext(r.Context()).Error("could not create DELETE request", "error", err)
 			http.NotFound(w, r)
 			return
 		}
@@ -269,20 +309,33 @@ func deleteHandler(c conmansys, target string) http.HandlerFunc {
 		m["string"] = c.String()
 		err = json.NewDecoder(resp.Body).Decode(&m)
 		if err != nil {
-			log.Printf("could not decode response body: %v", err)
+			logging.FromContext(r.Context()).Error("could not decode response body", "error", err)
 			http.NotFound(w, r)
 			return
 		}
 
-		renderTemplate(w, "delete.html", m)
+		renderTemplate(w, r, "delete.html", m)
 	}
 }
 
 func viewHandler(c conmansys, target string) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		resp, err := http.Get(target)
+		url := target
+		// pass the filters on to the api gateway
+		if r.URL.RawQuery != "" {
+			url = url + "?" + r.URL.RawQuery
+		}
+
+		req, err := http.NewRequestWithContext(r.Context(), http.MethodGet, url, nil)
+		if err != nil {
+			logging.FromContext(r.Context()).Error("could not create GET request", "error", err)
+			http.NotFound(w, r)
+			return
+		}
+
+		resp, err := client.Do(req)
 		if err != nil {
-			log.Printf("could not receive data: %v", err)
+			logging.FromContext(r.Context()).Error("could not receive data", "error", err)
 			http.NotFound(w, r)
 			return
 		}
@@ -291,23 +344,24 @@ func viewHandler(c conmansys, target string) http.HandlerFunc {
 		var m map[string]interface{}
 		err = json.NewDecoder(resp.Body).Decode(&m)
 		if err != nil {
-			log.Printf("could not decode data: %v", err)
+			logging.FromContext(r.Context()).Error("could not decode data", "error", err)
 			http.NotFound(w, r)
 			return
 		}
 
 		m["string"] = c.String()
+		m["query"] = r.URL.Query()
 
-		renderTemplate(w, "view.html", m)
+		renderTemplate(w, r, "view.html", m)
 	}
 }
 
 func adminHandler(w http.ResponseWriter, r *http.Request) {
-	renderTemplate(w, "admin.html", nil)
+	renderTemplate(w, r, "admin.html", nil)
 }
 
 func insfileHandler(w http.ResponseWriter, r *http.Request) {
-	renderTemplate(w, "insfile.html", nil)
+	renderTemplate(w, r, "insfile.html", nil)
 }
 
 func convertCommaToJSON(modules string) []byte {
@@ -348,7 +402,7 @@ func insfileCreationHandler(target string) http.HandlerFunc {
 		data := convertCommaToJSON(mods)
 
 		if !json.Valid(data) {
-			log.Printf("not valid json: %v", data)
+			logging.FromContext(r.Context()).Warn("not valid json", "data", string(data))
 		}
 
 		url := target
@@ -361,13 +415,25 @@ func insfileCreationHandler(target string) http.HandlerFunc {
 			url = url + "/traverse/text"
 		}
 
-		req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+		// the insservice decides the defaults, so only forward what is set
+		q := make(neturl.Values)
+		if p := r.FormValue("policy"); p != "" {
+			q.Set("policy", p)
+		}
+		if r.FormValue("optional") == "true" {
+			q.Set("optional", "true")
+		}
+		if len(q) > 0 {
+			url = url + "?" + q.Encode()
+		}
+
+		req, err := http.NewRequestWithContext(r.Context(), http.MethodPost, url, bytes.NewReader(data))
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
 
-		resp, err := http.DefaultClient.Do(req)
+		resp, err := client.Do(req)
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusNotFound)
 			return
@@ -383,7 +449,7 @@ func insfileCreationHandler(target string) http.HandlerFunc {
 		// if they are not empty, the value read from body must be in json format
 		if j != "" || jTraverse != "" {
 			if !json.Valid(b) {
-				log.Printf("given data: %v, was not valid json", string(b))
+				logging.FromContext(r.Context()).Error("response was not valid json", "data", string(b))
 				http.Error(w, "Ups something went wrong", http.StatusInternalServerError)
 				return
 			}
@@ -391,12 +457,12 @@ func insfileCreationHandler(target string) http.HandlerFunc {
 			json.Unmarshal(b, &d)
 
 			if d["error"] != nil && d["error"].(string) != "" {
-				log.Printf("There was an error: %v", d["error"])
+				logging.FromContext(r.Context()).Error("the apigateway answered with an error", "error", d["error"])
 			}
 
 			d["string"] = "insfile"
 
-			renderTemplate(w, "save.html", d)
+			renderTemplate(w, r, "save.html", d)
 		} else {
 			// TODO: maybe write text to a text area?
 			w.Header().Set("Content-Type", "text/plain")
@@ -405,23 +471,75 @@ func insfileCreationHandler(target string) http.HandlerFunc {
 	}
 }
 
-func renderTemplate(w http.ResponseWriter, tmpl string, data interface{}) {
+func renderTemplate(w http.ResponseWriter, r *http.Request, tmpl string, data interface{}) {
 	err := templates.ExecuteTemplate(w, tmpl, data)
 
 	if err != nil {
-		log.Printf("could not render template %v: %v", tmpl, err)
+		logging.FromContext(r.Context()).Error("could not render template", "template", tmpl, "error", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 	}
 }
 
 var templates *template.Template
 
+// configuration of the frontend. Every setting is read from the YAML file of
+// -config, the environment variable and the flag of its field, see config.Load.
+type configuration struct {
+	Addr            string        `yaml:"addr" env:"ADDR" flag:"addr" usage:"address of the HTTP server"`
+	ReadTimeout     time.Duration `yaml:"read_timeout" env:"READ_TIMEOUT" flag:"read-timeout" usage:"maximum duration of reading a request"`
+	WriteTimeout    time.Duration `yaml:"write_timeout" env:"WRITE_TIMEOUT" flag:"write-timeout" usage:"maximum duration of writing a response"`
+	IdleTimeout     time.Duration `yaml:"idle_timeout" env:"IDLE_TIMEOUT" flag:"idle-timeout" usage:"how long an idle connection is kept open"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT" flag:"shutdown-timeout" usage:"how long running requests may take to finish at shutdown"`
+	LogLevel        string        `yaml:"log_level" env:"LOG_LEVEL" flag:"log-level" usage:"level of the logs: debug, info, warn or error"`
+	TracesExporter  string        `yaml:"traces_exporter" env:"OTEL_TRACES_EXPORTER" flag:"traces-exporter" usage:"exporter of the spans: otlp, stdout or none"`
+
+	APIGatewayHost string `yaml:"apigateway_host" env:"APIGATEWAY_HOST" flag:"apigateway-host" usage:"host and port of the apigateway"`
+}
+
+// defaults returns the configuration used for what is not set.
+func defaults() configuration {
+	return configuration{
+		Addr:            ":80",
+		ReadTimeout:     15 * time.Second,
+		WriteTimeout:    15 * time.Second,
+		IdleTimeout:     60 * time.Second,
+		ShutdownTimeout: 15 * time.Second,
+		LogLevel:        "info",
+		TracesExporter:  "none",
+	}
+}
+
 func main() {
+	cfg := defaults()
+	printOnly, err := config.Load(flag.CommandLine, os.Args[1:], &cfg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if printOnly {
+		if err := config.Print(os.Stdout, &cfg); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if err := logging.Setup(cfg.LogLevel); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	shutdownTracing, err := tracing.Setup(context.Background(), "frontend", cfg.TracesExporter)
+	if err != nil {
+		slog.Error("could not set up tracing", "error", err)
+		os.Exit(1)
+	}
 
-	apigateway, ok := os.LookupEnv("APIGATEWAY_HOST")
-	if !ok {
-		log.Fatal("Missing enviroment variable APIGATEWAY_HOST")
+	if cfg.APIGatewayHost == "" {
+		slog.Error("apigateway_host required but not set")
+		os.Exit(2)
 	}
+	apigateway := cfg.APIGatewayHost
 
 	// endpoints to the api gateway
 	var (
@@ -440,6 +558,12 @@ func main() {
 			ParseGlob("templates/*.html"),
 	)
 	r := mux.NewRouter()
+	r.Use(otelmux.Middleware("frontend"), logging.Middleware, metrics.Instrument)
+	r.Handle("/metrics", promhttp.Handler()).Methods(http.MethodGet)
+	r.HandleFunc("/livez", health.Livez).Methods(http.MethodGet)
+	r.HandleFunc("/readyz", health.Readyz(
+		health.Check{Name: "apigateway", Fn: health.Upstream(fmt.Sprintf("http://%v", apigateway))},
+	)).Methods(http.MethodGet)
 
 	// create fileserver to serve static content.
 	fs := http.FileServer(http.Dir("./static/"))
@@ -482,12 +606,43 @@ func main() {
 	insfile.HandleFunc("/created", insfileCreationHandler(insfileURL))
 
 	srv := http.Server{
-		Addr:         "",
+		Addr:         cfg.Addr,
 		Handler:      r,
-		WriteTimeout: 15 * time.Second,
-		ReadTimeout:  15 * time.Second,
-		IdleTimeout:  60 * time.Second,
+		WriteTimeout: cfg.WriteTimeout,
+		ReadTimeout:  cfg.ReadTimeout,
+		IdleTimeout:  cfg.IdleTimeout,
+	}
+
+	// run server in own go routine.
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			slog.Error("http server failed", "error", err)
+			shutdownTracing(context.Background())
+			os.Exit(1)
+		}
+	}()
+
+	// buffered channel
+	c := make(chan os.Signal, 1)
+
+	// notify the channel of any interrupts.
+	signal.Notify(c, os.Interrupt)
+
+	// block until a signal is recieved
+	<-c
+
+	// wait some time before shutting down to finish work.
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+
+	// if there are no connections, then it shutsdown immediately otherwise
+	// block until wait time is over.
+	srv.Shutdown(ctx)
+
+	if err := shutdownTracing(ctx); err != nil {
+		slog.Error("could not export spans", "error", err)
 	}
 
-	log.Fatal(srv.ListenAndServe())
+	slog.Info("shutting down")
+	os.Exit(0)
 }
@@ -0,0 +1,12 @@
+module github.com/Glorforidor/conmansys/health
+
+go 1.21
+
+require github.com/Glorforidor/conmansys/logging v0.0.0
+
+require (
+	go.opentelemetry.io/otel v1.6.3 // indirect
+	go.opentelemetry.io/otel/trace v1.6.3 // indirect
+)
+
+replace github.com/Glorforidor/conmansys/logging => ../logging
@@ -0,0 +1,123 @@
+// Package health answers the liveness and readiness probes of a service. All
+// services answer them with it, so their responses are the same.
+//
+// /livez reports that the service is running and checks nothing, so a service
+// whose dependency is down is not restarted. /readyz runs the checks of the
+// dependencies the service needs to answer requests, e.g. the ping of the
+// database or the /readyz of the service a request is sent on to.
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Glorforidor/conmansys/logging"
+)
+
+// Timeout is how long a readiness check may take before it fails.
+const Timeout = 2 * time.Second
+
+// Check is a dependency the service needs to answer requests.
+type Check struct {
+	Name string
+	Fn   func(context.Context) error
+}
+
+// Result is the outcome of a check in the response of /readyz.
+type Result struct {
+	Status     string  `json:"status"`
+	Error      string  `json:"error,omitempty"`
+	DurationMS float64 `json:"duration_ms"`
+}
+
+// Response is the response body of /livez and /readyz. The status is ok or
+// unavailable.
+type Response struct {
+	Status string             `json:"status"`
+	Checks map[string]*Result `json:"checks,omitempty"`
+}
+
+func write(w http.ResponseWriter, r *http.Request, resp Response) {
+	status := http.StatusOK
+	if resp.Status != "ok" {
+		status = http.StatusServiceUnavailable
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		logging.FromContext(r.Context()).Error("could not encode the response", "error", err)
+	}
+}
+
+// Livez reports that the service is running.
+func Livez(w http.ResponseWriter, r *http.Request) {
+	write(w, r, Response{Status: "ok"})
+}
+
+// Readyz returns a handler reporting whether the service can answer requests.
+// The checks run concurrently and the service is ready when every one of them
+// passes.
+func Readyz(checks ...Check) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		resp := Response{Status: "ok", Checks: make(map[string]*Result, len(checks))}
+
+		var mu sync.Mutex
+		var wg sync.WaitGroup
+		for _, c := range checks {
+			wg.Add(1)
+			go func(c Check) {
+				defer wg.Done()
+
+				ctx, cancel := context.WithTimeout(r.Context(), Timeout)
+				defer cancel()
+
+				start := time.Now()
+				err := c.Fn(ctx)
+				res := &Result{
+					Status:     "ok",
+					DurationMS: float64(time.Since(start).Microseconds()) / 1000,
+				}
+				if err != nil {
+					res.Status = "failed"
+					res.Error = err.Error()
+				}
+
+				mu.Lock()
+				defer mu.Unlock()
+				resp.Checks[c.Name] = res
+				if err != nil {
+					resp.Status = "unavailable"
+				}
+			}(c)
+		}
+		wg.Wait()
+
+		write(w, r, resp)
+	}
+}
+
+// Upstream checks that the service at url is ready by its /readyz.
+func Upstream(url string) func(context.Context) error {
+	return func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url+"/readyz", nil)
+		if err != nil {
+			return err
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("not ready: %v", resp.Status)
+		}
+		return nil
+	}
+}
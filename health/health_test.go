@@ -0,0 +1,103 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestReadyz(t *testing.T) {
+	ok := func(context.Context) error { return nil }
+	down := func(context.Context) error { return errors.New("connection refused") }
+	slow := func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	tt := map[string]struct {
+		checks []Check
+		status int
+		failed []string
+	}{
+		"no checks": {status: http.StatusOK},
+		"ready":     {checks: []Check{{Name: "database", Fn: ok}}, status: http.StatusOK},
+		"down": {
+			checks: []Check{{Name: "database", Fn: down}, {Name: "other", Fn: ok}},
+			status: http.StatusServiceUnavailable,
+			failed: []string{"database"},
+		},
+		"timeout": {
+			checks: []Check{{Name: "database", Fn: slow}},
+			status: http.StatusServiceUnavailable,
+			failed: []string{"database"},
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			start := time.Now()
+			rec := httptest.NewRecorder()
+			Readyz(tc.checks...).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+			if time.Since(start) > 2*Timeout {
+				t.Fatal("expected the checks to time out")
+			}
+
+			if rec.Code != tc.status {
+				t.Fatalf("expected status: %v, got: %v", tc.status, rec.Code)
+			}
+
+			var resp Response
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatal(err)
+			}
+			if len(resp.Checks) != len(tc.checks) {
+				t.Fatalf("expected %v checks, got: %v", len(tc.checks), resp.Checks)
+			}
+			for _, n := range tc.failed {
+				if c := resp.Checks[n]; c == nil || c.Status != "failed" || c.Error == "" {
+					t.Fatalf("expected check %v to fail, got: %+v", n, c)
+				}
+			}
+			if c := resp.Checks["other"]; c != nil && c.Status != "ok" {
+				t.Fatalf("expected the other check to pass, got: %+v", resp.Checks["other"])
+			}
+		})
+	}
+}
+
+func TestUpstream(t *testing.T) {
+	ready := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/readyz" {
+			t.Errorf("expected the readiness of the upstream to be asked for, got: %v", r.URL.Path)
+		}
+	}))
+	defer ready.Close()
+	notReady := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer notReady.Close()
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+
+	tt := map[string]struct {
+		upstream string
+		ok       bool
+	}{
+		"ready":     {upstream: ready.URL, ok: true},
+		"not ready": {upstream: notReady.URL},
+		"down":      {upstream: down.URL},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			err := Upstream(tc.upstream)(context.Background())
+			if (err == nil) != tc.ok {
+				t.Fatalf("expected the upstream to be ready: %v, got: %v", tc.ok, err)
+			}
+		})
+	}
+}
@@ -1,3 +1,6 @@
+INSERT INTO conf_item_type (conf_item_type_name, conf_item_type_pattern) VALUES
+('window', '_window$');
+
 INSERT INTO conf_item (conf_item_value, conf_item_type, conf_item_version) VALUES
 ('tax_income_window', 'window', '1.0.0'),
 ('tax', 'domain', '1.0.0'),
@@ -1,8 +1,67 @@
 module github.com/Glorforidor/conmansys/insservice
 
-go 1.12
+go 1.21
 
 require (
-	github.com/gorilla/mux v1.7.2
+	github.com/Glorforidor/conmansys/config v0.0.0
+	github.com/Glorforidor/conmansys/database v0.0.0
+	github.com/Glorforidor/conmansys/health v0.0.0
+	github.com/Glorforidor/conmansys/labels v0.0.0
+	github.com/Glorforidor/conmansys/logging v0.0.0
+	github.com/Glorforidor/conmansys/metrics v0.0.0
+	github.com/Glorforidor/conmansys/resolve v0.0.0
+	github.com/Glorforidor/conmansys/tracing v0.0.0
+	github.com/getkin/kin-openapi v0.94.0
+	github.com/gorilla/mux v1.8.0
 	github.com/lib/pq v1.1.1
+	github.com/prometheus/client_golang v1.11.1
+	github.com/prometheus/client_model v0.2.0
+	go.opentelemetry.io/contrib/instrumentation/github.com/gorilla/mux/otelmux v0.31.0
+	go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc v0.31.0
+	google.golang.org/genproto v0.0.0-20211118181313-81c1377c94b1
+	google.golang.org/grpc v1.45.0
+	google.golang.org/protobuf v1.28.0
+)
+
+require (
+	github.com/XSAM/otelsql v0.13.0 // indirect
+	github.com/beorn7/perks v1.0.1 // indirect
+	github.com/cenkalti/backoff/v4 v4.1.2 // indirect
+	github.com/cespare/xxhash/v2 v2.1.1 // indirect
+	github.com/felixge/httpsnoop v1.0.2 // indirect
+	github.com/ghodss/yaml v1.0.0 // indirect
+	github.com/go-logr/logr v1.2.3 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	github.com/go-openapi/jsonpointer v0.19.5 // indirect
+	github.com/go-openapi/swag v0.19.5 // indirect
+	github.com/golang/protobuf v1.5.2 // indirect
+	github.com/grpc-ecosystem/grpc-gateway/v2 v2.7.0 // indirect
+	github.com/mailru/easyjson v0.0.0-20190626092158-b2ccc519800e // indirect
+	github.com/matttproud/golang_protobuf_extensions v1.0.1 // indirect
+	github.com/prometheus/common v0.26.0 // indirect
+	github.com/prometheus/procfs v0.6.0 // indirect
+	go.opentelemetry.io/otel v1.6.3 // indirect
+	go.opentelemetry.io/otel/exporters/otlp/internal/retry v1.6.3 // indirect
+	go.opentelemetry.io/otel/exporters/otlp/otlptrace v1.6.3 // indirect
+	go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp v1.6.3 // indirect
+	go.opentelemetry.io/otel/exporters/stdout/stdouttrace v1.6.3 // indirect
+	go.opentelemetry.io/otel/metric v0.28.0 // indirect
+	go.opentelemetry.io/otel/sdk v1.6.3 // indirect
+	go.opentelemetry.io/otel/trace v1.6.3 // indirect
+	go.opentelemetry.io/proto/otlp v0.15.0 // indirect
+	golang.org/x/net v0.0.0-20210405180319-a5a99cb37ef4 // indirect
+	golang.org/x/sys v0.0.0-20210603081109-ebe580a85c40 // indirect
+	golang.org/x/text v0.3.5 // indirect
+	gopkg.in/yaml.v2 v2.3.0 // indirect
+)
+
+replace (
+	github.com/Glorforidor/conmansys/config => ../config
+	github.com/Glorforidor/conmansys/database => ../database
+	github.com/Glorforidor/conmansys/health => ../health
+	github.com/Glorforidor/conmansys/labels => ../labels
+	github.com/Glorforidor/conmansys/logging => ../logging
+	github.com/Glorforidor/conmansys/metrics => ../metrics
+	github.com/Glorforidor/conmansys/resolve => ../resolve
+	github.com/Glorforidor/conmansys/tracing => ../tracing
 )
@@ -0,0 +1,440 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Glorforidor/conmansys/insservice/merge"
+	"github.com/Glorforidor/conmansys/insservice/storage"
+)
+
+// snapshot is the resolved closure of one side of a diff.
+type snapshot struct {
+	modules []*storage.Module
+	items   []*storage.Item
+}
+
+// itemChange is an item whose version differs between the two sides. A secret
+// item has the id and the masked value and changes if its value does too.
+type itemChange struct {
+	ID    int64  `json:"id,omitempty"`
+	Value string `json:"value"`
+	Type  string `json:"type"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+// moduleChange is a module whose value or version differs between the two
+// sides.
+type moduleChange struct {
+	ID    int64  `json:"id"`
+	Value string `json:"value"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+type itemDiff struct {
+	Added   []*storage.Item `json:"added"`
+	Removed []*storage.Item `json:"removed"`
+	Changed []*itemChange   `json:"changed"`
+}
+
+type moduleDiff struct {
+	Added   []*storage.Module `json:"added"`
+	Removed []*storage.Module `json:"removed"`
+	Changed []*moduleChange   `json:"changed"`
+}
+
+// diffResponse is the response body of a diff. Every collection is an empty
+// slice instead of a nil slice.
+type diffResponse struct {
+	From      string                `json:"from"`
+	To        string                `json:"to"`
+	Items     itemDiff              `json:"items"`
+	Modules   moduleDiff            `json:"modules"`
+	Conflicts []*merge.ItemConflict `json:"conflicts"`
+	Error     *string               `json:"error"`
+}
+
+func newDiffResponse() *diffResponse {
+	return &diffResponse{
+		Items: itemDiff{
+			Added:   []*storage.Item{},
+			Removed: []*storage.Item{},
+			Changed: []*itemChange{},
+		},
+		Modules: moduleDiff{
+			Added:   []*storage.Module{},
+			Removed: []*storage.Module{},
+			Changed: []*moduleChange{},
+		},
+		Conflicts: []*merge.ItemConflict{},
+	}
+}
+
+// compare fills the response with what is added, removed and changed going
+// from one snapshot to the other. Items are the same if they have the same
+// value and type, modules if they have the same id. Secret items are the same
+// if they have the same id and type and their values are masked, as a diff is
+// reported and not rendered.
+func (resp *diffResponse) compare(from, to *snapshot) {
+	type key struct {
+		id         int64
+		value, typ string
+	}
+	keyOf := func(i *storage.Item) key {
+		if i.Secret {
+			return key{id: i.ID, typ: i.Type}
+		}
+		return key{value: i.Value, typ: i.Type}
+	}
+	masked := func(i *storage.Item) *storage.Item {
+		if !i.Secret {
+			return i
+		}
+		m := *i
+		m.Value = storage.Mask
+		return &m
+	}
+
+	fromItems := make(map[key]*storage.Item, len(from.items))
+	for _, i := range from.items {
+		fromItems[keyOf(i)] = i
+	}
+
+	toItems := make(map[key]*storage.Item, len(to.items))
+	for _, i := range to.items {
+		k := keyOf(i)
+		toItems[k] = i
+
+		f, ok := fromItems[k]
+		switch {
+		case !ok:
+			resp.Items.Added = append(resp.Items.Added, masked(i))
+		case i.Secret && (f.Version != i.Version || f.Value != i.Value):
+			resp.Items.Changed = append(resp.Items.Changed, &itemChange{
+				ID: i.ID, Value: storage.Mask, Type: i.Type, From: f.Version, To: i.Version,
+			})
+		case f.Version != i.Version:
+			resp.Items.Changed = append(resp.Items.Changed, &itemChange{
+				Value: i.Value, Type: i.Type, From: f.Version, To: i.Version,
+			})
+		}
+	}
+
+	for _, i := range from.items {
+		if _, ok := toItems[keyOf(i)]; !ok {
+			resp.Items.Removed = append(resp.Items.Removed, masked(i))
+		}
+	}
+
+	fromModules := make(map[int64]*storage.Module, len(from.modules))
+	for _, m := range from.modules {
+		fromModules[m.ID] = m
+	}
+
+	toModules := make(map[int64]*storage.Module, len(to.modules))
+	for _, m := range to.modules {
+		toModules[m.ID] = m
+
+		f, ok := fromModules[m.ID]
+		switch {
+		case !ok:
+			resp.Modules.Added = append(resp.Modules.Added, m)
+		case f.Value != m.Value || f.Version != m.Version:
+			resp.Modules.Changed = append(resp.Modules.Changed, &moduleChange{
+				ID: m.ID, Value: m.Value, From: f.Version, To: m.Version,
+			})
+		}
+	}
+
+	for _, m := range from.modules {
+		if _, ok := toModules[m.ID]; !ok {
+			resp.Modules.Removed = append(resp.Modules.Removed, m)
+		}
+	}
+
+	// sort to give a stable response
+	byItem := func(items []*storage.Item) {
+		sort.Slice(items, func(a, b int) bool {
+			if items[a].Value != items[b].Value {
+				return items[a].Value < items[b].Value
+			}
+			if items[a].Type != items[b].Type {
+				return items[a].Type < items[b].Type
+			}
+			return items[a].ID < items[b].ID
+		})
+	}
+	byItem(resp.Items.Added)
+	byItem(resp.Items.Removed)
+	sort.Slice(resp.Items.Changed, func(a, b int) bool {
+		c := resp.Items.Changed
+		if c[a].Value != c[b].Value {
+			return c[a].Value < c[b].Value
+		}
+		if c[a].Type != c[b].Type {
+			return c[a].Type < c[b].Type
+		}
+		return c[a].ID < c[b].ID
+	})
+
+	byID := func(mods []*storage.Module) {
+		sort.Slice(mods, func(a, b int) bool { return mods[a].ID < mods[b].ID })
+	}
+	byID(resp.Modules.Added)
+	byID(resp.Modules.Removed)
+	sort.Slice(resp.Modules.Changed, func(a, b int) bool {
+		return resp.Modules.Changed[a].ID < resp.Modules.Changed[b].ID
+	})
+}
+
+const (
+	releasePrefix = "release:"
+	modulesPrefix = "modules:"
+)
+
+// parseSide parses one side of a diff, which is either release:<name>,
+// modules:<ids> or without a prefix a comma separated list of module ids or
+// else the name of a release. It returns the release name or the module ids.
+func parseSide(s string) (string, []int64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return "", nil, fmt.Errorf("missing value")
+	}
+
+	if strings.HasPrefix(s, releasePrefix) {
+		name := strings.TrimPrefix(s, releasePrefix)
+		if name == "" {
+			return "", nil, fmt.Errorf("missing release name")
+		}
+		return name, nil, nil
+	}
+
+	explicit := strings.HasPrefix(s, modulesPrefix)
+	var ids []int64
+	for _, f := range strings.Split(strings.TrimPrefix(s, modulesPrefix), ",") {
+		id, err := strconv.ParseInt(strings.TrimSpace(f), 10, 64)
+		if err != nil || id < 1 {
+			if explicit {
+				return "", nil, fmt.Errorf("module ids must be positive numbers: %q", s)
+			}
+			return s, nil, nil
+		}
+		ids = append(ids, id)
+	}
+
+	return "", ids, nil
+}
+
+// snapshot resolves one side of a diff. A release gives what was frozen in it
+// and module ids their live closure. The items are merged with the policy. If
+// the items conflict and the policy is error the conflicts are returned with a
+// conflict status and an error.
+func (h handler) snapshot(ctx context.Context, side string, optional bool, policy string) (*snapshot, []*merge.ItemConflict, int, error) {
+	name, ids, err := parseSide(side)
+	if err != nil {
+		return nil, nil, http.StatusBadRequest, err
+	}
+
+	if name != "" {
+		release, err := h.storage.GetRelease(ctx, name)
+		if err != nil {
+			logError(ctx, "could not retrieve data from database", err)
+			return nil, nil, http.StatusInternalServerError, fmt.Errorf("Ups something went wrong")
+		}
+
+		if release == nil {
+			return nil, nil, http.StatusNotFound, fmt.Errorf("release %q not found", name)
+		}
+
+		nodes, byModule, mods := releaseModules(release)
+		items, conflicts, err := merge.Items(nodes, byModule, policy)
+		if err != nil {
+			return nil, conflicts, http.StatusConflict, err
+		}
+
+		return &snapshot{modules: mods, items: items}, conflicts, http.StatusOK, nil
+	}
+
+	res, status, err := h.resolve(ctx, ids, optional)
+	if err != nil {
+		return nil, nil, status, err
+	}
+
+	items, conflicts, status, err := h.moduleItems(ctx, res.Modules, policy)
+	if err != nil {
+		return nil, conflicts, status, err
+	}
+
+	all, err := h.storage.GetModules(ctx, res.IDs()...)
+	if err != nil {
+		logError(ctx, "could not retrieve data from database", err)
+		return nil, nil, http.StatusInternalServerError, fmt.Errorf("Ups something went wrong")
+	}
+
+	byID := make(map[int64]*storage.Module, len(all))
+	for _, m := range all {
+		byID[m.ID] = m
+	}
+
+	mods := []*storage.Module{}
+	for _, n := range res.Modules {
+		m := &storage.Module{ID: n.ID}
+		if live := byID[n.ID]; live != nil {
+			m.Value = live.Value
+			m.Version = live.Version
+		}
+		mods = append(mods, m)
+	}
+
+	return &snapshot{modules: mods, items: items}, conflicts, http.StatusOK, nil
+}
+
+// diff resolves the from and to sides in the query and packs what changes
+// going from one to the other into a response. The optional and policy query
+// parameters apply to both sides. It returns the response and a http status.
+func (h handler) diff(r *http.Request) (*diffResponse, int) {
+	resp := newDiffResponse()
+	q := r.URL.Query()
+	resp.From = q.Get("from")
+	resp.To = q.Get("to")
+
+	fail := func(status int, err error) (*diffResponse, int) {
+		errMsg := err.Error()
+		resp.Error = &errMsg
+		return resp, status
+	}
+
+	if resp.From == "" || resp.To == "" {
+		return fail(http.StatusBadRequest, fmt.Errorf("from and to are required"))
+	}
+
+	policy, err := itemPolicy(r)
+	if err != nil {
+		return fail(http.StatusBadRequest, err)
+	}
+
+	optional, err := followOptional(r)
+	if err != nil {
+		return fail(http.StatusBadRequest, err)
+	}
+
+	from, fromConflicts, status, err := h.snapshot(r.Context(), resp.From, optional, policy)
+	resp.Conflicts = append(resp.Conflicts, fromConflicts...)
+	if err != nil {
+		return fail(status, fmt.Errorf("from: %v", err))
+	}
+
+	to, toConflicts, status, err := h.snapshot(r.Context(), resp.To, optional, policy)
+	resp.Conflicts = append(resp.Conflicts, toConflicts...)
+	if err != nil {
+		return fail(status, fmt.Errorf("to: %v", err))
+	}
+
+	resp.compare(from, to)
+	return resp, http.StatusOK
+}
+
+// responseDiffJSON packs the diff in application/json format.
+func responseDiffJSON(h func(r *http.Request) (*diffResponse, int)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		resp, status := h(r)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			logError(r.Context(), "could not decode to output", err)
+		}
+	}
+}
+
+// words joins the values which are not empty with spaces.
+func words(values ...interface{}) string {
+	var ws []string
+	for _, v := range values {
+		if w := fmt.Sprint(v); w != "" {
+			ws = append(ws, w)
+		}
+	}
+	return strings.Join(ws, " ")
+}
+
+// responseDiffText packs the diff in text/plain format with a section for every
+// kind of change which is not empty.
+func responseDiffText(h func(r *http.Request) (*diffResponse, int)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		resp, status := h(r)
+
+		var b bytes.Buffer
+		sep := strings.Repeat("-", 20) + "\r\n"
+		section := func(header string, lines []string) {
+			if len(lines) == 0 {
+				return
+			}
+			fmt.Fprintf(&b, "%v\r\n", header)
+			fmt.Fprint(&b, sep)
+			for _, l := range lines {
+				// https://www.ietf.org/rfc/rfc2046.txt says that the newline of
+				// text is CRLF
+				fmt.Fprintf(&b, "%v\r\n", l)
+			}
+			fmt.Fprint(&b, sep)
+		}
+
+		if resp.Error != nil {
+			fmt.Fprintf(&b, "%v\r\n", *resp.Error)
+			var reasons []string
+			for _, c := range resp.Conflicts {
+				reasons = append(reasons, c.Reason)
+			}
+			section("conflicts", reasons)
+		} else {
+			var lines []string
+			for _, i := range resp.Items.Added {
+				lines = append(lines, words(i.Value, i.Version))
+			}
+			section("items added", lines)
+
+			lines = nil
+			for _, i := range resp.Items.Removed {
+				lines = append(lines, words(i.Value, i.Version))
+			}
+			section("items removed", lines)
+
+			lines = nil
+			for _, c := range resp.Items.Changed {
+				lines = append(lines, words(c.Value, c.From, "->", c.To))
+			}
+			section("items changed", lines)
+
+			lines = nil
+			for _, m := range resp.Modules.Added {
+				lines = append(lines, words(m.ID, m.Value, m.Version))
+			}
+			section("modules added", lines)
+
+			lines = nil
+			for _, m := range resp.Modules.Removed {
+				lines = append(lines, words(m.ID, m.Value, m.Version))
+			}
+			section("modules removed", lines)
+
+			lines = nil
+			for _, c := range resp.Modules.Changed {
+				lines = append(lines, words(c.ID, c.Value, c.From, "->", c.To))
+			}
+			section("modules changed", lines)
+		}
+
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(status)
+		w.Write(b.Bytes())
+	}
+}
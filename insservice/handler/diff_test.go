@@ -0,0 +1,250 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/Glorforidor/conmansys/insservice/storage"
+)
+
+func TestParseSide(t *testing.T) {
+	tt := map[string]struct {
+		side string
+		name string
+		ids  []int64
+		err  bool
+	}{
+		"ids":              {side: "1,2", ids: []int64{1, 2}},
+		"modules prefix":   {side: "modules:3", ids: []int64{3}},
+		"release":          {side: "2026.10-prod", name: "2026.10-prod"},
+		"release prefix":   {side: "release:2026", name: "2026"},
+		"missing":          {side: " ", err: true},
+		"missing release":  {side: "release:", err: true},
+		"invalid modules":  {side: "modules:1,a", err: true},
+		"negative modules": {side: "modules:-1", err: true},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			n, ids, err := parseSide(tc.side)
+			if tc.err {
+				if err == nil {
+					t.Fatal("expected an error, got: nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+
+			if n != tc.name || fmt.Sprint(ids) != fmt.Sprint(tc.ids) {
+				t.Fatalf("expected: %q %v, got: %q %v", tc.name, tc.ids, n, ids)
+			}
+		})
+	}
+}
+
+func TestDiff(t *testing.T) {
+	tt := map[string]struct {
+		from, to string
+		query    string
+		status   int
+		added    []string
+		removed  []string
+		changed  []string
+		modules  []int64
+	}{
+		"release to modules": {
+			from: "release:2026.10-prod", to: "1", status: http.StatusOK,
+			added: []string{"Management", "Payment", "Refund"}, removed: []string{"Frozen"},
+		},
+		"highest version": {
+			from: "2026.10-prod", to: "1,6", query: "&policy=highest-version", status: http.StatusOK,
+			added: []string{"Management", "Payment", "Refund"}, removed: []string{"Frozen"},
+			changed: []string{"Taxonomy 1.0.0 -> 2.0.0"}, modules: []int64{6},
+		},
+		"same":             {from: "1", to: "1", status: http.StatusOK},
+		"missing to":       {from: "1", status: http.StatusBadRequest},
+		"unknown release":  {from: "nope", to: "1", status: http.StatusNotFound},
+		"item conflict":    {from: "1", to: "1,6", query: "&policy=error", status: http.StatusConflict},
+		"module conflict":  {from: "1", to: "1,4", status: http.StatusConflict},
+		"invalid optional": {from: "1", to: "1", query: "&optional=maybe", status: http.StatusBadRequest},
+	}
+
+	r := New(service)
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			u := fmt.Sprintf("%v/diff?from=%v&to=%v%v", srv.URL, url.QueryEscape(tc.from), url.QueryEscape(tc.to), tc.query)
+			resp, err := srv.Client().Get(u)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tc.status {
+				t.Fatalf("expected status: %v, got: %v", tc.status, resp.StatusCode)
+			}
+
+			var body diffResponse
+			if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+				t.Fatal(err)
+			}
+
+			if tc.status != http.StatusOK {
+				if body.Error == nil {
+					t.Fatal("expected an error, got: nil")
+				}
+				return
+			}
+
+			var added, removed, changed []string
+			for _, i := range body.Items.Added {
+				added = append(added, i.Value)
+			}
+			for _, i := range body.Items.Removed {
+				removed = append(removed, i.Value)
+			}
+			for _, c := range body.Items.Changed {
+				changed = append(changed, fmt.Sprintf("%v %v -> %v", c.Value, c.From, c.To))
+			}
+			var modules []int64
+			for _, m := range body.Modules.Added {
+				modules = append(modules, m.ID)
+			}
+
+			if fmt.Sprint(added) != fmt.Sprint(tc.added) {
+				t.Errorf("expected added: %v, got: %v", tc.added, added)
+			}
+			if fmt.Sprint(removed) != fmt.Sprint(tc.removed) {
+				t.Errorf("expected removed: %v, got: %v", tc.removed, removed)
+			}
+			if fmt.Sprint(changed) != fmt.Sprint(tc.changed) {
+				t.Errorf("expected changed: %v, got: %v", tc.changed, changed)
+			}
+			if fmt.Sprint(modules) != fmt.Sprint(tc.modules) {
+				t.Errorf("expected modules added: %v, got: %v", tc.modules, modules)
+			}
+		})
+	}
+}
+
+func TestDiffText(t *testing.T) {
+	r := New(service)
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	resp, err := srv.Client().Get(srv.URL + "/diff/text?from=2026.10-prod&to=1,6&policy=highest-version")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status: %v, got: %v", http.StatusOK, resp.StatusCode)
+	}
+
+	if ct := resp.Header.Get("Content-Type"); ct != "text/plain" {
+		t.Fatalf("expected Content-Type: text/plain, got: %v", ct)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sep := strings.Repeat("-", 20) + "\r\n"
+	for _, want := range []string{
+		"items removed\r\n" + sep + "Frozen\r\n" + sep,
+		"items changed\r\n" + sep + "Taxonomy 1.0.0 -> 2.0.0\r\n" + sep,
+		"modules added\r\n" + sep + "6\r\n" + sep,
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("expected body to contain: %q, got: %q", want, body)
+		}
+	}
+}
+
+func TestDiffSecrets(t *testing.T) {
+	secret := func(id int64, value, version string) *storage.Item {
+		return &storage.Item{ID: id, Value: value, Type: "password", Version: version, Secret: true}
+	}
+
+	// the release has the old password of item 10 and item 12, which is gone,
+	// and modules 10 and 11 have a password of the same value in two versions
+	s := &serviceMock{
+		items: map[int64][]*storage.Item{
+			7:  {secret(10, "new-pass", "2")},
+			9:  {secret(11, "added-pass", "1")},
+			10: {secret(13, "shared-pass", "1")},
+			11: {secret(14, "shared-pass", "2")},
+		},
+		modules: []*storage.Module{{ID: 7}, {ID: 9}, {ID: 10}, {ID: 11}},
+		releases: []*storage.Release{
+			{
+				Name:    "rotation",
+				Modules: []int64{7, 8},
+				Closure: []*storage.ReleaseModule{
+					{ID: 7, Items: []*storage.Item{secret(10, "old-pass", "1")}},
+					{ID: 8, Items: []*storage.Item{secret(12, "gone-pass", "1")}},
+				},
+			},
+		},
+	}
+
+	srv := httptest.NewServer(New(s))
+	defer srv.Close()
+
+	for _, path := range []string{"/diff", "/diff/text"} {
+		t.Run(path, func(t *testing.T) {
+			resp, err := srv.Client().Get(srv.URL + path + "?from=release:rotation&to=7,9,10,11")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("expected status: %v, got: %v", http.StatusOK, resp.StatusCode)
+			}
+
+			body, err := ioutil.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, plain := range []string{"old-pass", "new-pass", "added-pass", "gone-pass", "shared-pass"} {
+				if strings.Contains(string(body), plain) {
+					t.Fatalf("expected no secret value, got %q in: %s", plain, body)
+				}
+			}
+			if !strings.Contains(string(body), storage.Mask) {
+				t.Fatalf("expected the masked values, got: %s", body)
+			}
+
+			if path != "/diff" {
+				return
+			}
+
+			var diff diffResponse
+			if err := json.Unmarshal(body, &diff); err != nil {
+				t.Fatal(err)
+			}
+			// the rotated password of item 10 is a change and not a removed and
+			// an added item
+			if len(diff.Items.Changed) != 1 || diff.Items.Changed[0].ID != 10 || diff.Items.Changed[0].To != "2" {
+				t.Fatalf("expected item 10 to change, got: %v", diff.Items.Changed)
+			}
+			if len(diff.Items.Added) != 2 || len(diff.Items.Removed) != 1 || len(diff.Conflicts) != 1 {
+				t.Fatalf("expected 2 added, 1 removed and 1 conflict, got: %+v", diff)
+			}
+		})
+	}
+}
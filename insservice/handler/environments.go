@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Glorforidor/conmansys/insservice/storage"
+	"github.com/gorilla/mux"
+)
+
+// environmentResponse is the response body telling which release is in an
+// environment. An empty error should be stored as nil.
+type environmentResponse struct {
+	Environment string             `json:"environment"`
+	Promotion   *storage.Promotion `json:"promotion"`
+	Error       *string            `json:"error"`
+}
+
+// promotion finds the promotion of the release which is in the environment
+// now. An environment no release was promoted to is not found.
+func (h handler) promotion(ctx context.Context, env string) (*storage.Promotion, int, error) {
+	promotion, err := h.storage.GetEnvironmentPromotion(ctx, env)
+	if err != nil {
+		logError(ctx, "could not retrieve data from database", err)
+		return nil, http.StatusInternalServerError, fmt.Errorf("Ups something went wrong")
+	}
+
+	if promotion == nil {
+		return nil, http.StatusNotFound, fmt.Errorf("no release was promoted to %q", env)
+	}
+
+	return promotion, http.StatusOK, nil
+}
+
+// environment answers which release is in the environment in the path with the
+// promotion which put it there.
+func (h handler) environment(w http.ResponseWriter, r *http.Request) {
+	env := strings.TrimSpace(mux.Vars(r)["env"])
+	resp := environmentResponse{Environment: env}
+
+	promotion, status, err := h.promotion(r.Context(), env)
+	if err != nil {
+		v := err.Error()
+		resp.Error = &v
+	}
+	resp.Promotion = promotion
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	err = json.NewEncoder(w).Encode(resp)
+	if err != nil {
+		logError(r.Context(), "could not decode to output", err)
+	}
+}
+
+// environmentInsfile renders the insfile of the release which is in the
+// environment in the path like releaseInsfile and adds the promotion which put
+// the release there.
+func (h handler) environmentInsfile(r *http.Request) ([]interface{}, int, error) {
+	policy, err := itemPolicy(r)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	env := strings.TrimSpace(mux.Vars(r)["env"])
+	promotion, status, err := h.promotion(r.Context(), env)
+	if err != nil {
+		return nil, status, err
+	}
+
+	release, err := h.storage.GetRelease(r.Context(), promotion.Release)
+	if err != nil {
+		logError(r.Context(), "could not retrieve data from database", err)
+		return nil, http.StatusInternalServerError, fmt.Errorf("Ups something went wrong")
+	}
+
+	// the promotion refers to the release, so it should always be there
+	if release == nil {
+		return nil, http.StatusNotFound, fmt.Errorf("release %q not found", promotion.Release)
+	}
+
+	data, status, err := renderRelease(release, policy)
+	return append([]interface{}{promotion}, data...), status, err
+}
@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestEnvironment(t *testing.T) {
+	tt := map[string]struct {
+		env     string
+		status  int
+		release string
+		closed  bool
+	}{
+		"promoted":       {env: "dev", status: http.StatusOK, release: "2026.10-prod"},
+		"pending":        {env: "prod", status: http.StatusNotFound},
+		"unknown":        {env: "qa", status: http.StatusNotFound},
+		"closed storage": {env: "dev", status: http.StatusInternalServerError, closed: true},
+	}
+
+	srv := httptest.NewServer(New(service))
+	defer srv.Close()
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			if tc.closed {
+				service.closed = true
+				defer func() { service.closed = false }()
+			}
+
+			resp, err := srv.Client().Get(srv.URL + "/environments/" + tc.env)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tc.status {
+				t.Fatalf("expected status: %v, got: %v", tc.status, resp.StatusCode)
+			}
+
+			var body environmentResponse
+			if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+				t.Fatal(err)
+			}
+
+			if tc.release == "" {
+				if body.Error == nil {
+					t.Fatal("expected an error, got: nil")
+				}
+				return
+			}
+
+			if body.Promotion == nil || body.Promotion.Release != tc.release {
+				t.Fatalf("expected release: %v, got: %+v", tc.release, body.Promotion)
+			}
+		})
+	}
+}
+
+func TestEnvironmentInsfile(t *testing.T) {
+	tt := map[string]struct {
+		url    string
+		status int
+		want   string
+		closed bool
+	}{
+		"text": {
+			url: "/insfile/environment/dev/text", status: http.StatusOK,
+			want: "release\r\n--------------------\r\n2026.10-prod\r\n",
+		},
+		"json": {url: "/insfile/environment/dev", status: http.StatusOK, want: `"release":"2026.10-prod"`},
+		// the items of the modules the release depends on are not merged
+		"error policy":    {url: "/insfile/environment/dev?policy=error", status: http.StatusOK},
+		"unknown policy":  {url: "/insfile/environment/dev?policy=newest", status: http.StatusBadRequest},
+		"pending":         {url: "/insfile/environment/prod", status: http.StatusNotFound},
+		"missing release": {url: "/insfile/environment/staging", status: http.StatusNotFound},
+		"closed storage":  {url: "/insfile/environment/dev", status: http.StatusInternalServerError, closed: true},
+	}
+
+	srv := httptest.NewServer(New(service))
+	defer srv.Close()
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			if tc.closed {
+				service.closed = true
+				defer func() { service.closed = false }()
+			}
+
+			resp, err := srv.Client().Get(srv.URL + tc.url)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tc.status {
+				t.Fatalf("expected status: %v, got: %v", tc.status, resp.StatusCode)
+			}
+
+			body, err := ioutil.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tc.want != "" && !strings.Contains(string(body), tc.want) {
+				t.Fatalf("expected body to contain: %q, got: %q", tc.want, body)
+			}
+		})
+	}
+}
@@ -0,0 +1,19 @@
+package handler
+
+import (
+	"github.com/Glorforidor/conmansys/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	closureModules = metrics.Register(prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "insfile_closure_modules",
+		Help:    "Number of modules in the resolved closure of an insfile.",
+		Buckets: prometheus.ExponentialBuckets(1, 2, 10),
+	})).(prometheus.Histogram)
+	closureItems = metrics.Register(prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "insfile_closure_items",
+		Help:    "Number of items of the modules in the closure of an insfile.",
+		Buckets: prometheus.ExponentialBuckets(1, 2, 12),
+	})).(prometheus.Histogram)
+)
@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Glorforidor/conmansys/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// observations returns the number of values observed by the histogram.
+func observations(t *testing.T, h prometheus.Histogram) uint64 {
+	var m dto.Metric
+	if err := h.Write(&m); err != nil {
+		t.Fatal(err)
+	}
+	return m.GetHistogram().GetSampleCount()
+}
+
+func TestMetrics(t *testing.T) {
+	h := New(service)
+
+	route := "/insfile/traverse"
+	traverse := metrics.Requests.WithLabelValues(route, "post", "200")
+	requestsBefore := testutil.ToFloat64(traverse)
+	modulesBefore, itemsBefore := observations(t, closureModules), observations(t, closureItems)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, route, strings.NewReader(`[{"id": 1}]`)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status: %v, got: %v: %v", http.StatusOK, rec.Code, rec.Body)
+	}
+
+	if got := testutil.ToFloat64(traverse) - requestsBefore; got != 1 {
+		t.Errorf("expected 1 request to %v, got: %v", route, got)
+	}
+	if got := observations(t, closureModules) - modulesBefore; got != 1 {
+		t.Errorf("expected the modules of 1 closure to be observed, got: %v", got)
+	}
+	if got := observations(t, closureItems) - itemsBefore; got != 1 {
+		t.Errorf("expected the items of 1 closure to be observed, got: %v", got)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	for _, want := range []string{"insfile_closure_modules_bucket", `route="/insfile/traverse"`} {
+		if !strings.Contains(rec.Body.String(), want) {
+			t.Errorf("expected %v in the metrics", want)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/gorilla/mux"
+)
+
+// loadSpec parses and validates the OpenAPI document of the service.
+func loadSpec() (*openapi3.T, error) {
+	doc, err := openapi3.NewLoader().LoadFromData([]byte(spec))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := doc.Validate(context.Background()); err != nil {
+		return nil, err
+	}
+
+	return doc, nil
+}
+
+// openAPI returns a handler serving the OpenAPI document as JSON.
+func openAPI(doc *openapi3.T) http.HandlerFunc {
+	b, err := json.Marshal(doc)
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err != nil {
+			logError(r.Context(), "could not encode the OpenAPI document", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(b)
+	}
+}
+
+// validateRequests is a middleware which validates the parameters and body of
+// a request against the operation of the matched route in the document. Routes
+// which are not in the document are not validated. A request without a
+// Content-Type is validated as the only media type of the operation, so clients
+// which never sent one keep working. The error is written like the route
+// writes its own errors, as text or as its JSON response.
+func validateRequests(doc *openapi3.T) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route := mux.CurrentRoute(r)
+			if route == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			tmpl, err := route.GetPathTemplate()
+			if err != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			item := doc.Paths.Find(tmpl)
+			if item == nil || item.GetOperation(r.Method) == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+			op := item.GetOperation(r.Method)
+
+			if rb := op.RequestBody; rb != nil && rb.Value != nil && len(rb.Value.Content) == 1 &&
+				r.Header.Get("Content-Type") == "" {
+				for mime := range rb.Value.Content {
+					r.Header.Set("Content-Type", mime)
+				}
+			}
+
+			input := &openapi3filter.RequestValidationInput{
+				Request:    r,
+				PathParams: mux.Vars(r),
+				Route: &routers.Route{
+					Spec:      doc,
+					Path:      tmpl,
+					PathItem:  item,
+					Method:    r.Method,
+					Operation: op,
+				},
+				Options: &openapi3filter.Options{
+					AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+				},
+			}
+			err = openapi3filter.ValidateRequest(r.Context(), input)
+			if err == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			err = requestError(err)
+			switch {
+			case strings.HasSuffix(tmpl, "/text"):
+				responseText(func(*http.Request) (interface{}, int, error) {
+					return nil, http.StatusBadRequest, err
+				})(w, r)
+			case strings.HasPrefix(tmpl, "/diff"):
+				responseDiffJSON(func(*http.Request) (*diffResponse, int) {
+					resp := newDiffResponse()
+					errMsg := err.Error()
+					resp.Error = &errMsg
+					return resp, http.StatusBadRequest
+				})(w, r)
+			default:
+				responseJSON(func(*http.Request) (interface{}, int, error) {
+					return nil, http.StatusBadRequest, err
+				})(w, r)
+			}
+		})
+	}
+}
+
+// requestError tells why a request does not match the document. A value which
+// does not match its schema is named by the parameter or the path to the value
+// in the body.
+func requestError(err error) error {
+	var re *openapi3filter.RequestError
+	if !errors.As(err, &re) {
+		return fmt.Errorf("invalid request: %v", err)
+	}
+
+	var se *openapi3.SchemaError
+	if errors.As(re.Err, &se) {
+		field := strings.Join(se.JSONPointer(), ".")
+		if re.Parameter != nil {
+			field = re.Parameter.Name
+		}
+		if field == "" {
+			field = "body"
+		}
+		return fmt.Errorf("invalid request: %v: %v", field, se.Reason)
+	}
+
+	reason := re.Reason
+	if reason == "" && re.Err != nil {
+		reason = re.Err.Error()
+	}
+	if re.Parameter != nil {
+		reason = fmt.Sprintf("parameter %q in %v: %v", re.Parameter.Name, re.Parameter.In, reason)
+	}
+	return fmt.Errorf("invalid request: %v", reason)
+}
@@ -0,0 +1,547 @@
+package handler
+
+// spec is the OpenAPI 3 document of the routes registered by New. It is
+// written in YAML and served as JSON at /openapi.json.
+const spec = `
+openapi: 3.0.3
+info:
+  title: insservice
+  description: Renders the insfiles of modules, releases and environments of conmansys.
+  version: 1.0.0
+paths:
+  /health:
+    get:
+      summary: Reports that the service is running, like /livez.
+      responses:
+        "200":
+          description: The service is running.
+          content:
+            application/json:
+              schema:
+                $ref: "#/components/schemas/Health"
+  /livez:
+    get:
+      summary: Reports that the service is running.
+      responses:
+        "200":
+          description: The service is running.
+          content:
+            application/json:
+              schema:
+                $ref: "#/components/schemas/Health"
+  /readyz:
+    get:
+      summary: Reports whether the service and the dependencies it needs are ready.
+      responses:
+        "200":
+          description: Every dependency is ready.
+          content:
+            application/json:
+              schema:
+                $ref: "#/components/schemas/Health"
+        "503":
+          description: A dependency is not ready.
+          content:
+            application/json:
+              schema:
+                $ref: "#/components/schemas/Health"
+  /metrics:
+    get:
+      summary: The Prometheus metrics of the service.
+      responses:
+        "200":
+          description: The metrics in the Prometheus text format.
+          content:
+            text/plain:
+              schema:
+                type: string
+  /openapi.json:
+    get:
+      summary: This document.
+      responses:
+        "200":
+          description: The OpenAPI document of the service.
+          content:
+            application/json:
+              schema:
+                type: object
+  /insfile:
+    post:
+      summary: Renders the insfile of the modules with the modules it is made of.
+      parameters:
+        - $ref: "#/components/parameters/optional"
+        - $ref: "#/components/parameters/policy"
+      requestBody:
+        $ref: "#/components/requestBodies/Modules"
+      responses:
+        "200":
+          $ref: "#/components/responses/Insfile"
+        "400":
+          $ref: "#/components/responses/Insfile"
+        "409":
+          $ref: "#/components/responses/Insfile"
+  /insfile/text:
+    post:
+      summary: Renders the insfile of the modules with the modules it is made of as text.
+      parameters:
+        - $ref: "#/components/parameters/optional"
+        - $ref: "#/components/parameters/policy"
+      requestBody:
+        $ref: "#/components/requestBodies/Modules"
+      responses:
+        "200":
+          $ref: "#/components/responses/Text"
+        "400":
+          $ref: "#/components/responses/Text"
+        "409":
+          $ref: "#/components/responses/Text"
+  /insfile/traverse:
+    post:
+      summary: Renders the items of the insfile of the modules.
+      parameters:
+        - $ref: "#/components/parameters/optional"
+        - $ref: "#/components/parameters/policy"
+      requestBody:
+        $ref: "#/components/requestBodies/Modules"
+      responses:
+        "200":
+          $ref: "#/components/responses/Insfile"
+        "400":
+          $ref: "#/components/responses/Insfile"
+        "409":
+          $ref: "#/components/responses/Insfile"
+  /insfile/traverse/text:
+    post:
+      summary: Renders the items of the insfile of the modules as text.
+      parameters:
+        - $ref: "#/components/parameters/optional"
+        - $ref: "#/components/parameters/policy"
+      requestBody:
+        $ref: "#/components/requestBodies/Modules"
+      responses:
+        "200":
+          $ref: "#/components/responses/Text"
+        "400":
+          $ref: "#/components/responses/Text"
+        "409":
+          $ref: "#/components/responses/Text"
+  /insfile/release/{name}:
+    parameters:
+      - $ref: "#/components/parameters/name"
+    get:
+      summary: Renders the insfile of a release from what was frozen in it.
+      parameters:
+        - $ref: "#/components/parameters/policy"
+      responses:
+        "200":
+          $ref: "#/components/responses/Insfile"
+        "400":
+          $ref: "#/components/responses/Insfile"
+        "404":
+          $ref: "#/components/responses/Insfile"
+        "409":
+          $ref: "#/components/responses/Insfile"
+  /insfile/release/{name}/text:
+    parameters:
+      - $ref: "#/components/parameters/name"
+    get:
+      summary: Renders the insfile of a release as text.
+      parameters:
+        - $ref: "#/components/parameters/policy"
+      responses:
+        "200":
+          $ref: "#/components/responses/Text"
+        "400":
+          $ref: "#/components/responses/Text"
+        "404":
+          $ref: "#/components/responses/Text"
+        "409":
+          $ref: "#/components/responses/Text"
+  /insfile/environment/{env}:
+    parameters:
+      - $ref: "#/components/parameters/env"
+    get:
+      summary: Renders the insfile of the release promoted to an environment.
+      parameters:
+        - $ref: "#/components/parameters/policy"
+      responses:
+        "200":
+          $ref: "#/components/responses/Insfile"
+        "400":
+          $ref: "#/components/responses/Insfile"
+        "404":
+          $ref: "#/components/responses/Insfile"
+        "409":
+          $ref: "#/components/responses/Insfile"
+  /insfile/environment/{env}/text:
+    parameters:
+      - $ref: "#/components/parameters/env"
+    get:
+      summary: Renders the insfile of the release promoted to an environment as text.
+      parameters:
+        - $ref: "#/components/parameters/policy"
+      responses:
+        "200":
+          $ref: "#/components/responses/Text"
+        "400":
+          $ref: "#/components/responses/Text"
+        "404":
+          $ref: "#/components/responses/Text"
+        "409":
+          $ref: "#/components/responses/Text"
+  /environments/{env}:
+    parameters:
+      - $ref: "#/components/parameters/env"
+    get:
+      summary: Gets the promotion of the release which is in an environment.
+      responses:
+        "200":
+          $ref: "#/components/responses/Environment"
+        "404":
+          $ref: "#/components/responses/Environment"
+  /diff:
+    get:
+      summary: Lists the items and modules which change going from one side to the other.
+      parameters:
+        - $ref: "#/components/parameters/from"
+        - $ref: "#/components/parameters/to"
+        - $ref: "#/components/parameters/optional"
+        - $ref: "#/components/parameters/policy"
+      responses:
+        "200":
+          $ref: "#/components/responses/Diff"
+        "400":
+          $ref: "#/components/responses/Diff"
+        "404":
+          $ref: "#/components/responses/Diff"
+        "409":
+          $ref: "#/components/responses/Diff"
+  /diff/text:
+    get:
+      summary: Lists the items and modules which change going from one side to the other as text.
+      parameters:
+        - $ref: "#/components/parameters/from"
+        - $ref: "#/components/parameters/to"
+        - $ref: "#/components/parameters/optional"
+        - $ref: "#/components/parameters/policy"
+      responses:
+        "200":
+          $ref: "#/components/responses/Text"
+        "400":
+          $ref: "#/components/responses/Text"
+        "404":
+          $ref: "#/components/responses/Text"
+        "409":
+          $ref: "#/components/responses/Text"
+components:
+  parameters:
+    name:
+      name: name
+      in: path
+      required: true
+      description: The name of the release.
+      schema:
+        type: string
+    env:
+      name: env
+      in: path
+      required: true
+      description: The environment, e.g. dev, staging or prod.
+      schema:
+        type: string
+    optional:
+      name: optional
+      in: query
+      allowEmptyValue: true
+      description: Follow the optional dependencies too.
+      schema:
+        type: boolean
+    policy:
+      name: policy
+      in: query
+      allowEmptyValue: true
+      description: Decides which version of an item contributed by several modules is used.
+      schema:
+        type: string
+        enum: [nearest-module, highest-version, error]
+        default: nearest-module
+    from:
+      name: from
+      in: query
+      allowEmptyValue: true
+      description: >-
+        The side to compare from, a release as release:<name> or module ids as
+        modules:<id>,<id>.
+      schema:
+        type: string
+    to:
+      name: to
+      in: query
+      allowEmptyValue: true
+      description: The side to compare to, like from.
+      schema:
+        type: string
+  requestBodies:
+    Modules:
+      required: true
+      description: The modules as a list of their ids or a label selector.
+      content:
+        application/json:
+          schema:
+            oneOf:
+              - type: array
+                items:
+                  type: object
+                  required: [id]
+                  properties:
+                    id:
+                      type: integer
+                      format: int64
+                      minimum: 1
+              - type: object
+                required: [selector]
+                additionalProperties: false
+                properties:
+                  selector:
+                    type: string
+  responses:
+    Text:
+      description: The insfile, or the error and the conflicts, one per line.
+      content:
+        text/plain:
+          schema:
+            type: string
+    Insfile:
+      description: The insfile with what it was resolved from.
+      content:
+        application/json:
+          schema:
+            type: object
+            properties:
+              items:
+                type: array
+                items:
+                  $ref: "#/components/schemas/Item"
+              modules:
+                type: array
+                items:
+                  $ref: "#/components/schemas/Module"
+              excluded:
+                type: array
+                items:
+                  $ref: "#/components/schemas/Exclusion"
+              module_conflicts:
+                type: array
+                items:
+                  $ref: "#/components/schemas/ModuleConflict"
+              conflicts:
+                type: array
+                items:
+                  $ref: "#/components/schemas/ItemConflict"
+              promotion:
+                $ref: "#/components/schemas/Promotion"
+              error:
+                $ref: "#/components/schemas/ErrorMessage"
+    Environment:
+      description: The promotion of the environment.
+      content:
+        application/json:
+          schema:
+            type: object
+            properties:
+              environment:
+                type: string
+              promotion:
+                allOf:
+                  - $ref: "#/components/schemas/Promotion"
+                nullable: true
+              error:
+                $ref: "#/components/schemas/ErrorMessage"
+    Diff:
+      description: The items and modules which are added, removed or changed.
+      content:
+        application/json:
+          schema:
+            type: object
+            properties:
+              from:
+                type: string
+              to:
+                type: string
+              items:
+                type: object
+                properties:
+                  added:
+                    type: array
+                    items:
+                      $ref: "#/components/schemas/Item"
+                  removed:
+                    type: array
+                    items:
+                      $ref: "#/components/schemas/Item"
+                  changed:
+                    type: array
+                    items:
+                      type: object
+                      properties:
+                        value:
+                          type: string
+                        type:
+                          type: string
+                        from:
+                          type: string
+                        to:
+                          type: string
+              modules:
+                type: object
+                properties:
+                  added:
+                    type: array
+                    items:
+                      $ref: "#/components/schemas/Module"
+                  removed:
+                    type: array
+                    items:
+                      $ref: "#/components/schemas/Module"
+                  changed:
+                    type: array
+                    items:
+                      type: object
+                      properties:
+                        id:
+                          type: integer
+                          format: int64
+                        value:
+                          type: string
+                        from:
+                          type: string
+                        to:
+                          type: string
+              conflicts:
+                type: array
+                items:
+                  $ref: "#/components/schemas/ItemConflict"
+              error:
+                $ref: "#/components/schemas/ErrorMessage"
+  schemas:
+    Health:
+      type: object
+      properties:
+        status:
+          type: string
+          enum: [ok, unavailable]
+        checks:
+          type: object
+          additionalProperties:
+            type: object
+            properties:
+              status:
+                type: string
+                enum: [ok, failed]
+              error:
+                type: string
+              duration_ms:
+                type: number
+    ErrorMessage:
+      type: string
+      nullable: true
+    Item:
+      type: object
+      properties:
+        id:
+          type: integer
+          format: int64
+        value:
+          type: string
+        type:
+          type: string
+        version:
+          type: string
+    Module:
+      type: object
+      properties:
+        id:
+          type: integer
+          format: int64
+        value:
+          type: string
+        version:
+          type: string
+        labels:
+          type: object
+          additionalProperties:
+            type: string
+    Exclusion:
+      type: object
+      description: A module left out of the insfile and why.
+      properties:
+        id:
+          type: integer
+          format: int64
+        from:
+          type: integer
+          format: int64
+        kind:
+          type: string
+        reason:
+          type: string
+    ModuleConflict:
+      type: object
+      properties:
+        id:
+          type: integer
+          format: int64
+        with:
+          type: integer
+          format: int64
+        reason:
+          type: string
+    ItemConflict:
+      type: object
+      description: An item contributed by several modules at different versions.
+      properties:
+        value:
+          type: string
+        type:
+          type: string
+        candidates:
+          type: array
+          items:
+            type: object
+            properties:
+              module:
+                type: integer
+                format: int64
+              depth:
+                type: integer
+              version:
+                type: string
+        winner:
+          type: string
+        policy:
+          type: string
+        reason:
+          type: string
+    Promotion:
+      type: object
+      properties:
+        id:
+          type: integer
+          format: int64
+        release:
+          type: string
+        environment:
+          type: string
+        status:
+          type: string
+        requested_by:
+          type: string
+        requested_at:
+          type: string
+          format: date-time
+        approved_by:
+          type: string
+        promoted_at:
+          type: string
+          format: date-time
+`
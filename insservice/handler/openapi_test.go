@@ -0,0 +1,156 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/gorilla/mux"
+)
+
+// TestSpecRoutes makes sure that the document and the routes of the handler
+// describe the same operations.
+func TestSpecRoutes(t *testing.T) {
+	doc, err := loadSpec()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	routes := make(map[string]bool)
+	r := New(service).(*mux.Router)
+	err = r.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		tmpl, err := route.GetPathTemplate()
+		if err != nil {
+			return err
+		}
+
+		methods, err := route.GetMethods()
+		if err != nil {
+			// routes without methods answer every method, like /health
+			methods = []string{http.MethodGet}
+		}
+
+		for _, m := range methods {
+			routes[m+" "+tmpl] = true
+			if doc.Paths.Find(tmpl) == nil || doc.Paths.Find(tmpl).GetOperation(m) == nil {
+				t.Errorf("expected %v %v to be in the document", m, tmpl)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for path, item := range doc.Paths {
+		for m := range item.Operations() {
+			if !routes[m+" "+path] {
+				t.Errorf("expected %v %v to be routed", m, path)
+			}
+		}
+	}
+}
+
+func TestOpenAPI(t *testing.T) {
+	srv := httptest.NewServer(New(service))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/openapi.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status: %v, got: %v", http.StatusOK, resp.StatusCode)
+	}
+
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	doc, err := openapi3.NewLoader().LoadFromData(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.Paths.Find("/insfile") == nil {
+		t.Fatalf("expected the insfile to be documented, got: %v", doc.Paths)
+	}
+}
+
+func TestValidateRequests(t *testing.T) {
+	tt := map[string]struct {
+		method string
+		url    string
+		body   string
+		status int
+		text   bool
+	}{
+		"modules":         {method: http.MethodPost, url: "/insfile", body: `[{"id": 1}]`, status: http.StatusOK},
+		"selector":        {method: http.MethodPost, url: "/insfile", body: `{"selector": "team=billing"}`, status: http.StatusOK},
+		"wrong type":      {method: http.MethodPost, url: "/insfile", body: `[{"id": "1"}]`, status: http.StatusBadRequest},
+		"unknown field":   {method: http.MethodPost, url: "/insfile", body: `{"id": 1}`, status: http.StatusBadRequest},
+		"malformed":       {method: http.MethodPost, url: "/insfile", body: `[{"id": `, status: http.StatusBadRequest},
+		"text":            {method: http.MethodPost, url: "/insfile/traverse/text", body: `[{"id": 0}]`, status: http.StatusBadRequest, text: true},
+		"query":           {method: http.MethodPost, url: "/insfile?optional=maybe", body: `[{"id": 1}]`, status: http.StatusBadRequest},
+		"query enum":      {method: http.MethodGet, url: "/insfile/release/2026.10/text?policy=oldest", status: http.StatusBadRequest, text: true},
+		"empty query":     {method: http.MethodPost, url: "/insfile?optional=&policy=", body: `[{"id": 1}]`, status: http.StatusOK},
+		"diff query enum": {method: http.MethodGet, url: "/diff?from=1&to=2&policy=oldest", status: http.StatusBadRequest},
+		"not routed":      {method: http.MethodGet, url: "/insfile", status: http.StatusMethodNotAllowed},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			srv := httptest.NewServer(New(service))
+			defer srv.Close()
+
+			req, err := http.NewRequest(tc.method, srv.URL+tc.url, bytes.NewBufferString(tc.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tc.status {
+				t.Fatalf("expected status: %v, got: %v", tc.status, resp.StatusCode)
+			}
+
+			if tc.status != http.StatusBadRequest {
+				return
+			}
+
+			b, err := ioutil.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			msg := string(b)
+			if !tc.text {
+				var body struct {
+					Error *string `json:"error"`
+				}
+				if err := json.Unmarshal(b, &body); err != nil {
+					t.Fatal(err)
+				}
+				if body.Error == nil {
+					t.Fatal("expected an error, got: nil")
+				}
+				msg = *body.Error
+			}
+
+			if !strings.HasPrefix(msg, "invalid request") {
+				t.Fatalf("expected the request to be refused, got: %v", msg)
+			}
+		})
+	}
+}
@@ -2,69 +2,162 @@ package handler
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
-	"log"
 	"net/http"
+	"strconv"
 	"strings"
 
+	"github.com/Glorforidor/conmansys/health"
+	"github.com/Glorforidor/conmansys/insservice/merge"
 	"github.com/Glorforidor/conmansys/insservice/storage"
+	"github.com/Glorforidor/conmansys/labels"
+	"github.com/Glorforidor/conmansys/logging"
+	"github.com/Glorforidor/conmansys/metrics"
+	"github.com/Glorforidor/conmansys/resolve"
 	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gorilla/mux/otelmux"
 )
 
 type handler struct {
 	storage storage.Service
+	checks  []health.Check
 }
 
+// Option configures optional behaviour of the handler.
+type Option func(*handler)
+
 // New registers the service to the handler and registers the "/insfile"
-// endpoint to the handler.
-func New(service storage.Service) http.Handler {
+// endpoint to the handler. Requests are validated against the OpenAPI document
+// served at "/openapi.json".
+func New(service storage.Service, opts ...Option) http.Handler {
 	r := mux.NewRouter()
 
-	h := handler{service}
+	h := handler{storage: service}
+	for _, opt := range opts {
+		opt(&h)
+	}
+
+	doc, err := loadSpec()
+	if err != nil {
+		panic(fmt.Sprintf("invalid OpenAPI document: %v", err))
+	}
+	r.Use(otelmux.Middleware("insservice"), logging.Middleware, metrics.Instrument, validateRequests(doc))
 
-	r.HandleFunc("/health", health)
+	r.HandleFunc("/health", health.Livez)
+	r.HandleFunc("/livez", health.Livez).Methods(http.MethodGet)
+	r.HandleFunc("/readyz", health.Readyz(h.checks...)).Methods(http.MethodGet)
+	r.Handle("/metrics", promhttp.Handler()).Methods(http.MethodGet)
+	r.HandleFunc("/openapi.json", openAPI(doc)).Methods(http.MethodGet)
 	r.HandleFunc("/insfile", responseJSONWithModules(h.insfileWithModules)).Methods(http.MethodPost)
 	r.HandleFunc("/insfile/text", responseTextWithModules(h.insfileWithModules)).Methods(http.MethodPost)
 	r.HandleFunc("/insfile/traverse", responseJSON(h.insfile)).Methods(http.MethodPost)
 	r.HandleFunc("/insfile/traverse/text", responseText(h.insfile)).Methods(http.MethodPost)
+	r.HandleFunc("/insfile/release/{name}", responseJSONWithModules(h.releaseInsfile)).Methods(http.MethodGet)
+	r.HandleFunc("/insfile/release/{name}/text", responseTextWithModules(h.releaseInsfile)).Methods(http.MethodGet)
+	r.HandleFunc("/insfile/environment/{env}", responseJSONWithModules(h.environmentInsfile)).Methods(http.MethodGet)
+	r.HandleFunc("/insfile/environment/{env}/text", responseTextWithModules(h.environmentInsfile)).Methods(http.MethodGet)
+	r.HandleFunc("/environments/{env}", h.environment).Methods(http.MethodGet)
+	r.HandleFunc("/diff", responseDiffJSON(h.diff)).Methods(http.MethodGet)
+	r.HandleFunc("/diff/text", responseDiffText(h.diff)).Methods(http.MethodGet)
 
 	return r
 }
 
-func health(w http.ResponseWriter, r *http.Request) {
-	w.WriteHeader(http.StatusOK)
+// logError logs the error with the request id of the request of ctx.
+func logError(ctx context.Context, msg string, err error) {
+	logging.FromContext(ctx).Error(msg, "error", err)
 }
 
 // response is the response body for the client. Items and Modules should be set
 // to an empty slice instead of nil slice. An empty error should be stored as
 // nil
 type response struct {
-	Items   []*storage.Item   `json:"items"`
-	Modules []*storage.Module `json:"modules"`
-	Error   *string           `json:"error"`
+	Items           []*storage.Item       `json:"items"`
+	Modules         []*storage.Module     `json:"modules"`
+	Excluded        []*resolve.Exclusion  `json:"excluded"`
+	ModuleConflicts []*resolve.Conflict   `json:"module_conflicts"`
+	Conflicts       []*merge.ItemConflict `json:"conflicts"`
+	Promotion       *storage.Promotion    `json:"promotion,omitempty"`
+	Error           *string               `json:"error"`
+}
+
+// add sets the part of the response which matches the type of d.
+func (resp *response) add(d interface{}) {
+	switch v := d.(type) {
+	case []*storage.Item:
+		resp.Items = v
+	case []*storage.Module:
+		resp.Modules = v
+	case *resolve.Result:
+		if v != nil {
+			resp.Excluded = v.Excluded
+			resp.ModuleConflicts = v.Conflicts
+		}
+	case []*merge.ItemConflict:
+		resp.Conflicts = v
+	case *storage.Promotion:
+		resp.Promotion = v
+	}
+}
+
+// conflicts returns why the modules or items of an insfile conflict if d is
+// the resolved modules or the item conflicts.
+func conflicts(d interface{}) []string {
+	var reasons []string
+	switch v := d.(type) {
+	case *resolve.Result:
+		if v == nil {
+			return nil
+		}
+		for _, c := range v.Conflicts {
+			reasons = append(reasons, c.Reason)
+		}
+	case []*merge.ItemConflict:
+		for _, c := range v {
+			reasons = append(reasons, c.Reason)
+		}
+	}
+	return reasons
+}
+
+// parts returns the parts of the data returned by a handler.
+func parts(data interface{}) []interface{} {
+	if p, ok := data.([]interface{}); ok {
+		return p
+	}
+	return []interface{}{data}
 }
 
 // responseText packs data into text/plain format.
 func responseText(h func(r *http.Request) (interface{}, int, error)) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		data, status, err := h(r)
+
+		var b bytes.Buffer
 		if err != nil {
-			data = err.Error()
+			fmt.Fprintf(&b, "%v\r\n", err)
 		}
 
-		var b bytes.Buffer
-		switch v := data.(type) {
-		case []*storage.Item:
-			for _, item := range v {
-				// https://www.ietf.org/rfc/rfc2046.txt says that the newline of
-				// text is CRLF
-				fmt.Fprintf(&b, "%v\r\n", item.Value)
+		for _, d := range parts(data) {
+			switch v := d.(type) {
+			case []*storage.Item:
+				for _, item := range v {
+					// https://www.ietf.org/rfc/rfc2046.txt says that the newline of
+					// text is CRLF
+					fmt.Fprintf(&b, "%v\r\n", item.Value)
+				}
+			}
+
+			if err != nil {
+				for _, c := range conflicts(d) {
+					fmt.Fprintf(&b, "%v\r\n", c)
+				}
 			}
-		default:
-			fmt.Fprintf(&b, "%v\r\n", data)
 		}
 
 		w.Header().Set("Content-Type", "text/plain")
@@ -87,28 +180,44 @@ func responseJSON(h func(r *http.Request) (interface{}, int, error)) http.Handle
 		// it null
 		resp.Items = []*storage.Item{}
 		resp.Modules = []*storage.Module{}
+		resp.Excluded = []*resolve.Exclusion{}
+		resp.ModuleConflicts = []*resolve.Conflict{}
+		resp.Conflicts = []*merge.ItemConflict{}
 
-		switch v := data.(type) {
-		case []*storage.Item:
-			resp.Items = v
+		for _, d := range parts(data) {
+			resp.add(d)
 		}
 
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(status)
 		err = json.NewEncoder(w).Encode(resp)
 		if err != nil {
-			log.Printf("could not decode to output: %v", err)
+			logError(r.Context(), "could not decode to output", err)
 		}
 	}
 }
 
-func readModules(r io.Reader) ([]storage.Module, int, error) {
+// selectorRequest is the alternative request body which selects the modules
+// by their labels instead of listing their IDs.
+type selectorRequest struct {
+	Selector string `json:"selector"`
+}
+
+// readModules reads the modules from the request body. The body is either a
+// list of modules, e.g. [{"id": 1}], or a label selector, e.g.
+// {"selector": "release=2026.10"}, which selects every module with matching
+// labels.
+func (h handler) readModules(ctx context.Context, r io.Reader) ([]storage.Module, int, error) {
 	var modules []storage.Module
 	t, err := ioutil.ReadAll(r)
 	if err != nil {
 		return nil, http.StatusBadRequest, fmt.Errorf("could not read request body: %v", err)
 	}
 
+	if b := bytes.TrimSpace(t); len(b) > 0 && b[0] == '{' {
+		return h.selectModules(ctx, b)
+	}
+
 	err = json.Unmarshal(t, &modules)
 	if err != nil {
 		return nil, http.StatusBadRequest, fmt.Errorf("could not decode JSON request body: %v", err)
@@ -123,19 +232,153 @@ func readModules(r io.Reader) ([]storage.Module, int, error) {
 	return modules, 0, nil
 }
 
-func (h handler) insfile(r *http.Request) (interface{}, int, error) {
-	modules, status, err := readModules(r.Body)
+// selectModules decodes a selectorRequest and returns the modules whose labels
+// are selected by it.
+func (h handler) selectModules(ctx context.Context, t []byte) ([]storage.Module, int, error) {
+	var req selectorRequest
+	dec := json.NewDecoder(bytes.NewReader(t))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		return nil, http.StatusBadRequest, fmt.Errorf("could not decode JSON request body: %v", err)
+	}
+
+	if strings.TrimSpace(req.Selector) == "" {
+		return nil, http.StatusBadRequest, fmt.Errorf("missing selector: %v", string(t))
+	}
+
+	sel, err := labels.Parse(req.Selector)
+	if err != nil {
+		return nil, http.StatusBadRequest, fmt.Errorf("invalid selector: %v", err)
+	}
+
+	selected, err := h.storage.SelectModules(ctx, sel)
+	if err != nil {
+		logError(ctx, "could not retrieve data from database", err)
+		return nil, http.StatusInternalServerError, fmt.Errorf("Ups something went wrong")
+	}
+
+	modules := []storage.Module{}
+	for _, m := range selected {
+		modules = append(modules, *m)
+	}
+
+	return modules, 0, nil
+}
+
+// followOptional reports whether the query asks for optional dependencies to
+// be followed with optional=true.
+func followOptional(r *http.Request) (bool, error) {
+	o := r.URL.Query().Get("optional")
+	if o == "" {
+		return false, nil
+	}
+
+	b, err := strconv.ParseBool(o)
 	if err != nil {
-		return nil, status, err
+		return false, fmt.Errorf("optional must be true or false, got: %q", o)
 	}
 
-	items, err := h.storage.GetItems(modules...)
+	return b, nil
+}
+
+// resolve resolves the modules of the insfile of the roots. If the resolved
+// modules conflict the result is returned with an error.
+func (h handler) resolve(ctx context.Context, roots []int64, optional bool) (*resolve.Result, int, error) {
+	deps, err := h.storage.GetModuleDependencies(ctx, roots...)
 	if err != nil {
-		log.Println(fmt.Errorf("could not retrieve data from database: %v", err))
+		logError(ctx, "could not retrieve data from database", err)
 		return nil, http.StatusInternalServerError, fmt.Errorf("Ups something went wrong")
 	}
 
-	return items, http.StatusOK, nil
+	res := resolve.Resolve(deps, roots, optional)
+	closureModules.Observe(float64(len(res.Modules)))
+	if len(res.Conflicts) > 0 {
+		return res, http.StatusConflict, fmt.Errorf("the modules of the insfile conflict")
+	}
+
+	return res, 0, nil
+}
+
+// resolveModules reads the requested modules from the request body and
+// resolves the modules of their insfile. Optional dependencies are followed if
+// the query has optional=true. If the resolved modules conflict the result is
+// returned with an error.
+func (h handler) resolveModules(r *http.Request) ([]int64, *resolve.Result, int, error) {
+	optional, err := followOptional(r)
+	if err != nil {
+		return nil, nil, http.StatusBadRequest, err
+	}
+
+	modules, status, err := h.readModules(r.Context(), r.Body)
+	if err != nil {
+		return nil, nil, status, err
+	}
+
+	roots := make([]int64, len(modules))
+	for i, m := range modules {
+		roots[i] = m.ID
+	}
+
+	res, status, err := h.resolve(r.Context(), roots, optional)
+	return roots, res, status, err
+}
+
+// itemPolicy returns the item conflict policy of the query, which is
+// nearest-module if it is not given.
+func itemPolicy(r *http.Request) (string, error) {
+	p := r.URL.Query().Get("policy")
+	if p == "" {
+		return merge.PolicyNearestModule, nil
+	}
+
+	if !merge.ValidPolicy(p) {
+		return "", fmt.Errorf("policy must be one of %q, got: %q", merge.Policies, p)
+	}
+
+	return p, nil
+}
+
+// moduleItems finds the items of the modules and merges them with the item
+// conflict policy. If the items conflict and the policy is error the conflicts
+// are returned with a conflict status and an error.
+func (h handler) moduleItems(ctx context.Context, modules []*resolve.Node, policy string) ([]*storage.Item, []*merge.ItemConflict, int, error) {
+	ids := make([]int64, len(modules))
+	for i, n := range modules {
+		ids[i] = n.ID
+	}
+
+	byModule, err := h.storage.GetModuleItems(ctx, ids...)
+	if err != nil {
+		logError(ctx, "could not retrieve data from database", err)
+		return nil, nil, http.StatusInternalServerError, fmt.Errorf("Ups something went wrong")
+	}
+
+	items, conflicts, err := merge.Items(modules, byModule, policy)
+	if err != nil {
+		return nil, conflicts, http.StatusConflict, err
+	}
+	closureItems.Observe(float64(len(items)))
+
+	return items, conflicts, http.StatusOK, nil
+}
+
+func (h handler) insfile(r *http.Request) (interface{}, int, error) {
+	policy, err := itemPolicy(r)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	_, res, status, err := h.resolveModules(r)
+	if err != nil {
+		return []interface{}{res}, status, err
+	}
+
+	items, conflicts, status, err := h.moduleItems(r.Context(), res.Modules, policy)
+	if err != nil {
+		return []interface{}{res, conflicts}, status, err
+	}
+
+	return []interface{}{items, res, conflicts}, http.StatusOK, nil
 }
 
 // TODO: would be better to return a csv style file back.
@@ -145,12 +388,27 @@ func responseTextWithModules(h func(r *http.Request) ([]interface{}, int, error)
 
 		var b bytes.Buffer
 
+		sep := strings.Repeat("-", 20) + "\r\n"
 		if err != nil {
 			fmt.Fprintf(&b, "%v\r\n", err.Error())
+			for _, d := range data {
+				if c := conflicts(d); len(c) > 0 {
+					fmt.Fprint(&b, "conflicts\r\n")
+					fmt.Fprint(&b, sep)
+					for _, reason := range c {
+						fmt.Fprintf(&b, "%v\r\n", reason)
+					}
+					fmt.Fprint(&b, sep)
+				}
+			}
 		} else {
-			sep := strings.Repeat("-", 20) + "\r\n"
 			for _, d := range data {
 				switch v := d.(type) {
+				case *storage.Promotion:
+					fmt.Fprint(&b, "release\r\n")
+					fmt.Fprint(&b, sep)
+					fmt.Fprintf(&b, "%v\r\n", v.Release)
+					fmt.Fprint(&b, sep)
 				case []*storage.Item:
 					fmt.Fprint(&b, "items\r\n")
 					fmt.Fprint(&b, sep)
@@ -169,6 +427,26 @@ func responseTextWithModules(h func(r *http.Request) ([]interface{}, int, error)
 						fmt.Fprintf(&b, "%v\r\n", mod.ID)
 					}
 					fmt.Fprint(&b, sep)
+				case *resolve.Result:
+					if len(v.Excluded) == 0 {
+						continue
+					}
+					fmt.Fprint(&b, "excluded\r\n")
+					fmt.Fprint(&b, sep)
+					for _, e := range v.Excluded {
+						fmt.Fprintf(&b, "%v: %v\r\n", e.ID, e.Reason)
+					}
+					fmt.Fprint(&b, sep)
+				case []*merge.ItemConflict:
+					if len(v) == 0 {
+						continue
+					}
+					fmt.Fprint(&b, "conflicts\r\n")
+					fmt.Fprint(&b, sep)
+					for _, c := range v {
+						fmt.Fprintf(&b, "%v, %v picked %v\r\n", c.Reason, c.Policy, c.Winner)
+					}
+					fmt.Fprint(&b, sep)
 				}
 			}
 		}
@@ -190,14 +468,12 @@ func responseJSONWithModules(h func(r *http.Request) ([]interface{}, int, error)
 
 		resp.Items = []*storage.Item{}
 		resp.Modules = []*storage.Module{}
+		resp.Excluded = []*resolve.Exclusion{}
+		resp.ModuleConflicts = []*resolve.Conflict{}
+		resp.Conflicts = []*merge.ItemConflict{}
 
 		for _, d := range data {
-			switch v := d.(type) {
-			case []*storage.Item:
-				resp.Items = v
-			case []*storage.Module:
-				resp.Modules = v
-			}
+			resp.add(d)
 		}
 
 		w.Header().Set("Content-Type", "application/json")
@@ -207,17 +483,102 @@ func responseJSONWithModules(h func(r *http.Request) ([]interface{}, int, error)
 }
 
 func (h handler) insfileWithModules(r *http.Request) ([]interface{}, int, error) {
-	modules, status, err := readModules(r.Body)
+	policy, err := itemPolicy(r)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	_, res, status, err := h.resolveModules(r)
 	if err != nil {
-		return nil, status, err
+		return []interface{}{res}, status, err
 	}
 
-	items, mods, err := h.storage.GetItemsAndModules(modules...)
+	// the requested modules give the items and the modules they depend on are
+	// listed
+	var roots []*resolve.Node
+	mods := []*storage.Module{}
+	for _, n := range res.Modules {
+		if n.Depth == 0 {
+			roots = append(roots, n)
+		} else {
+			mods = append(mods, &storage.Module{ID: n.ID})
+		}
+	}
 
+	items, conflicts, status, err := h.moduleItems(r.Context(), roots, policy)
 	if err != nil {
-		log.Println(fmt.Errorf("could not retrieve data from database: %v", err))
+		return []interface{}{res, conflicts}, status, err
+	}
+
+	return []interface{}{items, mods, res, conflicts}, http.StatusOK, nil
+}
+
+// releaseInsfile renders the insfile of the release with the name in the path.
+// Like /insfile the items of the modules of the release are merged with the
+// item conflict policy of the query and the modules they depend on are listed.
+// Only what was frozen in the release is used, so the insfile does not change
+// with the live modules.
+func (h handler) releaseInsfile(r *http.Request) ([]interface{}, int, error) {
+	policy, err := itemPolicy(r)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	name := strings.TrimSpace(mux.Vars(r)["name"])
+	release, err := h.storage.GetRelease(r.Context(), name)
+	if err != nil {
+		logError(r.Context(), "could not retrieve data from database", err)
 		return nil, http.StatusInternalServerError, fmt.Errorf("Ups something went wrong")
 	}
 
-	return []interface{}{items, mods}, http.StatusOK, nil
+	if release == nil {
+		return nil, http.StatusNotFound, fmt.Errorf("release %q not found", name)
+	}
+
+	return renderRelease(release, policy)
+}
+
+// renderRelease merges the items of the modules of the release with the item
+// conflict policy and lists the modules of the closure they depend on, like
+// insfileWithModules does for live modules. If the items conflict and the
+// policy is error the conflicts are returned with a conflict status and an
+// error.
+func renderRelease(release *storage.Release, policy string) ([]interface{}, int, error) {
+	nodes, byModule, all := releaseModules(release)
+	closureModules.Observe(float64(len(nodes)))
+
+	// the modules of the release give the items and the modules they depend on
+	// are listed
+	var roots []*resolve.Node
+	mods := []*storage.Module{}
+	for i, n := range nodes {
+		if n.Depth == 0 {
+			roots = append(roots, n)
+		} else {
+			mods = append(mods, all[i])
+		}
+	}
+
+	items, conflicts, err := merge.Items(roots, byModule, policy)
+	if err != nil {
+		return []interface{}{conflicts}, http.StatusConflict, err
+	}
+	closureItems.Observe(float64(len(items)))
+
+	return []interface{}{items, mods, conflicts}, http.StatusOK, nil
+}
+
+// releaseModules returns the modules of the closure of the release as resolved
+// nodes, their items by module id and the modules themselves.
+func releaseModules(release *storage.Release) ([]*resolve.Node, map[int64][]*storage.Item, []*storage.Module) {
+	var nodes []*resolve.Node
+	byModule := make(map[int64][]*storage.Item)
+	mods := []*storage.Module{}
+	for _, m := range release.Closure {
+		nodes = append(nodes, &resolve.Node{ID: m.ID, Depth: m.Depth, From: m.From, Kind: m.Kind})
+		byModule[m.ID] = m.Items
+		mods = append(mods, &storage.Module{ID: m.ID, Value: m.Value, Version: m.Version})
+	}
+
+	return nodes, byModule, mods
 }
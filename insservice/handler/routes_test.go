@@ -2,6 +2,7 @@ package handler
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -13,6 +14,7 @@ import (
 	"testing"
 
 	"github.com/Glorforidor/conmansys/insservice/storage"
+	"github.com/Glorforidor/conmansys/labels"
 )
 
 // TODO: make the test better.
@@ -20,41 +22,167 @@ import (
 // output is actually those from the mock data.
 
 type serviceMock struct {
-	items   []*storage.Item
-	modules []*storage.Module
-	closed  bool
+	items      map[int64][]*storage.Item
+	modules    []*storage.Module
+	deps       []*storage.ModuleDependency
+	releases   []*storage.Release
+	promotions []*storage.Promotion
+	closed     bool
 }
 
-func (s *serviceMock) GetItems(modules ...storage.Module) ([]*storage.Item, error) {
+func (s *serviceMock) GetModules(_ context.Context, ids ...int64) ([]*storage.Module, error) {
 	if s.closed {
 		return nil, errors.New("")
 	}
 
-	return s.items, nil
+	var modules []*storage.Module
+	for _, m := range s.modules {
+		for _, id := range ids {
+			if m.ID == id {
+				modules = append(modules, m)
+				break
+			}
+		}
+	}
+	return modules, nil
+}
+
+func (s *serviceMock) SelectModules(_ context.Context, sel labels.Selector) ([]*storage.Module, error) {
+	if s.closed {
+		return nil, errors.New("")
+	}
+
+	var modules []*storage.Module
+	for _, m := range s.modules {
+		if sel.Matches(m.Labels) {
+			modules = append(modules, m)
+		}
+	}
+	return modules, nil
+}
+
+// GetModuleDependencies returns every dependency, which the resolver does not
+// mind as it only follows the ones reached from the ids.
+func (s *serviceMock) GetModuleDependencies(_ context.Context, _ ...int64) ([]*storage.ModuleDependency, error) {
+	if s.closed {
+		return nil, errors.New("")
+	}
+
+	return s.deps, nil
+}
+
+func (s *serviceMock) GetModuleItems(_ context.Context, ids ...int64) (map[int64][]*storage.Item, error) {
+	if s.closed {
+		return nil, errors.New("")
+	}
+
+	m := make(map[int64][]*storage.Item)
+	for _, id := range ids {
+		if it, ok := s.items[id]; ok {
+			m[id] = it
+		}
+	}
+	return m, nil
+}
+
+func (s *serviceMock) GetRelease(_ context.Context, name string) (*storage.Release, error) {
+	if s.closed {
+		return nil, errors.New("")
+	}
+
+	for _, r := range s.releases {
+		if r.Name == name {
+			return r, nil
+		}
+	}
+	return nil, nil
 }
 
-func (s *serviceMock) GetItemsAndModules(modules ...storage.Module) ([]*storage.Item, []*storage.Module, error) {
+// GetEnvironmentPromotion returns the last promoted promotion of the
+// environment, the promotions are kept in the order they were promoted.
+func (s *serviceMock) GetEnvironmentPromotion(_ context.Context, env string) (*storage.Promotion, error) {
 	if s.closed {
-		return nil, nil, errors.New("")
+		return nil, errors.New("")
 	}
 
-	return s.items, s.modules, nil
+	var current *storage.Promotion
+	for _, p := range s.promotions {
+		if p.Environment == env && p.Status == "promoted" {
+			current = p
+		}
+	}
+	return current, nil
 }
 
 var (
-	items = []*storage.Item{
-		{Value: "Taxonomy"},
-		{Value: "Management"},
-		{Value: "Payment"},
+	items = map[int64][]*storage.Item{
+		1: {
+			{Value: "Taxonomy", Version: "1.0.0"},
+			{Value: "Management"},
+			{Value: "Payment"},
+		},
+		2: {
+			{Value: "Payment"},
+			{Value: "Refund"},
+		},
+		3: {
+			{Value: "Invoice"},
+		},
+		5: {
+			{Value: "Reports"},
+		},
+		6: {
+			{Value: "Taxonomy", Version: "2.0.0"},
+		},
 	}
 
 	modules = []*storage.Module{
-		{ID: 1},
-		{ID: 2},
+		{ID: 1, Labels: map[string]string{"release": "2026.10"}},
+		{ID: 2, Labels: map[string]string{"release": "2026.10", "tier": "experimental"}},
 		{ID: 3},
+		{ID: 4},
+		{ID: 5},
+		{ID: 6},
+	}
+
+	deps = []*storage.ModuleDependency{
+		{Dependent: 1, Dependee: 2, Kind: storage.Required},
+		{Dependent: 2, Dependee: 3, Kind: storage.Optional},
+		{Dependent: 1, Dependee: 5, Kind: storage.Recommends},
+		{Dependent: 4, Dependee: 2, Kind: storage.Conflicts},
 	}
 
-	service = &serviceMock{items: items, modules: modules}
+	// the release froze module 1 with Taxonomy at 1.0.0 and module 2 with
+	// Taxonomy at 0.9.0, which differ from the live items, and the other
+	// release has both modules in it, so their items conflict
+	releases = []*storage.Release{
+		{
+			Name:    "2026.10-prod",
+			Modules: []int64{1},
+			Closure: []*storage.ReleaseModule{
+				{ID: 1, Items: []*storage.Item{{Value: "Taxonomy", Version: "1.0.0"}, {Value: "Frozen"}}},
+				{ID: 2, Depth: 1, From: 1, Kind: storage.Required, Items: []*storage.Item{{Value: "Taxonomy", Version: "0.9.0"}}},
+			},
+		},
+		{
+			Name:    "2026.11-prod",
+			Modules: []int64{1, 2},
+			Closure: []*storage.ReleaseModule{
+				{ID: 1, Items: []*storage.Item{{Value: "Taxonomy", Version: "1.0.0"}}},
+				{ID: 2, Items: []*storage.Item{{Value: "Taxonomy", Version: "0.9.0"}}},
+			},
+		},
+	}
+
+	// prod only has a pending promotion and staging refers to a release
+	// which is gone
+	promotions = []*storage.Promotion{
+		{ID: 1, Release: "2026.10-prod", Environment: "dev", Status: "promoted"},
+		{ID: 2, Release: "2026.10-prod", Environment: "prod", Status: "pending"},
+		{ID: 3, Release: "2026.09-prod", Environment: "staging", Status: "promoted"},
+	}
+
+	service = &serviceMock{items: items, modules: modules, deps: deps, releases: releases, promotions: promotions}
 )
 
 func TestResponseJSONWithModules(t *testing.T) {
@@ -272,7 +400,7 @@ func TestResponseTextWithModules(t *testing.T) {
 
 			s := string(body)
 			if tc.status != http.StatusBadRequest {
-				for _, item := range items {
+				for _, item := range items[1] {
 					if !strings.Contains(s, item.Value) {
 						t.Fatalf("missing value: %v in text body: %v", item.Value, s)
 					}
@@ -337,7 +465,7 @@ func TestResponseText(t *testing.T) {
 
 			s := string(body)
 			if tc.status != http.StatusBadRequest {
-				for _, item := range items {
+				for _, item := range items[1] {
 					if !strings.Contains(s, item.Value) {
 						t.Fatalf("missing value: %v in text body: %v", item.Value, s)
 					}
@@ -380,6 +508,11 @@ func TestInsfile(t *testing.T) {
 			err:    true,
 			closed: true,
 		},
+		"conflicting modules": {
+			body:   bytes.NewReader([]byte("[{\"id\": 1}, {\"id\": 4}]\r\n")),
+			status: http.StatusConflict,
+			err:    true,
+		},
 	}
 
 	for name, tc := range tt {
@@ -389,7 +522,7 @@ func TestInsfile(t *testing.T) {
 				t.Fatalf("could not create POST request: %v", err)
 			}
 
-			h := handler{service}
+			h := handler{storage: service}
 			if tc.closed {
 				service.closed = true
 				defer func() { service.closed = false }()
@@ -435,6 +568,11 @@ func TestInsfileWithModules(t *testing.T) {
 			err:    true,
 			closed: true,
 		},
+		"conflicting modules": {
+			body:   bytes.NewReader([]byte("[{\"id\": 1}, {\"id\": 4}]\r\n")),
+			status: http.StatusConflict,
+			err:    true,
+		},
 	}
 
 	for name, tc := range tt {
@@ -444,7 +582,7 @@ func TestInsfileWithModules(t *testing.T) {
 				t.Fatalf("could not create POST request: %v", err)
 			}
 
-			h := handler{service}
+			h := handler{storage: service}
 			if tc.closed {
 				service.closed = true
 				defer func() { service.closed = false }()
@@ -462,3 +600,310 @@ func TestInsfileWithModules(t *testing.T) {
 		})
 	}
 }
+
+func TestReadModules(t *testing.T) {
+	tt := map[string]struct {
+		body   string
+		want   []int64
+		status int
+		closed bool
+	}{
+		"ids":              {body: `[{"id": 1}, {"id": 3}]`, want: []int64{1, 3}},
+		"selector":         {body: `{"selector": "release=2026.10"}`, want: []int64{1, 2}},
+		"selector notin":   {body: `{"selector": "release, tier notin (experimental)"}`, want: []int64{1}},
+		"selector nothing": {body: `{"selector": "release=2027.01"}`, want: []int64{}},
+		"missing selector": {body: `{"selector": ""}`, status: http.StatusBadRequest},
+		"unknown field":    {body: `{"id": 1}`, status: http.StatusBadRequest},
+		"invalid selector": {body: `{"selector": "release in (2026.10"}`, status: http.StatusBadRequest},
+		"closed storage": {
+			body: `{"selector": "release=2026.10"}`, status: http.StatusInternalServerError, closed: true,
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			h := handler{storage: service}
+			if tc.closed {
+				service.closed = true
+				defer func() { service.closed = false }()
+			}
+
+			mods, status, err := h.readModules(context.Background(), strings.NewReader(tc.body))
+			if status != tc.status {
+				t.Fatalf("expected status: %v, got: %v (%v)", tc.status, status, err)
+			}
+			if tc.status != 0 {
+				if err == nil {
+					t.Fatal("expected an error, got: nil")
+				}
+				return
+			}
+
+			if len(mods) != len(tc.want) {
+				t.Fatalf("expected modules: %v, got: %v", tc.want, mods)
+			}
+			for i, m := range mods {
+				if m.ID != tc.want[i] {
+					t.Fatalf("expected modules: %v, got: %v", tc.want, mods)
+				}
+			}
+		})
+	}
+}
+
+func TestInsfileKinds(t *testing.T) {
+	tt := map[string]struct {
+		query    string
+		want     []string
+		excluded []int64
+		status   int
+	}{
+		"required": {
+			want:     []string{"Taxonomy", "Management", "Payment", "Refund"},
+			excluded: []int64{3, 5},
+			status:   http.StatusOK,
+		},
+		"optional": {
+			query:    "?optional=true",
+			want:     []string{"Taxonomy", "Management", "Payment", "Refund", "Invoice"},
+			excluded: []int64{5},
+			status:   http.StatusOK,
+		},
+		"invalid optional": {
+			query:  "?optional=maybe",
+			status: http.StatusBadRequest,
+		},
+	}
+
+	r := New(service)
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			url := fmt.Sprintf("%v/insfile/traverse%v", srv.URL, tc.query)
+			resp, err := srv.Client().Post(url, "application/json", strings.NewReader(`[{"id": 1}]`))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tc.status {
+				t.Fatalf("expected status: %v, got: %v", tc.status, resp.StatusCode)
+			}
+			if tc.status != http.StatusOK {
+				return
+			}
+
+			var body struct {
+				Items    []*storage.Item `json:"items"`
+				Excluded []struct {
+					ID int64 `json:"id"`
+				} `json:"excluded"`
+			}
+			if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+				t.Fatal(err)
+			}
+
+			var got []string
+			for _, it := range body.Items {
+				got = append(got, it.Value)
+			}
+			if strings.Join(got, ",") != strings.Join(tc.want, ",") {
+				t.Fatalf("expected items: %v, got: %v", tc.want, got)
+			}
+
+			var excluded []int64
+			for _, e := range body.Excluded {
+				excluded = append(excluded, e.ID)
+			}
+			if fmt.Sprint(excluded) != fmt.Sprint(tc.excluded) {
+				t.Fatalf("expected excluded: %v, got: %v", tc.excluded, excluded)
+			}
+		})
+	}
+}
+
+func TestInsfilePolicy(t *testing.T) {
+	tt := map[string]struct {
+		query   string
+		version string
+		status  int
+	}{
+		"default":         {version: "1.0.0", status: http.StatusOK},
+		"nearest module":  {query: "?policy=nearest-module", version: "1.0.0", status: http.StatusOK},
+		"highest version": {query: "?policy=highest-version", version: "2.0.0", status: http.StatusOK},
+		"error":           {query: "?policy=error", status: http.StatusConflict},
+		"unknown policy":  {query: "?policy=newest", status: http.StatusBadRequest},
+	}
+
+	r := New(service)
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			url := fmt.Sprintf("%v/insfile%v", srv.URL, tc.query)
+			resp, err := srv.Client().Post(url, "application/json", strings.NewReader(`[{"id": 1}, {"id": 6}]`))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tc.status {
+				t.Fatalf("expected status: %v, got: %v", tc.status, resp.StatusCode)
+			}
+			if tc.status == http.StatusBadRequest {
+				return
+			}
+
+			var body struct {
+				Items     []*storage.Item `json:"items"`
+				Conflicts []struct {
+					Value  string `json:"value"`
+					Winner string `json:"winner"`
+				} `json:"conflicts"`
+			}
+			if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+				t.Fatal(err)
+			}
+
+			if len(body.Conflicts) != 1 || body.Conflicts[0].Value != "Taxonomy" {
+				t.Fatalf("expected a conflict on Taxonomy, got: %v", body.Conflicts)
+			}
+			if body.Conflicts[0].Winner != tc.version {
+				t.Fatalf("expected winner: %q, got: %q", tc.version, body.Conflicts[0].Winner)
+			}
+
+			if tc.status != http.StatusOK {
+				return
+			}
+
+			n := 0
+			for _, it := range body.Items {
+				if it.Value == "Taxonomy" {
+					n++
+					if it.Version != tc.version {
+						t.Fatalf("expected Taxonomy at version: %v, got: %v", tc.version, it.Version)
+					}
+				}
+			}
+			if n != 1 {
+				t.Fatalf("expected Taxonomy once, got it %v times", n)
+			}
+		})
+	}
+}
+
+func TestReleaseInsfile(t *testing.T) {
+	tt := map[string]struct {
+		url    string
+		status int
+		want   string
+		closed bool
+	}{
+		"release":         {url: "/insfile/release/2026.10-prod/text", status: http.StatusOK, want: "Taxonomy\r\nFrozen\r\n"},
+		"highest version": {url: "/insfile/release/2026.11-prod?policy=highest-version", status: http.StatusOK},
+		"error policy":    {url: "/insfile/release/2026.11-prod?policy=error", status: http.StatusConflict},
+		"unknown policy":  {url: "/insfile/release/2026.10-prod?policy=newest", status: http.StatusBadRequest},
+		"missing":         {url: "/insfile/release/nope", status: http.StatusNotFound},
+		"closed storage":  {url: "/insfile/release/2026.10-prod", status: http.StatusInternalServerError, closed: true},
+	}
+
+	r := New(service)
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			if tc.closed {
+				service.closed = true
+				defer func() { service.closed = false }()
+			}
+
+			resp, err := srv.Client().Get(srv.URL + tc.url)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tc.status {
+				t.Fatalf("expected status: %v, got: %v", tc.status, resp.StatusCode)
+			}
+
+			body, err := ioutil.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tc.want != "" && !strings.Contains(string(body), tc.want) {
+				t.Fatalf("expected body to contain: %q, got: %q", tc.want, body)
+			}
+		})
+	}
+}
+
+// TestReleaseInsfileModules pins that the insfile of a release, like the one of
+// live modules, has the items of the modules asked for and only lists the
+// modules they depend on.
+func TestReleaseInsfileModules(t *testing.T) {
+	tt := map[string]struct {
+		method  string
+		url     string
+		body    string
+		items   []string
+		modules []int64
+	}{
+		"live": {
+			method: http.MethodPost, url: "/insfile", body: `[{"id": 1}]`,
+			items: []string{"Taxonomy", "Management", "Payment"}, modules: []int64{2},
+		},
+		"release": {
+			method: http.MethodGet, url: "/insfile/release/2026.10-prod",
+			items: []string{"Taxonomy", "Frozen"}, modules: []int64{2},
+		},
+	}
+
+	srv := httptest.NewServer(New(service))
+	defer srv.Close()
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			req, err := http.NewRequest(tc.method, srv.URL+tc.url, strings.NewReader(tc.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			resp, err := srv.Client().Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("expected status: %v, got: %v", http.StatusOK, resp.StatusCode)
+			}
+
+			var body response
+			if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+				t.Fatal(err)
+			}
+
+			var items []string
+			for _, i := range body.Items {
+				items = append(items, i.Value)
+			}
+			var modules []int64
+			for _, m := range body.Modules {
+				modules = append(modules, m.ID)
+			}
+
+			if fmt.Sprint(items) != fmt.Sprint(tc.items) {
+				t.Errorf("expected items: %v, got: %v", tc.items, items)
+			}
+			if fmt.Sprint(modules) != fmt.Sprint(tc.modules) {
+				t.Errorf("expected modules: %v, got: %v", tc.modules, modules)
+			}
+		})
+	}
+}
@@ -0,0 +1,5 @@
+// Package inspb holds the protobuf messages and the gRPC service of the
+// insservice generated from insservice.proto.
+package inspb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative insservice.proto
@@ -0,0 +1,205 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.2.0
+// - protoc             v3.19.1
+// source: insservice.proto
+
+package inspb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// InsServiceClient is the client API for InsService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type InsServiceClient interface {
+	// GetItems streams the items of every module in the resolved closure of
+	// the modules like POST /insfile/traverse.
+	GetItems(ctx context.Context, in *InsfileRequest, opts ...grpc.CallOption) (InsService_GetItemsClient, error)
+	// GetItemsAndModules streams the items of the requested modules, the
+	// modules they depend on, the excluded modules and the item conflicts
+	// settled by the policy like POST /insfile.
+	GetItemsAndModules(ctx context.Context, in *InsfileRequest, opts ...grpc.CallOption) (InsService_GetItemsAndModulesClient, error)
+}
+
+type insServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewInsServiceClient(cc grpc.ClientConnInterface) InsServiceClient {
+	return &insServiceClient{cc}
+}
+
+func (c *insServiceClient) GetItems(ctx context.Context, in *InsfileRequest, opts ...grpc.CallOption) (InsService_GetItemsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &InsService_ServiceDesc.Streams[0], "/conmansys.insservice.InsService/GetItems", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &insServiceGetItemsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type InsService_GetItemsClient interface {
+	Recv() (*Item, error)
+	grpc.ClientStream
+}
+
+type insServiceGetItemsClient struct {
+	grpc.ClientStream
+}
+
+func (x *insServiceGetItemsClient) Recv() (*Item, error) {
+	m := new(Item)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *insServiceClient) GetItemsAndModules(ctx context.Context, in *InsfileRequest, opts ...grpc.CallOption) (InsService_GetItemsAndModulesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &InsService_ServiceDesc.Streams[1], "/conmansys.insservice.InsService/GetItemsAndModules", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &insServiceGetItemsAndModulesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type InsService_GetItemsAndModulesClient interface {
+	Recv() (*InsfileEntry, error)
+	grpc.ClientStream
+}
+
+type insServiceGetItemsAndModulesClient struct {
+	grpc.ClientStream
+}
+
+func (x *insServiceGetItemsAndModulesClient) Recv() (*InsfileEntry, error) {
+	m := new(InsfileEntry)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// InsServiceServer is the server API for InsService service.
+// All implementations must embed UnimplementedInsServiceServer
+// for forward compatibility
+type InsServiceServer interface {
+	// GetItems streams the items of every module in the resolved closure of
+	// the modules like POST /insfile/traverse.
+	GetItems(*InsfileRequest, InsService_GetItemsServer) error
+	// GetItemsAndModules streams the items of the requested modules, the
+	// modules they depend on, the excluded modules and the item conflicts
+	// settled by the policy like POST /insfile.
+	GetItemsAndModules(*InsfileRequest, InsService_GetItemsAndModulesServer) error
+	mustEmbedUnimplementedInsServiceServer()
+}
+
+// UnimplementedInsServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedInsServiceServer struct {
+}
+
+func (UnimplementedInsServiceServer) GetItems(*InsfileRequest, InsService_GetItemsServer) error {
+	return status.Errorf(codes.Unimplemented, "method GetItems not implemented")
+}
+func (UnimplementedInsServiceServer) GetItemsAndModules(*InsfileRequest, InsService_GetItemsAndModulesServer) error {
+	return status.Errorf(codes.Unimplemented, "method GetItemsAndModules not implemented")
+}
+func (UnimplementedInsServiceServer) mustEmbedUnimplementedInsServiceServer() {}
+
+// UnsafeInsServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to InsServiceServer will
+// result in compilation errors.
+type UnsafeInsServiceServer interface {
+	mustEmbedUnimplementedInsServiceServer()
+}
+
+func RegisterInsServiceServer(s grpc.ServiceRegistrar, srv InsServiceServer) {
+	s.RegisterService(&InsService_ServiceDesc, srv)
+}
+
+func _InsService_GetItems_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(InsfileRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(InsServiceServer).GetItems(m, &insServiceGetItemsServer{stream})
+}
+
+type InsService_GetItemsServer interface {
+	Send(*Item) error
+	grpc.ServerStream
+}
+
+type insServiceGetItemsServer struct {
+	grpc.ServerStream
+}
+
+func (x *insServiceGetItemsServer) Send(m *Item) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _InsService_GetItemsAndModules_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(InsfileRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(InsServiceServer).GetItemsAndModules(m, &insServiceGetItemsAndModulesServer{stream})
+}
+
+type InsService_GetItemsAndModulesServer interface {
+	Send(*InsfileEntry) error
+	grpc.ServerStream
+}
+
+type insServiceGetItemsAndModulesServer struct {
+	grpc.ServerStream
+}
+
+func (x *insServiceGetItemsAndModulesServer) Send(m *InsfileEntry) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// InsService_ServiceDesc is the grpc.ServiceDesc for InsService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var InsService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "conmansys.insservice.InsService",
+	HandlerType: (*InsServiceServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "GetItems",
+			Handler:       _InsService_GetItems_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "GetItemsAndModules",
+			Handler:       _InsService_GetItemsAndModules_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "insservice.proto",
+}
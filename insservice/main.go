@@ -2,42 +2,104 @@ package main
 
 import (
 	"context"
-	"log"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"time"
 
+	"github.com/Glorforidor/conmansys/config"
+	"github.com/Glorforidor/conmansys/database"
 	"github.com/Glorforidor/conmansys/insservice/handler"
+	"github.com/Glorforidor/conmansys/insservice/rpc"
+	"github.com/Glorforidor/conmansys/insservice/secret"
 	"github.com/Glorforidor/conmansys/insservice/storage/postgres"
+	"github.com/Glorforidor/conmansys/logging"
+	"github.com/Glorforidor/conmansys/tracing"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 func main() {
-	conf := dbConfig()
-	p, err := postgres.New(
-		conf[dbhost], conf[dbport],
-		conf[dbuser], conf[dbpass],
-		conf[dbname],
-	)
+	cfg := defaults()
+	printConfig, err := config.Load(flag.CommandLine, os.Args[1:], &cfg)
 	if err != nil {
-		panic(err)
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if printConfig {
+		if err := config.Print(os.Stdout, &cfg); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if err := logging.Setup(cfg.LogLevel); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	shutdownTracing, err := tracing.Setup(context.Background(), "insservice", cfg.TracesExporter)
+	if err != nil {
+		slog.Error("could not set up tracing", "error", err)
+		os.Exit(1)
+	}
+
+	dsn, dbOpts, err := cfg.DB.Options()
+	if err != nil {
+		slog.Error("invalid database configuration", "error", err)
+		os.Exit(2)
+	}
+	p, err := postgres.Open(dsn, dbOpts...)
+	if err != nil {
+		slog.Error("could not connect to database", "error", err)
+		os.Exit(1)
 	}
 	defer p.Close()
 
-	handler := handler.New(p)
+	if cfg.SecretKeyring != "" {
+		k, err := secret.LoadKeyring(cfg.SecretKeyring)
+		if err != nil {
+			slog.Error("could not load keyring", "error", err)
+			os.Exit(1)
+		}
+		p.UseKeyring(k)
+	}
+
+	prometheus.MustRegister(newDBStats(p.Stats))
+
+	handler := handler.New(p, handler.WithReadinessCheck("database", p.Ping))
 
 	srv := http.Server{
-		Addr:         "",
+		Addr:         cfg.Addr,
 		Handler:      handler,
-		ReadTimeout:  15 * time.Second,
-		WriteTimeout: 15 * time.Second,
-		IdleTimeout:  60 * time.Second,
+		ReadTimeout:  cfg.ReadTimeout,
+		WriteTimeout: cfg.WriteTimeout,
+		IdleTimeout:  cfg.IdleTimeout,
 	}
 
 	// run server in own go routine.
 	go func() {
-		if err := srv.ListenAndServe(); err != nil {
-			log.Println(err)
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			slog.Error("http server failed", "error", err)
+		}
+	}()
+
+	lis, err := net.Listen("tcp", ":"+cfg.GRPCPort)
+	if err != nil {
+		slog.Error("could not listen for grpc", "error", err)
+		os.Exit(1)
+	}
+
+	g := rpc.New(p)
+
+	// run the gRPC server in own go routine.
+	go func() {
+		if err := g.Serve(lis); err != nil {
+			slog.Error("grpc server failed", "error", err)
 		}
 	}()
 
@@ -51,59 +113,53 @@ func main() {
 	<-c
 
 	// wait some time before shutting down to finish work.
-	wait := 15 * time.Second
-	ctx, cancel := context.WithTimeout(context.Background(), wait)
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
 	defer cancel()
 
 	// if there are no connections, then it shutsdown immediately otherwise
 	// block until wait time is over.
 	srv.Shutdown(ctx)
+	g.GracefulStop()
 
-	log.Println("shutting down")
-	os.Exit(0)
-}
-
-const (
-	dbhost = "DBHOST"
-	dbport = "DBPORT"
-	dbuser = "DBUSER"
-	dbpass = "DBPASS"
-	dbname = "DBNAME"
-)
-
-func dbConfig() map[string]string {
-	conf := make(map[string]string)
-
-	host, ok := os.LookupEnv(dbhost)
-	if !ok {
-		panic("DBHOST environment variable required but not set")
+	if err := shutdownTracing(ctx); err != nil {
+		slog.Error("could not export spans", "error", err)
 	}
 
-	port, ok := os.LookupEnv(dbport)
-	if !ok {
-		panic("DBPORT environment variable required but not set")
-	}
-
-	user, ok := os.LookupEnv(dbuser)
-	if !ok {
-		panic("DBUSER environment variable required but not set")
-	}
+	slog.Info("shutting down")
+	os.Exit(0)
+}
 
-	password, ok := os.LookupEnv(dbpass)
-	if !ok {
-		panic("DBPASS environment variable required but not set")
-	}
+// configuration of the service. Every setting is read from the YAML file of
+// -config, the environment variable and the flag of its field, as described
+// in the config package.
+type configuration struct {
+	Addr            string        `yaml:"addr" env:"ADDR" flag:"addr" usage:"address of the HTTP server"`
+	GRPCPort        string        `yaml:"grpc_port" env:"GRPC_PORT" flag:"grpc-port" usage:"port of the gRPC server, which runs next to the HTTP server"`
+	ReadTimeout     time.Duration `yaml:"read_timeout" env:"READ_TIMEOUT" flag:"read-timeout" usage:"maximum duration of reading a request"`
+	WriteTimeout    time.Duration `yaml:"write_timeout" env:"WRITE_TIMEOUT" flag:"write-timeout" usage:"maximum duration of writing a response"`
+	IdleTimeout     time.Duration `yaml:"idle_timeout" env:"IDLE_TIMEOUT" flag:"idle-timeout" usage:"how long an idle connection is kept open"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT" flag:"shutdown-timeout" usage:"how long running requests may take to finish at shutdown"`
+	LogLevel        string        `yaml:"log_level" env:"LOG_LEVEL" flag:"log-level" usage:"level of the logs: debug, info, warn or error"`
+	TracesExporter  string        `yaml:"traces_exporter" env:"OTEL_TRACES_EXPORTER" flag:"traces-exporter" usage:"exporter of the spans: otlp, stdout or none"`
+
+	// DB is the database, whose keys like db_host are inlined
+	DB database.Config `yaml:",inline"`
+
+	SecretKeyring string `yaml:"secret_keyring" env:"SECRET_KEYRING" flag:"secret-keyring" usage:"keyring used to decrypt secret items"`
+}
 
-	name, ok := os.LookupEnv(dbname)
-	if !ok {
-		panic("DBNAME environment variable required but not set")
+// defaults returns the configuration used for what is not set.
+func defaults() configuration {
+	return configuration{
+		Addr:            ":80",
+		GRPCPort:        "9091",
+		ReadTimeout:     15 * time.Second,
+		WriteTimeout:    15 * time.Second,
+		IdleTimeout:     60 * time.Second,
+		ShutdownTimeout: 15 * time.Second,
+		LogLevel:        "info",
+		TracesExporter:  tracing.None,
+
+		DB: database.Defaults(),
 	}
-
-	conf[dbhost] = host
-	conf[dbport] = port
-	conf[dbuser] = user
-	conf[dbpass] = password
-	conf[dbname] = name
-
-	return conf
 }
@@ -0,0 +1,188 @@
+// Package merge merges the items of the modules of an insfile. Modules may
+// contribute the same item at different versions, and a policy picks the one
+// which ends up in the insfile.
+package merge
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Glorforidor/conmansys/insservice/storage"
+	"github.com/Glorforidor/conmansys/resolve"
+)
+
+// The policies deciding which item wins when modules contribute the same item
+// at different versions.
+const (
+	// PolicyError refuses to pick an item.
+	PolicyError = "error"
+	// PolicyHighestVersion picks the item with the highest version.
+	PolicyHighestVersion = "highest-version"
+	// PolicyNearestModule picks the item of the module closest to the
+	// requested modules and the one resolved first if they are as close.
+	PolicyNearestModule = "nearest-module"
+)
+
+// Policies are the known item conflict policies.
+var Policies = []string{PolicyError, PolicyHighestVersion, PolicyNearestModule}
+
+// ValidPolicy reports whether p is a known item conflict policy.
+func ValidPolicy(p string) bool {
+	for _, k := range Policies {
+		if p == k {
+			return true
+		}
+	}
+	return false
+}
+
+// Candidate is one of the versions of an item in conflict.
+type Candidate struct {
+	Module  int64  `json:"module"`
+	Depth   int    `json:"depth"`
+	Version string `json:"version"`
+}
+
+// ItemConflict is an item which modules contribute with the same value and
+// type but at different versions.
+type ItemConflict struct {
+	Value      string       `json:"value"`
+	Type       string       `json:"type"`
+	Candidates []*Candidate `json:"candidates"`
+	// Winner is the version picked by the policy and empty if none was.
+	Winner string `json:"winner,omitempty"`
+	Policy string `json:"policy"`
+	Reason string `json:"reason"`
+}
+
+// ErrItemConflict is returned by Items when items conflict and the policy is
+// PolicyError.
+var ErrItemConflict = errors.New("items of the insfile conflict")
+
+// Items merges the items of the given modules in the order of the modules. An
+// item with the same value, type and version as an earlier item is left out.
+// Items with the same value and type but different versions are reported as
+// conflicts and the policy picks the one to keep. With PolicyError no item is
+// picked and ErrItemConflict is returned together with the conflicts. The
+// values of secret items are masked in the conflicts.
+func Items(modules []*resolve.Node, items map[int64][]*storage.Item, policy string) ([]*storage.Item, []*ItemConflict, error) {
+	type key struct{ value, typ string }
+
+	var order []key
+	found := make(map[key][]*storage.Item)
+	candidates := make(map[key][]*Candidate)
+	for _, n := range modules {
+		for _, it := range items[n.ID] {
+			k := key{it.Value, it.Type}
+			if _, ok := found[k]; !ok {
+				order = append(order, k)
+			}
+
+			same := false
+			for _, f := range found[k] {
+				if f.Version == it.Version {
+					same = true
+					break
+				}
+			}
+			if same {
+				continue
+			}
+
+			found[k] = append(found[k], it)
+			candidates[k] = append(candidates[k], &Candidate{Module: n.ID, Depth: n.Depth, Version: it.Version})
+		}
+	}
+
+	merged := []*storage.Item{}
+	conflicts := []*ItemConflict{}
+	for _, k := range order {
+		if len(found[k]) == 1 {
+			merged = append(merged, found[k][0])
+			continue
+		}
+
+		// conflicts are reported and not rendered, so secret values are masked
+		value := k.value
+		for _, it := range found[k] {
+			if it.Secret {
+				value = storage.Mask
+			}
+		}
+
+		c := &ItemConflict{Value: value, Type: k.typ, Candidates: candidates[k], Policy: policy}
+		versions := make([]string, len(c.Candidates))
+		for i, cand := range c.Candidates {
+			versions[i] = fmt.Sprintf("%v (module %v)", cand.Version, cand.Module)
+		}
+		c.Reason = fmt.Sprintf("item %q of type %q has versions %v", value, k.typ, strings.Join(versions, ", "))
+		conflicts = append(conflicts, c)
+
+		if policy == PolicyError {
+			continue
+		}
+
+		w := 0
+		for i, cand := range c.Candidates[1:] {
+			best := c.Candidates[w]
+			switch policy {
+			case PolicyHighestVersion:
+				if CompareVersions(cand.Version, best.Version) > 0 {
+					w = i + 1
+				}
+			case PolicyNearestModule:
+				if cand.Depth < best.Depth {
+					w = i + 1
+				}
+			}
+		}
+		c.Winner = c.Candidates[w].Version
+		merged = append(merged, found[k][w])
+	}
+
+	if policy == PolicyError && len(conflicts) > 0 {
+		return nil, conflicts, ErrItemConflict
+	}
+
+	return merged, conflicts, nil
+}
+
+// CompareVersions compares two dot separated versions part by part, numerically
+// if both parts are numbers and as text otherwise. A leading v is ignored. It
+// returns -1 if a is lower than b, 1 if it is higher and 0 if they are equal.
+func CompareVersions(a, b string) int {
+	as := strings.Split(strings.TrimPrefix(a, "v"), ".")
+	bs := strings.Split(strings.TrimPrefix(b, "v"), ".")
+
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		// a missing part counts as 0, so 1.0 equals 1.0.0
+		x, y := "0", "0"
+		if i < len(as) {
+			x = as[i]
+		}
+		if i < len(bs) {
+			y = bs[i]
+		}
+
+		xi, xerr := strconv.ParseInt(x, 10, 64)
+		yi, yerr := strconv.ParseInt(y, 10, 64)
+		switch {
+		case xerr == nil && yerr == nil:
+			if xi != yi {
+				if xi < yi {
+					return -1
+				}
+				return 1
+			}
+		case x != y:
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+
+	return 0
+}
@@ -0,0 +1,89 @@
+package merge
+
+import (
+	"testing"
+
+	"github.com/Glorforidor/conmansys/insservice/storage"
+	"github.com/Glorforidor/conmansys/resolve"
+)
+
+func TestItems(t *testing.T) {
+	modules := []*resolve.Node{{ID: 1}, {ID: 2, Depth: 1}, {ID: 3, Depth: 1}}
+	items := map[int64][]*storage.Item{
+		1: {
+			{Value: "payment_window", Type: "config", Version: "1.0.0"},
+			{Value: "taxonomy", Type: "config", Version: "1.0.0"},
+		},
+		2: {
+			{Value: "payment_window", Type: "config", Version: "2.0.0"},
+			{Value: "taxonomy", Type: "config", Version: "1.0.0"}, // same item
+			{Value: "taxonomy", Type: "schema", Version: "3.0.0"}, // other type
+		},
+		3: {
+			{Value: "payment_window", Type: "config", Version: "1.10.0"},
+		},
+	}
+
+	tt := map[string]struct {
+		policy string
+		winner string
+		err    bool
+	}{
+		"nearest module":  {policy: PolicyNearestModule, winner: "1.0.0"},
+		"highest version": {policy: PolicyHighestVersion, winner: "2.0.0"},
+		"error":           {policy: PolicyError, err: true},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			merged, conflicts, err := Items(modules, items, tc.policy)
+			if len(conflicts) != 1 || len(conflicts[0].Candidates) != 3 {
+				t.Fatalf("expected one conflict with 3 candidates, got: %v", conflicts)
+			}
+
+			if tc.err {
+				if err != ErrItemConflict {
+					t.Fatalf("expected error: %v, got: %v", ErrItemConflict, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+
+			if conflicts[0].Winner != tc.winner {
+				t.Fatalf("expected winner: %v, got: %v", tc.winner, conflicts[0].Winner)
+			}
+
+			if len(merged) != 3 {
+				t.Fatalf("expected 3 items, got: %v", merged)
+			}
+			if merged[0].Value != "payment_window" || merged[0].Version != tc.winner {
+				t.Fatalf("expected payment_window at %v first, got: %v", tc.winner, merged[0])
+			}
+		})
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	tt := map[string]struct {
+		a, b string
+		want int
+	}{
+		"equal":          {a: "1.0.0", b: "1.0.0", want: 0},
+		"missing part":   {a: "1.0", b: "1.0.0", want: 0},
+		"numeric":        {a: "1.10.0", b: "1.9.0", want: 1},
+		"lower":          {a: "1.2.3", b: "2.0.0", want: -1},
+		"leading v":      {a: "v2.0", b: "1.5", want: 1},
+		"text parts":     {a: "1.0.beta", b: "1.0.alpha", want: 1},
+		"number vs text": {a: "1.0.1", b: "1.0.rc", want: -1},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			if got := CompareVersions(tc.a, tc.b); got != tc.want {
+				t.Fatalf("expected: %v, got: %v", tc.want, got)
+			}
+		})
+	}
+}
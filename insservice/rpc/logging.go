@@ -0,0 +1,97 @@
+package rpc
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/Glorforidor/conmansys/logging"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// requestIDKey is the metadata key of the request id, the gRPC form of the
+// X-Request-ID header.
+const requestIDKey = "x-request-id"
+
+// internalError hides the error which failed a call from the caller, who gets
+// errInternal. The interceptors log the error with the request id of the call.
+type internalError struct {
+	err error
+}
+
+func (e internalError) Error() string {
+	return errInternal.Error()
+}
+
+func (e internalError) GRPCStatus() *status.Status {
+	return status.Convert(errInternal)
+}
+
+// internal hides the error from the caller and has it logged.
+func internal(err error) error {
+	return internalError{err: err}
+}
+
+// withRequestID returns the context of a call with the request id of its
+// metadata, or a new one.
+func withRequestID(ctx context.Context) context.Context {
+	var id string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if ids := md.Get(requestIDKey); len(ids) > 0 {
+			id = ids[0]
+		}
+	}
+	return logging.NewContext(ctx, id)
+}
+
+// logCall logs a finished call and the error which failed it.
+func logCall(ctx context.Context, method string, start time.Time, err error) {
+	l := logging.FromContext(ctx)
+	level := slog.LevelInfo
+	if ie, ok := err.(internalError); ok {
+		l.Error("call failed", "method", method, "error", ie.err)
+		level = slog.LevelError
+	}
+
+	l.Log(ctx, level, "call",
+		"method", method,
+		"code", status.Code(err).String(),
+		"duration_ms", float64(time.Since(start).Microseconds())/1000,
+	)
+}
+
+// logUnary gives every unary call a request id, echoes it in the header and
+// logs the call.
+func logUnary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	start := time.Now()
+	ctx = withRequestID(ctx)
+	grpc.SetHeader(ctx, metadata.Pairs(requestIDKey, logging.RequestID(ctx)))
+
+	resp, err := handler(ctx, req)
+	logCall(ctx, info.FullMethod, start, err)
+	return resp, err
+}
+
+// serverStream is a stream with the context of withRequestID.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+// logStream gives every streaming call a request id, echoes it in the header
+// and logs the call.
+func logStream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+	ctx := withRequestID(ss.Context())
+	ss.SetHeader(metadata.Pairs(requestIDKey, logging.RequestID(ctx)))
+
+	err := handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+	logCall(ctx, info.FullMethod, start, err)
+	return err
+}
@@ -0,0 +1,251 @@
+// Package rpc serves the insfiles of the insservice over gRPC with the same
+// storage as the HTTP handlers. The messages and the service are defined in
+// the inspb package.
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Glorforidor/conmansys/insservice/inspb"
+	"github.com/Glorforidor/conmansys/insservice/merge"
+	"github.com/Glorforidor/conmansys/insservice/storage"
+	"github.com/Glorforidor/conmansys/labels"
+	"github.com/Glorforidor/conmansys/resolve"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+)
+
+var errInternal = status.Error(codes.Internal, "Ups something went wrong")
+
+type server struct {
+	inspb.UnimplementedInsServiceServer
+	storage storage.Service
+}
+
+// New registers the InsService backed by the storage.Service and the server
+// reflection to a gRPC server and returns it.
+func New(service storage.Service) *grpc.Server {
+	srv := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(otelgrpc.UnaryServerInterceptor(), logUnary),
+		grpc.ChainStreamInterceptor(otelgrpc.StreamServerInterceptor(), logStream),
+	)
+	inspb.RegisterInsServiceServer(srv, &server{storage: service})
+	reflection.Register(srv)
+	return srv
+}
+
+// conflict returns a FAILED_PRECONDITION error with the violations as a
+// PreconditionFailure detail.
+func conflict(msg string, violations []*errdetails.PreconditionFailure_Violation) error {
+	st := status.New(codes.FailedPrecondition, msg)
+	d, err := st.WithDetails(&errdetails.PreconditionFailure{Violations: violations})
+	if err != nil {
+		return st.Err()
+	}
+	return d.Err()
+}
+
+// roots returns the ids of the modules of the request, which names them either
+// by id or by a label selector.
+func (s *server) roots(ctx context.Context, req *inspb.InsfileRequest) ([]int64, error) {
+	if strings.TrimSpace(req.Selector) == "" {
+		for _, id := range req.Modules {
+			if id == 0 {
+				return nil, status.Errorf(codes.InvalidArgument, "one or more values are incorrect: %v", req.Modules)
+			}
+		}
+		return req.Modules, nil
+	}
+
+	if len(req.Modules) > 0 {
+		return nil, status.Error(codes.InvalidArgument, "either modules or a selector may be given")
+	}
+
+	sel, err := labels.Parse(req.Selector)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid selector: %v", err)
+	}
+
+	selected, err := s.storage.SelectModules(ctx, sel)
+	if err != nil {
+		return nil, internal(err)
+	}
+
+	var roots []int64
+	for _, m := range selected {
+		roots = append(roots, m.ID)
+	}
+
+	return roots, nil
+}
+
+// resolve resolves the modules of the insfile of the request. If the resolved
+// modules conflict the conflicts are returned as the error.
+func (s *server) resolve(ctx context.Context, req *inspb.InsfileRequest) (*resolve.Result, string, error) {
+	policy := req.Policy
+	if policy == "" {
+		policy = merge.PolicyNearestModule
+	}
+	if !merge.ValidPolicy(policy) {
+		return nil, "", status.Errorf(codes.InvalidArgument, "policy must be one of %q, got: %q", merge.Policies, policy)
+	}
+
+	roots, err := s.roots(ctx, req)
+	if err != nil {
+		return nil, "", err
+	}
+
+	deps, err := s.storage.GetModuleDependencies(ctx, roots...)
+	if err != nil {
+		return nil, "", internal(err)
+	}
+
+	res := resolve.Resolve(deps, roots, req.Optional)
+	if len(res.Conflicts) > 0 {
+		var violations []*errdetails.PreconditionFailure_Violation
+		for _, c := range res.Conflicts {
+			violations = append(violations, &errdetails.PreconditionFailure_Violation{
+				Type:        "module",
+				Subject:     fmt.Sprintf("%v", c.ID),
+				Description: c.Reason,
+			})
+		}
+		return nil, "", conflict("the modules of the insfile conflict", violations)
+	}
+
+	return res, policy, nil
+}
+
+// moduleItems merges the items of the modules with the policy. If the items
+// conflict and the policy is error the conflicts are returned as the error.
+func (s *server) moduleItems(ctx context.Context, modules []*resolve.Node, policy string) ([]*storage.Item, []*merge.ItemConflict, error) {
+	ids := make([]int64, len(modules))
+	for i, n := range modules {
+		ids[i] = n.ID
+	}
+
+	byModule, err := s.storage.GetModuleItems(ctx, ids...)
+	if err != nil {
+		return nil, nil, internal(err)
+	}
+
+	items, conflicts, err := merge.Items(modules, byModule, policy)
+	if err != nil {
+		var violations []*errdetails.PreconditionFailure_Violation
+		for _, c := range conflicts {
+			violations = append(violations, &errdetails.PreconditionFailure_Violation{
+				Type:        "item",
+				Subject:     c.Value,
+				Description: c.Reason,
+			})
+		}
+		return nil, nil, conflict(err.Error(), violations)
+	}
+
+	return items, conflicts, nil
+}
+
+// GetItems streams the items of every module in the resolved closure of the
+// requested modules.
+func (s *server) GetItems(req *inspb.InsfileRequest, stream inspb.InsService_GetItemsServer) error {
+	res, policy, err := s.resolve(stream.Context(), req)
+	if err != nil {
+		return err
+	}
+
+	items, _, err := s.moduleItems(stream.Context(), res.Modules, policy)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		if err := stream.Send(toItem(item)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetItemsAndModules streams the items of the requested modules followed by
+// the modules they depend on, the excluded modules and the item conflicts.
+func (s *server) GetItemsAndModules(req *inspb.InsfileRequest, stream inspb.InsService_GetItemsAndModulesServer) error {
+	res, policy, err := s.resolve(stream.Context(), req)
+	if err != nil {
+		return err
+	}
+
+	// the requested modules give the items and the modules they depend on are
+	// listed
+	var roots []*resolve.Node
+	var entries []*inspb.InsfileEntry
+	for _, n := range res.Modules {
+		if n.Depth == 0 {
+			roots = append(roots, n)
+		} else {
+			entries = append(entries, &inspb.InsfileEntry{
+				Entry: &inspb.InsfileEntry_Module{Module: &inspb.Module{Id: n.ID}},
+			})
+		}
+	}
+
+	items, conflicts, err := s.moduleItems(stream.Context(), roots, policy)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		e := &inspb.InsfileEntry{Entry: &inspb.InsfileEntry_Item{Item: toItem(item)}}
+		if err := stream.Send(e); err != nil {
+			return err
+		}
+	}
+
+	for _, e := range res.Excluded {
+		entries = append(entries, &inspb.InsfileEntry{Entry: &inspb.InsfileEntry_Excluded{Excluded: toExclusion(e)}})
+	}
+	for _, c := range conflicts {
+		entries = append(entries, &inspb.InsfileEntry{Entry: &inspb.InsfileEntry_Conflict{Conflict: toItemConflict(c)}})
+	}
+
+	for _, e := range entries {
+		if err := stream.Send(e); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func toItem(item *storage.Item) *inspb.Item {
+	return &inspb.Item{Id: item.ID, Value: item.Value, Type: item.Type, Version: item.Version}
+}
+
+func toExclusion(e *resolve.Exclusion) *inspb.Exclusion {
+	return &inspb.Exclusion{Id: e.ID, From: e.From, Kind: e.Kind, Reason: e.Reason}
+}
+
+func toItemConflict(c *merge.ItemConflict) *inspb.ItemConflict {
+	ic := &inspb.ItemConflict{
+		Value:  c.Value,
+		Type:   c.Type,
+		Winner: c.Winner,
+		Policy: c.Policy,
+		Reason: c.Reason,
+	}
+	for _, cand := range c.Candidates {
+		ic.Candidates = append(ic.Candidates, &inspb.Candidate{
+			Module:  cand.Module,
+			Depth:   int32(cand.Depth),
+			Version: cand.Version,
+		})
+	}
+
+	return ic
+}
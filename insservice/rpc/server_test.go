@@ -0,0 +1,268 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/Glorforidor/conmansys/insservice/inspb"
+	"github.com/Glorforidor/conmansys/insservice/storage"
+	"github.com/Glorforidor/conmansys/labels"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type serviceMock struct {
+	items   map[int64][]*storage.Item
+	modules []*storage.Module
+	deps    []*storage.ModuleDependency
+	closed  bool
+}
+
+func (s *serviceMock) GetModules(_ context.Context, ids ...int64) ([]*storage.Module, error) {
+	if s.closed {
+		return nil, errors.New("")
+	}
+
+	var modules []*storage.Module
+	for _, m := range s.modules {
+		for _, id := range ids {
+			if m.ID == id {
+				modules = append(modules, m)
+				break
+			}
+		}
+	}
+	return modules, nil
+}
+
+func (s *serviceMock) SelectModules(_ context.Context, sel labels.Selector) ([]*storage.Module, error) {
+	if s.closed {
+		return nil, errors.New("")
+	}
+
+	var modules []*storage.Module
+	for _, m := range s.modules {
+		if sel.Matches(m.Labels) {
+			modules = append(modules, m)
+		}
+	}
+	return modules, nil
+}
+
+// GetModuleDependencies returns every dependency, which the resolver does not
+// mind as it only follows the ones reached from the ids.
+func (s *serviceMock) GetModuleDependencies(_ context.Context, _ ...int64) ([]*storage.ModuleDependency, error) {
+	if s.closed {
+		return nil, errors.New("")
+	}
+
+	return s.deps, nil
+}
+
+func (s *serviceMock) GetModuleItems(_ context.Context, ids ...int64) (map[int64][]*storage.Item, error) {
+	m := make(map[int64][]*storage.Item)
+	for _, id := range ids {
+		if it, ok := s.items[id]; ok {
+			m[id] = it
+		}
+	}
+	return m, nil
+}
+
+func (s *serviceMock) GetRelease(_ context.Context, name string) (*storage.Release, error) {
+	return nil, nil
+}
+
+func (s *serviceMock) GetEnvironmentPromotion(_ context.Context, env string) (*storage.Promotion, error) {
+	return nil, nil
+}
+
+// newService returns module 1 which requires module 2 and recommends module
+// 3. Module 4 conflicts with module 2. Modules 1 and 2 hold the tax item at
+// different versions.
+func newService() *serviceMock {
+	return &serviceMock{
+		items: map[int64][]*storage.Item{
+			1: {{ID: 1, Value: "tax", Type: "domain", Version: "1.0.0"}},
+			2: {
+				{ID: 2, Value: "tax", Type: "domain", Version: "2.0.0"},
+				{ID: 3, Value: "payment", Type: "domain", Version: "1.0.0"},
+			},
+			4: {{ID: 4, Value: "invoice", Type: "domain", Version: "1.0.0"}},
+		},
+		modules: []*storage.Module{
+			{ID: 1, Labels: map[string]string{"team": "billing"}},
+			{ID: 2},
+			{ID: 3},
+			{ID: 4},
+		},
+		deps: []*storage.ModuleDependency{
+			{Dependent: 1, Dependee: 2, Kind: storage.Required},
+			{Dependent: 1, Dependee: 3, Kind: storage.Recommends},
+			{Dependent: 4, Dependee: 2, Kind: storage.Conflicts},
+		},
+	}
+}
+
+// dial serves the storage over an in-memory connection and returns a client of
+// it.
+func dial(t *testing.T, s storage.Service) inspb.InsServiceClient {
+	lis := bufconn.Listen(1 << 20)
+	srv := New(s)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.Dial(
+		"bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+			return lis.Dial()
+		}),
+		grpc.WithInsecure(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return inspb.NewInsServiceClient(conn)
+}
+
+// violations returns the subjects of the PreconditionFailure detail of err.
+func violations(err error) []string {
+	var subjects []string
+	for _, d := range status.Convert(err).Details() {
+		if pf, ok := d.(*errdetails.PreconditionFailure); ok {
+			for _, v := range pf.Violations {
+				subjects = append(subjects, v.Type+" "+v.Subject)
+			}
+		}
+	}
+	return subjects
+}
+
+func TestGetItems(t *testing.T) {
+	tt := map[string]struct {
+		req        *inspb.InsfileRequest
+		values     []string
+		code       codes.Code
+		violations []string
+		closed     bool
+	}{
+		"closure":  {req: &inspb.InsfileRequest{Modules: []int64{1}}, values: []string{"tax 1.0.0", "payment 1.0.0"}},
+		"selector": {req: &inspb.InsfileRequest{Selector: "team=billing"}, values: []string{"tax 1.0.0", "payment 1.0.0"}},
+		"highest version": {
+			req:    &inspb.InsfileRequest{Modules: []int64{1}, Policy: "highest-version"},
+			values: []string{"tax 2.0.0", "payment 1.0.0"},
+		},
+		"item conflict": {
+			req:  &inspb.InsfileRequest{Modules: []int64{1}, Policy: "error"},
+			code: codes.FailedPrecondition, violations: []string{"item tax"},
+		},
+		"module conflict": {
+			req:  &inspb.InsfileRequest{Modules: []int64{1, 4}},
+			code: codes.FailedPrecondition, violations: []string{"module 4"},
+		},
+		"no modules":       {req: &inspb.InsfileRequest{}},
+		"invalid module":   {req: &inspb.InsfileRequest{Modules: []int64{0}}, code: codes.InvalidArgument},
+		"invalid policy":   {req: &inspb.InsfileRequest{Modules: []int64{1}, Policy: "newest"}, code: codes.InvalidArgument},
+		"invalid selector": {req: &inspb.InsfileRequest{Selector: "team in billing"}, code: codes.InvalidArgument},
+		"modules and selector": {
+			req:  &inspb.InsfileRequest{Modules: []int64{1}, Selector: "team=billing"},
+			code: codes.InvalidArgument,
+		},
+		"closed storage": {req: &inspb.InsfileRequest{Modules: []int64{1}}, code: codes.Internal, closed: true},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			s := newService()
+			s.closed = tc.closed
+			client := dial(t, s)
+
+			stream, err := client.GetItems(context.Background(), tc.req)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var values []string
+			for {
+				item, err := stream.Recv()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					if status.Code(err) != tc.code {
+						t.Fatalf("expected code: %v, got: %v", tc.code, err)
+					}
+					if v := violations(err); len(v) != len(tc.violations) || (len(v) > 0 && v[0] != tc.violations[0]) {
+						t.Fatalf("expected violations: %v, got: %v", tc.violations, v)
+					}
+					return
+				}
+				values = append(values, item.Value+" "+item.Version)
+			}
+
+			if tc.code != codes.OK {
+				t.Fatalf("expected code: %v, got: OK", tc.code)
+			}
+			if len(values) != len(tc.values) {
+				t.Fatalf("expected: %v, got: %v", tc.values, values)
+			}
+			for i := range values {
+				if values[i] != tc.values[i] {
+					t.Fatalf("expected: %v, got: %v", tc.values, values)
+				}
+			}
+		})
+	}
+}
+
+func TestGetItemsAndModules(t *testing.T) {
+	client := dial(t, newService())
+
+	stream, err := client.GetItemsAndModules(context.Background(), &inspb.InsfileRequest{Modules: []int64{1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var entries []string
+	for {
+		e, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		switch v := e.Entry.(type) {
+		case *inspb.InsfileEntry_Item:
+			entries = append(entries, "item "+v.Item.Value)
+		case *inspb.InsfileEntry_Module:
+			entries = append(entries, fmt.Sprintf("module %v", v.Module.Id))
+		case *inspb.InsfileEntry_Excluded:
+			entries = append(entries, fmt.Sprintf("excluded %v", v.Excluded.Id))
+		case *inspb.InsfileEntry_Conflict:
+			entries = append(entries, "conflict "+v.Conflict.Value)
+		}
+	}
+
+	// only module 1 gives items, module 2 is listed and module 3 is only
+	// recommended
+	want := []string{"item tax", "module 2", "excluded 3"}
+	if len(entries) != len(want) {
+		t.Fatalf("expected: %v, got: %v", want, entries)
+	}
+	for i := range entries {
+		if entries[i] != want[i] {
+			t.Fatalf("expected: %v, got: %v", want, entries)
+		}
+	}
+}
@@ -0,0 +1,197 @@
+// Package secret opens secret item values sealed by the confservice.
+//
+// Every value is encrypted with its own random data key and the data key is
+// encrypted with a key encryption key from a Keyring. The insservice only needs
+// to decrypt and therefore holds no code to seal values.
+package secret
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// prefix marks a value as sealed by the confservice. The version makes it
+// possible to change the format without breaking already sealed values.
+const prefix = "enc:v1:"
+
+// keySize is the size in bytes of both the key encryption keys and the data
+// keys. 32 bytes selects AES-256.
+const keySize = 32
+
+var (
+	errNotSealed  = errors.New("value is not sealed")
+	errUnknownKey = errors.New("value is sealed with an unknown key")
+)
+
+type key struct {
+	id   string
+	aead cipher.AEAD
+}
+
+// Keyring holds the key encryption keys. Every key in the keyring can open
+// values. It reads the same keyring file as the confservice.
+type Keyring struct {
+	keys []*key
+}
+
+// NewKeyring creates a keyring from the given keys in order of precedence. The
+// keys must be 32 bytes long.
+func NewKeyring(ids []string, keys [][]byte) (*Keyring, error) {
+	if len(ids) != len(keys) {
+		return nil, fmt.Errorf("got %v key ids but %v keys", len(ids), len(keys))
+	}
+
+	if len(keys) == 0 {
+		return nil, errors.New("keyring must contain at least one key")
+	}
+
+	k := &Keyring{}
+	for i, id := range ids {
+		if id == "" || strings.Contains(id, ":") {
+			return nil, fmt.Errorf("key id %q must be non empty and without ':'", id)
+		}
+
+		if len(keys[i]) != keySize {
+			return nil, fmt.Errorf("key %q must be %v bytes, got: %v", id, keySize, len(keys[i]))
+		}
+
+		aead, err := newAEAD(keys[i])
+		if err != nil {
+			return nil, fmt.Errorf("could not use key %q: %v", id, err)
+		}
+
+		k.keys = append(k.keys, &key{id: id, aead: aead})
+	}
+
+	return k, nil
+}
+
+// LoadKeyring reads a keyring from the file at path. Every non empty line,
+// which does not start with '#', holds a key as "id:base64 encoded key". The
+// first key in the file is the primary key.
+func LoadKeyring(path string) (*Keyring, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not open keyring: %v", err)
+	}
+	defer f.Close()
+
+	var ids []string
+	var keys [][]byte
+
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("keyring line %q is not of the form id:key", line)
+		}
+
+		b, err := base64.StdEncoding.DecodeString(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("could not decode key %q: %v", parts[0], err)
+		}
+
+		ids = append(ids, parts[0])
+		keys = append(keys, b)
+	}
+
+	if err := s.Err(); err != nil {
+		return nil, fmt.Errorf("could not read keyring: %v", err)
+	}
+
+	return NewKeyring(ids, keys)
+}
+
+// IsSealed reports whether the value has been sealed by a Keyring.
+func IsSealed(value string) bool {
+	return strings.HasPrefix(value, prefix)
+}
+
+// Open decrypts a sealed value with any key in the keyring and returns the
+// plaintext.
+func (k *Keyring) Open(sealed string) (string, error) {
+	kk, wrapped, ct, err := k.parse(sealed)
+	if err != nil {
+		return "", err
+	}
+
+	dek, err := decrypt(kk.aead, wrapped)
+	if err != nil {
+		return "", fmt.Errorf("could not decrypt data key: %v", err)
+	}
+
+	aead, err := newAEAD(dek)
+	if err != nil {
+		return "", err
+	}
+
+	pt, err := decrypt(aead, ct)
+	if err != nil {
+		return "", fmt.Errorf("could not decrypt value: %v", err)
+	}
+
+	return string(pt), nil
+}
+
+func (k *Keyring) parse(sealed string) (*key, []byte, []byte, error) {
+	if !IsSealed(sealed) {
+		return nil, nil, nil, errNotSealed
+	}
+
+	parts := strings.Split(strings.TrimPrefix(sealed, prefix), ":")
+	if len(parts) != 3 {
+		return nil, nil, nil, fmt.Errorf("sealed value is malformed")
+	}
+
+	var kk *key
+	for _, key := range k.keys {
+		if key.id == parts[0] {
+			kk = key
+			break
+		}
+	}
+	if kk == nil {
+		return nil, nil, nil, errUnknownKey
+	}
+
+	wrapped, err := base64.StdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("could not decode data key: %v", err)
+	}
+
+	ct, err := base64.StdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("could not decode value: %v", err)
+	}
+
+	return kk, wrapped, ct, nil
+}
+
+func newAEAD(k []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(k)
+	if err != nil {
+		return nil, fmt.Errorf("could not create cipher: %v", err)
+	}
+
+	return cipher.NewGCM(block)
+}
+
+func decrypt(aead cipher.AEAD, data []byte) ([]byte, error) {
+	n := aead.NonceSize()
+	if len(data) < n {
+		return nil, errors.New("ciphertext too short")
+	}
+
+	return aead.Open(nil, data[:n], data[n:], nil)
+}
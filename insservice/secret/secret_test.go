@@ -0,0 +1,73 @@
+package secret
+
+import (
+	"encoding/base64"
+	"testing"
+)
+
+// sealed is "hunter2" sealed by the confservice with the key 0x00..0x1f under
+// the id "test".
+const sealed = "enc:v1:test:stGAIEdl2qc6NXgi6OQlrxeGVp9aQPZOF2eInBUUEF72cK+6PDGzUcPX4/HOPbwJ9wcQEEfaWZOPhc/T:xSQrFewamh+eA/HTpby52VdKhPySSGl/S/wRIU+C0zTtwUY="
+
+func testKey() []byte {
+	k := make([]byte, keySize)
+	for i := range k {
+		k[i] = byte(i)
+	}
+	return k
+}
+
+func TestOpen(t *testing.T) {
+	other, _ := base64.StdEncoding.DecodeString("AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=")
+
+	tt := map[string]struct {
+		ids   []string
+		keys  [][]byte
+		value string
+		want  string
+		err   bool
+	}{
+		"known key": {
+			ids: []string{"test"}, keys: [][]byte{testKey()}, value: sealed, want: "hunter2",
+		},
+		"secondary key": {
+			ids: []string{"new", "test"}, keys: [][]byte{other, testKey()}, value: sealed, want: "hunter2",
+		},
+		"unknown key": {
+			ids: []string{"new"}, keys: [][]byte{testKey()}, value: sealed, err: true,
+		},
+		"wrong key": {
+			ids: []string{"test"}, keys: [][]byte{other}, value: sealed, err: true,
+		},
+		"not sealed": {
+			ids: []string{"test"}, keys: [][]byte{testKey()}, value: "hunter2", err: true,
+		},
+		"malformed": {
+			ids: []string{"test"}, keys: [][]byte{testKey()}, value: "enc:v1:test:abc", err: true,
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			k, err := NewKeyring(tc.ids, tc.keys)
+			if err != nil {
+				t.Fatalf("could not create keyring: %v", err)
+			}
+
+			got, err := k.Open(tc.value)
+			if err != nil {
+				if !tc.err {
+					t.Fatalf("expected no error, got: %v", err)
+				}
+				return
+			}
+			if tc.err {
+				t.Fatalf("expected an error, got: %q", got)
+			}
+
+			if got != tc.want {
+				t.Fatalf("expected: %q, got: %q", tc.want, got)
+			}
+		})
+	}
+}
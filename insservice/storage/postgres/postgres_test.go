@@ -1,14 +1,20 @@
 package postgres
 
 import (
+	"context"
+	"fmt"
 	"os"
 	"testing"
 
 	"github.com/Glorforidor/conmansys/insservice/storage"
+	"github.com/Glorforidor/conmansys/labels"
+	"github.com/Glorforidor/conmansys/resolve"
 )
 
 const (
 	table = `
+DROP TABLE IF EXISTS conf_promotion;
+DROP TABLE IF EXISTS conf_release;
 DROP TABLE IF EXISTS conf_item_module;
 DROP TABLE IF EXISTS conf_item;
 DROP TABLE IF EXISTS conf_module;
@@ -18,18 +24,22 @@ CREATE TABLE conf_item(
 	conf_item_id SERIAL PRIMARY KEY,
 	conf_item_value TEXT NOT NULL,
 	conf_item_type TEXT NOT NULL,
-	conf_item_version TEXT NOT NULL
+	conf_item_version TEXT NOT NULL,
+	conf_item_secret BOOLEAN NOT NULL DEFAULT false,
+	conf_item_labels JSONB NOT NULL DEFAULT '{}'
 );
 
 CREATE TABLE conf_module(
 	conf_module_id SERIAL PRIMARY KEY,
 	conf_module_value TEXT NOT NULL,
-	conf_module_version TEXT NOT NULL
+	conf_module_version TEXT NOT NULL,
+	conf_module_labels JSONB NOT NULL DEFAULT '{}'
 );
 
 CREATE TABLE conf_module_dependency(
 	dependent int,
 	dependee int,
+	kind TEXT NOT NULL DEFAULT 'required',
 	PRIMARY KEY (dependent, dependee)
 );
 
@@ -39,6 +49,25 @@ CREATE TABLE conf_item_module(
 	conf_module_id INTEGER,
 	FOREIGN KEY (conf_item_id) REFERENCES conf_item(conf_item_id) ON DELETE CASCADE,
 	FOREIGN KEY (conf_module_id) REFERENCES conf_module(conf_module_id) ON DELETE CASCADE
+);
+
+CREATE TABLE conf_release(
+	conf_release_name TEXT PRIMARY KEY,
+	conf_release_modules JSONB NOT NULL,
+	conf_release_optional BOOLEAN NOT NULL DEFAULT false,
+	conf_release_closure JSONB NOT NULL,
+	conf_release_created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE conf_promotion(
+	conf_promotion_id SERIAL PRIMARY KEY,
+	conf_promotion_release TEXT NOT NULL REFERENCES conf_release (conf_release_name),
+	conf_promotion_environment TEXT NOT NULL,
+	conf_promotion_status TEXT NOT NULL,
+	conf_promotion_requested_by TEXT NOT NULL,
+	conf_promotion_requested_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	conf_promotion_approved_by TEXT NOT NULL DEFAULT '',
+	conf_promotion_promoted_at TIMESTAMPTZ
 );`
 
 	insert = `
@@ -52,13 +81,13 @@ INSERT INTO conf_item (conf_item_value, conf_item_type, conf_item_version) VALUE
 ('management_tax_window', 'window', '2.0.0'),
 ('mangement', 'domain', '0.0.2');
 
-INSERT INTO conf_module (conf_module_value, conf_module_version) VALUES
-('A', '0.0.10'),
-('B', '0.0.11'),
-('C', '0.0.12'),
-('D', '0.0.13'),
-('E', '0.0.14'),
-('F', '0.0.15');
+INSERT INTO conf_module (conf_module_value, conf_module_version, conf_module_labels) VALUES
+('A', '0.0.10', '{"release": "2026.10"}'),
+('B', '0.0.11', '{"release": "2026.10"}'),
+('C', '0.0.12', '{}'),
+('D', '0.0.13', '{"release": "2026.11"}'),
+('E', '0.0.14', '{}'),
+('F', '0.0.15', '{}');
 
 INSERT INTO conf_item_module (conf_item_id, conf_module_id) VALUES
 (1, 1),
@@ -77,6 +106,17 @@ INSERT INTO conf_module_dependency (dependent, dependee) VALUES
 (5, 4),
 (6, 1),
 (6, 5);
+
+INSERT INTO conf_release (conf_release_name, conf_release_modules, conf_release_closure) VALUES
+('2026.10-prod', '[1]', '[{"id": 1, "value": "A", "version": "0.0.10", "depth": 0,
+	"items": [{"id": 1, "value": "tax_income_window", "type": "window", "version": "1.0.0", "secret": false}]}]'),
+('2026.09-prod', '[2]', '[]');
+
+INSERT INTO conf_promotion (conf_promotion_release, conf_promotion_environment, conf_promotion_status,
+	conf_promotion_requested_by, conf_promotion_promoted_at) VALUES
+('2026.09-prod', 'prod', 'promoted', 'alice', now() - interval '1 day'),
+('2026.10-prod', 'prod', 'promoted', 'alice', now()),
+('2026.09-prod', 'staging', 'pending', 'alice', NULL);
 `
 )
 
@@ -159,7 +199,7 @@ func TestNew(t *testing.T) {
 	}
 }
 
-func TestGetItems(t *testing.T) {
+func TestGetModuleItems(t *testing.T) {
 	p := setup(t)
 
 	tt := map[string]struct {
@@ -252,11 +292,27 @@ func TestGetItems(t *testing.T) {
 
 	for name, tc := range tt {
 		t.Run(name, func(t *testing.T) {
-			items, err := p.GetItems(tc.modules...)
+			var roots []int64
+			for _, m := range tc.modules {
+				roots = append(roots, m.ID)
+			}
+
+			deps, err := p.GetModuleDependencies(context.Background(), roots...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			byModule, err := p.GetModuleItems(context.Background(), resolve.Resolve(deps, roots, false).IDs()...)
 			if err != nil {
 				t.Fatal(err)
 			}
 
+			// the modules share no items, so there are no duplicates
+			var items []*storage.Item
+			for _, its := range byModule {
+				items = append(items, its...)
+			}
+
 			if len(items) != len(tc.want) {
 				t.Errorf("expected map length of: %v, got: %v", len(tc.want), len(items))
 			}
@@ -279,19 +335,189 @@ func testValueInItems(value string, items []*storage.Item) bool {
 	return false
 }
 
-func TestGetItemsAndModules(t *testing.T) {
+func TestGetModuleDependencies(t *testing.T) {
+	p := setup(t)
+
+	tt := map[string]struct {
+		ids  []int64
+		want int
+	}{
+		"leaf":            {ids: []int64{1}, want: 0},
+		"direct":          {ids: []int64{4}, want: 3},
+		"transitive":      {ids: []int64{5}, want: 4},
+		"whole closure":   {ids: []int64{6}, want: 6},
+		"overlapping ids": {ids: []int64{4, 5}, want: 4},
+		"no ids":          {want: 0},
+		"unknown module":  {ids: []int64{42}, want: 0},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			deps, err := p.GetModuleDependencies(context.Background(), tc.ids...)
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+
+			if len(deps) != tc.want {
+				t.Fatalf("expected %v module dependencies, got: %v", tc.want, len(deps))
+			}
+
+			for _, d := range deps {
+				if d.Kind != storage.Required {
+					t.Fatalf("expected kind: %v, got: %v", storage.Required, d.Kind)
+				}
+			}
+		})
+	}
+}
+
+func TestGetModuleDependenciesKinds(t *testing.T) {
 	p := setup(t)
 
-	tests := []storage.Module{
-		{ID: 4},
+	// module 1 optionally depends on 5, which is followed and leads back to 1
+	// through 4, and recommends 6, which 2 conflicts with. Neither is followed
+	// to 6, so the dependencies of 6 are left out.
+	_, err := p.db.Exec(`
+INSERT INTO conf_module_dependency (dependent, dependee, kind) VALUES
+(1, 5, 'optional'),
+(1, 6, 'recommends'),
+(2, 6, 'conflicts')`)
+	if err != nil {
+		t.Fatal(err)
 	}
 
-	its, mods, err := p.GetItemsAndModules(tests...)
+	deps, err := p.GetModuleDependencies(context.Background(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := make(map[[2]int64]string)
+	for _, d := range deps {
+		got[[2]int64{d.Dependent, d.Dependee}] = d.Kind
+	}
+
+	want := map[[2]int64]string{
+		{1, 5}: storage.Optional,
+		{1, 6}: storage.Recommends,
+		{2, 6}: storage.Conflicts,
+		{5, 4}: storage.Required,
+		{4, 1}: storage.Required,
+		{4, 2}: storage.Required,
+		{4, 3}: storage.Required,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected dependencies: %v, got: %v", want, got)
+	}
+	for k, kind := range want {
+		if got[k] != kind {
+			t.Fatalf("expected %v to be %v, got: %q", k, kind, got[k])
+		}
+	}
+}
+
+func TestGetModules(t *testing.T) {
+	p := setup(t)
+
+	mods, err := p.GetModules(context.Background(), 1, 3, 42)
 	if err != nil {
 		t.Fatal(err.Error())
 	}
 
-	if len(its) == 0 && len(mods) == 0 {
-		t.Fatalf("expected non empty data, got: items: %v and modules: %v", its, mods)
+	if len(mods) != 2 || mods[0].ID != 1 || mods[1].ID != 3 {
+		t.Fatalf("expected modules 1 and 3, got: %v", mods)
+	}
+
+	if mods[0].Value != "A" || mods[0].Labels["release"] != "2026.10" {
+		t.Fatalf("expected module A to have label release=2026.10, got: %v", mods[0])
+	}
+}
+
+func TestSelectModules(t *testing.T) {
+	p := setup(t)
+
+	tt := map[string]struct {
+		selector string
+		want     []int64
+	}{
+		"everything":     {selector: "", want: []int64{1, 2, 3, 4, 5, 6}},
+		"exists":         {selector: "release", want: []int64{1, 2, 4}},
+		"does not exist": {selector: "!release", want: []int64{3, 5, 6}},
+		"equals":         {selector: "release=2026.10", want: []int64{1, 2}},
+		"not equals":     {selector: "release!=2026.10", want: []int64{3, 4, 5, 6}},
+		"in":             {selector: "release in (2026.10, 2026.11)", want: []int64{1, 2, 4}},
+		"not in":         {selector: "release notin (2026.10)", want: []int64{3, 4, 5, 6}},
+		"all of them":    {selector: "release,release!=2026.11", want: []int64{1, 2}},
+		"none":           {selector: "release=2027.01", want: nil},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			sel, err := labels.Parse(tc.selector)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			mods, err := p.SelectModules(context.Background(), sel)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got []int64
+			for _, m := range mods {
+				if !sel.Matches(m.Labels) {
+					t.Fatalf("expected module %v to be matched by %q, got labels: %v", m.ID, tc.selector, m.Labels)
+				}
+				got = append(got, m.ID)
+			}
+
+			if fmt.Sprint(got) != fmt.Sprint(tc.want) {
+				t.Fatalf("expected modules: %v, got: %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestGetRelease(t *testing.T) {
+	p := setup(t)
+
+	r, err := p.GetRelease(context.Background(), "2026.10-prod")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if r == nil || len(r.Modules) != 1 || r.Modules[0] != 1 {
+		t.Fatalf("expected release of module 1, got: %v", r)
+	}
+
+	if len(r.Closure) != 1 || len(r.Closure[0].Items) != 1 {
+		t.Fatalf("expected one module with one item, got: %v", r.Closure)
+	}
+
+	if it := r.Closure[0].Items[0]; it.Value != "tax_income_window" || it.Version != "1.0.0" {
+		t.Fatalf("expected tax_income_window at 1.0.0, got: %v", it)
+	}
+
+	r, err = p.GetRelease(context.Background(), "nope")
+	if err != nil || r != nil {
+		t.Fatalf("expected no release and no error, got: %v, %v", r, err)
+	}
+}
+
+func TestGetEnvironmentPromotion(t *testing.T) {
+	p := setup(t)
+
+	pr, err := p.GetEnvironmentPromotion(context.Background(), "prod")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if pr == nil || pr.Release != "2026.10-prod" || pr.PromotedAt == nil {
+		t.Fatalf("expected release 2026.10-prod in prod, got: %v", pr)
+	}
+
+	// a pending promotion does not put the release in the environment
+	pr, err = p.GetEnvironmentPromotion(context.Background(), "staging")
+	if err != nil || pr != nil {
+		t.Fatalf("expected no promotion and no error, got: %v, %v", pr, err)
 	}
 }
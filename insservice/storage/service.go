@@ -1,17 +1,34 @@
 package storage
 
-import "fmt"
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Glorforidor/conmansys/labels"
+	"github.com/Glorforidor/conmansys/resolve"
+)
 
 type Service interface {
-	GetItems(modules ...Module) ([]*Item, error)
-	GetItemsAndModules(modules ...Module) ([]*Item, []*Module, error)
+	GetModules(ctx context.Context, ids ...int64) ([]*Module, error)
+	SelectModules(ctx context.Context, sel labels.Selector) ([]*Module, error)
+	GetModuleDependencies(ctx context.Context, ids ...int64) ([]*ModuleDependency, error)
+	GetModuleItems(ctx context.Context, ids ...int64) (map[int64][]*Item, error)
+	GetRelease(ctx context.Context, name string) (*Release, error)
+	GetEnvironmentPromotion(ctx context.Context, env string) (*Promotion, error)
 }
 
+// Mask replaces the value of secret items wherever they are reported instead
+// of rendered into an insfile.
+const Mask = "********"
+
 type Item struct {
 	ID      int64  `json:"id,omitempty"`
 	Value   string `json:"value,omitempty"`
 	Type    string `json:"type,omitempty"`
 	Version string `json:"version,omitempty"`
+	// Secret items have their value decrypted, which only an insfile may show.
+	Secret bool `json:"-"`
 }
 
 func (i *Item) String() string {
@@ -22,9 +39,10 @@ func (i *Item) String() string {
 }
 
 type Module struct {
-	ID      int64  `json:"id"`
-	Value   string `json:"value,omitempty"`
-	Version string `json:"version,omitempty"`
+	ID      int64             `json:"id"`
+	Value   string            `json:"value,omitempty"`
+	Version string            `json:"version,omitempty"`
+	Labels  map[string]string `json:"labels,omitempty"`
 }
 
 func (m *Module) String() string {
@@ -33,3 +51,48 @@ func (m *Module) String() string {
 		m.ID, m.Value, m.Version,
 	)
 }
+
+// The kinds of module dependencies, see the resolve module for what they mean.
+const (
+	Required   = resolve.Required
+	Optional   = resolve.Optional
+	Conflicts  = resolve.Conflicts
+	Recommends = resolve.Recommends
+)
+
+type ModuleDependency = resolve.Dependency
+
+// Release is a named set of modules frozen by the confservice together with
+// the modules and items of their resolved closure.
+type Release struct {
+	Name      string           `json:"name"`
+	Modules   []int64          `json:"modules"`
+	Optional  bool             `json:"optional"`
+	Closure   []*ReleaseModule `json:"closure"`
+	CreatedAt time.Time        `json:"created_at"`
+}
+
+// ReleaseModule is a module of the closure of a release with its items.
+type ReleaseModule struct {
+	ID      int64   `json:"id"`
+	Value   string  `json:"value"`
+	Version string  `json:"version"`
+	Depth   int     `json:"depth"`
+	From    int64   `json:"from"`
+	Kind    string  `json:"kind"`
+	Items   []*Item `json:"items"`
+}
+
+// Promotion is the promotion of a release to an environment done by the
+// confservice. The release in an environment is the one of the promotion which
+// was promoted last.
+type Promotion struct {
+	ID          int64      `json:"id"`
+	Release     string     `json:"release"`
+	Environment string     `json:"environment"`
+	Status      string     `json:"status"`
+	RequestedBy string     `json:"requested_by"`
+	RequestedAt time.Time  `json:"requested_at"`
+	ApprovedBy  string     `json:"approved_by,omitempty"`
+	PromotedAt  *time.Time `json:"promoted_at,omitempty"`
+}
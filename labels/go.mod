@@ -0,0 +1,3 @@
+module github.com/Glorforidor/conmansys/labels
+
+go 1.21
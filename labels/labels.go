@@ -0,0 +1,237 @@
+// Package labels parses label selectors and matches them against the labels of
+// items and modules. The confservice and the insservice select with it, so a
+// selector selects the same items and modules in both.
+//
+// A selector is a comma separated list of requirements which all must be met.
+// The requirements are written like Kubernetes label selectors:
+//
+//	key              the label is set
+//	!key             the label is not set
+//	key=value        the label is set to value, key==value is the same
+//	key!=value       the label is not set or set to something else than value
+//	key in (a,b)     the label is set to a or b
+//	key notin (a,b)  the label is not set or set to something else than a and b
+package labels
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	keyRegexp   = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9_./-]{0,61}[A-Za-z0-9])?$`)
+	valueRegexp = regexp.MustCompile(`^([A-Za-z0-9]([A-Za-z0-9_.-]{0,61}[A-Za-z0-9])?)?$`)
+)
+
+// ValidKey reports whether key can be used as a label key.
+func ValidKey(key string) bool {
+	return keyRegexp.MatchString(key)
+}
+
+// ValidValue reports whether value can be used as a label value. The empty
+// string is a valid value.
+func ValidValue(value string) bool {
+	return valueRegexp.MatchString(value)
+}
+
+// Operator is the operator of a requirement.
+type Operator string
+
+// The operators of the requirements.
+const (
+	Exists       Operator = "exists"
+	DoesNotExist Operator = "!"
+	Equals       Operator = "="
+	NotEquals    Operator = "!="
+	In           Operator = "in"
+	NotIn        Operator = "notin"
+)
+
+// Requirement is a requirement of a selector on the label with the key. The
+// values are the one value of = and != and the values of in and notin.
+type Requirement struct {
+	Key    string
+	Op     Operator
+	Values []string
+}
+
+func (r Requirement) matches(labels map[string]string) bool {
+	v, ok := labels[r.Key]
+
+	switch r.Op {
+	case Exists:
+		return ok
+	case DoesNotExist:
+		return !ok
+	case Equals, In:
+		return ok && contains(r.Values, v)
+	case NotEquals, NotIn:
+		return !ok || !contains(r.Values, v)
+	}
+
+	return false
+}
+
+func (r Requirement) String() string {
+	switch r.Op {
+	case Exists:
+		return r.Key
+	case DoesNotExist:
+		return "!" + r.Key
+	case Equals, NotEquals:
+		return r.Key + string(r.Op) + r.Values[0]
+	}
+
+	return fmt.Sprintf("%v %v (%v)", r.Key, r.Op, strings.Join(r.Values, ","))
+}
+
+func contains(values []string, v string) bool {
+	for _, vv := range values {
+		if vv == v {
+			return true
+		}
+	}
+	return false
+}
+
+// Selector selects labels which meet all of its requirements. The zero
+// Selector selects everything.
+type Selector struct {
+	requirements []Requirement
+}
+
+// Matches reports whether the labels meet every requirement of the selector.
+func (s Selector) Matches(labels map[string]string) bool {
+	for _, r := range s.requirements {
+		if !r.matches(labels) {
+			return false
+		}
+	}
+	return true
+}
+
+// Requirements returns the requirements of the selector, sorted by their key.
+// They let a store select in its queries what Matches would select.
+func (s Selector) Requirements() []Requirement {
+	return append([]Requirement(nil), s.requirements...)
+}
+
+// Empty reports whether the selector has no requirements and therefore
+// selects everything.
+func (s Selector) Empty() bool {
+	return len(s.requirements) == 0
+}
+
+func (s Selector) String() string {
+	rs := make([]string, len(s.requirements))
+	for i, r := range s.requirements {
+		rs[i] = r.String()
+	}
+	return strings.Join(rs, ",")
+}
+
+// Parse parses a selector. The empty string is parsed to a Selector which
+// selects everything.
+func Parse(selector string) (Selector, error) {
+	var s Selector
+
+	for _, part := range split(selector) {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			return Selector{}, fmt.Errorf("selector %q has an empty requirement", selector)
+		}
+
+		r, err := parseRequirement(part)
+		if err != nil {
+			return Selector{}, err
+		}
+
+		s.requirements = append(s.requirements, r)
+	}
+
+	// sort to give a stable string representation
+	sort.SliceStable(s.requirements, func(i, j int) bool {
+		return s.requirements[i].Key < s.requirements[j].Key
+	})
+
+	return s, nil
+}
+
+// split splits the selector on the commas which are not inside parentheses.
+func split(selector string) []string {
+	if strings.TrimSpace(selector) == "" {
+		return nil
+	}
+
+	var parts []string
+	depth, start := 0, 0
+	for i, c := range selector {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, selector[start:i])
+				start = i + 1
+			}
+		}
+	}
+
+	return append(parts, selector[start:])
+}
+
+func parseRequirement(s string) (Requirement, error) {
+	if strings.HasPrefix(s, "!") {
+		return newRequirement(strings.TrimSpace(s[1:]), DoesNotExist, nil)
+	}
+
+	for _, op := range []string{"!=", "==", "="} {
+		if i := strings.Index(s, op); i >= 0 {
+			o := Equals
+			if op == "!=" {
+				o = NotEquals
+			}
+			key := strings.TrimSpace(s[:i])
+			value := strings.TrimSpace(s[i+len(op):])
+			return newRequirement(key, o, []string{value})
+		}
+	}
+
+	if i := strings.Index(s, "("); i >= 0 {
+		if !strings.HasSuffix(s, ")") {
+			return Requirement{}, fmt.Errorf("requirement %q is missing ')'", s)
+		}
+
+		fields := strings.Fields(s[:i])
+		if len(fields) != 2 || (fields[1] != string(In) && fields[1] != string(NotIn)) {
+			return Requirement{}, fmt.Errorf("requirement %q must be of the form: key in (values)", s)
+		}
+
+		var values []string
+		for _, v := range strings.Split(s[i+1:len(s)-1], ",") {
+			values = append(values, strings.TrimSpace(v))
+		}
+
+		return newRequirement(fields[0], Operator(fields[1]), values)
+	}
+
+	return newRequirement(s, Exists, nil)
+}
+
+func newRequirement(key string, op Operator, values []string) (Requirement, error) {
+	if !ValidKey(key) {
+		return Requirement{}, fmt.Errorf("invalid label key %q", key)
+	}
+
+	for _, v := range values {
+		if !ValidValue(v) {
+			return Requirement{}, fmt.Errorf("invalid label value %q for key %q", v, key)
+		}
+	}
+
+	return Requirement{Key: key, Op: op, Values: values}, nil
+}
@@ -0,0 +1,114 @@
+package labels
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	tt := map[string]struct {
+		selector string
+		want     string
+		err      bool
+	}{
+		"empty":          {selector: "", want: ""},
+		"exists":         {selector: "team", want: "team"},
+		"does not exist": {selector: "!team", want: "!team"},
+		"equals":         {selector: "team=billing", want: "team=billing"},
+		"double equals":  {selector: "team==billing", want: "team=billing"},
+		"not equals":     {selector: "tier != experimental", want: "tier!=experimental"},
+		"in":             {selector: "tier in (critical, high)", want: "tier in (critical,high)"},
+		"notin":          {selector: "tier notin (low)", want: "tier notin (low)"},
+		"multiple": {
+			selector: "team=billing,tier!=experimental,release in (2026.10,2026.11)",
+			want:     "release in (2026.10,2026.11),team=billing,tier!=experimental",
+		},
+		"empty requirement": {selector: "team=billing,", err: true},
+		"invalid key":       {selector: "te am=billing", err: true},
+		"invalid value":     {selector: "team=bil ling", err: true},
+		"missing paren":     {selector: "tier in (a,b", err: true},
+		"unknown operator":  {selector: "tier within (a)", err: true},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			s, err := Parse(tc.selector)
+			if err != nil {
+				if !tc.err {
+					t.Fatalf("expected no error, got: %v", err)
+				}
+				return
+			}
+			if tc.err {
+				t.Fatalf("expected an error, got: %v", s)
+			}
+
+			if s.String() != tc.want {
+				t.Fatalf("expected: %q, got: %q", tc.want, s.String())
+			}
+		})
+	}
+}
+
+func TestMatches(t *testing.T) {
+	labels := map[string]string{
+		"team":    "billing",
+		"tier":    "critical",
+		"release": "2026.10",
+	}
+
+	tt := map[string]struct {
+		selector string
+		want     bool
+	}{
+		"everything":          {selector: "", want: true},
+		"exists":              {selector: "team", want: true},
+		"missing":             {selector: "owner", want: false},
+		"does not exist":      {selector: "!owner", want: true},
+		"exists not wanted":   {selector: "!team", want: false},
+		"equals":              {selector: "team=billing", want: true},
+		"equals other":        {selector: "team=payments", want: false},
+		"not equals":          {selector: "tier!=experimental", want: true},
+		"not equals missing":  {selector: "owner!=bob", want: true},
+		"not equals same":     {selector: "tier!=critical", want: false},
+		"in":                  {selector: "release in (2026.09,2026.10)", want: true},
+		"not in":              {selector: "release notin (2026.09,2026.10)", want: false},
+		"not in missing":      {selector: "owner notin (bob)", want: true},
+		"all requirements":    {selector: "team=billing,tier!=experimental", want: true},
+		"one requirement off": {selector: "team=billing,tier=low", want: false},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			s, err := Parse(tc.selector)
+			if err != nil {
+				t.Fatalf("could not parse selector: %v", err)
+			}
+
+			if got := s.Matches(labels); got != tc.want {
+				t.Fatalf("expected: %v, got: %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestRequirements(t *testing.T) {
+	sel, err := Parse("tier notin (low), team=billing")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rs := sel.Requirements()
+	if len(rs) != 2 {
+		t.Fatalf("expected 2 requirements, got: %v", rs)
+	}
+	if r := rs[0]; r.Key != "team" || r.Op != Equals || len(r.Values) != 1 || r.Values[0] != "billing" {
+		t.Fatalf("expected team=billing first, got: %v", r)
+	}
+	if r := rs[1]; r.Key != "tier" || r.Op != NotIn || len(r.Values) != 1 || r.Values[0] != "low" {
+		t.Fatalf("expected tier notin (low) second, got: %v", r)
+	}
+
+	// the requirements are a copy
+	rs[0].Key = "owner"
+	if sel.String() != "team=billing,tier notin (low)" {
+		t.Fatalf("expected the selector to be left alone, got: %v", sel)
+	}
+}
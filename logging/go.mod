@@ -0,0 +1,7 @@
+module github.com/Glorforidor/conmansys/logging
+
+go 1.21
+
+require go.opentelemetry.io/otel/trace v1.6.3
+
+require go.opentelemetry.io/otel v1.6.3 // indirect
@@ -0,0 +1,194 @@
+// Package logging writes the logs of a service as JSON lines and carries the
+// request id of a request to every line logged for it. All services log with
+// it, so their lines have the same fields.
+//
+// The request id is taken from the X-Request-ID header, which the apigateway
+// sets on every request it proxies and the frontend on every request it sends
+// with Transport, so the lines of every service a request went through can be
+// found by its id.
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Header is the header of the request id.
+const Header = "X-Request-ID"
+
+// validID matches the request ids which are taken from a request. Any other id
+// is replaced, so a caller can not write whatever it likes into the logs.
+var validID = regexp.MustCompile(`^[A-Za-z0-9._-]{1,128}$`)
+
+// New returns a logger which writes JSON lines to w. Lines below the level,
+// which is debug, info, warn or error, are left out. The empty level is info.
+func New(w io.Writer, level string) (*slog.Logger, error) {
+	var l slog.Level
+	if level != "" {
+		if err := l.UnmarshalText([]byte(level)); err != nil {
+			return nil, fmt.Errorf("unknown log level %q, use debug, info, warn or error", level)
+		}
+	}
+
+	return slog.New(slog.NewJSONHandler(w, &slog.HandlerOptions{Level: l})), nil
+}
+
+// Setup makes a logger of the level writing to stderr the default, so the
+// lines of the log package are JSON lines as well.
+func Setup(level string) error {
+	l, err := New(os.Stderr, level)
+	if err != nil {
+		return err
+	}
+
+	slog.SetDefault(l)
+	return nil
+}
+
+// NewRequestID returns a random request id.
+func NewRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		// there is no id to correlate by then, but that must not fail the
+		// request
+		return "unknown"
+	}
+	return hex.EncodeToString(b)
+}
+
+type contextKey struct{}
+
+type requestContext struct {
+	id     string
+	logger *slog.Logger
+}
+
+// NewContext returns a context which carries the request id and a logger which
+// adds it, and the trace id of the span of ctx if there is one, to every line.
+// A new id is used if id is not a valid request id.
+func NewContext(ctx context.Context, id string) context.Context {
+	id = strings.TrimSpace(id)
+	if !validID.MatchString(id) {
+		id = NewRequestID()
+	}
+
+	logger := slog.Default().With("request_id", id)
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		logger = logger.With("trace_id", sc.TraceID().String())
+	}
+
+	return context.WithValue(ctx, contextKey{}, &requestContext{
+		id:     id,
+		logger: logger,
+	})
+}
+
+// RequestID returns the request id of ctx, or the empty string.
+func RequestID(ctx context.Context) string {
+	if rc, ok := ctx.Value(contextKey{}).(*requestContext); ok {
+		return rc.id
+	}
+	return ""
+}
+
+// FromContext returns the logger of the request of ctx, or the default logger
+// when ctx does not belong to a request.
+func FromContext(ctx context.Context) *slog.Logger {
+	if rc, ok := ctx.Value(contextKey{}).(*requestContext); ok {
+		return rc.logger
+	}
+	return slog.Default()
+}
+
+// statusWriter records the status of a response.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the flusher of the response.
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Middleware gives every request a request id, the one of its X-Request-ID
+// header or a new one, and echoes it in the response. The request carries the
+// id in its context and header, so a proxy forwards it, and a line is logged
+// for every response.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := NewContext(r.Context(), r.Header.Get(Header))
+		id := RequestID(ctx)
+		r = r.WithContext(ctx)
+		r.Header.Set(Header, id)
+		w.Header().Set(Header, id)
+
+		// a proxy changes the path of the request
+		path := r.URL.Path
+		start := time.Now()
+		sw := &statusWriter{ResponseWriter: w}
+		next.ServeHTTP(sw, r)
+		if sw.status == 0 {
+			sw.status = http.StatusOK
+		}
+
+		level := slog.LevelInfo
+		if sw.status >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+		FromContext(ctx).Log(ctx, level, "request",
+			"method", r.Method,
+			"path", path,
+			"status", sw.status,
+			"duration_ms", float64(time.Since(start).Microseconds())/1000,
+		)
+	})
+}
+
+// Transport sets the request id of the context of a request on it before it is
+// sent with Next, or http.DefaultTransport if Next is nil.
+type Transport struct {
+	Next http.RoundTripper
+}
+
+func (t Transport) RoundTrip(r *http.Request) (*http.Response, error) {
+	next := t.Next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	id := RequestID(r.Context())
+	if id == "" {
+		return next.RoundTrip(r)
+	}
+
+	// a RoundTripper must not change the request it is given
+	r = r.Clone(r.Context())
+	r.Header.Set(Header, id)
+	return next.RoundTrip(r)
+}
@@ -0,0 +1,130 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	for _, level := range []string{"", "debug", "INFO", "warn", "error"} {
+		if _, err := New(&bytes.Buffer{}, level); err != nil {
+			t.Fatalf("expected level %q to be accepted, got: %v", level, err)
+		}
+	}
+
+	if _, err := New(&bytes.Buffer{}, "loud"); err == nil {
+		t.Fatal("expected an unknown level to fail")
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	var buf bytes.Buffer
+	l, err := New(&buf, "info")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(l)
+
+	var seen string
+	h := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = r.Header.Get(Header)
+		if RequestID(r.Context()) != seen {
+			t.Fatalf("expected the context to carry id %q, got: %q", seen, RequestID(r.Context()))
+		}
+		FromContext(r.Context()).Error("request failed")
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+
+	tt := map[string]struct {
+		id   string
+		keep bool
+	}{
+		"accepted":  {id: "abc-123.DEF_4", keep: true},
+		"generated": {id: ""},
+		"invalid":   {id: "no spaces\nor newlines"},
+		"too long":  {id: strings.Repeat("a", 129)},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			buf.Reset()
+			req := httptest.NewRequest(http.MethodGet, "/items", nil)
+			if tc.id != "" {
+				req.Header.Set(Header, tc.id)
+			}
+
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			id := rec.Header().Get(Header)
+			if id != seen {
+				t.Fatalf("expected the echoed id %q to be the id of the request, got: %q", id, seen)
+			}
+			if tc.keep && id != tc.id {
+				t.Fatalf("expected id: %q, got: %q", tc.id, id)
+			}
+			if !tc.keep && (id == tc.id || !validID.MatchString(id)) {
+				t.Fatalf("expected a new id, got: %q", id)
+			}
+
+			lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+			if len(lines) != 2 {
+				t.Fatalf("expected 2 lines, got: %q", lines)
+			}
+			for _, line := range lines {
+				var m map[string]interface{}
+				if err := json.Unmarshal([]byte(line), &m); err != nil {
+					t.Fatalf("expected a JSON line, got: %q", line)
+				}
+				if m["request_id"] != id {
+					t.Fatalf("expected request_id %q, got: %v", id, m["request_id"])
+				}
+				if m["level"] != "ERROR" {
+					t.Fatalf("expected level ERROR, got: %v", m["level"])
+				}
+			}
+		})
+	}
+}
+
+func TestTransport(t *testing.T) {
+	var got string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get(Header)
+	}))
+	defer srv.Close()
+
+	client := &http.Client{Transport: Transport{}}
+	for name, ctx := range map[string]context.Context{
+		"request":    NewContext(context.Background(), "abc-123"),
+		"no request": context.Background(),
+	} {
+		t.Run(name, func(t *testing.T) {
+			got = ""
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			resp, err := client.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+
+			if got != RequestID(ctx) {
+				t.Fatalf("expected id: %q, got: %q", RequestID(ctx), got)
+			}
+			if req.Header.Get(Header) != "" {
+				t.Fatal("expected the request given to be left alone")
+			}
+		})
+	}
+}
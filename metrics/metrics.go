@@ -0,0 +1,68 @@
+// Package metrics counts the HTTP requests of a service and observes their
+// latency for Prometheus. All services are instrumented with it, so their
+// metrics have the same names and labels.
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	// Requests is the number of HTTP requests by route, method and status
+	// code.
+	Requests = Register(prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "http_requests_total",
+			Help: "Number of HTTP requests by route, method and status code.",
+		},
+		[]string{"route", "method", "code"},
+	)).(*prometheus.CounterVec)
+	// RequestDuration is the latency of HTTP requests by route and method.
+	RequestDuration = Register(prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "Latency of HTTP requests by route and method.",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"route", "method"},
+	)).(*prometheus.HistogramVec)
+)
+
+// Register registers the collector with the default registry and returns it.
+// When a collector of the same metric is registered already, like when the
+// handlers of a service are made several times in tests, the collector
+// registered first is returned instead.
+func Register(c prometheus.Collector) prometheus.Collector {
+	if err := prometheus.Register(c); err != nil {
+		if are, ok := err.(prometheus.AlreadyRegisteredError); ok {
+			return are.ExistingCollector
+		}
+		panic(err)
+	}
+	return c
+}
+
+// Instrument is a middleware which counts the requests and observes their
+// latency. The requests are labelled by the template of the matched route,
+// e.g. /items/{id:[0-9]+}, so the number of series does not grow with the ids
+// asked for.
+func Instrument(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := "unknown"
+		if cur := mux.CurrentRoute(r); cur != nil {
+			if tmpl, err := cur.GetPathTemplate(); err == nil {
+				route = tmpl
+			}
+		}
+
+		labels := prometheus.Labels{"route": route}
+		promhttp.InstrumentHandlerDuration(
+			RequestDuration.MustCurryWith(labels),
+			promhttp.InstrumentHandlerCounter(Requests.MustCurryWith(labels), next),
+		).ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,51 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestInstrument(t *testing.T) {
+	r := mux.NewRouter()
+	r.Use(Instrument)
+	r.HandleFunc("/items/{id:[0-9]+}", func(w http.ResponseWriter, r *http.Request) {})
+	r.HandleFunc("/fail", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	route := "/items/{id:[0-9]+}"
+	ok := Requests.WithLabelValues(route, "get", "200")
+	failed := Requests.WithLabelValues("/fail", "post", "500")
+	okBefore, failedBefore := testutil.ToFloat64(ok), testutil.ToFloat64(failed)
+
+	for _, req := range []*http.Request{
+		httptest.NewRequest(http.MethodGet, "/items/1", nil),
+		httptest.NewRequest(http.MethodGet, "/items/2", nil),
+		httptest.NewRequest(http.MethodPost, "/fail", nil),
+	} {
+		r.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	if got := testutil.ToFloat64(ok) - okBefore; got != 2 {
+		t.Errorf("expected 2 requests to %v, got: %v", route, got)
+	}
+	if got := testutil.ToFloat64(failed) - failedBefore; got != 1 {
+		t.Errorf("expected 1 failed request to /fail, got: %v", got)
+	}
+	if got := testutil.ToFloat64(Requests.WithLabelValues("/items/1", "get", "200")); got != 0 {
+		t.Errorf("expected the requests to be labelled by the route template, got: %v", got)
+	}
+}
+
+func TestRegister(t *testing.T) {
+	opts := prometheus.CounterOpts{Name: "test_total", Help: "Number of tests."}
+	first := Register(prometheus.NewCounter(opts))
+	if again := Register(prometheus.NewCounter(opts)); again != first {
+		t.Fatal("expected the collector registered first to be returned")
+	}
+}
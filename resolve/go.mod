@@ -0,0 +1,3 @@
+module github.com/Glorforidor/conmansys/resolve
+
+go 1.21
@@ -0,0 +1,205 @@
+// Package resolve computes which modules are part of an insfile by following
+// the module dependencies from the requested modules.
+//
+// Required dependencies are always followed and optional dependencies only
+// when asked for. Recommended modules are reported but not included. Two
+// modules in a conflicts dependency may not both be part of the same insfile.
+//
+// Walk follows the dependencies of a module in either direction, for the graph
+// of a module and the modules affected by deleting it.
+//
+// The confservice freezes releases and the insservice renders insfiles with
+// the same resolver, so a release holds the modules its live insfile would.
+package resolve
+
+import (
+	"fmt"
+	"sort"
+)
+
+// The kinds of module dependencies.
+const (
+	// Required dependees are always part of the insfile of the dependent.
+	Required = "required"
+	// Optional dependees are only part of the insfile when asked for.
+	Optional = "optional"
+	// Conflicts makes the dependent and dependee mutually exclusive in an
+	// insfile.
+	Conflicts = "conflicts"
+	// Recommends reports the dependee but does not make it part of the
+	// insfile.
+	Recommends = "recommends"
+)
+
+// Dependency makes the dependent depend on the dependee in the way given by the
+// kind. A dependency without a kind is required.
+type Dependency struct {
+	Dependent int64  `json:"dependent"`
+	Dependee  int64  `json:"dependee"`
+	Kind      string `json:"kind"`
+}
+
+// Node is a module which is part of the insfile.
+type Node struct {
+	ID int64 `json:"id"`
+	// Depth is the number of dependencies followed from a requested module,
+	// which have depth 0.
+	Depth int `json:"depth"`
+	// From is the module the node was first reached from and 0 for the
+	// requested modules.
+	From int64 `json:"from,omitempty"`
+	// Kind is the kind of the dependency the node was first reached by.
+	Kind string `json:"kind,omitempty"`
+}
+
+// Exclusion explains why a module which is depended on is not part of the
+// insfile.
+type Exclusion struct {
+	ID     int64  `json:"id"`
+	From   int64  `json:"from"`
+	Kind   string `json:"kind"`
+	Reason string `json:"reason"`
+}
+
+// Conflict explains why two modules can not be part of the same insfile.
+type Conflict struct {
+	ID     int64  `json:"id"`
+	With   int64  `json:"with"`
+	Reason string `json:"reason"`
+}
+
+// Result is the outcome of resolving the modules of an insfile.
+type Result struct {
+	Modules   []*Node      `json:"modules"`
+	Excluded  []*Exclusion `json:"excluded"`
+	Conflicts []*Conflict  `json:"conflicts"`
+}
+
+// IDs returns the ids of the modules which are part of the insfile.
+func (r *Result) IDs() []int64 {
+	ids := make([]int64, len(r.Modules))
+	for i, n := range r.Modules {
+		ids[i] = n.ID
+	}
+	return ids
+}
+
+// Resolve follows the dependencies breadth first from the roots. Optional
+// dependencies are only followed if optional is true.
+func Resolve(deps []*Dependency, roots []int64, optional bool) *Result {
+	next := make(map[int64][]*Dependency)
+	for _, d := range deps {
+		next[d.Dependent] = append(next[d.Dependent], d)
+	}
+
+	nodes := make(map[int64]*Node)
+	var queue []int64
+	for _, id := range roots {
+		if _, ok := nodes[id]; ok {
+			continue
+		}
+		nodes[id] = &Node{ID: id}
+		queue = append(queue, id)
+	}
+
+	var excluded []*Exclusion
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+
+		for _, d := range next[id] {
+			switch kind(d) {
+			case Conflicts:
+				// checked when every module is known
+				continue
+			case Recommends:
+				excluded = append(excluded, &Exclusion{
+					ID: d.Dependee, From: id, Kind: Recommends,
+					Reason: fmt.Sprintf("recommended by module %v, recommended modules are not included", id),
+				})
+				continue
+			case Optional:
+				if !optional {
+					excluded = append(excluded, &Exclusion{
+						ID: d.Dependee, From: id, Kind: Optional,
+						Reason: fmt.Sprintf("optional dependency of module %v, ask for optional modules to include it", id),
+					})
+					continue
+				}
+			}
+
+			if _, ok := nodes[d.Dependee]; ok {
+				continue
+			}
+
+			nodes[d.Dependee] = &Node{
+				ID: d.Dependee, Depth: nodes[id].Depth + 1, From: id, Kind: kind(d),
+			}
+			queue = append(queue, d.Dependee)
+		}
+	}
+
+	res := &Result{
+		Modules:   []*Node{},
+		Excluded:  []*Exclusion{},
+		Conflicts: []*Conflict{},
+	}
+
+	for _, n := range nodes {
+		res.Modules = append(res.Modules, n)
+	}
+	sort.Slice(res.Modules, func(i, j int) bool {
+		if res.Modules[i].Depth != res.Modules[j].Depth {
+			return res.Modules[i].Depth < res.Modules[j].Depth
+		}
+		return res.Modules[i].ID < res.Modules[j].ID
+	})
+
+	// a module excluded on one path might still be included by another
+	seen := make(map[[2]int64]bool)
+	for _, e := range excluded {
+		key := [2]int64{e.ID, e.From}
+		if _, ok := nodes[e.ID]; ok || seen[key] {
+			continue
+		}
+		seen[key] = true
+		res.Excluded = append(res.Excluded, e)
+	}
+	sort.Slice(res.Excluded, func(i, j int) bool {
+		if res.Excluded[i].ID != res.Excluded[j].ID {
+			return res.Excluded[i].ID < res.Excluded[j].ID
+		}
+		return res.Excluded[i].From < res.Excluded[j].From
+	})
+
+	for _, d := range deps {
+		if kind(d) != Conflicts {
+			continue
+		}
+
+		_, ok1 := nodes[d.Dependent]
+		_, ok2 := nodes[d.Dependee]
+		if ok1 && ok2 {
+			res.Conflicts = append(res.Conflicts, &Conflict{
+				ID: d.Dependent, With: d.Dependee,
+				Reason: fmt.Sprintf("module %v conflicts with module %v", d.Dependent, d.Dependee),
+			})
+		}
+	}
+	sort.Slice(res.Conflicts, func(i, j int) bool {
+		if res.Conflicts[i].ID != res.Conflicts[j].ID {
+			return res.Conflicts[i].ID < res.Conflicts[j].ID
+		}
+		return res.Conflicts[i].With < res.Conflicts[j].With
+	})
+
+	return res
+}
+
+// kind returns the kind of the dependency, which is required if it is not set.
+func kind(d *Dependency) string {
+	if d.Kind == "" {
+		return Required
+	}
+	return d.Kind
+}
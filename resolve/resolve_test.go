@@ -0,0 +1,130 @@
+package resolve
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestResolve(t *testing.T) {
+	deps := []*Dependency{
+		{Dependent: 1, Dependee: 2},
+		{Dependent: 2, Dependee: 3, Kind: Required},
+		{Dependent: 1, Dependee: 4, Kind: Optional},
+		{Dependent: 4, Dependee: 5, Kind: Required},
+		{Dependent: 3, Dependee: 6, Kind: Recommends},
+		{Dependent: 5, Dependee: 7, Kind: Conflicts},
+		{Dependent: 3, Dependee: 1, Kind: Required}, // cycle
+		{Dependent: 8, Dependee: 6, Kind: Required},
+	}
+
+	tt := map[string]struct {
+		roots     []int64
+		optional  bool
+		modules   []int64
+		excluded  []int64
+		conflicts int
+	}{
+		"required": {
+			roots: []int64{1}, modules: []int64{1, 2, 3}, excluded: []int64{4, 6},
+		},
+		"optional": {
+			roots: []int64{1}, optional: true, modules: []int64{1, 2, 4, 3, 5}, excluded: []int64{6},
+		},
+		"recommended included by other root": {
+			roots: []int64{1, 8}, modules: []int64{1, 8, 2, 6, 3}, excluded: []int64{4},
+		},
+		"conflict": {
+			roots: []int64{1, 7}, optional: true, modules: []int64{1, 7, 2, 4, 3, 5}, excluded: []int64{6},
+			conflicts: 1,
+		},
+		"conflict not included": {
+			roots: []int64{1, 7}, modules: []int64{1, 7, 2, 3}, excluded: []int64{4, 6},
+		},
+		"unknown root": {
+			roots: []int64{9}, modules: []int64{9}, excluded: []int64{},
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			res := Resolve(deps, tc.roots, tc.optional)
+
+			if fmt.Sprint(res.IDs()) != fmt.Sprint(tc.modules) {
+				t.Fatalf("expected modules: %v, got: %v", tc.modules, res.IDs())
+			}
+
+			excluded := []int64{}
+			for _, e := range res.Excluded {
+				excluded = append(excluded, e.ID)
+			}
+			if fmt.Sprint(excluded) != fmt.Sprint(tc.excluded) {
+				t.Fatalf("expected excluded: %v, got: %v", tc.excluded, excluded)
+			}
+
+			if len(res.Conflicts) != tc.conflicts {
+				t.Fatalf("expected %v conflicts, got: %v", tc.conflicts, res.Conflicts)
+			}
+		})
+	}
+}
+
+func TestResolveDepth(t *testing.T) {
+	deps := []*Dependency{
+		{Dependent: 1, Dependee: 2},
+		{Dependent: 2, Dependee: 3},
+		{Dependent: 1, Dependee: 3},
+	}
+
+	res := Resolve(deps, []int64{1}, false)
+	for _, n := range res.Modules {
+		want := map[int64]int{1: 0, 2: 1, 3: 1}[n.ID]
+		if n.Depth != want {
+			t.Errorf("expected module %v at depth %v, got: %v", n.ID, want, n.Depth)
+		}
+	}
+	if res.Modules[2].From != 1 {
+		t.Errorf("expected module 3 to be reached from module 1, got: %v", res.Modules[2].From)
+	}
+}
+
+func TestWalk(t *testing.T) {
+	// 1 -> 2 -> 3 -> 4, 5 -> 3 and the cycle 4 -> 2, 5 conflicts 1 which is
+	// not followed
+	deps := []*Dependency{
+		{Dependent: 1, Dependee: 2},
+		{Dependent: 2, Dependee: 3},
+		{Dependent: 3, Dependee: 4},
+		{Dependent: 5, Dependee: 3},
+		{Dependent: 4, Dependee: 2},
+		{Dependent: 5, Dependee: 1, Kind: Conflicts},
+	}
+
+	tt := map[string]struct {
+		root      int64
+		direction string
+		depth     int
+		want      map[int64]int
+		edges     int
+	}{
+		"down":           {root: 1, direction: Down, want: map[int64]int{1: 0, 2: 1, 3: 2, 4: 3}, edges: 4},
+		"down depth 1":   {root: 1, direction: Down, depth: 1, want: map[int64]int{1: 0, 2: 1}, edges: 1},
+		"down from leaf": {root: 5, direction: Down, want: map[int64]int{5: 0, 3: 1, 4: 2, 2: 3}, edges: 4},
+		"up":             {root: 3, direction: Up, want: map[int64]int{3: 0, 2: 1, 5: 1, 1: 2, 4: 2}, edges: 5},
+		"up depth 1":     {root: 3, direction: Up, depth: 1, want: map[int64]int{3: 0, 2: 1, 5: 1}, edges: 2},
+		"up from root":   {root: 5, direction: Up, want: map[int64]int{5: 0}, edges: 0},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			depths, edges := Walk(deps, tc.root, tc.direction, tc.depth)
+
+			if fmt.Sprint(depths) != fmt.Sprint(tc.want) {
+				t.Fatalf("expected: %v, got: %v", tc.want, depths)
+			}
+
+			if len(edges) != tc.edges {
+				t.Fatalf("expected %v edges, got: %v", tc.edges, len(edges))
+			}
+		})
+	}
+}
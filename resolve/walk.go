@@ -0,0 +1,62 @@
+package resolve
+
+const (
+	// Down follows the dependencies from a dependent to its dependees, which
+	// are the modules the root needs.
+	Down = "down"
+	// Up follows the dependencies from a dependee to its dependents, which
+	// are the modules affected if the root changes.
+	Up = "up"
+)
+
+// Walk walks the dependencies breadth first from root in the given
+// direction. Conflicts are not dependencies and are not followed. It stops
+// after depth hops, a depth less than 1 walks the whole closure. It returns the
+// depth at which every reached module was first found, with the root at depth
+// 0, and the edges which were followed.
+func Walk(deps []*Dependency, root int64, direction string, depth int) (map[int64]int, []*Dependency) {
+	next := make(map[int64][]*Dependency)
+	for _, d := range deps {
+		if d.Kind == Conflicts {
+			continue
+		}
+
+		if direction == Up {
+			next[d.Dependee] = append(next[d.Dependee], d)
+		} else {
+			next[d.Dependent] = append(next[d.Dependent], d)
+		}
+	}
+
+	depths := map[int64]int{root: 0}
+	var edges []*Dependency
+	queue := []int64{root}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+
+		if depth > 0 && depths[id] >= depth {
+			continue
+		}
+
+		for _, d := range next[id] {
+			edges = append(edges, d)
+
+			n := d.Dependee
+			if direction == Up {
+				n = d.Dependent
+			}
+
+			// a module already found is closer to the root or as close, so
+			// there is no need to walk it again. This also stops cycles.
+			if _, ok := depths[n]; ok {
+				continue
+			}
+
+			depths[n] = depths[id] + 1
+			queue = append(queue, n)
+		}
+	}
+
+	return depths, edges
+}
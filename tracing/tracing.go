@@ -0,0 +1,74 @@
+// Package tracing sets up the OpenTelemetry tracing of a service. All services
+// are traced with it, so they propagate the trace context the same way.
+//
+// The trace context of a request is taken from and passed on in the W3C
+// traceparent and tracestate headers, so the spans of the frontend, the
+// apigateway and the services a request went through form a single trace.
+// Spans are exported with OTLP over HTTP, to the endpoint of the standard
+// OTEL_EXPORTER_OTLP_ENDPOINT variable, or written to stdout for local runs.
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+)
+
+// The exporters of Setup.
+const (
+	None   = "none"
+	OTLP   = "otlp"
+	Stdout = "stdout"
+)
+
+// Setup makes the service propagate the trace context of its requests and
+// export its spans with the exporter, which is otlp, stdout or none. The empty
+// exporter is none: the trace context is still passed on, but no spans are
+// recorded. The returned function exports the spans not exported yet and must
+// be called before the service exits.
+func Setup(ctx context.Context, service, exporter string) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	var exp sdktrace.SpanExporter
+	var err error
+	switch exporter {
+	case "", None:
+		return func(context.Context) error { return nil }, nil
+	case OTLP:
+		exp, err = otlptracehttp.New(ctx)
+	case Stdout:
+		exp, err = stdouttrace.New(stdouttrace.WithPrettyPrint())
+	default:
+		return nil, fmt.Errorf("unknown traces exporter %q, use otlp, stdout or none", exporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not create %v exporter: %v", exporter, err)
+	}
+
+	// OTEL_SERVICE_NAME and OTEL_RESOURCE_ATTRIBUTES override the name given
+	res, err := resource.New(ctx,
+		resource.WithAttributes(semconv.ServiceNameKey.String(service)),
+		resource.WithFromEnv(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("could not create resource: %v", err)
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exp),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(tp)
+
+	return tp.Shutdown, nil
+}
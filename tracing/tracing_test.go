@@ -0,0 +1,22 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+)
+
+func TestSetup(t *testing.T) {
+	for _, exporter := range []string{"", None, Stdout} {
+		shutdown, err := Setup(context.Background(), "service", exporter)
+		if err != nil {
+			t.Fatalf("expected exporter %q to be accepted, got: %v", exporter, err)
+		}
+		if err := shutdown(context.Background()); err != nil {
+			t.Fatalf("expected exporter %q to shut down, got: %v", exporter, err)
+		}
+	}
+
+	if _, err := Setup(context.Background(), "service", "zipkin"); err == nil {
+		t.Fatal("expected an unknown exporter to fail")
+	}
+}